@@ -0,0 +1,25 @@
+##@ Protobuf
+
+BUF_VERSION                := v1.57.2
+PROTOC_GEN_GO_VERSION      := v1.36.10
+PROTOC_GEN_GO_GRPC_VERSION := v1.5.1
+
+PROTO_BIN := $(CURDIR)/bin
+
+.PHONY: proto-tools
+proto-tools: ## Installs buf and the protoc plugins into ./bin.
+	@echo "====> $@"
+	GOBIN=$(PROTO_BIN) go install github.com/bufbuild/buf/cmd/buf@$(BUF_VERSION)
+	GOBIN=$(PROTO_BIN) go install google.golang.org/protobuf/cmd/protoc-gen-go@$(PROTOC_GEN_GO_VERSION)
+	GOBIN=$(PROTO_BIN) go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@$(PROTOC_GEN_GO_GRPC_VERSION)
+
+.PHONY: generate-proto
+generate-proto: proto-tools ## Generates the Go code of the protobuf definitions in proto/.
+	@echo "====> $@"
+	PATH=$(PROTO_BIN):$$PATH $(PROTO_BIN)/buf generate
+
+.PHONY: check-proto
+check-proto: generate-proto ## Fails if the generated protobuf code is not up to date.
+	@echo "====> $@"
+	@git diff --exit-code -- proto/ || (echo "Generated code in proto/ is out of date, run make generate-proto" && exit 1)
+	@test -z "$$(git ls-files --others --exclude-standard proto/)" || (echo "Generated code in proto/ is not committed, run make generate-proto" && exit 1)
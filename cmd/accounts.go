@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+var accountNamesFile string
+
+func init() {
+	analyseCmd.Flags().StringVar(&accountNamesFile, "account-names", "", fmt.Sprintf("YAML file mapping AWS account IDs to names, e.g. of teams or cost centers, shown instead of the IDs when grouping by %s", accountDimension))
+}
+
+// readAccountNames reads a YAML file mapping account IDs to names, like
+// this:
+//
+//	"123456789012": Platform
+//	"210987654321": Data science
+func readAccountNames(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names map[string]string
+	if err := yaml.NewDecoder(f).Decode(&names); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	for id, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("account %s has an empty name", id)
+		}
+	}
+	return names, nil
+}
+
+// withAccountNames returns dimensions with the account dimension showing
+// the names of accounts instead of their IDs. Accounts with the same name
+// are grouped together, and accounts without a name keep their ID.
+func withAccountNames(dimensions []Dimension, names map[string]string) []Dimension {
+	result := append([]Dimension{}, dimensions...)
+	for i, d := range result {
+		if d.Name != accountDimension {
+			continue
+		}
+		id := d.Value
+		result[i].Value = func(r ReportRow) string {
+			if name, exists := names[id(r)]; exists {
+				return name
+			}
+			return id(r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_readAccountNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "quoted IDs",
+			content: "\"123456789012\": Platform\n\"012345678901\": Data science\n",
+			want:    map[string]string{"123456789012": "Platform", "012345678901": "Data science"},
+		},
+		{
+			name:    "unquoted IDs",
+			content: "123456789012: Platform\n",
+			want:    map[string]string{"123456789012": "Platform"},
+		},
+		{name: "empty file", content: "", want: nil},
+		{name: "empty name", content: "\"123456789012\": \"\"\n", wantErr: true},
+		{name: "not a mapping", content: "- Platform\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "accounts.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readAccountNames(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAccountNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readAccountNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withAccountNames(t *testing.T) {
+	dimensions, err := parseGroupBy("account,region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]string{"111111111111": "Platform", "222222222222": "Platform"}
+
+	got := withAccountNames(dimensions, names)
+
+	for id, want := range map[string]string{"111111111111": "Platform", "222222222222": "Platform", "333333333333": "333333333333"} {
+		if label := got[0].Value(ReportRow{UsageAccountID: id}); label != want {
+			t.Errorf("account label of %s = %q, want %q", id, label, want)
+		}
+	}
+	if label := got[1].Value(ReportRow{UsageAccountID: "111111111111", Region: "eu-west-1"}); label != "eu-west-1" {
+		t.Errorf("region label = %q, want eu-west-1", label)
+	}
+	if label := dimensions[0].Value(ReportRow{UsageAccountID: "111111111111"}); label != "111111111111" {
+		t.Error("withAccountNames() changed its input")
+	}
+}
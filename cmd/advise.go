@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var adviseCmd = &cobra.Command{
+	Use:   "advise",
+	Short: "Suggest the lowest-carbon hours to run batch workloads",
+	Long: `Suggest the lowest-carbon hours to run batch workloads.
+
+Requests the carbon intensity forecast of the grid of an AWS region from an
+hourly intensity provider, and lists the start times within the next
+--window at which a workload running for --duration has the lowest average
+carbon intensity, along with the saving compared to starting right away.
+The suggested time slots don't overlap.
+
+Example:
+
+  cloud-carbon advise --region eu-central-1 --duration 3h --electricity-maps-token TOKEN
+`,
+	RunE: advise,
+	Args: cobra.NoArgs,
+}
+
+var (
+	adviseRegion       string
+	adviseDuration     time.Duration
+	adviseWindow       time.Duration
+	adviseCount        int
+	adviseOutputFormat string
+)
+
+// maxAdviseWindow is the longest forecast window, as forecasts get less
+// reliable further ahead.
+const maxAdviseWindow = 48 * time.Hour
+
+func init() {
+	adviseCmd.Flags().StringVar(&adviseRegion, "region", "", "AWS region the workload runs in")
+	adviseCmd.Flags().DurationVar(&adviseDuration, "duration", time.Hour, "Duration of the workload, rounded up to full hours")
+	adviseCmd.Flags().DurationVar(&adviseWindow, "window", 24*time.Hour, fmt.Sprintf("Time from now within which the workload has to finish, at most %s", maxAdviseWindow))
+	adviseCmd.Flags().IntVar(&adviseCount, "count", 3, "Number of time slots to suggest")
+	adviseCmd.Flags().StringVar(&intensityProvider, "intensity-provider", "", fmt.Sprintf("Provider of the carbon intensity forecast, one of: %s", strings.Join(intensityProviders, ", ")))
+	adviseCmd.Flags().StringVar(&emapsToken, "electricity-maps-token", "", "Electricity Maps API key. Implies --intensity-provider electricitymaps if no provider is given")
+	adviseCmd.Flags().StringVar(&wattTimeUsername, "watttime-username", "", "WattTime account user name, for --intensity-provider watttime")
+	adviseCmd.Flags().StringVar(&wattTimePassword, "watttime-password", "", "WattTime account password, for --intensity-provider watttime")
+	adviseCmd.Flags().StringVarP(&adviseOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	adviseCmd.MarkFlagRequired("region")
+}
+
+// Advice holds the suggested time slots for running a workload.
+type Advice struct {
+	Region        string  `json:"region"`
+	DurationHours float64 `json:"duration_hours"`
+
+	// ImmediateCarbonIntensity is the average carbon intensity when
+	// starting the workload in the current hour.
+	ImmediateCarbonIntensity float64 `json:"immediate_carbon_intensity"`
+
+	// Slots are the suggested time slots, lowest carbon intensity first.
+	Slots []TimeSlot `json:"slots"`
+}
+
+// TimeSlot is a time range for running a workload.
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// CarbonIntensity is the average forecast carbon intensity during the
+	// slot, in grams of CO2 per kilowatt hour.
+	CarbonIntensity float64 `json:"carbon_intensity"`
+
+	// SavingPercent is the reduction of the carbon intensity compared to
+	// starting the workload in the current hour.
+	SavingPercent float64 `json:"saving_percent"`
+}
+
+func advise(cmd *cobra.Command, args []string) error {
+	if adviseOutputFormat != outputTable && adviseOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", adviseOutputFormat, outputTable, outputJSON)
+	}
+	if adviseWindow <= 0 || adviseWindow > maxAdviseWindow {
+		return usageErrorf("invalid --window value %s, must be positive and at most %s", adviseWindow, maxAdviseWindow)
+	}
+	if adviseDuration <= 0 || adviseDuration > adviseWindow {
+		return usageErrorf("invalid --duration value %s, must be positive and at most --window", adviseDuration)
+	}
+	if adviseCount < 1 {
+		return usageErrorf("invalid --count value %d, must be at least 1", adviseCount)
+	}
+
+	if intensityProvider == "" && emapsToken != "" {
+		intensityProvider = intensityProviderElectricityMaps
+	}
+	if intensityProvider == "" {
+		return usageErrorf("an intensity provider is required, set --intensity-provider to one of: %s", strings.Join(intensityProviders, ", "))
+	}
+	provider, err := newIntensityProvider(intensityProvider)
+	if err != nil {
+		return usageErrorf("invalid --intensity-provider value: %s", err)
+	}
+	forecaster, ok := provider.(footprint.CarbonIntensityForecaster)
+	if !ok {
+		return usageErrorf("intensity provider %s does not forecast carbon intensity", intensityProvider)
+	}
+
+	a, err := adviseSlots(cmd.Context(), forecaster, adviseRegion, adviseDuration, adviseWindow, adviseCount)
+	if err != nil {
+		return exitErrorf(exitData, "could not suggest time slots: %w", err)
+	}
+
+	switch adviseOutputFormat {
+	case outputTable:
+		writeAdvice(os.Stdout, a)
+	case outputJSON:
+		err = writeJSON(os.Stdout, a)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// adviseSlots requests the forecast of a region for the given window and
+// returns up to count time slots of the given duration with the lowest
+// carbon intensity.
+func adviseSlots(ctx context.Context, forecaster footprint.CarbonIntensityForecaster, region string, duration, window time.Duration, count int) (Advice, error) {
+	hours := int(math.Ceil(duration.Hours()))
+	windowHours := int(math.Ceil(window.Hours()))
+	forecast, err := forecaster.CarbonIntensityForecast(ctx, region, windowHours)
+	if err != nil {
+		return Advice{}, err
+	}
+
+	slots := timeSlots(forecast, hours)
+	if len(slots) == 0 {
+		return Advice{}, fmt.Errorf("forecast of %d hours for region %s has no %d consecutive hours", len(forecast), region, hours)
+	}
+
+	return Advice{
+		Region:                   region,
+		DurationHours:            float64(hours),
+		ImmediateCarbonIntensity: slots[0].CarbonIntensity,
+		Slots:                    bestTimeSlots(slots, count),
+	}, nil
+}
+
+// timeSlots returns a time slot of the given number of hours for each hour
+// of a forecast a workload can start at, in chronological order. Slots
+// must consist of consecutive hours of the forecast. Savings are relative
+// to the first slot.
+func timeSlots(forecast []footprint.HourlyIntensity, hours int) []TimeSlot {
+	var slots []TimeSlot
+	for i := 0; i+hours <= len(forecast); i++ {
+		start := forecast[i].Hour
+		end := start.Add(time.Duration(hours) * time.Hour)
+		if !forecast[i+hours-1].Hour.Equal(end.Add(-time.Hour)) {
+			continue
+		}
+
+		var sum float64
+		for _, h := range forecast[i : i+hours] {
+			sum += h.CarbonIntensity
+		}
+		slots = append(slots, TimeSlot{Start: start, End: end, CarbonIntensity: sum / float64(hours)})
+	}
+
+	if len(slots) > 0 && slots[0].CarbonIntensity > 0 {
+		for i := range slots {
+			slots[i].SavingPercent = 100 * (1 - slots[i].CarbonIntensity/slots[0].CarbonIntensity)
+		}
+	}
+	return slots
+}
+
+// bestTimeSlots returns up to count slots with the lowest carbon intensity
+// that don't overlap, lowest first. Of slots with the same intensity, the
+// earliest is preferred.
+func bestTimeSlots(slots []TimeSlot, count int) []TimeSlot {
+	ranked := append([]TimeSlot(nil), slots...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].CarbonIntensity < ranked[j].CarbonIntensity
+	})
+
+	var best []TimeSlot
+	for _, slot := range ranked {
+		if len(best) == count {
+			break
+		}
+		overlaps := false
+		for _, b := range best {
+			if slot.Start.Before(b.End) && b.Start.Before(slot.End) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			best = append(best, slot)
+		}
+	}
+	return best
+}
+
+// writeAdvice writes the suggested time slots as a table.
+func writeAdvice(w io.Writer, a Advice) {
+	fmt.Fprintf(w, "Starting a %g hour workload in %s now: %.0f gCO2e/kWh on average.\n\n", a.DurationHours, a.Region, a.ImmediateCarbonIntensity)
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Rank", "Start (UTC)", "End (UTC)", "Carbon intensity", "Saving"})
+	for i, slot := range a.Slots {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			slot.Start.UTC().Format(periodLayouts[periodHour]),
+			slot.End.UTC().Format(periodLayouts[periodHour]),
+			fmt.Sprintf("%.0f gCO2e/kWh", slot.CarbonIntensity),
+			fmt.Sprintf("%.0f%%", slot.SavingPercent),
+		})
+	}
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
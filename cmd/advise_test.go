@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// staticForecast is a forecaster returning the same forecast for all
+// regions.
+type staticForecast []footprint.HourlyIntensity
+
+func (f staticForecast) CarbonIntensityForecast(ctx context.Context, regionCode string, hours int) ([]footprint.HourlyIntensity, error) {
+	if len(f) == 0 {
+		return nil, errors.New("no forecast")
+	}
+	return f[:min(hours, len(f))], nil
+}
+
+// testForecast returns a forecast starting at midnight with the given
+// carbon intensity per hour.
+func testForecast(values ...float64) staticForecast {
+	start := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	var forecast staticForecast
+	for i, v := range values {
+		forecast = append(forecast, footprint.HourlyIntensity{Hour: start.Add(time.Duration(i) * time.Hour), CarbonIntensity: v})
+	}
+	return forecast
+}
+
+func Test_timeSlots(t *testing.T) {
+	forecast := testForecast(400, 300, 100, 200, 500)
+	// A gap after the fourth hour.
+	forecast[4].Hour = forecast[4].Hour.Add(time.Hour)
+
+	slots := timeSlots(forecast, 2)
+	if len(slots) != 3 {
+		t.Fatalf("timeSlots() = %v, want 3 slots of consecutive hours", slots)
+	}
+	want := []float64{350, 200, 150}
+	for i, slot := range slots {
+		if slot.CarbonIntensity != want[i] || !slot.End.Equal(slot.Start.Add(2*time.Hour)) {
+			t.Errorf("slot %d = %+v, want carbon intensity %g for 2 hours", i, slot, want[i])
+		}
+	}
+	if saving := slots[2].SavingPercent; math.Abs(saving-100*(1-150.0/350)) > 1e-9 {
+		t.Errorf("saving = %g, want relative to the first slot", saving)
+	}
+}
+
+func Test_bestTimeSlots(t *testing.T) {
+	slots := timeSlots(testForecast(400, 300, 100, 100, 200, 500, 50), 2)
+	best := bestTimeSlots(slots, 3)
+
+	var starts []int
+	for _, slot := range best {
+		starts = append(starts, slot.Start.Hour())
+	}
+	// The slot starting at 2 is best and the ones at 1 and 3 overlap it,
+	// so the slots at 5 and 0 follow.
+	if want := []int{2, 5, 0}; !slices.Equal(starts, want) {
+		t.Errorf("bestTimeSlots() start at hours %v, want %v", starts, want)
+	}
+}
+
+func Test_adviseSlots(t *testing.T) {
+	a, err := adviseSlots(context.Background(), testForecast(400, 300, 100, 200), "eu-central-1", 90*time.Minute, 3*time.Hour, 1)
+	if err != nil {
+		t.Fatalf("adviseSlots() error = %v", err)
+	}
+	if a.DurationHours != 2 || a.ImmediateCarbonIntensity != 350 || len(a.Slots) != 1 || a.Slots[0].CarbonIntensity != 200 {
+		t.Errorf("adviseSlots() = %+v, want the 2 hour slot starting at 1 within 3 hours", a)
+	}
+
+	var b strings.Builder
+	writeAdvice(&b, a)
+	if out := b.String(); !strings.Contains(out, "now: 350 gCO2e/kWh") || !strings.Contains(out, "2022-08-01 01:00 | 2022-08-01 03:00 | 200 gCO2e/kWh    | 43%") {
+		t.Errorf("writeAdvice() = %s", out)
+	}
+
+	if _, err := adviseSlots(context.Background(), testForecast(400), "eu-central-1", 2*time.Hour, 24*time.Hour, 1); err == nil {
+		t.Error("adviseSlots() error = nil, want error for a forecast shorter than the workload")
+	}
+	if _, err := adviseSlots(context.Background(), staticForecast{}, "eu-central-1", time.Hour, 24*time.Hour, 1); err == nil {
+		t.Error("adviseSlots() error = nil, want error of forecaster")
+	}
+}
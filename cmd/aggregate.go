@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"maps"
+	"strings"
+)
+
+// aggregateBlockRows is the number of aggregate rows, and their labels,
+// allocated at once.
+const aggregateBlockRows = 1024
+
+// stringTable interns strings, so that each distinct value is held once and
+// can be referred to by a small ID.
+type stringTable struct {
+	ids    map[string]uint32
+	values []string
+
+	// last is the ID of the last string looked up, which is often looked
+	// up again, as report lines of the same account or region follow each
+	// other.
+	last uint32
+}
+
+// id returns the ID of s, adding a copy of s to the table if it is new. The
+// copy does not share memory with s, which may be part of a CSV record.
+func (t *stringTable) id(s string) uint32 {
+	if int(t.last) < len(t.values) && t.values[t.last] == s {
+		return t.last
+	}
+	if id, exists := t.ids[s]; exists {
+		t.last = id
+		return id
+	}
+	if t.ids == nil {
+		t.ids = make(map[string]uint32)
+	}
+	id := uint32(len(t.values))
+	s = strings.Clone(s)
+	t.ids[s] = id
+	t.values = append(t.values, s)
+	t.last = id
+	return id
+}
+
+// intern returns the copy of s held by the table.
+func (t *stringTable) intern(s string) string {
+	return t.values[t.id(s)]
+}
+
+// addAggregate adds the usage of row to the aggregate row with the same
+// labels, category, region, instance type, storage type, Multi-AZ setting
+// and period, or appends it as a new aggregate row. The strings of new rows
+// are interned, and row.Labels is not retained. It returns the aggregate
+// row.
+func (s *ReportSummary) addAggregate(row AggregateReportRow) *AggregateReportRow {
+	s.key = s.appendCompactKey(s.key[:0], row)
+	if aggregate, exists := s.index[string(s.key)]; exists {
+		aggregate.addMetrics(row)
+		return aggregate
+	}
+
+	if len(s.rowBlock) == 0 {
+		s.rowBlock = make([]AggregateReportRow, aggregateBlockRows)
+	}
+	aggregate := &s.rowBlock[0]
+	s.rowBlock = s.rowBlock[1:]
+
+	*aggregate = row
+	// The costs are copied, as rows of other summaries may still be added
+	// to, e.g. when caching them.
+	aggregate.ProductCosts = maps.Clone(row.ProductCosts)
+	aggregate.Labels = s.internLabels(row.Labels)
+	n := len(row.Labels)
+	aggregate.Category = s.column(n).intern(row.Category)
+	aggregate.Region = s.column(n + 1).intern(row.Region)
+	aggregate.InstanceType = s.column(n + 2).intern(row.InstanceType)
+	aggregate.StorageType = s.column(n + 3).intern(row.StorageType)
+	s.index[string(s.key)] = aggregate
+	s.Aggregate = append(s.Aggregate, aggregate)
+	return aggregate
+}
+
+// appendCompactKey appends the key of row in the index of the summary to
+// b. Unlike AggregateReportRow.key, it is made of the IDs of the interned
+// strings, so it is short but only valid within the summary.
+func (s *ReportSummary) appendCompactKey(b []byte, row AggregateReportRow) []byte {
+	for i, label := range row.Labels {
+		b = binary.AppendUvarint(b, uint64(s.column(i).id(label)))
+	}
+	n := len(row.Labels)
+	for i, part := range [...]string{row.Category, row.Region, row.InstanceType, row.StorageType} {
+		b = binary.AppendUvarint(b, uint64(s.column(n+i).id(part)))
+	}
+	if row.MultiAZ {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	return binary.AppendVarint(b, row.Period.Unix())
+}
+
+// column returns the string table of the labels at index i of aggregate
+// rows, followed by the tables of their category, region, instance type and
+// storage type. Each has its own table, so that lookups of values with few
+// distinct values stay in small tables.
+func (s *ReportSummary) column(i int) *stringTable {
+	for len(s.strings) <= i {
+		s.strings = append(s.strings, stringTable{})
+	}
+	return &s.strings[i]
+}
+
+// internLabels returns a copy of labels with interned strings, taken from
+// a block allocated for many rows at once.
+func (s *ReportSummary) internLabels(labels []string) []string {
+	n := len(labels)
+	if n == 0 {
+		return nil
+	}
+	if len(s.labelBlock) < n {
+		s.labelBlock = make([]string, n*aggregateBlockRows)
+	}
+	interned := s.labelBlock[:n:n]
+	s.labelBlock = s.labelBlock[n:]
+	for i, label := range labels {
+		interned[i] = s.column(i).intern(label)
+	}
+	return interned
+}
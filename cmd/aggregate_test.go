@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// aggregateByKey returns the aggregate rows of a summary by their key.
+func aggregateByKey(s *ReportSummary) map[string]AggregateReportRow {
+	rows := make(map[string]AggregateReportRow, len(s.Aggregate))
+	for _, row := range s.Aggregate {
+		rows[row.key()] = *row
+	}
+	return rows
+}
+
+func TestReportSummary_addAggregate(t *testing.T) {
+	summary := newReportSummary(nil)
+	august := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	line := "eu-west-1,team-a,m5.large"
+	fields := strings.Split(line, ",")
+	labels := []string{fields[1]}
+	summary.addAggregate(AggregateReportRow{Labels: labels, Category: categoryEC2, Region: fields[0], InstanceType: fields[2], Duration: time.Hour})
+	labels[0] = "changed"
+	summary.addAggregate(AggregateReportRow{Labels: []string{"team-a"}, Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.large", Duration: time.Hour})
+	summary.addAggregate(AggregateReportRow{Labels: []string{"team-a"}, Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.large", Period: august, Duration: time.Hour})
+	summary.addAggregate(AggregateReportRow{Labels: []string{"team-a"}, Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.large", MultiAZ: true, Duration: time.Hour})
+	summary.addAggregate(AggregateReportRow{Labels: []string{"m5.large"}, Category: categoryEC2, Region: "eu-west-1", InstanceType: "team-a", Duration: time.Hour})
+
+	if len(summary.Aggregate) != 4 {
+		t.Fatalf("addAggregate() kept %d rows, want 4: %+v", len(summary.Aggregate), summary.Aggregate)
+	}
+	first := summary.Aggregate[0]
+	if first.Duration != 2*time.Hour || first.Labels[0] != "team-a" {
+		t.Errorf("addAggregate() first row = %+v, want 2h of team-a", first)
+	}
+	if summary.Aggregate[1].Period != august || !summary.Aggregate[2].MultiAZ {
+		t.Errorf("addAggregate() did not keep periods and Multi-AZ apart: %+v", summary.Aggregate)
+	}
+	if labels := summary.column(0).values; len(labels) != 2 || labels[0] != "team-a" || labels[1] != "m5.large" {
+		t.Errorf("addAggregate() interned labels %q, want each distinct label once", labels)
+	}
+}
+
+// benchmarkRow returns the i-th row of a large report grouped by tags,
+// with a distinct service tag per row. Like the fields of a CSV record, its
+// strings share the memory of its line.
+func benchmarkRow(i int) ReportRow {
+	start := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	line := fmt.Sprintf("%s,eu-west-%d,m5.large,team-%d,service-%d,%s", categoryEC2, i%3+1, i%50, i, strings.Repeat("x", 1000))
+	fields := strings.Split(line, ",")
+	return ReportRow{
+		Category:       fields[0],
+		UsageAccountID: "123456789012",
+		Region:         fields[1],
+		InstanceType:   fields[2],
+		Tags:           map[string]string{"team": fields[3], "service": fields[4]},
+		UsageStartTime: start,
+		UsageEndTime:   start.Add(time.Hour),
+		Duration:       time.Hour,
+	}
+}
+
+// BenchmarkReportSummary_add measures the time and memory to aggregate
+// rows grouped by tags with many distinct values, with one or many lines
+// per aggregate row. The heap-B/row metric is the memory held by the
+// summary per aggregate row.
+func BenchmarkReportSummary_add(b *testing.B) {
+	dimensions, err := parseGroupBy("account,region,tag:team,tag:service")
+	if err != nil {
+		b.Fatal(err)
+	}
+	const n = 100000
+
+	for _, lines := range []int{1, 24} {
+		b.Run(fmt.Sprintf("lines=%d", lines), func(b *testing.B) {
+			b.ReportAllocs()
+			var heap float64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				before := heapAlloc()
+				rows := make([]ReportRow, n)
+				for j := range rows {
+					rows[j] = benchmarkRow(j)
+				}
+				b.StartTimer()
+
+				summary := newReportSummary(dimensions)
+				for _, row := range rows {
+					for l := 0; l < lines; l++ {
+						summary.add(row)
+					}
+				}
+
+				b.StopTimer()
+				// Only the memory held by the summary is left, unless it
+				// holds on to the rows.
+				rows = nil
+				heap += float64(heapAlloc()) - float64(before)
+				runtime.KeepAlive(summary)
+				b.StartTimer()
+			}
+			b.ReportMetric(heap/float64(b.N)/n, "heap-B/row")
+		})
+	}
+}
+
+// heapAlloc returns the bytes of live heap objects after a garbage
+// collection.
+func heapAlloc() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
@@ -1,240 +1,1434 @@
 package cmd
 
 import (
-	"compress/gzip"
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
 	"github.com/giantswarm/cloud-carbon/pkg/footprint"
-
-	"github.com/olekukonko/tablewriter"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
 	"github.com/spf13/cobra"
 )
 
 var analyseCmd = &cobra.Command{
-	Use:   "analyse PATH",
-	Short: "Analyse an AWS usage report",
-	Long: `Analyse an AWS usage report.
+	Use:   "analyse PATH...",
+	Short: "Analyse AWS, GCP or Azure usage reports",
+	Long: `Analyse AWS, GCP or Azure usage reports.
+
+The input files, specified by PATH, must be CSV files in the format
+"hourly usage without IDs". Files can be gzip compressed, as delivered by
+AWS, or plain, e. g. as exported from Athena. Compression is detected
+automatically.
+
+Multiple paths can be given. If a path is a directory, all files ending in
+.csv or .csv.gz within it and its subdirectories are analysed. This allows
+to pass the folder of a report which AWS split into several chunks. Usage
+from all files is aggregated into one result.
+
+Reports can also be read directly from S3, by passing a path in the form
+s3://BUCKET/PREFIX. If the prefix contains report manifests, as created by
+AWS for each billing period, the report files listed in the manifests are
+analysed. Otherwise, all files ending in .csv or .csv.gz under the prefix
+are analysed. The prefix can also point to a single manifest or report
+file. AWS credentials are taken from the environment, as with the AWS CLI.
+
+As a result, the usage and estimated emissions by category, region and
+instance type will be printed. Covered categories are:
+
+- EC2: instance usage, estimated from instance hours
+- EBS: volume storage, estimated from provisioned gigabyte-hours per volume
+  type
+- Network: data transferred out of a region or availability zone, including
+  internet egress and transfer to other regions, estimated from gigabytes
+  sent and accounted to the sending region
+- S3: object storage, estimated from stored gigabyte-hours
+- RDS: database instances, estimated from instance hours like the equivalent
+  EC2 instance type, with the standby instance of Multi-AZ deployments
+  included
+- Lambda: function execution, estimated from allocated memory in GB-seconds
+  and the vCPU share that comes with it
+- Fargate: ECS and EKS tasks on Fargate, estimated from vCPU-hours and
+  memory GB-hours
+
+Use --group-by to choose how usage is grouped, as a comma-separated list of
+dimensions. Available dimensions are:
+
+{{dimensions}}
+
+The default is "category,region,instance-type".
+
+Use --output to choose the output format:
+
+{{output formats}}
+
+By default, processing stops at the first file that cannot be read. With
+--continue-on-error, the remaining files are processed, failures are listed
+at the end and the result is marked as partial.
 
-The input file, specified by PATH, must be a gzipped CSV file in the format
-"hourly usage without IDs".
+With --provider gcp, the input files must be CSV files exported from a GCP
+billing export in BigQuery, flattened with this query:
 
-As a result, the EC2 usage by region and instance will be printed.
+` + gcpBillingExportQuery + `
+
+Only Compute Engine VMs are covered, as category ComputeEngine, estimated
+from vCPU time by machine type. Grouping by tags is not supported.
+
+With --provider azure, the input files must be Azure cost details exports,
+either with actual or amortized costs. Only virtual machines are covered, as
+category VirtualMachines, estimated from usage hours by VM size. Grouping
+by tags is not supported.
 `,
-	Run:  analyse,
-	Args: cobra.MinimumNArgs(1),
+	RunE: analyse,
+	Args: configArgs(cobra.MinimumNArgs(1)),
 }
 
+const dateTimeLayout = "2006-01-02T15:04:05Z"
+
+// Carbon intensity modes, corresponding to the location-based and the
+// market-based method of GHG Protocol scope 2 accounting.
+const (
+	intensityLocation = "location"
+	intensityMarket   = "market"
+)
+
+var intensityModes = []string{intensityLocation, intensityMarket}
+
+// Providers of hourly carbon intensity.
+const (
+	intensityProviderElectricityMaps = "electricitymaps"
+	intensityProviderWattTime        = "watttime"
+)
+
+var intensityProviders = []string{intensityProviderElectricityMaps, intensityProviderWattTime}
+
 const (
-	headerBillPayerAccountID     = "bill/PayerAccountId"
-	headerIdentityTimeInterval   = "identity/TimeInterval"
-	headerLineItemLineItemType   = "lineItem/LineItemType"
-	headerLineItemOperation      = "lineItem/Operation"
-	headerLineItemProductCode    = "lineItem/ProductCode"
-	headerLineItemUsageAccountID = "lineItem/UsageAccountId"
-	headerLineItemUsageEndDate   = "lineItem/UsageEndDate"
-	headerLineItemUsageStartDate = "lineItem/UsageStartDate"
-	headerProductInstanceType    = "product/instanceType"
-	headerProductProductFamily   = "product/productFamily"
-	headerProductRegionCode      = "product/regionCode"
-
-	dateTimeLayout = "2006-01-02T15:04:05Z"
+	providerAWS   = "aws"
+	providerGCP   = "gcp"
+	providerAzure = "azure"
 )
 
+// reportReader reads report data from r and adds the usage found to summary.
+type reportReader func(r io.Reader, summary *ReportSummary) error
+
+// reportReaders maps the supported cloud providers to the reader for their
+// reports.
+var reportReaders = map[string]reportReader{
+	providerAWS:   analyseReport,
+	providerGCP:   analyseGCPReport,
+	providerAzure: analyseAzureReport,
+}
+
+var providers = []string{providerAWS, providerGCP, providerAzure}
+
 var (
-	headers map[string]int
+	cacheDir           string
+	continueOnError    bool
+	coveredUsage       bool
+	emapsToken         string
+	end                string
+	filterAccount      string
+	filterInstanceType string
+	filterRegion       string
+	granularity        string
+	groupBy            string
+	instanceFallback   string
+	intensityMode      string
+	intensityProvider  string
+	moveRegion         []string
+	nodeMappingFile    string
+	openCostFile       string
+	outputFormat       string
+	provider           string
+	quiet              bool
+	start              string
+	timeseries         string
+	utilization        float64
+	utilizationFile    string
+	wattTimePassword   string
+	wattTimeUsername   string
+	workers            int
 )
 
+func init() {
+	analyseCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory caching the results of report files, so that only new or changed files are analysed when running again")
+	analyseCmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Keep processing remaining files when a file cannot be read, and report the result as partial")
+	analyseCmd.Flags().BoolVar(&coveredUsage, "covered-usage", true, "Include usage covered by Reserved Instances and Savings Plans. Set to false to only analyse usage paid On-Demand or as Spot")
+	analyseCmd.Flags().StringVar(&groupBy, "group-by", defaultGroupBy, fmt.Sprintf("Comma-separated list of dimensions to group usage by. Available: %s, %s<key>", strings.Join(dimensionNames(), ", "), tagDimensionPrefix))
+	analyseCmd.Flags().StringVar(&provider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+	analyseCmd.Flags().Float64Var(&utilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of EC2 and RDS instances in percent, used to estimate their power consumption")
+	analyseCmd.Flags().StringVar(&utilizationFile, "utilization-file", "", "CSV file with the measured average CPU utilization per instance type and optionally account, overriding --utilization for the instances listed")
+	analyseCmd.Flags().StringVar(&instanceFallback, "instance-fallback", footprint.FallbackFamily, fmt.Sprintf("Method of estimating EC2 and RDS instance types missing from the footprint dataset, one of: %s", strings.Join(footprint.FallbackMethods, ", ")))
+	analyseCmd.Flags().StringVar(&intensityMode, "intensity-mode", intensityLocation, fmt.Sprintf("Carbon intensity used for operational emissions, one of: %s", strings.Join(intensityModes, ", ")))
+	analyseCmd.Flags().StringVar(&intensityProvider, "intensity-provider", "", fmt.Sprintf("Provider of hourly carbon intensity, applied per hour of usage instead of the yearly average of the region. One of: %s", strings.Join(intensityProviders, ", ")))
+	analyseCmd.Flags().StringVar(&emapsToken, "electricity-maps-token", "", "Electricity Maps API key. Implies --intensity-provider electricitymaps if no provider is given")
+	analyseCmd.Flags().StringVar(&wattTimeUsername, "watttime-username", "", "WattTime account user name, for --intensity-provider watttime")
+	analyseCmd.Flags().StringVar(&wattTimePassword, "watttime-password", "", "WattTime account password, for --intensity-provider watttime")
+	analyseCmd.Flags().StringVar(&nodeMappingFile, "node-mapping", "", fmt.Sprintf("CSV file mapping EC2 instances to Kubernetes clusters and namespaces, for grouping by %s and %s", clusterDimension, namespaceDimension))
+	analyseCmd.Flags().StringVar(&openCostFile, "opencost-allocation", "", "Response of the OpenCost allocation API aggregated by node and namespace, attributing EC2 instances to namespaces by their share of the cost. Alternative to --node-mapping")
+	analyseCmd.Flags().StringVar(&start, "start", "", "Only include usage starting at or after this date (YYYY-MM-DD, UTC) or time (RFC 3339)")
+	analyseCmd.Flags().StringVar(&end, "end", "", "Only include usage starting before the end of this date (YYYY-MM-DD, UTC) or before this time (RFC 3339)")
+	analyseCmd.Flags().StringVar(&filterAccount, "filter-account", "", "Only include usage of these accounts. Comma-separated list, glob patterns like 1234* are supported")
+	analyseCmd.Flags().StringVar(&filterRegion, "filter-region", "", "Only include usage in these regions. Comma-separated list, glob patterns like eu-* are supported")
+	analyseCmd.Flags().StringVar(&filterInstanceType, "filter-instance-type", "", "Only include usage of these instance types. Comma-separated list, glob patterns like m5.* are supported")
+	analyseCmd.Flags().StringVar(&granularity, "granularity", "", fmt.Sprintf("Break down emissions by period, one of: %s", strings.Join(periodNames, ", ")))
+	analyseCmd.Flags().StringVar(&timeseries, "timeseries", "", fmt.Sprintf("Split emissions into periods of the given length, one of: %s. Requires output format %s or %s", strings.Join(periodNames, ", "), outputCSV, outputJSON))
+	analyseCmd.Flags().StringArrayVar(&moveRegion, "move-region", nil, "What-if analysis: also estimate the emissions with the usage in region SRC moved to region DST, given as SRC=DST. Can be repeated")
+	analyseCmd.Flags().BoolVar(&quiet, "quiet", false, "Do not print the progress of long analyses")
+	analyseCmd.Flags().IntVar(&workers, "workers", 0, "Number of goroutines parsing each AWS report. Defaults to the number of CPUs")
+	analyseCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s", strings.Join(outputFormats, ", ")))
+
+	withGeneratedHelp(analyseCmd, map[string]func() string{
+		"{{dimensions}}":     dimensionsHelp,
+		"{{output formats}}": outputFormatsHelp,
+	})
+}
+
 type ReportRow struct {
-	PayerAccountID string
-	UsageAccountID string
-	Region         string
-	InstanceType   string
-	UsageStartTime time.Time
-	UsageEndTime   time.Time
-	Duration       time.Duration
+	// Category is the usage category of the row, e.g. categoryEC2.
+	Category string
+
+	PayerAccountID   string
+	UsageAccountID   string
+	Region           string
+	AvailabilityZone string
+	InstanceType     string
+	ResourceID       string
+	UsageStartTime   time.Time
+	UsageEndTime     time.Time
+
+	// Duration is the instance usage time, for EC2 and RDS rows.
+	Duration time.Duration
+
+	// MultiAZ is set for RDS rows of Multi-AZ deployments.
+	MultiAZ bool
+
+	// StorageType is the EBS volume type or S3 storage class, and GBHours
+	// the amount of storage provisioned or used, for EBS and S3 rows. For
+	// Lambda and Fargate rows, GBHours is the amount of memory allocated.
+	StorageType string
+	GBHours     float64
+
+	// VCPUHours is the amount of vCPU time allocated, for Fargate rows.
+	VCPUHours float64
+
+	// TransferGB is the amount of data sent, for network rows.
+	TransferGB float64
+
+	// Tags holds the values of the cost allocation tags needed for grouping,
+	// keyed by tag key as used in the --group-by flag. Tags without a
+	// value are omitted.
+	Tags map[string]string
+
+	// Cost is the unblended cost of the usage in the billing currency.
+	// Only known for AWS usage.
+	Cost float64
+
+	// ProductCode is the code of the service the usage is billed by, e.g.
+	// "AmazonEC2". Only known for AWS usage.
+	ProductCode string
+
+	// PurchaseOption is how the usage was paid for, e.g. purchaseSpot.
+	// Only known for AWS usage.
+	PurchaseOption string
+
+	// Cluster and Namespace are the Kubernetes cluster and namespace the
+	// usage is attributed to, if known.
+	Cluster   string
+	Namespace string
+
+	// NodeGroup is the EKS node group of an EC2 instance, if tagged.
+	NodeGroup string
+
+	// Outpost is the location of the AWS Outposts rack the usage ran on, if
+	// any. Region is the Outpost's parent region.
+	Outpost string
 }
 
 type AggregateReportRow struct {
-	Region        string
-	InstanceType  string
-	Duration      time.Duration
-	EmissionGrams float64
+	// Labels holds the values of the grouping dimensions, in the order
+	// the dimensions were given.
+	Labels []string
+
+	Category     string
+	Region       string
+	InstanceType string
+	StorageType  string
+	MultiAZ      bool
+	Duration     time.Duration
+	GBHours      float64
+	VCPUHours    float64
+	TransferGB   float64
+	Cost         float64
+
+	// ProductCosts splits Cost by the product code of the service the
+	// usage is billed by. Only known for AWS usage.
+	ProductCosts map[string]float64
+
+	// Period is the start of the period the usage happened in, if the
+	// summary is split into periods.
+	Period time.Time
+
+	// EnergyKiloWattHours, EmbodiedGrams and EmissionGrams hold the
+	// estimated footprint. EmissionGrams is the total of operational and
+	// embodied emissions.
+	EnergyKiloWattHours float64
+	EmbodiedGrams       float64
+	EmissionGrams       float64
+
+	// InstanceVCPUHours is the vCPU time of the instances and tasks, used
+	// to compare emissions per vCPU-hour. It is set with the footprint.
+	InstanceVCPUHours float64
+
+	// WaterLiters is the estimated water consumed on site by the data
+	// centers.
+	WaterLiters float64
+}
+
+// addMetrics adds the usage and emissions of another row to this row.
+func (r *AggregateReportRow) addMetrics(o AggregateReportRow) {
+	r.Duration += o.Duration
+	r.GBHours += o.GBHours
+	r.VCPUHours += o.VCPUHours
+	r.TransferGB += o.TransferGB
+	r.Cost += o.Cost
+	for productCode, cost := range o.ProductCosts {
+		r.addProductCost(productCode, cost)
+	}
+	r.EnergyKiloWattHours += o.EnergyKiloWattHours
+	r.EmbodiedGrams += o.EmbodiedGrams
+	r.EmissionGrams += o.EmissionGrams
+	r.InstanceVCPUHours += o.InstanceVCPUHours
+	r.WaterLiters += o.WaterLiters
+}
+
+// operationalGrams returns the emissions from producing the energy consumed.
+func (r AggregateReportRow) operationalGrams() float64 {
+	return r.EmissionGrams - r.EmbodiedGrams
+}
+
+// ReportSummary holds the usage aggregated from one or more report files.
+type ReportSummary struct {
+	// Dimensions are the properties usage is grouped by.
+	Dimensions []Dimension
+
+	LineCount    int
+	EarliestDate time.Time
+	LatestDate   time.Time
+
+	// Aggregate report rows, one per combination of grouping labels plus
+	// category, region, instance type and storage type. These are always
+	// distinguished, as emissions can only be estimated per category,
+	// region and resource type. Rows are in the order they were first
+	// added; add to them with addAggregate.
+	Aggregate []*AggregateReportRow
+
+	// Period returns the start of the period a usage start time belongs
+	// to. If set, aggregate rows are split by period.
+	Period periodFunc
+
+	// Filters select the line items included, when reports are run through
+	// the pipeline with run. Line items not included are only counted in
+	// SkippedCount.
+	Filters      []pipeline.Filter
+	SkippedCount int
+
+	// LenientDates, if set, skips lines with invalid dates instead of
+	// failing. They are counted in InvalidDateCount, and the first ones
+	// kept in InvalidDates.
+	LenientDates     bool
+	InvalidDateCount int
+	InvalidDates     []invalidDate
+
+	// Nodes, if set, attributes the usage of Kubernetes nodes to clusters
+	// and namespaces.
+	Nodes nodeMapping
+
+	// Workers is the number of goroutines parsing a report, if supported by
+	// the report reader. Zero means one per CPU.
+	Workers int
+
+	// Progress, if set, counts the lines about usage.
+	Progress *progressReporter
+
+	// Spend holds the cost of all lines by the product code of their
+	// service, including usage the carbon model doesn't cover. Filters
+	// don't apply to it.
+	Spend map[string]float64
+
+	// index holds the aggregate rows by their compact key, made of the
+	// IDs of their strings in the string tables of their columns.
+	// Interning keeps each distinct label once, instead of one copy per
+	// aggregate row holding on to the CSV record it was read from.
+	// rowBlock and labelBlock hold the rows and labels allocated for the
+	// next aggregate rows, so that rows are not copied as they are added.
+	index      map[string]*AggregateReportRow
+	strings    []stringTable
+	rowBlock   []AggregateReportRow
+	labelBlock []string
+
+	// productCodes interns the product codes of the cost of aggregate rows
+	// and of the spend.
+	productCodes stringTable
+
+	// labels and key are reused by add to avoid allocations per row.
+	labels []string
+	key    []byte
+}
+
+// summaryOptions control how rows are added to a summary.
+type summaryOptions struct {
+	period  periodFunc
+	filters []pipeline.Filter
+	nodes   nodeMapping
+
+	// lenientDates skips lines with invalid dates instead of failing.
+	lenientDates bool
+
+	// workers is the number of goroutines parsing a report, zero for one
+	// per CPU.
+	workers int
+
+	// progress, if set, counts the bytes read and lines about usage.
+	progress *progressReporter
+}
+
+// periodFunc returns the start of the period a point in time belongs to.
+type periodFunc func(t time.Time) time.Time
+
+// hourPeriod splits usage by hour.
+func hourPeriod(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
+
+// FileFailure describes a report file that could not be processed completely.
+type FileFailure struct {
+	Path string
+	Err  error
+
+	// Summary covers the rows read from the file before the failure
+	// occurred. These rows are not part of the overall result.
+	Summary *ReportSummary
+}
+
+// reportHeaders holds the positions of the columns in a CSV file.
+type reportHeaders struct {
+	// index maps column names to their position.
+	index map[string]int
+}
+
+// newReportHeaders returns the column positions for the header record of
+// a CSV file.
+func newReportHeaders(record []string) reportHeaders {
+	headers := reportHeaders{index: make(map[string]int)}
+	for index, field := range record {
+		headers.index[field] = index
+	}
+	return headers
+}
+
+// value returns the field of the given column, or an empty string if the
+// file has no such column.
+func (h reportHeaders) value(fields []string, column string) string {
+	index, exists := h.index[column]
+	if !exists || index >= len(fields) {
+		return ""
+	}
+	return fields[index]
+}
+
+func newReportSummary(dimensions []Dimension) *ReportSummary {
+	return &ReportSummary{
+		Dimensions:   dimensions,
+		EarliestDate: mustParseDate("2100-12-31T23:59:59Z"),
+		index:        make(map[string]*AggregateReportRow),
+	}
+}
+
+// run adds the line items read from r to the summary, through the stages
+// of the pipeline: line items not passing the summary's filters are only
+// counted in SkippedCount.
+func (s *ReportSummary) run(r pipeline.Reader) error {
+	counter := &itemCounter{reader: r}
+	lines := s.LineCount
+	err := pipeline.Run(counter, s.Filters, s)
+	s.SkippedCount += counter.count - (s.LineCount - lines)
+	return err
 }
 
-func readReportRow(fields []string) ReportRow {
-	r := ReportRow{
-		PayerAccountID: fields[headers[headerBillPayerAccountID]],
-		UsageAccountID: fields[headers[headerLineItemUsageAccountID]],
-		Region:         fields[headers[headerProductRegionCode]],
-		InstanceType:   fields[headers[headerProductInstanceType]],
-		UsageStartTime: mustParseDate(fields[headers[headerLineItemUsageStartDate]]),
-		UsageEndTime:   mustParseDate(fields[headers[headerLineItemUsageEndDate]]),
+// itemCounter counts the line items provided by a reader.
+type itemCounter struct {
+	reader pipeline.Reader
+	count  int
+}
+
+func (r *itemCounter) Next() (cur.LineItem, error) {
+	item, err := r.reader.Next()
+	if err == nil {
+		r.count++
+	}
+	return item, err
+}
+
+// Add adds a line item included by the filters to the summary, so that the
+// summary is the aggregator of the pipeline.
+func (s *ReportSummary) Add(item cur.LineItem) {
+	s.add(reportRow(item))
+}
+
+// add accounts a single report row to the summary.
+func (s *ReportSummary) add(r ReportRow) {
+	s.LineCount++
+	if s.Progress != nil {
+		s.Progress.addLine()
+	}
+
+	if s.Nodes != nil {
+		for _, r := range s.Nodes.attribute(r) {
+			s.addRow(r)
+		}
+	} else {
+		s.addRow(r)
+	}
+	s.addTimeRange(r.UsageStartTime, r.UsageEndTime)
+}
+
+// addRow adds the usage of a row included in the summary to its aggregate
+// row.
+func (s *ReportSummary) addRow(r ReportRow) {
+	s.labels = s.labels[:0]
+	for _, d := range s.Dimensions {
+		s.labels = append(s.labels, d.Value(r))
+	}
+
+	row := AggregateReportRow{
+		Labels:       s.labels,
+		Category:     r.Category,
+		Region:       r.Region,
+		InstanceType: r.InstanceType,
+		StorageType:  r.StorageType,
+		MultiAZ:      r.MultiAZ,
+		Duration:     r.Duration,
+		GBHours:      r.GBHours,
+		VCPUHours:    r.VCPUHours,
+		TransferGB:   r.TransferGB,
+		Cost:         r.Cost,
+	}
+	if r.Outpost != "" {
+		row.Region = outpostRegion(r.Outpost)
+	}
+	if s.Period != nil {
+		row.Period = s.Period(r.UsageStartTime)
+	}
+	aggregate := s.addAggregate(row)
+	if r.ProductCode != "" {
+		aggregate.addProductCost(s.productCodes.intern(r.ProductCode), r.Cost)
 	}
+}
 
-	// Fancy logic to basically compute a duration of one hour.
-	interval := fields[headers[headerIdentityTimeInterval]]
-	parts := strings.Split(interval, "/")
-	r.UsageStartTime = mustParseDate(parts[0])
-	r.UsageEndTime = mustParseDate(parts[1])
-	r.Duration = r.UsageEndTime.Sub(r.UsageStartTime)
+// tagKeys returns the keys of the cost allocation tags used by the
+// summary's dimensions.
+func (s *ReportSummary) tagKeys() []string {
+	var keys []string
+	for _, d := range s.Dimensions {
+		if d.TagKey != "" {
+			keys = append(keys, d.TagKey)
+		}
+	}
+	return keys
+}
 
-	return r
+// fork returns an empty summary with the same dimensions and options.
+func (s *ReportSummary) fork() *ReportSummary {
+	f := newReportSummary(s.Dimensions)
+	f.Period = s.Period
+	f.Filters = s.Filters
+	f.Nodes = s.Nodes
+	f.LenientDates = s.LenientDates
+	f.Workers = s.Workers
+	f.Progress = s.Progress
+	return f
 }
 
+// merge adds all data from another summary to this one. Both summaries
+// must use the same dimensions.
+func (s *ReportSummary) merge(o *ReportSummary) {
+	s.LineCount += o.LineCount
+	s.SkippedCount += o.SkippedCount
+	s.InvalidDateCount += o.InvalidDateCount
+	s.addInvalidDates(o.InvalidDates...)
+	for productCode, cost := range o.Spend {
+		s.addSpend(productCode, cost)
+	}
+	s.Aggregate = slices.Grow(s.Aggregate, len(o.Aggregate))
+	for _, row := range o.Aggregate {
+		s.addAggregate(*row)
+	}
+	s.addTimeRange(o.EarliestDate, o.LatestDate)
+}
+
+func (s *ReportSummary) addTimeRange(start, end time.Time) {
+	if start.Before(s.EarliestDate) {
+		s.EarliestDate = start
+	}
+	if end.After(s.LatestDate) {
+		s.LatestDate = end
+	}
+}
+
+// key returns a key identifying an aggregate row, used to order rows.
+func (r AggregateReportRow) key() string {
+	return string(r.appendKey(nil))
+}
+
+// appendKey appends the key of the row to b.
+func (r AggregateReportRow) appendKey(b []byte) []byte {
+	for _, label := range r.Labels {
+		b = append(append(b, label...), 0)
+	}
+	for _, part := range []string{r.Category, r.Region, r.InstanceType, r.StorageType} {
+		b = append(append(b, part...), 0)
+	}
+	b = strconv.AppendBool(b, r.MultiAZ)
+	if !r.Period.IsZero() {
+		b = r.Period.AppendFormat(append(b, 0), time.RFC3339)
+	}
+	return b
+}
+
+// reportRow returns the report row of a line item of an AWS Cost and Usage
+// Report.
+func reportRow(item cur.LineItem) ReportRow {
+	return ReportRow{
+		Category:         item.Category,
+		PayerAccountID:   item.PayerAccountID,
+		UsageAccountID:   item.UsageAccountID,
+		Region:           item.Region,
+		AvailabilityZone: item.AvailabilityZone,
+		InstanceType:     item.InstanceType,
+		ResourceID:       item.ResourceID,
+		UsageStartTime:   item.UsageStartTime,
+		UsageEndTime:     item.UsageEndTime,
+		Duration:         item.Duration,
+		MultiAZ:          item.MultiAZ,
+		StorageType:      item.StorageType,
+		GBHours:          item.GBHours,
+		VCPUHours:        item.VCPUHours,
+		TransferGB:       item.TransferGB,
+		Tags:             item.Tags,
+		Cost:             item.Cost,
+		ProductCode:      item.ProductCode,
+		PurchaseOption:   item.PurchaseOption,
+		Cluster:          item.Cluster,
+		NodeGroup:        item.NodeGroup,
+		Outpost:          item.Outpost,
+	}
+}
+
+// mustParseDate parses a point in time given as a constant. It panics if s
+// is invalid.
 func mustParseDate(s string) time.Time {
-	dateTime, _ := time.Parse(dateTimeLayout, s)
+	dateTime, err := time.Parse(dateTimeLayout, s)
+	if err != nil {
+		panic(err)
+	}
 	return dateTime
 }
 
+// formatGrams formats an amount of emissions given in grams, in the unit
+// given by --unit or else in g, kg or t depending on the amount.
 func formatGrams(g float64) string {
+	u, exists := emissionUnits[unit]
+	if rawNumbers {
+		if exists {
+			g /= u.grams
+		}
+		return formatRaw(g)
+	}
+	if exists {
+		return u.format(g)
+	}
 	if g > (1000 * 1000) {
-		return fmt.Sprintf("%.1f MTCO2e", g/1000/1000)
+		return formatNumber(g/1000/1000, 1) + " MTCO2e"
 	}
 	if g > 1000 {
-		return fmt.Sprintf("%.1f kgCO2e", g/1000)
+		return formatNumber(g/1000, 1) + " kgCO2e"
 	}
-	return fmt.Sprintf("%.0f gCO2e", g)
+	return formatNumber(g, 0) + " gCO2e"
 }
 
-func analyse(cmd *cobra.Command, args []string) {
-	path := args[0]
-	fmt.Printf("Analysing report from path %s\n", path)
+// formatCost formats a cost in the billing currency, usually US dollars.
+func formatCost(cost float64) string {
+	if rawNumbers {
+		return formatRaw(cost)
+	}
+	return formatNumber(cost, 2)
+}
+
+// formatGramsPerVCPUHour formats the emissions per vCPU-hour, or "-" if
+// there were no instances.
+func formatGramsPerVCPUHour(grams, vcpuHours float64) string {
+	if vcpuHours == 0 {
+		return "-"
+	}
+	if rawNumbers {
+		return formatRaw(grams / vcpuHours)
+	}
+	return formatNumber(grams/vcpuHours, 2)
+}
+
+// formatGramsPerDollar formats the emissions per unit of cost, or "-" if
+// there was no cost.
+func formatGramsPerDollar(grams, cost float64) string {
+	if cost == 0 {
+		return "-"
+	}
+	if rawNumbers {
+		return formatRaw(grams / cost)
+	}
+	return formatNumber(grams/cost, 1)
+}
 
-	gzFile, err := os.Open(path)
+// formatLiters formats an amount of water, in cubic meters for large
+// amounts. Milliliters are avoided, as table footers are upper case.
+func formatLiters(liters float64) string {
+	if rawNumbers {
+		return formatRaw(liters)
+	}
+	if liters > 1000 {
+		return formatNumber(liters/1000, 1) + " m³"
+	}
+	if liters > 1 {
+		return formatNumber(liters, 1) + " L"
+	}
+	return formatNumber(liters, 2) + " L"
+}
+
+func formatKiloWattHours(kwh float64) string {
+	if rawNumbers {
+		return formatRaw(kwh)
+	}
+	if kwh > 1000 {
+		return formatNumber(kwh/1000, 1) + " MWh"
+	}
+	if kwh > 1 {
+		return formatNumber(kwh, 1) + " kWh"
+	}
+	return formatNumber(kwh*1000, 0) + " Wh"
+}
+
+// analyseSource reads the report from src using read and returns the
+// summary of its usage, split by period and filtered according to options.
+// In case of an error, the summary of the rows read so far is returned along
+// with the error.
+func analyseSource(ctx context.Context, src ReportSource, read reportReader, dimensions []Dimension, options summaryOptions) (*ReportSummary, error) {
+	summary := newReportSummary(dimensions)
+	summary.Period = options.period
+	summary.Filters = options.filters
+	summary.Nodes = options.nodes
+	summary.LenientDates = options.lenientDates
+	summary.Workers = options.workers
+	summary.Progress = options.progress
+
+	r, err := src.Open(ctx)
 	if err != nil {
-		log.Fatalf("Could not open file: %s", err)
+		return summary, err
 	}
-	defer gzFile.Close()
+	defer r.Close()
+
+	var input io.Reader = r
+	if options.progress != nil {
+		input = options.progress.reader(r)
+	}
+	err = read(input, summary)
+	return summary, err
+}
 
-	csvFile, err := gzip.NewReader(gzFile)
+// analyseReport reads CSV data from an AWS Cost and Usage Report, optionally
+// gzip compressed, from r and adds the usage found to summary. Lines are
+// parsed concurrently by summary.Workers goroutines.
+func analyseReport(r io.Reader, summary *ReportSummary) error {
+	csvFile, err := maybeDecompress(r)
 	if err != nil {
-		log.Fatalf("Could not uncompress file: %s", err)
+		return fmt.Errorf("could not uncompress file: %w", err)
 	}
 	defer csvFile.Close()
 
-	processedHeaders := false
-	lineCount := 0
-	headers = make(map[string]int)
-	earliestDate := mustParseDate("2100-12-31T23:59:59Z")
-	latestDate := mustParseDate("0000-00-00T00:00:00Z")
+	fcsv := csv.NewReader(csvFile)
+	csvRecord, err := fcsv.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read CSV: %w", err)
+	}
+
+	if err := cur.ValidateHeader(csvRecord); err != nil {
+		return parseErrorf("%w%s", err, providerHint(csvRecord))
+	}
+	parser := cur.NewParser(csvRecord, summary.tagKeys())
+	for _, key := range parser.MissingTags() {
+		log.Printf("Warning: report has no column %q, all usage will be shown as %s. Make sure the tag is activated as cost allocation tag and the report includes resource IDs.", cur.TagColumn(key), untaggedLabel)
+	}
 
-	// Aggregate report rows where key is in the form of
-	// region_instancetype
-	aggregate := make(map[string]AggregateReportRow)
+	return parseRecords(fcsv, summary, func(fields []string, summary *ReportSummary) (cur.LineItem, bool, error) {
+		summary.addSpend(parser.ProductCode(fields), parser.Cost(fields))
+		return parser.Parse(fields)
+	})
+}
 
-	fcsv := csv.NewReader(csvFile)
-	for {
-		csvRecord, err := fcsv.Read()
-		if err == io.EOF {
-			break
+// providerHint returns a hint to use --provider if a file read as AWS Cost
+// and Usage Report has the header record of a GCP or Azure export.
+func providerHint(header []string) string {
+	columns := make(map[string]bool, len(header))
+	for _, column := range header {
+		columns[strings.ToLower(strings.TrimPrefix(column, "\ufeff"))] = true
+	}
+	switch {
+	case columns[gcpHeaderService] && columns[gcpHeaderSKU]:
+		return fmt.Sprintf(", the file looks like a GCP billing export: use --provider %s", providerGCP)
+	case columns[azureHeaderMeterCategory] && columns[azureHeaderUnitOfMeasure]:
+		return fmt.Sprintf(", the file looks like an Azure cost details export: use --provider %s", providerAzure)
+	}
+	return ""
+}
+
+func analyse(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	if !isValidOutputFormat(outputFormat) {
+		return usageErrorf("invalid output format %q, must be one of: %s", outputFormat, strings.Join(allOutputFormats(), ", "))
+	}
+
+	// Status information goes to stderr when stdout carries
+	// machine-readable output.
+	var info io.Writer = os.Stdout
+	if outputFormat != outputTable {
+		info = os.Stderr
+	}
+
+	read, exists := reportReaders[provider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", provider, strings.Join(providers, ", "))
+	}
+
+	dimensions, err := parseGroupBy(groupBy)
+	if err != nil {
+		return usageErrorf("invalid --group-by value: %w", err)
+	}
+
+	if utilization < 0 || utilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", utilization)
+	}
+
+	switch intensityMode {
+	case intensityLocation:
+	case intensityMarket:
+		if provider != providerAWS {
+			return usageErrorf("invalid --intensity-mode value %q, market-based carbon intensity is only available for provider %s", intensityMode, providerAWS)
+		}
+	default:
+		return usageErrorf("invalid --intensity-mode value %q, must be one of: %s", intensityMode, strings.Join(intensityModes, ", "))
+	}
+
+	if !footprint.IsFallbackMethod(instanceFallback) {
+		return usageErrorf("invalid --instance-fallback value %q, must be one of: %s", instanceFallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+
+	if sinkName != "" || sinkURL != "" {
+		if !containsString(sinks, sinkName) {
+			return usageErrorf("invalid --sink value %q, must be one of: %s", sinkName, strings.Join(sinks, ", "))
+		}
+		if sinkURL == "" {
+			return usageErrorf("--sink %s requires --sink-url", sinkName)
 		}
+	}
+
+	var budgetGrams float64
+	if budget != "" {
+		budgetGrams, err = parseMass(budget)
 		if err != nil {
-			fmt.Println("ERROR: ", err.Error())
-			break
+			return usageErrorf("invalid --budget value: %w", err)
 		}
+	}
+	if sortField != "" && !containsString(sortFields, sortField) {
+		return usageErrorf("invalid --sort value %q, must be one of: %s", sortField, strings.Join(sortFields, ", "))
+	}
+	if sortDescending && sortField == "" {
+		return usageErrorf("--desc requires --sort")
+	}
+	if top < 0 {
+		return usageErrorf("invalid --top value %d, must be positive", top)
+	}
+	if top > 0 && outputFormat != outputTable && outputFormat != outputMarkdown && outputFormat != outputHTML {
+		return usageErrorf("--top requires output format %s, %s or %s", outputTable, outputMarkdown, outputHTML)
+	}
+	if top > 0 && granularity != "" {
+		return usageErrorf("--top cannot be combined with --granularity")
+	}
+	if !containsString(budgetPeriods, budgetPeriod) {
+		return usageErrorf("invalid --budget-period value %q, must be one of: %s", budgetPeriod, strings.Join(budgetPeriods, ", "))
+	}
 
-		if !processedHeaders {
-			for index, field := range csvRecord {
-				headers[field] = index
+	moves, err := parseRegionMoves(moveRegion)
+	if err != nil {
+		return usageErrorf("invalid --move-region value: %w", err)
+	}
+
+	var filters []pipeline.Filter
+	if start != "" || end != "" {
+		var startTime, endTime time.Time
+		if start != "" {
+			startTime, err = parseRangeTime(start, false)
+			if err != nil {
+				return usageErrorf("invalid --start value: %w", err)
 			}
-			processedHeaders = true
 		}
-
-		// Filtering out everything that is not EC2 instance usage
-		if csvRecord[headers[headerLineItemLineItemType]] != "Usage" {
-			continue
+		if end != "" {
+			endTime, err = parseRangeTime(end, true)
+			if err != nil {
+				return usageErrorf("invalid --end value: %w", err)
+			}
 		}
-		if csvRecord[headers[headerLineItemProductCode]] != "AmazonEC2" {
-			continue
+		if !startTime.IsZero() && !endTime.IsZero() && !startTime.Before(endTime) {
+			return usageErrorf("--start %s must be before --end %s", start, end)
 		}
-		if csvRecord[headers[headerProductProductFamily]] != "Compute Instance" {
+		filters = append(filters, timeRangeFilter(startTime, endTime))
+	}
+	for _, f := range []struct {
+		flag, patterns string
+		value          func(item cur.LineItem) string
+	}{
+		{"--filter-account", filterAccount, func(item cur.LineItem) string { return item.UsageAccountID }},
+		{"--filter-region", filterRegion, func(item cur.LineItem) string { return item.Region }},
+		{"--filter-instance-type", filterInstanceType, func(item cur.LineItem) string { return item.InstanceType }},
+	} {
+		if f.patterns == "" {
 			continue
 		}
-		if !strings.HasPrefix(csvRecord[headers[headerLineItemOperation]], "RunInstances") {
+		filter, err := patternFilter(f.patterns, f.value)
+		if err != nil {
+			return usageErrorf("invalid %s value: %w", f.flag, err)
+		}
+		filters = append(filters, filter)
+	}
+	if !coveredUsage {
+		filters = append(filters, uncoveredUsageFilter)
+	}
+	summaryOpts := summaryOptions{filters: filters, lenientDates: !strictDates, workers: workers}
+
+	if nodeMappingFile != "" && openCostFile != "" {
+		return usageErrorf("--node-mapping and --opencost-allocation cannot be combined")
+	}
+	if nodeMappingFile != "" {
+		summaryOpts.nodes, err = readNodeMappingFile(nodeMappingFile)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read node mapping file %s: %w", nodeMappingFile, err)
+		}
+	}
+	if openCostFile != "" {
+		summaryOpts.nodes, err = readOpenCostAllocationFile(openCostFile)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read OpenCost allocation file %s: %w", openCostFile, err)
+		}
+	}
+	if summaryOpts.nodes != nil {
+		if !hasDimension(dimensions, clusterDimension) && !hasDimension(dimensions, namespaceDimension) {
+			log.Printf("Warning: the node mapping is only visible when grouping by %s or %s.", clusterDimension, namespaceDimension)
+		}
+	}
+
+	var accountNames map[string]string
+	if accountNamesFile != "" {
+		accountNames, err = readAccountNames(accountNamesFile)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read account names file %s: %w", accountNamesFile, err)
+		}
+		if !hasDimension(dimensions, accountDimension) {
+			log.Printf("Warning: the account names are only visible when grouping by %s.", accountDimension)
+		}
+		dimensions = withAccountNames(dimensions, accountNames)
+	}
+
+	var redaction *redactor
+	if redact {
+		redaction, err = newRedactor(redactKey)
+		if err != nil {
+			return fmt.Errorf("could not create redaction key: %w", err)
+		}
+		dimensions = withRedaction(dimensions, redaction)
+	} else if redactKey != "" {
+		return usageErrorf("--redact-key requires --redact")
+	}
+
+	var seriesPeriod, tablePeriod periodFunc
+	if granularity != "" {
+		tablePeriod, exists = periods[granularity]
+		if !exists {
+			return usageErrorf("invalid --granularity value %q, must be one of: %s", granularity, strings.Join(periodNames, ", "))
+		}
+		if timeseries != "" && timeseries != granularity {
+			return usageErrorf("--timeseries %s and --granularity %s cannot be combined", timeseries, granularity)
+		}
+		seriesPeriod = tablePeriod
+		summaryOpts.period = tablePeriod
+	}
+	if timeseries != "" {
+		seriesPeriod, exists = periods[timeseries]
+		if !exists {
+			return usageErrorf("invalid --timeseries value %q, must be one of: %s", timeseries, strings.Join(periodNames, ", "))
+		}
+		if outputFormat != outputCSV && outputFormat != outputJSON {
+			return usageErrorf("--timeseries requires output format %s or %s", outputCSV, outputJSON)
+		}
+		summaryOpts.period = seriesPeriod
+	}
+
+	options := emissionOptions{intensityMode: intensityMode, fallback: instanceFallback}
+	if intensityProvider == "" && emapsToken != "" {
+		intensityProvider = intensityProviderElectricityMaps
+	}
+	if intensityProvider != "" {
+		options.hourlyIntensity, err = newIntensityProvider(intensityProvider)
+		if err != nil {
+			return usageErrorf("invalid --intensity-provider value: %w", err)
+		}
+		if provider != providerAWS {
+			return usageErrorf("hourly carbon intensity is only available for provider %s", providerAWS)
+		}
+		if intensityMode != intensityLocation {
+			return usageErrorf("hourly carbon intensity cannot be combined with --intensity-mode %s", intensityMode)
+		}
+		// Hours can still be summed up into a coarser time series.
+		summaryOpts.period = hourPeriod
+	}
+	if budgetGrams > 0 && budgetPeriod == budgetPeriodMonth && summaryOpts.period == nil {
+		summaryOpts.period = monthPeriod
+	}
+
+	utilizations := fixedUtilization(utilization)
+	if utilizationFile != "" {
+		utilizations, err = readUtilizationFile(utilizationFile, utilization)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read utilization file %s: %w", utilizationFile, err)
+		}
+		if utilizations.hasAccounts() && !hasDimension(dimensions, accountDimension) {
+			log.Printf("Warning: utilization values for specific accounts are only applied when grouping by %s.", accountDimension)
+		}
+		if accountNames != nil {
+			utilizations, err = utilizations.withAccountNames(accountNames)
+			if err != nil {
+				return exitErrorf(exitParse, "invalid utilization file %s: %w", utilizationFile, err)
+			}
+		}
+		if redaction != nil {
+			utilizations = utilizations.withRedaction(redaction)
+		}
+	}
+
+	stopProfiles, err := startProfiles(cpuProfile, memProfile)
+	if err != nil {
+		return exitErrorf(exitIO, "could not start CPU profile: %w", err)
+	}
+	defer func() {
+		if err := stopProfiles(); err != nil {
+			log.Printf("Warning: %s", err)
+		}
+	}()
+
+	sources, err := resolveSources(cmd.Context(), args)
+	if err != nil {
+		return exitErrorf(exitIO, "could not determine input files: %w", err)
+	}
+
+	if statsOnly {
+		if provider != providerAWS {
+			return usageErrorf("--stats-only is only available for provider %s", providerAWS)
+		}
+		if outputFormat != outputTable && outputFormat != outputJSON {
+			return usageErrorf("--stats-only only supports the output formats %s and %s", outputTable, outputJSON)
+		}
+		stats, err := collectReportStats(cmd.Context(), sources, allFilters(filters))
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "%w", err)
+		}
+		if outputFormat == outputJSON {
+			if err := writeJSON(os.Stdout, stats); err != nil {
+				return exitErrorf(exitIO, "%w", err)
+			}
+			return nil
+		}
+		writeReportStats(os.Stdout, stats)
+		return nil
+	}
+
+	if !quiet {
+		progress := newProgressReporter(os.Stderr, totalSize(sources))
+		progress.start(progressInterval)
+		summaryOpts.progress = progress
+	}
+
+	var cache *summaryCache
+	if cacheDir != "" {
+		// All options affecting the summary of a file are part of the
+		// cache key.
+		settings := []string{provider, groupBy, granularity, timeseries, intensityProvider, start, end, filterAccount, filterRegion, filterInstanceType, strconv.FormatBool(coveredUsage), strconv.FormatBool(strictDates)}
+		if nodeMappingFile != "" {
+			checksum, err := fileChecksum(nodeMappingFile)
+			if err != nil {
+				return exitErrorf(exitParse, "could not read node mapping file %s: %w", nodeMappingFile, err)
+			}
+			settings = append(settings, checksum)
+		}
+		if openCostFile != "" {
+			checksum, err := fileChecksum(openCostFile)
+			if err != nil {
+				return exitErrorf(exitParse, "could not read OpenCost allocation file %s: %w", openCostFile, err)
+			}
+			settings = append(settings, checksum)
+		}
+		if accountNamesFile != "" {
+			checksum, err := fileChecksum(accountNamesFile)
+			if err != nil {
+				return exitErrorf(exitParse, "could not read account names file %s: %w", accountNamesFile, err)
+			}
+			settings = append(settings, checksum)
+		}
+		if redaction != nil {
+			settings = append(settings, redaction.id())
+		}
+		cache, err = newSummaryCache(cacheDir, settings)
+		if err != nil {
+			return exitErrorf(exitIO, "invalid --cache-dir value: %w", err)
+		}
+	}
+
+	summary := newReportSummary(dimensions)
+	var failures []FileFailure
+	var inputs []manifestInput
+
+	for _, src := range sources {
+		var checksum string
+		if cache != nil {
+			var cached *ReportSummary
+			checksum, cached = cache.lookup(cmd.Context(), src, dimensions)
+			if cached != nil {
+				fmt.Fprintf(info, "Using cached result for report from path %s\n", src.Name)
+				summary.merge(cached)
+				if manifestPath != "" {
+					inputs = append(inputs, manifestInput{Path: src.Name, Checksum: checksum, Cached: true})
+				}
+				continue
+			}
+		}
+
+		var input manifestInput
+		if manifestPath != "" {
+			input = manifestSourceInput(cmd.Context(), src, checksum)
+		}
+
+		fmt.Fprintf(info, "Analysing report from path %s\n", src.Name)
+
+		fileSummary, err := analyseSource(cmd.Context(), src, read, dimensions, summaryOpts)
+		if err != nil {
+			if !continueOnError {
+				return exitErrorf(readErrorCode(err), "could not process file %s: %w", src.Name, err)
+			}
+			failures = append(failures, FileFailure{Path: src.Name, Err: err, Summary: fileSummary})
+			if manifestPath != "" {
+				input.Error = err.Error()
+				inputs = append(inputs, input)
+			}
 			continue
 		}
 
-		lineCount++
+		logInvalidDates(src.Name, fileSummary)
+		summary.merge(fileSummary)
+		if manifestPath != "" {
+			inputs = append(inputs, input)
+		}
 
-		r := readReportRow(csvRecord)
-		key := fmt.Sprintf("%s_%s", r.Region, r.InstanceType)
-		val, exists := aggregate[key]
-		if exists {
-			val.Duration += r.Duration
-			aggregate[key] = val
-		} else {
-			aggregate[key] = AggregateReportRow{
-				Region:       r.Region,
-				InstanceType: r.InstanceType,
-				Duration:     r.Duration,
+		if checksum != "" {
+			if err := cache.store(checksum, fileSummary); err != nil {
+				log.Printf("Warning: could not cache result for %s: %s", src.Name, err)
 			}
 		}
+	}
+	if summaryOpts.progress != nil {
+		summaryOpts.progress.stop()
+	}
 
-		if r.UsageStartTime.Before(earliestDate) {
-			earliestDate = r.UsageStartTime
+	fmt.Fprintf(info, "Processed %d lines about usage.\n", summary.LineCount)
+	if summary.SkippedCount > 0 {
+		fmt.Fprintf(info, "Skipped %d lines outside of the selected time range or filters.\n", summary.SkippedCount)
+	}
+	if summary.InvalidDateCount > 0 {
+		fmt.Fprintf(info, "Skipped %d lines with invalid dates.\n", summary.InvalidDateCount)
+	}
+	fmt.Fprintf(info, "Time range covered: %s - %s (%s).\n\n", summary.EarliestDate, summary.LatestDate, summary.LatestDate.Sub(summary.EarliestDate))
+
+	options.utilization = utilizations
+	options.coverage = newCoverage()
+	aggregateReportRows, total := computeEmissions(cmd.Context(), summary, options)
+	coverage := options.coverage
+	options.coverage = nil
+	if deterministic {
+		aggregateReportRows, total = roundRows(aggregateReportRows), roundResult(total)
+	}
+
+	result := analysisResult{summary: summary, rows: aggregateReportRows, total: total, partial: len(failures) > 0}
+	output := outputOptions{
+		format:       outputFormat,
+		tablePeriod:  tablePeriod,
+		periodLayout: periodLayouts[granularity],
+		seriesPeriod: seriesPeriod,
+		top:          top,
+		sortField:    sortField,
+		descending:   sortDescending,
+	}
+	if err := writeResult(os.Stdout, result, output); err != nil {
+		return exitErrorf(exitIO, "%w", err)
+	}
+
+	coverage.write(info)
+	writeSpendCoverage(info, summary.Spend, aggregateReportRows)
+	writeIntensityOverrides(info, aggregateReportRows)
+	writeEstimatedRegions(info, aggregateReportRows)
+	writeOutposts(info, aggregateReportRows)
+
+	if len(moves) > 0 {
+		_, movedTotal := computeEmissions(cmd.Context(), moveRegions(summary, moves), options)
+		printWhatIf(info, moves, total, movedTotal)
+	}
+
+	printFailures(info, failures, len(sources))
+
+	budgetExceeded := false
+	if budgetGrams > 0 {
+		budgetExceeded = writeBudget(info, budgetGrams, budgetUsages(aggregateReportRows, budgetPeriod))
+	}
+
+	if sinkName != "" {
+		points := sinkPoints(dimensions, groupRows(aggregateReportRows, seriesPeriod), summary.LatestDate)
+		if err := pushToSink(cmd.Context(), http.DefaultClient, sinkName, sinkURL, sinkToken, points); err != nil {
+			return exitErrorf(exitIO, "could not push results to %s: %w", sinkName, err)
+		}
+		fmt.Fprintf(info, "Pushed %d points to %s.\n", len(points), sinkName)
+	}
+
+	notify(cmd.Context(), summary, aggregateReportRows, total, len(failures) > 0)
+
+	if manifestPath != "" {
+		m, err := newManifest(inputs, summary, aggregateReportRows, total, len(failures) > 0)
+		if err != nil {
+			return exitErrorf(exitIO, "could not create manifest: %w", err)
 		}
-		if r.UsageEndTime.After(latestDate) {
-			latestDate = r.UsageEndTime
+		if err := writeManifest(manifestPath, m); err != nil {
+			return exitErrorf(exitIO, "could not write manifest %s: %w", manifestPath, err)
 		}
+		fmt.Fprintf(info, "Wrote manifest to %s.\n", manifestPath)
 	}
 
-	fmt.Printf("Processed %d lines about EC2 usage.\n", lineCount)
-	fmt.Printf("Time range covered: %s - %s (%s).\n\n", earliestDate, latestDate, latestDate.Sub(earliestDate))
+	if budgetExceeded {
+		return errBudgetExceeded
+	}
+	return nil
+}
+
+// emissionOptions are the assumptions and data sources used to estimate
+// emissions.
+type emissionOptions struct {
+	// utilization is the CPU utilization of instances.
+	utilization utilizationTable
+
+	// intensityMode is the carbon intensity mode, e.g. intensityLocation.
+	intensityMode string
+
+	// hourlyIntensity provides the carbon intensity per hour. If set,
+	// usage must be split into hours, and the hourly carbon intensity
+	// replaces the yearly average of a region.
+	hourlyIntensity footprint.CarbonIntensityProvider
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Region", "Instance type", "Duration", "Emissions"})
+	// fallback is the method of estimating instance types missing from
+	// the footprint dataset, e.g. footprint.FallbackFamily.
+	fallback string
 
+	// coverage, if set, records the usage estimated with the fallback or
+	// skipped. Otherwise, skipped usage is logged.
+	coverage *coverage
+}
+
+// newIntensityProvider returns the provider of hourly carbon intensity with
+// the given name, configured from the command line flags.
+func newIntensityProvider(name string) (footprint.CarbonIntensityProvider, error) {
+	switch name {
+	case intensityProviderElectricityMaps:
+		if emapsToken == "" {
+			return nil, fmt.Errorf("%s requires --electricity-maps-token", name)
+		}
+		return footprint.NewElectricityMaps(emapsToken), nil
+	case intensityProviderWattTime:
+		if wattTimeUsername == "" || wattTimePassword == "" {
+			return nil, fmt.Errorf("%s requires --watttime-username and --watttime-password", name)
+		}
+		return footprint.NewWattTime(wattTimeUsername, wattTimePassword), nil
+	}
+	return nil, fmt.Errorf("unknown provider %q, must be one of: %s", name, strings.Join(intensityProviders, ", "))
+}
+
+// defaultEmissionOptions returns the options used when no flags are given.
+func defaultEmissionOptions() emissionOptions {
+	return emissionOptions{
+		utilization:   fixedUtilization(footprint.DefaultUtilization),
+		intensityMode: intensityLocation,
+		fallback:      footprint.FallbackFamily,
+	}
+}
+
+// computeEmissions estimates the emissions for each aggregate row of the
+// summary using the given options. It returns the rows sorted by their key, and the total emissions.
+// Rows are estimated in the order of their keys, so that the total is the
+// same in every run.
+func computeEmissions(ctx context.Context, summary *ReportSummary, options emissionOptions) ([]AggregateReportRow, footprint.Result) {
 	var aggregateReportRows []AggregateReportRow
-	var total float64
+	var total footprint.Result
+
+	keys := make([]string, len(summary.Aggregate))
+	order := make([]int, len(summary.Aggregate))
+	for i, row := range summary.Aggregate {
+		keys[i] = row.key()
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
 
-	for key := range aggregate {
-		result, err := footprint.AWS(aggregate[key].Region, aggregate[key].InstanceType, aggregate[key].Duration)
+	for _, i := range order {
+		row := *summary.Aggregate[i]
+		utilization := options.utilization.rowUtilization(summary.Dimensions, row)
+		result, estimate, err := estimateWithFallback(row, utilization, options.fallback)
+		if err == nil && options.intensityMode == intensityMarket {
+			result, err = marketBased(row, result)
+		}
+		// Outposts are powered by the electricity of their sites, so
+		// the hourly intensity of the grid of a region does not apply.
+		if err == nil && options.hourlyIntensity != nil && !isOutpostRegion(row.Region) {
+			result, err = hourlyBased(ctx, row, result, options.hourlyIntensity)
+		}
+		if errors.Is(err, footprint.ErrUnknownRegion) && isOutpostRegion(row.Region) {
+			err = fmt.Errorf("%w, set the carbon intensity and PUE of its site with --outposts", err)
+		}
 		if err != nil {
-			log.Printf("Error for key %s: %s", key, err)
+			if options.coverage != nil {
+				options.coverage.addSkipped(row, err)
+			} else {
+				log.Printf("Error for %s usage in region %s, type %s: %s", row.Category, row.Region, row.InstanceType+row.StorageType, err)
+			}
 			continue
 		}
+		if options.coverage != nil {
+			options.coverage.add(row, estimate)
+		}
+		if verbose {
+			logModelInputs(row, utilization, estimate, result)
+		}
 
-		aggregateReportRows = append(aggregateReportRows, AggregateReportRow{
-			Region:        aggregate[key].Region,
-			InstanceType:  aggregate[key].InstanceType,
-			Duration:      aggregate[key].Duration,
-			EmissionGrams: result,
-		})
+		row.EnergyKiloWattHours = result.EnergyKiloWattHours
+		row.EmbodiedGrams = result.EmbodiedGrams
+		row.EmissionGrams = result.Total()
+		row.InstanceVCPUHours = instanceVCPUHours(row)
+		row.WaterLiters = result.WaterLiters
+		aggregateReportRows = append(aggregateReportRows, row)
 
-		total += result
+		total = total.Add(result)
 	}
 
-	sort.Slice(aggregateReportRows, func(i, j int) bool {
-		return aggregateReportRows[i].InstanceType < aggregateReportRows[j].InstanceType
-	})
-	sort.Slice(aggregateReportRows, func(i, j int) bool {
-		return aggregateReportRows[i].Region < aggregateReportRows[j].Region
+	return aggregateReportRows, total
+}
+
+// groupRows combines rows with the same labels into one row, summing up
+// their metrics. Rows must be sorted by labels. With a period, rows are
+// combined per period, and the result is sorted by period first. Category,
+// region, instance type, storage type and Multi-AZ deployment of the
+// resulting rows are only set if they are the same for all rows in the
+// group.
+func groupRows(rows []AggregateReportRow, period periodFunc) []AggregateReportRow {
+	periodRows := make([]AggregateReportRow, 0, len(rows))
+	for _, row := range rows {
+		if period == nil || row.Period.IsZero() {
+			row.Period = time.Time{}
+		} else {
+			row.Period = period(row.Period)
+		}
+		periodRows = append(periodRows, row)
+	}
+	sort.SliceStable(periodRows, func(i, j int) bool {
+		return periodRows[i].Period.Before(periodRows[j].Period)
 	})
 
-	for _, row := range aggregateReportRows {
-		table.Append([]string{
-			row.Region,
-			row.InstanceType,
-			row.Duration.String(),
-			formatGrams(row.EmissionGrams),
-		})
-	}
-
-	table.SetFooter([]string{"", "", "Total", formatGrams(total)})
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
-	table.SetHeaderLine(false)
-	table.SetColumnSeparator("")
-	table.SetCenterSeparator("")
-	table.SetRowSeparator("")
-	table.SetBorder(false)
-	table.SetTablePadding("   ")
-	table.Render()
+	var result []AggregateReportRow
+
+	for _, row := range periodRows {
+		last := len(result) - 1
+		if last >= 0 && result[last].Period.Equal(row.Period) && equalLabels(result[last].Labels, row.Labels) {
+			if result[last].Category != row.Category {
+				result[last].Category = ""
+			}
+			if result[last].Region != row.Region {
+				result[last].Region = ""
+			}
+			if result[last].InstanceType != row.InstanceType {
+				result[last].InstanceType = ""
+			}
+			if result[last].StorageType != row.StorageType {
+				result[last].StorageType = ""
+			}
+			if result[last].MultiAZ != row.MultiAZ {
+				result[last].MultiAZ = false
+			}
+			result[last].addMetrics(row)
+			continue
+		}
+		result = append(result, row)
+	}
+
+	return result
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// printFailures lists the files that could not be processed, along with the
+// time range of the data affected, if known.
+func printFailures(w io.Writer, failures []FileFailure, fileCount int) {
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nWARNING: The result is partial. %d of %d files could not be processed:\n", len(failures), fileCount)
+	for _, f := range failures {
+		fmt.Fprintf(w, "  - %s: %s\n", f.Path, f.Err)
+		if f.Summary == nil || f.Summary.LineCount == 0 {
+			fmt.Fprintf(w, "    Affected time range: unknown (no usage rows read)\n")
+		} else {
+			fmt.Fprintf(w, "    Affected time range: at least %s - %s\n", f.Summary.EarliestDate, f.Summary.LatestDate)
+		}
+	}
 }
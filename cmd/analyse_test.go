@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+var testReportHeader = []string{
+	"bill/PayerAccountId",
+	"identity/TimeInterval",
+	"lineItem/LineItemType",
+	"lineItem/Operation",
+	"lineItem/ProductCode",
+	"lineItem/UsageAccountId",
+	"lineItem/UsageEndDate",
+	"lineItem/UsageStartDate",
+	"product/instanceType",
+	"product/productFamily",
+	"product/regionCode",
+	"lineItem/AvailabilityZone",
+	"lineItem/UsageType",
+	"lineItem/UsageAmount",
+	"resourceTags/user:team",
+}
+
+// testUsageRecord returns a CSV record for one hour of EC2 instance usage,
+// matching testReportHeader.
+func testUsageRecord(region, instanceType, start string) []string {
+	startTime := mustParseDate(start)
+	end := startTime.Add(time.Hour).Format(dateTimeLayout)
+	return []string{
+		"111111111111",
+		start + "/" + end,
+		"Usage",
+		"RunInstances",
+		"AmazonEC2",
+		"222222222222",
+		end,
+		start,
+		instanceType,
+		"Compute Instance",
+		region,
+		region + "a",
+		"EUC1-BoxUsage:" + instanceType,
+		"1",
+		"platform",
+	}
+}
+
+// writeTestReport writes a CSV report with the given records into a
+// temporary directory and returns its path. The file is gzip compressed
+// if name ends in ".gz".
+func writeTestReport(t testing.TB, name string, records [][]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var out io.Writer = f
+	var gz *gzip.Writer
+	if strings.HasSuffix(name, ".gz") {
+		gz = gzip.NewWriter(f)
+		out = gz
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write(testReportHeader); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteAll(records); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+func Test_analyseSource(t *testing.T) {
+	valid := writeTestReport(t, "valid.csv.gz", [][]string{
+		testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T00:00:00Z"),
+		testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T01:00:00Z"),
+		testUsageRecord("eu-central-1", "m5.xlarge", "2022-08-01T05:00:00Z"),
+	})
+
+	plain := writeTestReport(t, "plain.csv", [][]string{
+		testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T00:00:00Z"),
+	})
+
+	// A file starting with the gzip magic bytes, but no valid gzip header.
+	brokenGzip := filepath.Join(t.TempDir(), "broken.csv.gz")
+	if err := os.WriteFile(brokenGzip, []byte{0x1f, 0x8b, 0x00}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		wantErr       bool
+		wantLineCount int
+		wantAggregate map[string]time.Duration
+	}{
+		{
+			name:          "valid",
+			path:          valid,
+			wantLineCount: 3,
+			wantAggregate: map[string]time.Duration{
+				defaultKey("eu-west-1", "t2.micro"):     2 * time.Hour,
+				defaultKey("eu-central-1", "m5.xlarge"): time.Hour,
+			},
+		},
+		{
+			name:          "plain",
+			path:          plain,
+			wantLineCount: 1,
+			wantAggregate: map[string]time.Duration{
+				defaultKey("eu-west-1", "t2.micro"): time.Hour,
+			},
+		},
+		{name: "missing", path: filepath.Join(t.TempDir(), "missing.csv.gz"), wantErr: true},
+		{name: "broken gzip", path: brokenGzip, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyseSource(context.Background(), localSource(tt.path), analyseReport, testDimensions(t, defaultGroupBy), summaryOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("analyseSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got == nil {
+				t.Fatal("analyseSource() returned nil summary")
+			}
+			if got.LineCount != tt.wantLineCount {
+				t.Errorf("analyseSource() LineCount = %d, want %d", got.LineCount, tt.wantLineCount)
+			}
+			if len(got.Aggregate) != len(tt.wantAggregate) {
+				t.Errorf("analyseSource() got %d aggregate rows, want %d", len(got.Aggregate), len(tt.wantAggregate))
+			}
+			rows := aggregateByKey(got)
+			for key, want := range tt.wantAggregate {
+				if rows[key].Duration != want {
+					t.Errorf("analyseSource() key %s duration = %s, want %s", key, rows[key].Duration, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_analyseReport_schema(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   string
+	}{
+		{
+			name:   "missing columns",
+			report: "lineItem/LineItemType,lineItem/ProductCode,lineItem/UsageStartDate,lineItem/UsageEndDate\nUsage,AmazonEC2,,\n",
+			want:   "report in legacy CUR format is missing columns lineItem/UsageType, lineItem/UsageAmount",
+		},
+		{
+			name:   "GCP billing export",
+			report: "service_description,sku_description,usage_amount\nCompute Engine,N1 Predefined Instance Core running in Americas,3600\n",
+			want:   "the file looks like a GCP billing export: use --provider gcp",
+		},
+		{
+			name:   "Azure cost details export",
+			report: testAzureReport,
+			want:   "the file looks like an Azure cost details export: use --provider azure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := analyseReport(strings.NewReader(tt.report), newReportSummary(nil))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("analyseReport() error = %v, want %q", err, tt.want)
+			}
+			if code := exitCode(err); code != exitParse {
+				t.Errorf("analyseReport() exit code = %d, want %d", code, exitParse)
+			}
+		})
+	}
+}
+
+func TestReportSummary_merge(t *testing.T) {
+	dimensions := testDimensions(t, defaultGroupBy)
+
+	a := newReportSummary(dimensions)
+	a.add(testReportRow(testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T00:00:00Z")))
+
+	b := newReportSummary(dimensions)
+	b.add(testReportRow(testUsageRecord("eu-west-1", "t2.micro", "2022-08-03T00:00:00Z")))
+	b.add(testReportRow(testUsageRecord("us-east-1", "t2.micro", "2022-08-02T00:00:00Z")))
+
+	a.merge(b)
+
+	if a.LineCount != 3 {
+		t.Errorf("merge() LineCount = %d, want 3", a.LineCount)
+	}
+	if got := aggregateByKey(a)[defaultKey("eu-west-1", "t2.micro")].Duration; got != 2*time.Hour {
+		t.Errorf("merge() eu-west-1_t2.micro duration = %s, want 2h", got)
+	}
+	if want := mustParseDate("2022-08-01T00:00:00Z"); !a.EarliestDate.Equal(want) {
+		t.Errorf("merge() EarliestDate = %s, want %s", a.EarliestDate, want)
+	}
+	if want := mustParseDate("2022-08-03T01:00:00Z"); !a.LatestDate.Equal(want) {
+		t.Errorf("merge() LatestDate = %s, want %s", a.LatestDate, want)
+	}
+}
+
+// testReportRow reads a report row from a record with testReportHeader,
+// as done by analyseReport for EC2 usage.
+func testReportRow(record []string) ReportRow {
+	item, _, err := cur.NewParser(testReportHeader, []string{"team"}).Parse(record)
+	if err != nil {
+		panic(err)
+	}
+	return reportRow(item)
+}
+
+func testDimensions(t *testing.T, groupBy string) []Dimension {
+	t.Helper()
+
+	dimensions, err := parseGroupBy(groupBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dimensions
+}
+
+// defaultKey returns the aggregate key of a row when grouping by the
+// default dimensions.
+func defaultKey(region, instanceType string) string {
+	return AggregateReportRow{Labels: []string{categoryEC2, region, instanceType}, Category: categoryEC2, Region: region, InstanceType: instanceType}.key()
+}
+
+// testHourlyIntensity returns 100 g/kWh for even and 200 g/kWh for odd hours.
+type testHourlyIntensity struct{}
+
+func (testHourlyIntensity) HourlyCarbonIntensity(ctx context.Context, regionCode string, t time.Time) (float64, error) {
+	if t.Hour()%2 == 0 {
+		return 100, nil
+	}
+	return 200, nil
+}
+
+func Test_computeEmissions_hourly(t *testing.T) {
+	summary, err := analyseSource(context.Background(), localSource("testdata/replay-usage.csv"), analyseReport, testDimensions(t, "region,instance-type"), summaryOptions{period: hourPeriod})
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+
+	options := defaultEmissionOptions()
+	options.hourlyIntensity = testHourlyIntensity{}
+	rows, _ := computeEmissions(context.Background(), summary, options)
+
+	hours := 0
+	for _, row := range rows {
+		if row.Category != categoryEC2 || row.InstanceType != "t2.micro" {
+			continue
+		}
+		hours++
+		if row.Duration != time.Hour {
+			t.Errorf("computeEmissions() row for %s duration = %s, want 1h", row.Period, row.Duration)
+		}
+		want := row.EnergyKiloWattHours * 100
+		if row.Period.Hour()%2 == 1 {
+			want = row.EnergyKiloWattHours * 200
+		}
+		if !withinTolerance(row.operationalGrams(), want, 1e-9) {
+			t.Errorf("computeEmissions() row for %s operational emissions = %v, want %v", row.Period, row.operationalGrams(), want)
+		}
+	}
+	if hours != 6 {
+		t.Errorf("computeEmissions() returned %d hourly rows for t2.micro, want 6", hours)
+	}
+
+	grouped := groupRows(rows, nil)
+	for _, row := range grouped {
+		if row.InstanceType == "t2.micro" && row.Duration != 6*time.Hour {
+			t.Errorf("groupRows() t2.micro duration = %s, want 6h", row.Duration)
+		}
+	}
+
+	daily := groupRows(rows, dayPeriod)
+	want := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	for _, row := range daily {
+		if !row.Period.Equal(want) {
+			t.Errorf("groupRows() period = %s, want %s", row.Period, want)
+		}
+		if row.InstanceType == "t2.micro" && row.Duration != 6*time.Hour {
+			t.Errorf("groupRows() daily t2.micro duration = %s, want 6h", row.Duration)
+		}
+	}
+}
+
+func Test_formatLiters(t *testing.T) {
+	tests := []struct {
+		liters float64
+		want   string
+	}{
+		{0.25, "0.25 L"},
+		{12.34, "12.3 L"},
+		{2500, "2.5 m³"},
+	}
+
+	for _, tt := range tests {
+		if got := formatLiters(tt.liters); got != tt.want {
+			t.Errorf("formatLiters(%v) = %q, want %q", tt.liters, got, tt.want)
+		}
+	}
+}
+
+// Benchmark_analyseSource measures parsing and aggregating a report of
+// 100,000 lines by all goroutines.
+func Benchmark_analyseSource(b *testing.B) {
+	start := mustParseDate("2022-08-01T00:00:00Z")
+	regions := []string{"eu-west-1", "eu-central-1", "us-east-1"}
+	instanceTypes := []string{"t2.micro", "m5.xlarge", "c5.2xlarge", "r5.large"}
+	records := make([][]string, 100000)
+	for i := range records {
+		hour := start.Add(time.Duration(i%720) * time.Hour).Format(dateTimeLayout)
+		records[i] = testUsageRecord(regions[i%len(regions)], instanceTypes[i%len(instanceTypes)], hour)
+	}
+	path := writeTestReport(b, "report.csv", records)
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	dimensions, err := parseGroupBy(defaultGroupBy)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(info.Size())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyseSource(context.Background(), localSource(path), analyseReport, dimensions, summaryOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportManifestFile is a Cost and Usage Report manifest found in a
+// directory or under an S3 prefix.
+type reportManifestFile struct {
+	// key is the slash-separated path or S3 key of the manifest.
+	key string
+
+	// modified is the time the manifest was last written.
+	modified time.Time
+}
+
+// isAssemblyManifest returns true if key is the copy of a manifest AWS
+// places in the folder of a report version, also known as assembly, inside
+// the folder of a billing period.
+func isAssemblyManifest(key string) bool {
+	return strings.HasSuffix(key, manifestSuffix) && billingPeriodPattern.MatchString(path.Base(path.Dir(path.Dir(key))))
+}
+
+// selectManifests returns the keys of the manifests listing the report files
+// to analyse, sorted. AWS delivers a new version of the report files of a
+// billing period whenever its data is updated, and keeps the previous
+// versions, so reading all report files would count usage several times.
+// The manifest in the folder of a billing period lists the files of its
+// latest version. For billing periods without it, e.g. if only the version
+// folders were copied, the most recently written manifest of a version is
+// used. Manifests in other places are ignored.
+func selectManifests(manifests []reportManifestFile) []string {
+	periods := make(map[string]bool)
+	for _, m := range manifests {
+		if isBillingPeriodManifest(m.key) {
+			periods[path.Dir(m.key)] = true
+		}
+	}
+
+	latest := make(map[string]reportManifestFile)
+	var keys []string
+	for _, m := range manifests {
+		switch {
+		case isBillingPeriodManifest(m.key):
+			keys = append(keys, m.key)
+		case isAssemblyManifest(m.key):
+			period := path.Dir(path.Dir(m.key))
+			if periods[period] {
+				continue
+			}
+			l, exists := latest[period]
+			if !exists || m.modified.After(l.modified) || m.modified.Equal(l.modified) && m.key > l.key {
+				latest[period] = m
+			}
+		}
+	}
+	for _, m := range latest {
+		keys = append(keys, m.key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// manifestPeriod returns the key of the billing period folder a manifest
+// selected by selectManifests belongs to.
+func manifestPeriod(key string) string {
+	if isBillingPeriodManifest(key) {
+		return path.Dir(key)
+	}
+	return path.Dir(path.Dir(key))
+}
+
+// mergeManifestReports returns the report files to analyse, given the keys
+// of all report files found, the selected manifests and the report files
+// listed in them. In the billing period folders of the selected manifests,
+// only the listed files are used, and the number of others, e.g. of earlier
+// report versions, is logged. Report files outside of these folders, e.g. of
+// billing periods exported without a manifest, are kept. All keys are
+// slash-separated.
+func mergeManifestReports(found, selected, listed []string) []string {
+	periods := make([]string, 0, len(selected))
+	for _, manifest := range selected {
+		periods = append(periods, manifestPeriod(manifest))
+	}
+	isListed := make(map[string]bool, len(listed))
+	for _, key := range listed {
+		isListed[key] = true
+	}
+
+	result := append([]string(nil), listed...)
+	ignored := make(map[string]int)
+	for _, key := range found {
+		period := ""
+		for _, p := range periods {
+			if strings.HasPrefix(key, p+"/") {
+				period = p
+				break
+			}
+		}
+		switch {
+		case period == "":
+			result = append(result, key)
+		case !isListed[key]:
+			ignored[period]++
+		}
+	}
+
+	for _, period := range sortedKeys(ignored) {
+		log.Printf("Ignoring %d report files in %s not listed in its latest manifest", ignored[period], period)
+	}
+	return result
+}
+
+// localReportPath returns the local path of a report file listed with key
+// in the manifest at manifestPath, for reports copied from S3 into a local
+// directory. Keys are relative to the folder of the manifest, which is found
+// by its name in the key, so that the location of the copy does not matter.
+func localReportPath(manifestPath, key string) string {
+	dir := filepath.Dir(manifestPath)
+	_, rest, found := strings.Cut(key, "/"+filepath.Base(dir)+"/")
+	if !found {
+		rest = path.Base(key)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rest))
+}
+
+// readLocalManifestPaths reads the manifest at path and returns the local
+// paths of the report files listed in it.
+func readLocalManifestPaths(manifestPath string) ([]string, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ReportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse manifest %s: %w", manifestPath, err)
+	}
+
+	paths := make([]string, 0, len(manifest.ReportKeys))
+	for _, key := range manifest.ReportKeys {
+		paths = append(paths, localReportPath(manifestPath, key))
+	}
+	return paths, nil
+}
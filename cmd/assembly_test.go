@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_selectManifests(t *testing.T) {
+	older := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		manifests []reportManifestFile
+		want      []string
+	}{
+		{
+			name: "billing period manifest",
+			manifests: []reportManifestFile{
+				{key: "cur/20220801-20220901/v1/cur-Manifest.json", modified: newer},
+				{key: "cur/20220801-20220901/cur-Manifest.json", modified: older},
+				{key: "cur/20220801-20220901/v2/cur-Manifest.json", modified: older},
+			},
+			want: []string{"cur/20220801-20220901/cur-Manifest.json"},
+		},
+		{
+			name: "latest assembly per billing period",
+			manifests: []reportManifestFile{
+				{key: "cur/20220801-20220901/v1/cur-Manifest.json", modified: older},
+				{key: "cur/20220801-20220901/v2/cur-Manifest.json", modified: newer},
+				{key: "cur/20220901-20221001/v3/cur-Manifest.json", modified: older},
+				{key: "cur/20220901-20221001/v4/cur-Manifest.json", modified: older},
+			},
+			want: []string{"cur/20220801-20220901/v2/cur-Manifest.json", "cur/20220901-20221001/v4/cur-Manifest.json"},
+		},
+		{
+			name:      "other manifests",
+			manifests: []reportManifestFile{{key: "cur/cur-Manifest.json"}, {key: "cur/20220801-20220901/v1/cur-RedshiftManifest.json"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectManifests(tt.manifests); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("selectManifests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_localReportPath(t *testing.T) {
+	tests := []struct {
+		manifest string
+		key      string
+		want     string
+	}{
+		{manifest: "copy/20220801-20220901/cur-Manifest.json", key: "cur/report/20220801-20220901/v2/cur-00001.csv.gz", want: "copy/20220801-20220901/v2/cur-00001.csv.gz"},
+		{manifest: "copy/20220801-20220901/v2/cur-Manifest.json", key: "cur/report/20220801-20220901/v2/cur-00001.csv.gz", want: "copy/20220801-20220901/v2/cur-00001.csv.gz"},
+		{manifest: "copy/cur-Manifest.json", key: "cur/report/20220801-20220901/cur-00001.csv.gz", want: "copy/cur-00001.csv.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := localReportPath(filepath.FromSlash(tt.manifest), tt.key); got != filepath.FromSlash(tt.want) {
+				t.Errorf("localReportPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expandPaths_assemblies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20220801-20220901/v1/cur-Manifest.json": `{"reportKeys": ["cur/20220801-20220901/v1/cur-00001.csv.gz"]}`,
+		"20220801-20220901/v1/cur-00001.csv.gz":  "",
+		"20220801-20220901/v2/cur-Manifest.json": `{"reportKeys": ["cur/20220801-20220901/v2/cur-00001.csv.gz", "cur/20220801-20220901/v2/cur-00002.csv.gz"]}`,
+		"20220801-20220901/v2/cur-00001.csv.gz":  "",
+		"20220801-20220901/v2/cur-00002.csv.gz":  "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "20220801-20220901", "v1", "cur-Manifest.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := expandPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("expandPaths() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "20220801-20220901", "v2", "cur-00001.csv.gz"),
+		filepath.Join(dir, "20220801-20220901", "v2", "cur-00002.csv.gz"),
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expandPaths() = %v, want %v", got, want)
+	}
+}
+
+func Test_expandPaths_periodWithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20220801-20220901/cur-Manifest.json":   `{"reportKeys": ["cur/20220801-20220901/v2/cur-00001.csv.gz"]}`,
+		"20220801-20220901/v1/cur-00001.csv.gz": "",
+		"20220801-20220901/v2/cur-00001.csv.gz": "",
+		"20220901-20221001/v3/cur-00001.csv.gz": "",
+		"20220901-20221001/v3/cur-00002.csv.gz": "",
+		"athena/2022-10.csv":                    "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := expandPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("expandPaths() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "20220801-20220901", "v2", "cur-00001.csv.gz"),
+		filepath.Join(dir, "20220901-20221001", "v3", "cur-00001.csv.gz"),
+		filepath.Join(dir, "20220901-20221001", "v3", "cur-00002.csv.gz"),
+		filepath.Join(dir, "athena", "2022-10.csv"),
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expandPaths() = %v, want %v", got, want)
+	}
+}
+
+func Test_mergeManifestReports(t *testing.T) {
+	found := []string{
+		"cur/20220801-20220901/v1/cur-00001.csv.gz",
+		"cur/20220801-20220901/v2/cur-00001.csv.gz",
+		"cur/20220901-20221001/v3/cur-00001.csv.gz",
+	}
+	selected := []string{"cur/20220801-20220901/v2/cur-Manifest.json"}
+	listed := []string{"cur/20220801-20220901/v2/cur-00001.csv.gz"}
+
+	got := mergeManifestReports(found, selected, listed)
+	want := []string{
+		"cur/20220801-20220901/v2/cur-00001.csv.gz",
+		"cur/20220901-20221001/v3/cur-00001.csv.gz",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("mergeManifestReports() = %v, want %v", got, want)
+	}
+}
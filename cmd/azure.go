@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// categoryAzureVM is the usage category of Azure virtual machines.
+const categoryAzureVM = "VirtualMachines"
+
+// Columns of an Azure cost details export. Column names differ in case
+// between export versions, so they are matched in lower case.
+const (
+	azureHeaderAdditionalInfo   = "additionalinfo"
+	azureHeaderDate             = "date"
+	azureHeaderMeterCategory    = "metercategory"
+	azureHeaderMeterName        = "metername"
+	azureHeaderQuantity         = "quantity"
+	azureHeaderResourceLocation = "resourcelocation"
+	azureHeaderSubscriptionID   = "subscriptionid"
+	azureHeaderUnitOfMeasure    = "unitofmeasure"
+
+	// azureVMSizePrefix is the prefix of VM size names, e.g. Standard_D2s_v3.
+	azureVMSizePrefix = "Standard_"
+)
+
+// azureDateLayouts are the formats of the date column in the different
+// export versions.
+var azureDateLayouts = []string{"01/02/2006", "2006-01-02"}
+
+// azureLegacyLocations maps the location names used in older exports to
+// region codes.
+var azureLegacyLocations = map[string]string{
+	"ap east":          "eastasia",
+	"ap southeast":     "southeastasia",
+	"au east":          "australiaeast",
+	"au southeast":     "australiasoutheast",
+	"br south":         "brazilsouth",
+	"ca central":       "canadacentral",
+	"ca east":          "canadaeast",
+	"ch north":         "switzerlandnorth",
+	"de west central":  "germanywestcentral",
+	"eu north":         "northeurope",
+	"eu west":          "westeurope",
+	"fr central":       "francecentral",
+	"in central":       "centralindia",
+	"in south":         "southindia",
+	"in west":          "westindia",
+	"ja east":          "japaneast",
+	"ja west":          "japanwest",
+	"kr central":       "koreacentral",
+	"no east":          "norwayeast",
+	"se central":       "swedencentral",
+	"uk south":         "uksouth",
+	"uk west":          "ukwest",
+	"us central":       "centralus",
+	"us east":          "eastus",
+	"us east 2":        "eastus2",
+	"us north central": "northcentralus",
+	"us south central": "southcentralus",
+	"us west":          "westus",
+	"us west 2":        "westus2",
+	"us west 3":        "westus3",
+	"us west central":  "westcentralus",
+}
+
+// analyseAzureReport reads CSV data from an Azure cost details export,
+// optionally gzip compressed, from r and adds the virtual machine usage found
+// to summary. Both actual and amortized cost exports can be used, as only
+// the usage quantity is evaluated.
+func analyseAzureReport(r io.Reader, summary *ReportSummary) error {
+	csvFile, err := maybeDecompress(r)
+	if err != nil {
+		return fmt.Errorf("could not uncompress file: %w", err)
+	}
+	defer csvFile.Close()
+
+	fcsv := csv.NewReader(csvFile)
+	csvRecord, err := fcsv.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read CSV: %w", err)
+	}
+
+	columns := make([]string, len(csvRecord))
+	for i, column := range csvRecord {
+		// Exports may start with a byte order mark.
+		columns[i] = strings.ToLower(strings.TrimPrefix(column, "\ufeff"))
+	}
+	if len(summary.tagKeys()) > 0 {
+		log.Printf("Warning: grouping by tags is not supported for Azure reports, all usage will be shown as %s.", untaggedLabel)
+	}
+
+	return summary.run(&azureReader{csv: fcsv, headers: newReportHeaders(columns), summary: summary})
+}
+
+// azureReader provides the line items of the virtual machine usage in an
+// Azure cost details export. Lines with invalid dates are skipped if
+// summary.LenientDates is set.
+type azureReader struct {
+	csv     *csv.Reader
+	headers reportHeaders
+	summary *ReportSummary
+}
+
+func (r *azureReader) Next() (cur.LineItem, error) {
+	for {
+		csvRecord, err := r.csv.Read()
+		if err == io.EOF {
+			return cur.LineItem{}, io.EOF
+		}
+		if err != nil {
+			return cur.LineItem{}, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		if r.headers.value(csvRecord, azureHeaderMeterCategory) != "Virtual Machines" {
+			continue
+		}
+		if !strings.Contains(r.headers.value(csvRecord, azureHeaderUnitOfMeasure), "Hour") {
+			continue
+		}
+
+		row, err := readAzureReportRow(r.headers, csvRecord)
+		if err != nil {
+			line, _ := r.csv.FieldPos(0)
+			if r.summary.skipInvalidDate(line, err) {
+				continue
+			}
+			return cur.LineItem{}, parseErrorf("line %d: %w", line, err)
+		}
+		return lineItem(row), nil
+	}
+}
+
+func readAzureReportRow(headers reportHeaders, fields []string) (ReportRow, error) {
+	r := ReportRow{
+		Category:       categoryAzureVM,
+		UsageAccountID: headers.value(fields, azureHeaderSubscriptionID),
+		Region:         azureRegion(headers.value(fields, azureHeaderResourceLocation)),
+		InstanceType:   azureVMSize(headers.value(fields, azureHeaderAdditionalInfo), headers.value(fields, azureHeaderMeterName)),
+	}
+
+	date := headers.value(fields, azureHeaderDate)
+	var err error
+	r.UsageStartTime, err = parseAzureDate(date)
+	if err != nil {
+		return r, &cur.DateError{Column: azureHeaderDate, Value: date}
+	}
+	r.UsageEndTime = r.UsageStartTime.AddDate(0, 0, 1)
+
+	quantity, err := readUsageAmountColumn(headers, fields, azureHeaderQuantity)
+	if err != nil {
+		return r, err
+	}
+	hoursPerUnit, err := azureHoursPerUnit(headers.value(fields, azureHeaderUnitOfMeasure))
+	if err != nil {
+		return r, err
+	}
+	r.Duration = time.Duration(quantity * hoursPerUnit * float64(time.Hour))
+
+	return r, nil
+}
+
+// azureRegion returns the region code for a resource location, which is
+// either a region code like "westeurope" or a legacy name like "EU West".
+func azureRegion(location string) string {
+	location = strings.ToLower(strings.TrimSpace(location))
+	if region, exists := azureLegacyLocations[location]; exists {
+		return region
+	}
+	return strings.ReplaceAll(location, " ", "")
+}
+
+// azureVMSize returns the VM size of a usage record. It is taken from the
+// ServiceType in the additional info JSON, or else derived from the meter
+// name, e.g. "D2s v3".
+func azureVMSize(additionalInfo, meterName string) string {
+	var info struct {
+		ServiceType string
+	}
+	if json.Unmarshal([]byte(additionalInfo), &info) == nil && info.ServiceType != "" {
+		return info.ServiceType
+	}
+
+	// Meter names of low priority and spot VMs carry a suffix.
+	meterName = strings.TrimSuffix(strings.TrimSuffix(meterName, " Low Priority"), " Spot")
+	return azureVMSizePrefix + strings.ReplaceAll(meterName, " ", "_")
+}
+
+// azureHoursPerUnit returns the number of hours of a unit of measure like
+// "1 Hour" or "100 Hours".
+func azureHoursPerUnit(unit string) (float64, error) {
+	count, _, found := strings.Cut(unit, " ")
+	if !found {
+		count = "1"
+	}
+	hours, err := strconv.ParseFloat(count, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected unit of measure %q", unit)
+	}
+	return hours, nil
+}
+
+func parseAzureDate(s string) (time.Time, error) {
+	for _, layout := range azureDateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("error parsing date %q", s)
+}
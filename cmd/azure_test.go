@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testAzureReport = "\ufeff" + `SubscriptionId,Date,MeterCategory,MeterName,ResourceLocation,Quantity,UnitOfMeasure,AdditionalInfo
+sub-1,08/01/2022,Virtual Machines,D2s v3,EU West,24,1 Hour,"{""ServiceType"":""Standard_D2s_v3"",""VCPUs"":2}"
+sub-1,08/02/2022,Virtual Machines,D2s v3,westeurope,0.12,100 Hours,
+sub-2,08/01/2022,Virtual Machines,B2s Spot,eastus,10,1 Hour,
+sub-1,08/01/2022,Storage,P10 Disks,westeurope,1,1/Month,
+`
+
+func Test_analyseAzureReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure.csv")
+	err := os.WriteFile(path, []byte(testAzureReport), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := analyseSource(context.Background(), localSource(path), analyseAzureReport, testDimensions(t, "account,region,instance-type"), summaryOptions{})
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+
+	if summary.LineCount != 3 {
+		t.Errorf("analyseSource() LineCount = %d, want 3", summary.LineCount)
+	}
+	if want := mustParseDate("2022-08-03T00:00:00Z"); !summary.LatestDate.Equal(want) {
+		t.Errorf("analyseSource() LatestDate = %s, want %s", summary.LatestDate, want)
+	}
+
+	want := map[string]time.Duration{
+		"sub-1 westeurope Standard_D2s_v3": 36 * time.Hour,
+		"sub-2 eastus Standard_B2s":        10 * time.Hour,
+	}
+	if len(summary.Aggregate) != len(want) {
+		t.Fatalf("analyseSource() got %d aggregate rows, want %d", len(summary.Aggregate), len(want))
+	}
+	for _, row := range summary.Aggregate {
+		key := row.Labels[0] + " " + row.Labels[1] + " " + row.Labels[2]
+		if row.Duration != want[key] {
+			t.Errorf("analyseSource() row %s duration = %s, want %s", key, row.Duration, want[key])
+		}
+	}
+
+	rows, _ := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+	if len(rows) != 2 {
+		t.Errorf("computeEmissions() returned %d rows, want 2", len(rows))
+	}
+}
+
+func Test_azureHoursPerUnit(t *testing.T) {
+	tests := []struct {
+		unit    string
+		want    float64
+		wantErr bool
+	}{
+		{unit: "1 Hour", want: 1},
+		{unit: "100 Hours", want: 100},
+		{unit: "Hours", want: 1},
+		{unit: "a few Hours", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			got, err := azureHoursPerUnit(tt.unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("azureHoursPerUnit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("azureHoursPerUnit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
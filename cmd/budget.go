@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Periods an emissions budget can apply to.
+const (
+	budgetPeriodRun   = "run"
+	budgetPeriodMonth = "month"
+)
+
+var budgetPeriods = []string{budgetPeriodRun, budgetPeriodMonth}
+
+// errBudgetExceeded is returned by the analyse command if the emissions
+// exceed the budget, so that it exits with exitFailure once all output is
+// written.
+var errBudgetExceeded = errors.New("emissions exceed the budget")
+
+var (
+	budget       string
+	budgetPeriod string
+)
+
+func init() {
+	analyseCmd.Flags().StringVar(&budget, "budget", "", "Emissions budget, e.g. 500kg or 2t. If the emissions exceed it, a warning is printed and the command exits with status 1")
+	analyseCmd.Flags().StringVar(&budgetPeriod, "budget-period", budgetPeriodRun, fmt.Sprintf("Period the --budget applies to, one of: %s", strings.Join(budgetPeriods, ", ")))
+}
+
+// massUnits maps the units accepted for budgets to their size in grams.
+var massUnits = map[string]float64{
+	"g":  1,
+	"kg": 1000,
+	"t":  1000 * 1000,
+}
+
+// parseMass parses an amount of CO2e with a unit of g, kg or t, e.g. 500kg,
+// and returns it in grams.
+func parseMass(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	number := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyz")
+	unit := strings.TrimSpace(s[len(number):])
+
+	factor, exists := massUnits[unit]
+	if !exists {
+		return 0, fmt.Errorf("%q has no unit of g, kg or t", s)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%q is not a positive amount", s)
+	}
+	return value * factor, nil
+}
+
+// budgetUsage is the emissions of a budget period.
+type budgetUsage struct {
+	// Period names the period, e.g. 2022-08, empty for the whole run.
+	Period string
+	Grams  float64
+}
+
+// budgetUsages returns the emissions of each budget period of the rows, in
+// chronological order. For monthly budgets, rows must be split into periods
+// of at most a month.
+func budgetUsages(rows []AggregateReportRow, period string) []budgetUsage {
+	if period != budgetPeriodMonth {
+		var grams float64
+		for _, row := range rows {
+			grams += row.EmissionGrams
+		}
+		return []budgetUsage{{Grams: grams}}
+	}
+
+	var usages []budgetUsage
+	for _, p := range timeSeries(nil, withoutLabels(rows), monthPeriod) {
+		usages = append(usages, budgetUsage{Period: p.Period.Format(periodLayouts[periodMonth]), Grams: p.EmissionGrams})
+	}
+	return usages
+}
+
+// writeBudget compares the emissions of each period with the budget, given in
+// grams, and returns whether the budget was exceeded in any period.
+func writeBudget(w io.Writer, budget float64, usages []budgetUsage) bool {
+	exceeded := false
+	fmt.Fprintln(w)
+	for _, u := range usages {
+		name := "Emissions"
+		if u.Period != "" {
+			name = "Emissions in " + u.Period
+		}
+		share := u.Grams / budget * 100
+		if u.Grams > budget {
+			exceeded = true
+			fmt.Fprintf(w, "WARNING: %s of %s exceed the budget of %s by %s (%.0f%% of the budget).\n",
+				name, formatGrams(u.Grams), formatGrams(budget), formatGrams(u.Grams-budget), share)
+		} else {
+			fmt.Fprintf(w, "%s of %s are within the budget of %s (%.0f%% of the budget).\n",
+				name, formatGrams(u.Grams), formatGrams(budget), share)
+		}
+	}
+	return exceeded
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseMass(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "500kg", want: 500000},
+		{value: "2.5 t", want: 2500000},
+		{value: "800g", want: 800},
+		{value: "1KG", want: 1000},
+		{value: "500", wantErr: true},
+		{value: "500lb", wantErr: true},
+		{value: "kg", wantErr: true},
+		{value: "-5kg", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseMass(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseMass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_budgetUsages(t *testing.T) {
+	july := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Period: july, EmissionGrams: 400},
+		{Labels: []string{"eu-west-1"}, Period: july.AddDate(0, 1, 3), EmissionGrams: 200},
+		{Labels: []string{"us-east-1"}, Period: july.AddDate(0, 0, 5), EmissionGrams: 300},
+	}
+
+	if got, want := budgetUsages(rows, budgetPeriodRun), []budgetUsage{{Grams: 900}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("budgetUsages() per run = %v, want %v", got, want)
+	}
+	if got, want := budgetUsages(rows, budgetPeriodMonth), []budgetUsage{{Period: "2022-07", Grams: 700}, {Period: "2022-08", Grams: 200}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("budgetUsages() per month = %v, want %v", got, want)
+	}
+}
+
+func Test_writeBudget(t *testing.T) {
+	var buf bytes.Buffer
+	if writeBudget(&buf, 500, []budgetUsage{{Grams: 400}}) {
+		t.Error("writeBudget() reports the budget as exceeded")
+	}
+	if want := "Emissions of 400 gCO2e are within the budget of 500 gCO2e (80% of the budget)."; !strings.Contains(buf.String(), want) {
+		t.Errorf("writeBudget() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if !writeBudget(&buf, 500, []budgetUsage{{Period: "2022-07", Grams: 700}, {Period: "2022-08", Grams: 200}}) {
+		t.Error("writeBudget() does not report the budget as exceeded")
+	}
+	for _, want := range []string{
+		"WARNING: Emissions in 2022-07 of 700 gCO2e exceed the budget of 500 gCO2e by 200 gCO2e (140% of the budget).",
+		"Emissions in 2022-08 of 200 gCO2e are within the budget",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeBudget() is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
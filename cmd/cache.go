@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// summaryCacheVersion is part of all cache keys. It must be changed
+// whenever the content of summaries changes for the same report file and
+// settings, e.g. when parsing is fixed.
+const summaryCacheVersion = "3"
+
+// summaryCache stores the summaries of report files in a local directory,
+// so that unchanged files don't need to be parsed again. Entries are keyed
+// by the checksum of the file and the settings affecting summaries, like
+// the grouping dimensions.
+type summaryCache struct {
+	dir      string
+	settings string
+}
+
+// cachedSummary is the content of a cache entry.
+type cachedSummary struct {
+	LineCount    int
+	SkippedCount int
+
+	// InvalidDateCount is the number of lines skipped for invalid dates,
+	// without the lines, as they are only listed when a file is read.
+	InvalidDateCount int
+
+	EarliestDate time.Time
+	LatestDate   time.Time
+	Rows         []AggregateReportRow
+	Spend        map[string]float64
+}
+
+// newSummaryCache returns a cache storing entries in dir, which is created
+// if it doesn't exist. settings are the values of all options affecting
+// the summary of a file.
+func newSummaryCache(dir string, settings []string) (*summaryCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+	return &summaryCache{dir: dir, settings: strings.Join(settings, "\x00")}, nil
+}
+
+// path returns the path of the cache entry for a file with the given
+// checksum.
+func (c *summaryCache) path(checksum string) string {
+	sum := sha256.Sum256([]byte(summaryCacheVersion + "\x00" + c.settings + "\x00" + checksum))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// lookup returns the checksum of src and its cached summary, or a nil
+// summary if there is none. Errors are logged and treated as a cache miss.
+func (c *summaryCache) lookup(ctx context.Context, src ReportSource, dimensions []Dimension) (string, *ReportSummary) {
+	if src.Checksum == nil {
+		return "", nil
+	}
+	checksum, err := src.Checksum(ctx)
+	if err != nil {
+		log.Printf("Warning: not using cache for %s: %s", src.Name, err)
+		return "", nil
+	}
+
+	summary, err := c.load(checksum, dimensions)
+	if err != nil {
+		log.Printf("Warning: ignoring cached result for %s: %s", src.Name, err)
+		return checksum, nil
+	}
+	return checksum, summary
+}
+
+// load returns the cached summary of a file with the given checksum, or nil
+// if there is none.
+func (c *summaryCache) load(checksum string, dimensions []Dimension) (*ReportSummary, error) {
+	data, err := os.ReadFile(c.path(checksum))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read cache entry: %w", err)
+	}
+
+	var cached cachedSummary
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("could not parse cache entry: %w", err)
+	}
+
+	summary := newReportSummary(dimensions)
+	summary.LineCount = cached.LineCount
+	summary.SkippedCount = cached.SkippedCount
+	summary.InvalidDateCount = cached.InvalidDateCount
+	summary.EarliestDate = cached.EarliestDate
+	summary.LatestDate = cached.LatestDate
+	summary.Spend = cached.Spend
+	for _, row := range cached.Rows {
+		if len(row.Labels) != len(dimensions) {
+			return nil, fmt.Errorf("cache entry has %d labels per row, want %d", len(row.Labels), len(dimensions))
+		}
+		summary.addAggregate(row)
+	}
+	return summary, nil
+}
+
+// store adds the summary of a file with the given checksum to the cache.
+func (c *summaryCache) store(checksum string, summary *ReportSummary) error {
+	cached := cachedSummary{
+		LineCount:        summary.LineCount,
+		SkippedCount:     summary.SkippedCount,
+		InvalidDateCount: summary.InvalidDateCount,
+		EarliestDate:     summary.EarliestDate,
+		LatestDate:       summary.LatestDate,
+		Spend:            summary.Spend,
+	}
+	cached.Rows = make([]AggregateReportRow, 0, len(summary.Aggregate))
+	for _, row := range summary.Aggregate {
+		cached.Rows = append(cached.Rows, *row)
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("could not encode cache entry: %w", err)
+	}
+
+	// Entries are renamed into place, so that readers never see partially
+	// written entries.
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("could not create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(checksum)); err != nil {
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+	return nil
+}
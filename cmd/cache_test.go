@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_summaryCache(t *testing.T) {
+	dir := t.TempDir()
+	dimensions := []Dimension{{Name: "region", Header: "Region", Value: func(r ReportRow) string { return r.Region }}}
+
+	summary := newReportSummary(dimensions)
+	summary.Period = hourPeriod
+	start := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", Duration: time.Hour, UsageStartTime: start, UsageEndTime: start.Add(time.Hour)})
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", Duration: time.Hour, UsageStartTime: start, UsageEndTime: start.Add(time.Hour)})
+	summary.SkippedCount = 3
+	summary.InvalidDateCount = 4
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", Duration: time.Hour, UsageStartTime: start, UsageEndTime: start.Add(time.Hour), Cost: 0.5, ProductCode: "AmazonEC2"})
+	summary.addSpend("AmazonEC2", 0.5)
+	summary.addSpend("AWSSupportBusiness", 0.25)
+
+	cache, err := newSummaryCache(dir, []string{"aws", "region"})
+	if err != nil {
+		t.Fatalf("newSummaryCache() error = %v", err)
+	}
+
+	got, err := cache.load("sha256:abc", dimensions)
+	if err != nil || got != nil {
+		t.Fatalf("load() before store = %v, %v, want nil, nil", got, err)
+	}
+
+	if err := cache.store("sha256:abc", summary); err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+
+	got, err = cache.load("sha256:abc", dimensions)
+	if err != nil || got == nil {
+		t.Fatalf("load() = %v, %v, want summary", got, err)
+	}
+	if got.LineCount != 3 || got.SkippedCount != 3 || got.InvalidDateCount != 4 || !got.EarliestDate.Equal(start) || !got.LatestDate.Equal(start.Add(time.Hour)) {
+		t.Errorf("load() = %+v, want counts and time range of stored summary", got)
+	}
+	if got.Spend["AmazonEC2"] != 0.5 || got.Spend["AWSSupportBusiness"] != 0.25 {
+		t.Errorf("load() Spend = %v, want spend of stored summary", got.Spend)
+	}
+	if len(got.Aggregate) != 1 {
+		t.Fatalf("load() returned %d aggregate rows, want 1", len(got.Aggregate))
+	}
+	for key, row := range aggregateByKey(got) {
+		if _, exists := aggregateByKey(summary)[key]; !exists {
+			t.Errorf("load() returned unexpected key %q", key)
+		}
+		if row.Duration != 3*time.Hour || !row.Period.Equal(start) || row.ProductCosts["AmazonEC2"] != 0.5 {
+			t.Errorf("load() returned row %+v", row)
+		}
+	}
+
+	other, err := newSummaryCache(dir, []string{"aws", "account"})
+	if err != nil {
+		t.Fatalf("newSummaryCache() error = %v", err)
+	}
+	if got, _ := other.load("sha256:abc", dimensions); got != nil {
+		t.Errorf("load() with different settings returned a cached summary")
+	}
+}
+
+func Test_summaryCache_lookup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(path, []byte("some report"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := localSource(path)
+
+	cache, err := newSummaryCache(filepath.Join(dir, "cache"), nil)
+	if err != nil {
+		t.Fatalf("newSummaryCache() error = %v", err)
+	}
+
+	checksum, got := cache.lookup(context.Background(), src, nil)
+	if checksum == "" || got != nil {
+		t.Fatalf("lookup() = %q, %v, want checksum and no summary", checksum, got)
+	}
+
+	summary := newReportSummary(nil)
+	summary.LineCount = 5
+	if err := cache.store(checksum, summary); err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+	if _, got := cache.lookup(context.Background(), src, nil); got == nil || got.LineCount != 5 {
+		t.Errorf("lookup() after store = %v, want cached summary", got)
+	}
+
+	// Changed content changes the checksum.
+	if err := os.WriteFile(path, []byte("grown report"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, got := cache.lookup(context.Background(), src, nil); got != nil {
+		t.Errorf("lookup() after change returned a cached summary")
+	}
+
+	// Broken entries are ignored.
+	if err := os.WriteFile(cache.path(checksum), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cache.load(checksum, nil); got != nil || err == nil {
+		t.Errorf("load() of broken entry = %v, %v, want error", got, err)
+	}
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var estimateCAPICmd = &cobra.Command{
+	Use:   "estimate-capi FILE...",
+	Short: "Estimate the emissions of the node pools of Cluster API manifests",
+	Long: `Estimate the emissions of the node pools of Cluster API manifests.
+
+FILE is a YAML file with Cluster API resources for AWS, or the values of a
+Giant Swarm cluster-aws app. The node pools are read from:
+
+  - MachineDeployment and KubeadmControlPlane resources, with the instance
+    type of the AWSMachineTemplate they refer to,
+  - MachinePool resources, with the AWSMachinePool they refer to,
+  - global.controlPlane and global.nodePools of cluster-aws values.
+
+The region is taken from AWSCluster resources or from
+global.providerSpecific.region, unless set with --region. Pools that scale
+between a minimum and maximum size are estimated at the size given by
+--pool-size, unless they set a number of replicas.
+
+Example:
+
+  cloud-carbon estimate-capi --hours 730 cluster.yaml
+`,
+	RunE: estimateCAPI,
+	Args: configArgs(cobra.MinimumNArgs(1)),
+}
+
+var (
+	capiRegion       string
+	capiHours        float64
+	capiUtilization  float64
+	capiPoolSize     string
+	capiFallback     string
+	capiOutputFormat string
+)
+
+// Sizes of autoscaling pools for --pool-size.
+const (
+	poolSizeMin = "min"
+	poolSizeMax = "max"
+)
+
+// clusterAppControlPlaneReplicas is the number of control plane nodes of
+// clusters of the cluster-aws app.
+const clusterAppControlPlaneReplicas = 3
+
+func init() {
+	estimateCAPICmd.Flags().StringVar(&capiRegion, "region", "", "AWS region of the cluster. Defaults to the region of the manifests")
+	estimateCAPICmd.Flags().Float64Var(&capiHours, "hours", hoursPerMonth, "Hours the nodes run for")
+	estimateCAPICmd.Flags().Float64Var(&capiUtilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of the nodes in percent")
+	estimateCAPICmd.Flags().StringVar(&capiPoolSize, "pool-size", poolSizeMax, fmt.Sprintf("Size of autoscaling pools, one of: %s, %s", poolSizeMin, poolSizeMax))
+	estimateCAPICmd.Flags().StringVar(&capiFallback, "instance-fallback", footprint.FallbackFamily, fmt.Sprintf("How to estimate EC2 instance types missing from the dataset, one of: %s", strings.Join(footprint.FallbackMethods, ", ")))
+	estimateCAPICmd.Flags().StringVarP(&capiOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+}
+
+// capiDocument holds the fields of a Cluster API resource or of cluster-aws
+// app values needed to find the node pools of a cluster.
+type capiDocument struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec   capiSpec         `yaml:"spec"`
+	Global clusterAppValues `yaml:"global"`
+}
+
+// capiSpec holds the spec fields of the Cluster API resources describing
+// node pools.
+type capiSpec struct {
+	// Region is set by AWSCluster.
+	Region string `yaml:"region"`
+
+	// Replicas is set by MachineDeployment, MachinePool and
+	// KubeadmControlPlane.
+	Replicas *int `yaml:"replicas"`
+
+	// MinSize, MaxSize and AWSLaunchTemplate are set by AWSMachinePool.
+	MinSize           int `yaml:"minSize"`
+	MaxSize           int `yaml:"maxSize"`
+	AWSLaunchTemplate struct {
+		InstanceType string `yaml:"instanceType"`
+	} `yaml:"awsLaunchTemplate"`
+
+	Template struct {
+		Spec struct {
+			// InstanceType is set by AWSMachineTemplate.
+			InstanceType string `yaml:"instanceType"`
+
+			// InfrastructureRef is set by MachineDeployment and
+			// MachinePool.
+			InfrastructureRef capiReference `yaml:"infrastructureRef"`
+		} `yaml:"spec"`
+	} `yaml:"template"`
+
+	// MachineTemplate is set by KubeadmControlPlane.
+	MachineTemplate struct {
+		InfrastructureRef capiReference `yaml:"infrastructureRef"`
+	} `yaml:"machineTemplate"`
+}
+
+type capiReference struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+// clusterAppValues holds the global values of the Giant Swarm cluster-aws
+// app describing the nodes of a cluster.
+type clusterAppValues struct {
+	ProviderSpecific struct {
+		Region string `yaml:"region"`
+	} `yaml:"providerSpecific"`
+	ControlPlane struct {
+		InstanceType string `yaml:"instanceType"`
+	} `yaml:"controlPlane"`
+	NodePools map[string]struct {
+		InstanceType string `yaml:"instanceType"`
+		MinSize      int    `yaml:"minSize"`
+		MaxSize      int    `yaml:"maxSize"`
+	} `yaml:"nodePools"`
+}
+
+func estimateCAPI(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	if capiOutputFormat != outputTable && capiOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", capiOutputFormat, outputTable, outputJSON)
+	}
+	if capiHours <= 0 {
+		return usageErrorf("invalid --hours value %g, must be positive", capiHours)
+	}
+	if capiUtilization < 0 || capiUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", capiUtilization)
+	}
+	if capiPoolSize != poolSizeMin && capiPoolSize != poolSizeMax {
+		return usageErrorf("invalid --pool-size value %q, must be one of: %s, %s", capiPoolSize, poolSizeMin, poolSizeMax)
+	}
+	if !footprint.IsFallbackMethod(capiFallback) {
+		return usageErrorf("invalid --instance-fallback value %q, must be one of: %s", capiFallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+
+	var docs []capiDocument
+	for _, path := range args {
+		found, err := readCAPIDocuments(path)
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "could not read manifests: %w", err)
+		}
+		docs = append(docs, found...)
+	}
+
+	pools, err := capiPools(docs, capiRegion, capiPoolSize)
+	if err != nil {
+		return exitErrorf(exitData, "could not find node pools: %w", err)
+	}
+	spec := fleetSpec{Hours: capiHours, Utilization: &capiUtilization, Pools: pools}
+	if err := spec.validate(); err != nil {
+		return exitErrorf(exitData, "invalid node pools: %w", err)
+	}
+
+	e, err := estimateFleetSpec(spec, capiFallback)
+	if err != nil {
+		return exitErrorf(exitData, "could not estimate emissions: %w", err)
+	}
+
+	switch capiOutputFormat {
+	case outputTable:
+		writeFleetEstimate(os.Stdout, e)
+	case outputJSON:
+		err = writeJSON(os.Stdout, e)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// readCAPIDocuments reads all documents of a YAML file.
+func readCAPIDocuments(path string) ([]capiDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var docs []capiDocument
+	decoder := yaml.NewDecoder(f)
+	for {
+		var doc capiDocument
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, parseErrorf("could not parse %s: %w", path, err)
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// capiPools returns the node pools defined by Cluster API resources and
+// cluster-aws values, sorted by name. Pools without replicas are sized
+// according to poolSize. The region of all pools is region if set, or else
+// the single region found in the documents.
+func capiPools(docs []capiDocument, region, poolSize string) ([]fleetPool, error) {
+	templates := make(map[capiReference]capiDocument)
+	var regions []string
+	for _, doc := range docs {
+		switch {
+		case doc.Kind == "AWSMachineTemplate" || doc.Kind == "AWSMachinePool":
+			templates[capiReference{Kind: doc.Kind, Name: doc.Metadata.Name}] = doc
+		case doc.Kind == "AWSCluster" && doc.Spec.Region != "":
+			regions = append(regions, doc.Spec.Region)
+		case doc.Kind == "" && doc.Global.ProviderSpecific.Region != "":
+			regions = append(regions, doc.Global.ProviderSpecific.Region)
+		}
+	}
+
+	if region == "" {
+		slices.Sort(regions)
+		regions = slices.Compact(regions)
+		switch len(regions) {
+		case 0:
+			return nil, errors.New("no region found, set --region")
+		case 1:
+			region = regions[0]
+		default:
+			return nil, fmt.Errorf("several regions found (%s), set --region", strings.Join(regions, ", "))
+		}
+	}
+
+	size := func(min, max int) int {
+		if poolSize == poolSizeMin {
+			return min
+		}
+		return max
+	}
+
+	var pools []fleetPool
+	for _, doc := range docs {
+		var ref capiReference
+		switch doc.Kind {
+		case "MachineDeployment", "MachinePool":
+			ref = doc.Spec.Template.Spec.InfrastructureRef
+		case "KubeadmControlPlane":
+			ref = doc.Spec.MachineTemplate.InfrastructureRef
+		case "":
+			pools = append(pools, clusterAppPools(doc.Global, region, size)...)
+			continue
+		default:
+			continue
+		}
+
+		template, exists := templates[ref]
+		if !exists {
+			return nil, fmt.Errorf("%s %s refers to missing %s %s", doc.Kind, doc.Metadata.Name, ref.Kind, ref.Name)
+		}
+		pool := fleetPool{
+			Name:         doc.Metadata.Name,
+			Region:       region,
+			InstanceType: template.Spec.Template.Spec.InstanceType,
+			Count:        size(template.Spec.MinSize, template.Spec.MaxSize),
+		}
+		if template.Kind == "AWSMachinePool" {
+			pool.InstanceType = template.Spec.AWSLaunchTemplate.InstanceType
+		}
+		switch {
+		case doc.Spec.Replicas != nil:
+			pool.Count = *doc.Spec.Replicas
+		case doc.Kind != "MachinePool":
+			// Replicas of machine deployments and control planes
+			// default to 1.
+			pool.Count = 1
+		}
+		pools = append(pools, pool)
+	}
+
+	// Pools scaled to zero have no footprint.
+	pools = slices.DeleteFunc(pools, func(p fleetPool) bool { return p.Count == 0 })
+	if len(pools) == 0 {
+		return nil, errors.New("no node pools found")
+	}
+	sort.SliceStable(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+	return pools, nil
+}
+
+// clusterAppPools returns the control plane and node pools of cluster-aws
+// values.
+func clusterAppPools(values clusterAppValues, region string, size func(min, max int) int) []fleetPool {
+	var pools []fleetPool
+	if values.ControlPlane.InstanceType != "" {
+		pools = append(pools, fleetPool{
+			Name:         "control-plane",
+			Region:       region,
+			InstanceType: values.ControlPlane.InstanceType,
+			Count:        clusterAppControlPlaneReplicas,
+		})
+	}
+	for name, pool := range values.NodePools {
+		pools = append(pools, fleetPool{
+			Name:         name,
+			Region:       region,
+			InstanceType: pool.InstanceType,
+			Count:        size(pool.MinSize, pool.MaxSize),
+		})
+	}
+	return pools
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_capiPools(t *testing.T) {
+	docs, err := readCAPIDocuments("testdata/capi-cluster.yaml")
+	if err != nil {
+		t.Fatalf("readCAPIDocuments() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		region   string
+		poolSize string
+		want     []fleetPool
+	}{
+		{
+			name:     "max",
+			poolSize: poolSizeMax,
+			want: []fleetPool{
+				{Name: "demo-control-plane", Region: "eu-west-1", InstanceType: "m5.xlarge", Count: 3},
+				{Name: "demo-md-0", Region: "eu-west-1", InstanceType: "m5.2xlarge", Count: 5},
+				{Name: "demo-mp-0", Region: "eu-west-1", InstanceType: "r6i.xlarge", Count: 8},
+			},
+		},
+		{
+			name:     "min in other region",
+			region:   "eu-central-1",
+			poolSize: poolSizeMin,
+			want: []fleetPool{
+				{Name: "demo-control-plane", Region: "eu-central-1", InstanceType: "m5.xlarge", Count: 3},
+				{Name: "demo-md-0", Region: "eu-central-1", InstanceType: "m5.2xlarge", Count: 5},
+				{Name: "demo-mp-0", Region: "eu-central-1", InstanceType: "r6i.xlarge", Count: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := capiPools(docs, tt.region, tt.poolSize)
+			if err != nil {
+				t.Fatalf("capiPools() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("capiPools() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("capiPools()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_capiPools_clusterApp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	values := `global:
+  providerSpecific:
+    region: eu-central-1
+  controlPlane:
+    instanceType: m6i.xlarge
+  nodePools:
+    pool0:
+      instanceType: m6i.2xlarge
+      minSize: 3
+      maxSize: 10
+`
+	if err := os.WriteFile(path, []byte(values), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := readCAPIDocuments(path)
+	if err != nil {
+		t.Fatalf("readCAPIDocuments() error = %v", err)
+	}
+
+	got, err := capiPools(docs, "", poolSizeMin)
+	if err != nil {
+		t.Fatalf("capiPools() error = %v", err)
+	}
+	want := []fleetPool{
+		{Name: "control-plane", Region: "eu-central-1", InstanceType: "m6i.xlarge", Count: clusterAppControlPlaneReplicas},
+		{Name: "pool0", Region: "eu-central-1", InstanceType: "m6i.2xlarge", Count: 3},
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("capiPools() = %+v, want %+v", got, want)
+	}
+
+	cluster, err := readCAPIDocuments("testdata/capi-cluster.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := capiPools(append(docs, cluster...), "", poolSizeMax); err == nil || !strings.Contains(err.Error(), "several regions found (eu-central-1, eu-west-1)") {
+		t.Errorf("capiPools() of two regions error = %v", err)
+	}
+}
+
+func Test_capiPools_errors(t *testing.T) {
+	missing := capiDocument{Kind: "MachineDeployment"}
+	missing.Metadata.Name = "workers"
+	missing.Spec.Template.Spec.InfrastructureRef = capiReference{Kind: "AWSMachineTemplate", Name: "workers"}
+
+	zero := capiDocument{Kind: "MachineDeployment"}
+	zero.Spec.Replicas = new(int)
+	zero.Spec.Template.Spec.InfrastructureRef.Kind = "AWSMachineTemplate"
+
+	tests := []struct {
+		name    string
+		docs    []capiDocument
+		region  string
+		wantErr string
+	}{
+		{name: "no region", docs: []capiDocument{{Kind: "MachineDeployment"}}, wantErr: "no region found"},
+		{name: "no pools", docs: []capiDocument{{Kind: "Cluster"}}, region: "eu-west-1", wantErr: "no node pools found"},
+		{name: "scaled to zero", docs: []capiDocument{zero, {Kind: "AWSMachineTemplate"}}, region: "eu-west-1", wantErr: "no node pools found"},
+		{name: "missing template", docs: []capiDocument{missing}, region: "eu-west-1", wantErr: "MachineDeployment workers refers to missing AWSMachineTemplate workers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := capiPools(tt.docs, tt.region, poolSizeMax)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("capiPools() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var estimateCFNCmd = &cobra.Command{
+	Use:   "estimate-cfn TEMPLATE...",
+	Short: "Estimate the emissions of the instances of CloudFormation templates",
+	Long: `Estimate the emissions of the instances of CloudFormation templates.
+
+Each TEMPLATE is a CloudFormation template in YAML or JSON, e.g. as
+synthesized by the AWS CDK into cdk.out, and is estimated as a stack named
+after the file. The following resources are estimated:
+
+  - AWS::EC2::Instance,
+  - AWS::AutoScaling::AutoScalingGroup, with the instance type of its launch
+    template or launch configuration,
+  - AWS::RDS::DBInstance, including the standby instance of Multi-AZ
+    deployments.
+
+Property values can be literals or references to template parameters, which
+take their default value unless set with --parameter. Resources with other
+intrinsic functions in the properties needed are skipped with a warning.
+Auto scaling groups are estimated at their desired capacity, or at the size
+given by --pool-size.
+
+Example:
+
+  cloud-carbon estimate-cfn --region eu-west-1 cdk.out/*.template.json
+`,
+	RunE: estimateCFN,
+	Args: configArgs(cobra.MinimumNArgs(1)),
+}
+
+var (
+	cfnRegion       string
+	cfnParameters   []string
+	cfnHours        float64
+	cfnUtilization  float64
+	cfnPoolSize     string
+	cfnFallback     string
+	cfnOutputFormat string
+)
+
+func init() {
+	estimateCFNCmd.Flags().StringVar(&cfnRegion, "region", "", "AWS region the stacks are deployed to")
+	estimateCFNCmd.Flags().StringArrayVar(&cfnParameters, "parameter", nil, "Value of a template parameter as NAME=VALUE. Can be repeated")
+	estimateCFNCmd.Flags().Float64Var(&cfnHours, "hours", hoursPerMonth, "Hours the instances run for")
+	estimateCFNCmd.Flags().Float64Var(&cfnUtilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of the instances in percent")
+	estimateCFNCmd.Flags().StringVar(&cfnPoolSize, "pool-size", poolSizeMax, fmt.Sprintf("Size of auto scaling groups without desired capacity, one of: %s, %s", poolSizeMin, poolSizeMax))
+	estimateCFNCmd.Flags().StringVar(&cfnFallback, "instance-fallback", footprint.FallbackFamily, fmt.Sprintf("How to estimate EC2 instance types missing from the dataset, one of: %s", strings.Join(footprint.FallbackMethods, ", ")))
+	estimateCFNCmd.Flags().StringVarP(&cfnOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	estimateCFNCmd.MarkFlagRequired("region")
+}
+
+// Types of the CloudFormation resources estimated.
+const (
+	cfnEC2Instance         = "AWS::EC2::Instance"
+	cfnAutoScalingGroup    = "AWS::AutoScaling::AutoScalingGroup"
+	cfnLaunchTemplate      = "AWS::EC2::LaunchTemplate"
+	cfnLaunchConfiguration = "AWS::AutoScaling::LaunchConfiguration"
+	cfnDBInstance          = "AWS::RDS::DBInstance"
+)
+
+// cfnTemplate holds the parts of a CloudFormation template describing
+// instances.
+type cfnTemplate struct {
+	Parameters map[string]struct {
+		Default yaml.Node `yaml:"Default"`
+	} `yaml:"Parameters"`
+	Resources map[string]struct {
+		Type       string    `yaml:"Type"`
+		Properties yaml.Node `yaml:"Properties"`
+	} `yaml:"Resources"`
+
+	// values holds the values of parameters set on the command line.
+	values map[string]string
+}
+
+// StackEstimate holds the footprint of the instances of a stack.
+type StackEstimate struct {
+	Stack string `json:"stack"`
+	FleetEstimate
+}
+
+func estimateCFN(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	if cfnOutputFormat != outputTable && cfnOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", cfnOutputFormat, outputTable, outputJSON)
+	}
+	if cfnHours <= 0 {
+		return usageErrorf("invalid --hours value %g, must be positive", cfnHours)
+	}
+	if cfnUtilization < 0 || cfnUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", cfnUtilization)
+	}
+	if cfnPoolSize != poolSizeMin && cfnPoolSize != poolSizeMax {
+		return usageErrorf("invalid --pool-size value %q, must be one of: %s, %s", cfnPoolSize, poolSizeMin, poolSizeMax)
+	}
+	if !footprint.IsFallbackMethod(cfnFallback) {
+		return usageErrorf("invalid --instance-fallback value %q, must be one of: %s", cfnFallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+	values := make(map[string]string)
+	for _, p := range cfnParameters {
+		name, value, found := strings.Cut(p, "=")
+		if !found || name == "" {
+			return usageErrorf("invalid --parameter value %q, must be NAME=VALUE", p)
+		}
+		values[name] = value
+	}
+
+	var stacks []StackEstimate
+	for _, path := range args {
+		template, err := readCFNTemplate(path)
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "could not read template: %w", err)
+		}
+		template.values = values
+
+		stack := stackName(path)
+		spec := fleetSpec{Hours: cfnHours, Utilization: &cfnUtilization, Pools: template.pools(cfnRegion, cfnPoolSize)}
+		if len(spec.Pools) == 0 {
+			log.Printf("Stack %s has no instances to estimate", stack)
+			continue
+		}
+		if err := spec.validate(); err != nil {
+			return exitErrorf(exitData, "invalid instances of stack %s: %w", stack, err)
+		}
+		e, err := estimateFleetSpec(spec, cfnFallback)
+		if err != nil {
+			return exitErrorf(exitData, "could not estimate emissions of stack %s: %w", stack, err)
+		}
+		stacks = append(stacks, StackEstimate{Stack: stack, FleetEstimate: e})
+	}
+
+	var err error
+	switch cfnOutputFormat {
+	case outputTable:
+		for i, s := range stacks {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Stack %s:\n\n", s.Stack)
+			writeFleetEstimate(os.Stdout, s.FleetEstimate)
+		}
+	case outputJSON:
+		err = writeJSON(os.Stdout, stacks)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// readCFNTemplate reads a CloudFormation template in YAML or JSON.
+func readCFNTemplate(path string) (cfnTemplate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return cfnTemplate{}, err
+	}
+	defer f.Close()
+
+	var template cfnTemplate
+	if err := yaml.NewDecoder(f).Decode(&template); err != nil && err != io.EOF {
+		return cfnTemplate{}, parseErrorf("could not parse %s: %w", path, err)
+	}
+	return template, nil
+}
+
+// stackName returns the name of the stack of a template file, i.e. the file
+// name without extensions like .template.json.
+func stackName(path string) string {
+	name := filepath.Base(path)
+	for _, ext := range []string{".json", ".yaml", ".yml", ".template"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// pools returns a pool for each instance, auto scaling group and database
+// instance of a template, named after their logical ID, sorted by name.
+// Auto scaling groups without desired capacity are sized according to
+// poolSize, and skipped if of size zero. Resources that cannot be resolved
+// are logged and skipped.
+func (t cfnTemplate) pools(region, poolSize string) []fleetPool {
+	ids := make([]string, 0, len(t.Resources))
+	for id := range t.Resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var pools []fleetPool
+	for _, id := range ids {
+		resource := t.Resources[id]
+		var pool fleetPool
+		var err error
+		switch resource.Type {
+		case cfnEC2Instance:
+			pool, err = t.instancePool(&resource.Properties)
+		case cfnAutoScalingGroup:
+			pool, err = t.autoScalingPool(&resource.Properties, poolSize)
+		case cfnDBInstance:
+			pool, err = t.dbInstancePool(&resource.Properties)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("Skipping resource %s of type %s: %s", id, resource.Type, err)
+			continue
+		}
+		if pool.Count == 0 {
+			continue
+		}
+		pool.Name = id
+		pool.Region = region
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// instancePool returns the pool of an EC2 instance.
+func (t cfnTemplate) instancePool(properties *yaml.Node) (fleetPool, error) {
+	instanceType, err := t.value(cfnProperty(properties, "InstanceType"))
+	if err != nil {
+		return fleetPool{}, fmt.Errorf("instance type: %w", err)
+	}
+	if instanceType == "" {
+		instanceType, err = t.launchTemplateInstanceType(cfnProperty(properties, "LaunchTemplate"))
+		if err != nil {
+			return fleetPool{}, err
+		}
+	}
+	return fleetPool{InstanceType: instanceType, Count: 1}, nil
+}
+
+// autoScalingPool returns the pool of an auto scaling group, with the
+// instance type of its launch template, mixed instances policy or launch
+// configuration. Of mixed instance types, the first is used.
+func (t cfnTemplate) autoScalingPool(properties *yaml.Node, poolSize string) (fleetPool, error) {
+	var instanceType string
+	var err error
+	switch policy := cfnProperty(properties, "MixedInstancesPolicy", "LaunchTemplate"); {
+	case policy != nil:
+		overrides := cfnProperty(policy, "Overrides")
+		if overrides != nil && overrides.Kind == yaml.SequenceNode && len(overrides.Content) > 0 {
+			instanceType, err = t.value(cfnProperty(overrides.Content[0], "InstanceType"))
+		}
+		if err == nil && instanceType == "" {
+			instanceType, err = t.launchTemplateInstanceType(cfnProperty(policy, "LaunchTemplateSpecification"))
+		}
+	case cfnProperty(properties, "LaunchTemplate") != nil:
+		instanceType, err = t.launchTemplateInstanceType(cfnProperty(properties, "LaunchTemplate"))
+	default:
+		var name string
+		name, err = t.reference(cfnProperty(properties, "LaunchConfigurationName"))
+		if err == nil {
+			instanceType, err = t.resourceValue(name, cfnLaunchConfiguration, "InstanceType")
+		}
+	}
+	if err != nil {
+		return fleetPool{}, err
+	}
+
+	size := "MaxSize"
+	if poolSize == poolSizeMin {
+		size = "MinSize"
+	}
+	if cfnProperty(properties, "DesiredCapacity") != nil {
+		size = "DesiredCapacity"
+	}
+	count, err := t.intValue(cfnProperty(properties, size))
+	if err != nil {
+		return fleetPool{}, fmt.Errorf("%s: %w", size, err)
+	}
+	return fleetPool{InstanceType: instanceType, Count: count}, nil
+}
+
+// dbInstancePool returns the pool of a database instance, with a second
+// instance for the standby of a Multi-AZ deployment.
+func (t cfnTemplate) dbInstancePool(properties *yaml.Node) (fleetPool, error) {
+	class, err := t.value(cfnProperty(properties, "DBInstanceClass"))
+	if err != nil {
+		return fleetPool{}, fmt.Errorf("instance class: %w", err)
+	}
+	if class == "" {
+		return fleetPool{}, errors.New("no instance class, e.g. of an Aurora cluster")
+	}
+	multiAZ, err := t.value(cfnProperty(properties, "MultiAZ"))
+	if err != nil {
+		return fleetPool{}, fmt.Errorf("MultiAZ: %w", err)
+	}
+
+	pool := fleetPool{InstanceType: class, Count: 1}
+	if strings.EqualFold(multiAZ, "true") {
+		pool.Count = 2
+	}
+	return pool, nil
+}
+
+// launchTemplateInstanceType returns the instance type of the launch
+// template a launch template specification refers to by ID or name.
+func (t cfnTemplate) launchTemplateInstanceType(spec *yaml.Node) (string, error) {
+	if spec == nil {
+		return "", errors.New("no instance type or launch template")
+	}
+	ref := cfnProperty(spec, "LaunchTemplateId")
+	if ref == nil {
+		ref = cfnProperty(spec, "LaunchTemplateName")
+	}
+	name, err := t.reference(ref)
+	if err != nil {
+		return "", fmt.Errorf("launch template: %w", err)
+	}
+	return t.resourceValue(name, cfnLaunchTemplate, "LaunchTemplateData", "InstanceType")
+}
+
+// resourceValue returns the value of a property of a resource of the given
+// type.
+func (t cfnTemplate) resourceValue(id, typ string, path ...string) (string, error) {
+	resource, exists := t.Resources[id]
+	if !exists || resource.Type != typ {
+		return "", fmt.Errorf("no %s resource %s", typ, id)
+	}
+	value, err := t.value(cfnProperty(&resource.Properties, path...))
+	if err != nil {
+		return "", fmt.Errorf("%s of %s: %w", path[len(path)-1], id, err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s %s has no %s", typ, id, path[len(path)-1])
+	}
+	return value, nil
+}
+
+// value returns the value of a property, which is a literal or a reference
+// to a parameter. Missing properties have an empty value.
+func (t cfnTemplate) value(node *yaml.Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	if node.Kind == yaml.ScalarNode && !isCFNFunction(node) {
+		return node.Value, nil
+	}
+
+	name, err := t.reference(node)
+	if err != nil {
+		return "", err
+	}
+	if value, exists := t.values[name]; exists {
+		return value, nil
+	}
+	parameter, exists := t.Parameters[name]
+	if !exists {
+		return "", fmt.Errorf("unsupported reference to resource %s", name)
+	}
+	if parameter.Default.Kind == 0 {
+		return "", fmt.Errorf("parameter %s has no default value, set it with --parameter", name)
+	}
+	return t.value(&parameter.Default)
+}
+
+// intValue returns the value of a property as integer.
+func (t cfnTemplate) intValue(node *yaml.Node) (int, error) {
+	value, err := t.value(node)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return 0, errors.New("not set")
+	}
+	return strconv.Atoi(value)
+}
+
+// reference returns the logical ID referred to by a Ref function, in short
+// or full form.
+func (t cfnTemplate) reference(node *yaml.Node) (string, error) {
+	switch {
+	case node == nil:
+		return "", errors.New("not set")
+	case node.Kind == yaml.ScalarNode && node.Tag == "!Ref":
+		return node.Value, nil
+	case node.Kind == yaml.MappingNode && len(node.Content) == 2 && node.Content[0].Value == "Ref":
+		return node.Content[1].Value, nil
+	case isCFNFunction(node):
+		return "", fmt.Errorf("unsupported function %s", strings.TrimPrefix(node.Tag, "!"))
+	case node.Kind == yaml.MappingNode && len(node.Content) == 2:
+		return "", fmt.Errorf("unsupported function %s", node.Content[0].Value)
+	}
+	return "", errors.New("not a reference")
+}
+
+// isCFNFunction returns whether a node is an intrinsic function in short
+// form, like !Ref or !GetAtt.
+func isCFNFunction(node *yaml.Node) bool {
+	return strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!")
+}
+
+// cfnProperty returns the node at the path of keys below a mapping node, or
+// nil if it doesn't exist.
+func cfnProperty(node *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_cfnTemplate_pools(t *testing.T) {
+	template, err := readCFNTemplate("testdata/cfn-stack.yaml")
+	if err != nil {
+		t.Fatalf("readCFNTemplate() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		poolSize string
+		want     []fleetPool
+	}{
+		{
+			name:     "max",
+			poolSize: poolSizeMax,
+			want: []fleetPool{
+				{Name: "Bastion", Region: "eu-west-1", InstanceType: "t3.micro", Count: 1},
+				{Name: "Batch", Region: "eu-west-1", InstanceType: "c5.xlarge", Count: 6},
+				{Name: "Database", Region: "eu-west-1", InstanceType: "db.m5.large", Count: 2},
+				{Name: "Legacy", Region: "eu-west-1", InstanceType: "m4.large", Count: 1},
+				{Name: "Workers", Region: "eu-west-1", InstanceType: "m5.2xlarge", Count: 4},
+			},
+		},
+		{
+			name:     "min with parameters",
+			values:   map[string]string{"BastionType": "t3.small", "Environment": "m5.large"},
+			poolSize: poolSizeMin,
+			want: []fleetPool{
+				{Name: "Bastion", Region: "eu-west-1", InstanceType: "t3.small", Count: 1},
+				{Name: "Database", Region: "eu-west-1", InstanceType: "db.m5.large", Count: 2},
+				{Name: "Legacy", Region: "eu-west-1", InstanceType: "m4.large", Count: 1},
+				{Name: "Tenant", Region: "eu-west-1", InstanceType: "m5.large", Count: 1},
+				{Name: "Workers", Region: "eu-west-1", InstanceType: "m5.2xlarge", Count: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template.values = tt.values
+			got := template.pools("eu-west-1", tt.poolSize)
+			if len(got) != len(tt.want) {
+				t.Fatalf("pools() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("pools()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_cfnTemplate_pools_json(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Stack.template.json")
+	template := `{
+  "Resources": {
+    "Server": {"Type": "AWS::EC2::Instance", "Properties": {"InstanceType": {"Ref": "ServerType"}}},
+    "Replica": {"Type": "AWS::EC2::Instance", "Properties": {"InstanceType": {"Fn::FindInMap": ["Types", "prod", "replica"]}}}
+  },
+  "Parameters": {"ServerType": {"Type": "String", "Default": "m5.xlarge"}}
+}`
+	if err := os.WriteFile(path, []byte(template), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readCFNTemplate(path)
+	if err != nil {
+		t.Fatalf("readCFNTemplate() error = %v", err)
+	}
+
+	pools := got.pools("us-east-1", poolSizeMax)
+	want := fleetPool{Name: "Server", Region: "us-east-1", InstanceType: "m5.xlarge", Count: 1}
+	if len(pools) != 1 || pools[0] != want {
+		t.Errorf("pools() = %+v, want %+v", pools, want)
+	}
+	if name := stackName(path); name != "Stack" {
+		t.Errorf("stackName() = %q, want Stack", name)
+	}
+}
+
+func Test_cfnTemplate_value(t *testing.T) {
+	template, err := readCFNTemplate("testdata/cfn-stack.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr string
+	}{
+		{name: "no default", id: "Tenant", wantErr: "parameter Environment has no default value, set it with --parameter"},
+		{name: "unsupported function", id: "Staging", wantErr: "unsupported function If"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := template.Resources[tt.id]
+			_, err := template.value(cfnProperty(&resource.Properties, "InstanceType"))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("value() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
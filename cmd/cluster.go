@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var estimateClusterCmd = &cobra.Command{
+	Use:   "estimate-cluster",
+	Short: "Project the emissions of the nodes of a running Kubernetes cluster",
+	Long: `Project the emissions of the nodes of a running Kubernetes cluster.
+
+The nodes of the cluster are listed via the Kubernetes API. Their instance
+types and regions are taken from the well-known node labels
+node.kubernetes.io/instance-type and topology.kubernetes.io/region, the
+cloud provider from the node's provider ID. Nodes on AWS, GCP and Azure are
+supported.
+
+The output shows the emissions of running the current nodes for an hour, a
+day and a month, without the need for a usage report. The emissions of the
+control plane of managed clusters, storage and network are not included.
+`,
+	RunE: estimateCluster,
+	Args: cobra.NoArgs,
+}
+
+var (
+	clusterContext     string
+	clusterKubeconfig  string
+	clusterUtilization float64
+)
+
+func init() {
+	estimateClusterCmd.Flags().StringVar(&clusterKubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config")
+	estimateClusterCmd.Flags().StringVar(&clusterContext, "context", "", "Kubeconfig context to use. Defaults to the current context")
+	estimateClusterCmd.Flags().Float64Var(&clusterUtilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of the nodes in percent")
+}
+
+// Labels of Kubernetes nodes holding their instance type and region, with
+// their deprecated predecessors.
+var (
+	nodeInstanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+	nodeRegionLabels       = []string{"topology.kubernetes.io/region", "failure-domain.beta.kubernetes.io/region"}
+)
+
+// nodeCategories maps the scheme of node provider IDs to the usage
+// category of the node's instance.
+var nodeCategories = map[string]string{
+	"aws":   categoryEC2,
+	"gce":   categoryGCE,
+	"azure": categoryAzureVM,
+}
+
+func estimateCluster(cmd *cobra.Command, args []string) error {
+	if clusterUtilization < 0 || clusterUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", clusterUtilization)
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = clusterKubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: clusterContext}).ClientConfig()
+	if err != nil {
+		return exitErrorf(exitIO, "could not load kubeconfig: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("could not create Kubernetes client: %w", err)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(cmd.Context(), metav1.ListOptions{})
+	if err != nil {
+		return exitErrorf(exitIO, "could not list nodes: %w", err)
+	}
+
+	var instances []runningInstance
+	for _, node := range nodes.Items {
+		instance, err := nodeInstance(node)
+		if err != nil {
+			log.Printf("Skipping node %s: %s", node.Name, err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	fmt.Printf("Found %d nodes, %d with a known instance type and region.\n\n", len(nodes.Items), len(instances))
+
+	rows, total := projectEmissions(instances, clusterUtilization)
+	writeProjectionTable(os.Stdout, rows, total)
+	return nil
+}
+
+// nodeInstance returns the instance a Kubernetes node runs on, as
+// described by the node's labels and provider ID.
+func nodeInstance(node corev1.Node) (runningInstance, error) {
+	scheme, _, found := strings.Cut(node.Spec.ProviderID, "://")
+	if !found {
+		return runningInstance{}, fmt.Errorf("no provider ID")
+	}
+	category, exists := nodeCategories[scheme]
+	if !exists {
+		return runningInstance{}, fmt.Errorf("unsupported provider %q", scheme)
+	}
+
+	instance := runningInstance{
+		Category:     category,
+		InstanceType: firstLabel(node.Labels, nodeInstanceTypeLabels),
+		Region:       firstLabel(node.Labels, nodeRegionLabels),
+	}
+	if instance.InstanceType == "" {
+		return runningInstance{}, fmt.Errorf("no instance type label")
+	}
+	if instance.Region == "" {
+		return runningInstance{}, fmt.Errorf("no region label")
+	}
+	return instance, nil
+}
+
+// firstLabel returns the value of the first of the given labels that is
+// set.
+func firstLabel(labels map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
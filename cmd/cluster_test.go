@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_nodeInstance(t *testing.T) {
+	node := func(providerID string, labels map[string]string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       corev1.NodeSpec{ProviderID: providerID},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		node    corev1.Node
+		want    runningInstance
+		wantErr bool
+	}{
+		{
+			name: "aws",
+			node: node("aws:///eu-west-1a/i-0123456789abcdef0", map[string]string{
+				"node.kubernetes.io/instance-type": "m5.xlarge",
+				"topology.kubernetes.io/region":    "eu-west-1",
+			}),
+			want: runningInstance{Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.xlarge"},
+		},
+		{
+			name: "gce with deprecated labels",
+			node: node("gce://project/europe-west1-b/node-1", map[string]string{
+				"beta.kubernetes.io/instance-type":         "e2-standard-4",
+				"failure-domain.beta.kubernetes.io/region": "europe-west1",
+			}),
+			want: runningInstance{Category: categoryGCE, Region: "europe-west1", InstanceType: "e2-standard-4"},
+		},
+		{
+			name: "azure",
+			node: node("azure:///subscriptions/x/resourceGroups/y/providers/Microsoft.Compute/virtualMachines/z", map[string]string{
+				"node.kubernetes.io/instance-type": "Standard_D2s_v3",
+				"topology.kubernetes.io/region":    "westeurope",
+			}),
+			want: runningInstance{Category: categoryAzureVM, Region: "westeurope", InstanceType: "Standard_D2s_v3"},
+		},
+		{name: "no provider ID", node: node("", nil), wantErr: true},
+		{name: "unsupported provider", node: node("kind://docker/kind/kind-control-plane", nil), wantErr: true},
+		{name: "no instance type", node: node("aws:///eu-west-1a/i-1", map[string]string{"topology.kubernetes.io/region": "eu-west-1"}), wantErr: true},
+		{name: "no region", node: node("aws:///eu-west-1a/i-1", map[string]string{"node.kubernetes.io/instance-type": "m5.xlarge"}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nodeInstance(tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nodeInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("nodeInstance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare OLD NEW",
+	Short: "Compare the emissions of two usage reports",
+	Long: `Compare the emissions of two usage reports, e.g. of two billing periods.
+
+OLD and NEW accept the same paths as the analyse command, including
+directories and S3 prefixes. Both are analysed, and the emissions per group
+are printed side by side, with the change in absolute terms and in percent.
+The footer shows the overall change.
+`,
+	RunE: compare,
+	Args: configArgs(cobra.ExactArgs(2)),
+}
+
+const defaultCompareGroupBy = "region,instance-type"
+
+var (
+	compareGroupBy  string
+	compareProvider string
+)
+
+func init() {
+	compareCmd.Flags().StringVar(&compareGroupBy, "group-by", defaultCompareGroupBy, fmt.Sprintf("Comma-separated list of dimensions to group usage by. Available: %s, %s<key>", strings.Join(dimensionNames(), ", "), tagDimensionPrefix))
+	compareCmd.Flags().StringVar(&compareProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+}
+
+// ComparisonRow holds the emissions of one group in two reports.
+type ComparisonRow struct {
+	Labels   []string
+	OldGrams float64
+	NewGrams float64
+}
+
+func compare(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	read, exists := reportReaders[compareProvider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", compareProvider, strings.Join(providers, ", "))
+	}
+
+	dimensions, err := parseGroupBy(compareGroupBy)
+	if err != nil {
+		return usageErrorf("invalid --group-by value: %w", err)
+	}
+
+	var results [2][]AggregateReportRow
+	for i, path := range args {
+		summary, err := analysePath(cmd.Context(), path, read, dimensions, summaryOptions{})
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "could not analyse %s: %w", path, err)
+		}
+		fmt.Printf("%s: %d lines about usage, %s - %s\n", path, summary.LineCount, summary.EarliestDate, summary.LatestDate)
+
+		rows, _ := computeEmissions(cmd.Context(), summary, defaultEmissionOptions())
+		results[i] = groupRows(rows, nil)
+	}
+	fmt.Println()
+
+	writeComparisonTable(os.Stdout, dimensions, compareRows(results[0], results[1]))
+	return nil
+}
+
+// analysePath returns the summary of the usage in all reports found at
+// path.
+func analysePath(ctx context.Context, path string, read reportReader, dimensions []Dimension, options summaryOptions) (*ReportSummary, error) {
+	sources, err := resolveSources(ctx, []string{path})
+	if err != nil {
+		return nil, fmt.Errorf("could not determine input files: %w", err)
+	}
+
+	summary := newReportSummary(dimensions)
+	for _, src := range sources {
+		fileSummary, err := analyseSource(ctx, src, read, dimensions, options)
+		if err != nil {
+			return nil, fmt.Errorf("could not process file %s: %w", src.Name, err)
+		}
+		summary.merge(fileSummary)
+	}
+	return summary, nil
+}
+
+// compareRows matches the grouped rows of two reports by their labels. The
+// result is sorted by labels and includes groups found in only one of the
+// reports.
+func compareRows(oldRows, newRows []AggregateReportRow) []ComparisonRow {
+	byKey := make(map[string]*ComparisonRow)
+	var keys []string
+
+	get := func(labels []string) *ComparisonRow {
+		key := strings.Join(labels, "\x00")
+		row, exists := byKey[key]
+		if !exists {
+			row = &ComparisonRow{Labels: labels}
+			byKey[key] = row
+			keys = append(keys, key)
+		}
+		return row
+	}
+	for _, r := range oldRows {
+		get(r.Labels).OldGrams += r.EmissionGrams
+	}
+	for _, r := range newRows {
+		get(r.Labels).NewGrams += r.EmissionGrams
+	}
+
+	sort.Strings(keys)
+
+	result := make([]ComparisonRow, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *byKey[key])
+	}
+	return result
+}
+
+// formatChange formats the relative change from old to new, e.g. "+12.5%".
+func formatChange(oldGrams, newGrams float64) string {
+	switch {
+	case oldGrams == 0 && newGrams == 0:
+		return "-"
+	case oldGrams == 0:
+		return "new"
+	case newGrams == 0:
+		return "removed"
+	}
+	return fmt.Sprintf("%+.1f%%", (newGrams-oldGrams)/oldGrams*100)
+}
+
+// formatGramsDelta formats a change in emissions with its sign.
+func formatGramsDelta(g float64) string {
+	if g < 0 {
+		return "-" + formatGrams(-g)
+	}
+	return "+" + formatGrams(g)
+}
+
+// writeComparisonTable writes the emissions of both reports per group, and
+// their change.
+func writeComparisonTable(w io.Writer, dimensions []Dimension, rows []ComparisonRow) {
+	var header []string
+	for _, d := range dimensions {
+		header = append(header, d.Header)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(append(append([]string{}, header...), "Old", "New", "Change", "Change %"))
+
+	var oldTotal, newTotal float64
+	for _, row := range rows {
+		oldTotal += row.OldGrams
+		newTotal += row.NewGrams
+		table.Append(append(append([]string{}, row.Labels...),
+			formatGrams(row.OldGrams),
+			formatGrams(row.NewGrams),
+			formatGramsDelta(row.NewGrams-row.OldGrams),
+			formatChange(row.OldGrams, row.NewGrams),
+		))
+	}
+
+	footer := make([]string, len(header))
+	if len(footer) > 0 {
+		footer[len(footer)-1] = "Total"
+	}
+	table.SetFooter(append(footer,
+		formatGrams(oldTotal),
+		formatGrams(newTotal),
+		formatGramsDelta(newTotal-oldTotal),
+		formatChange(oldTotal, newTotal),
+	))
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
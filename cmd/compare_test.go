@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_compareRows(t *testing.T) {
+	oldRows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, EmissionGrams: 100},
+		{Labels: []string{"us-east-1"}, EmissionGrams: 50},
+	}
+	newRows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, EmissionGrams: 80},
+		{Labels: []string{"eu-north-1"}, EmissionGrams: 5},
+	}
+
+	got := compareRows(oldRows, newRows)
+
+	want := []ComparisonRow{
+		{Labels: []string{"eu-north-1"}, NewGrams: 5},
+		{Labels: []string{"eu-west-1"}, OldGrams: 100, NewGrams: 80},
+		{Labels: []string{"us-east-1"}, OldGrams: 50},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("compareRows() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !equalLabels(got[i].Labels, want[i].Labels) || got[i].OldGrams != want[i].OldGrams || got[i].NewGrams != want[i].NewGrams {
+			t.Errorf("compareRows()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_formatChange(t *testing.T) {
+	tests := []struct {
+		oldGrams, newGrams float64
+		want               string
+	}{
+		{100, 80, "-20.0%"},
+		{100, 125, "+25.0%"},
+		{0, 5, "new"},
+		{50, 0, "removed"},
+		{0, 0, "-"},
+	}
+
+	for _, tt := range tests {
+		if got := formatChange(tt.oldGrams, tt.newGrams); got != tt.want {
+			t.Errorf("formatChange(%v, %v) = %q, want %q", tt.oldGrams, tt.newGrams, got, tt.want)
+		}
+	}
+}
+
+func Test_writeComparisonTable(t *testing.T) {
+	rows := []ComparisonRow{
+		{Labels: []string{"eu-west-1"}, OldGrams: 100, NewGrams: 80},
+		{Labels: []string{"us-east-1"}, OldGrams: 100, NewGrams: 0},
+	}
+
+	var buf bytes.Buffer
+	writeComparisonTable(&buf, []Dimension{{Name: "region", Header: "Region"}}, rows)
+
+	for _, want := range []string{"-20 gCO2e", "-20.0%", "removed", "-120 GCO2E", "-60.0%"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeComparisonTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
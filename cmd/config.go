@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// envConfig is the environment variable setting the default of --config.
+const envConfig = "CLOUD_CARBON_CONFIG"
+
+// defaultConfigFile is the name of the config file read from the home
+// directory, if it exists and no other file is given.
+const defaultConfigFile = ".cloud-carbon.yaml"
+
+// configArgsKey is the key of the positional arguments in the section of a
+// command.
+const configArgsKey = "args"
+
+var configFile string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("YAML file with default values of flags and arguments. Defaults to $%s or ~/%s, if it exists", envConfig, defaultConfigFile))
+}
+
+// settings holds the values read from a config file, which look like this:
+//
+//	# Values of flags for all commands having them.
+//	instances-csv: aws-ec2-instances.csv
+//	utilization: 40
+//
+//	# Values of flags and arguments of a command. Subcommands are nested,
+//	# e.g. data: {update: {output: ...}}.
+//	analyse:
+//	  args: [s3://reports/cur/]
+//	  group-by: [account, region]
+//	  move-region: [ap-southeast-2=eu-north-1]
+//
+// Values given on the command line take precedence over those of the
+// section of the command, which take precedence over the global ones.
+// Lists are passed to repeatable flags one by one and joined with commas
+// for all other flags.
+type settings map[string]any
+
+// loadedConfig is the config file read for the running command.
+var loadedConfig = sync.OnceValues(func() (settings, error) {
+	return readConfig(configPath())
+})
+
+// configPath returns the path of the config file to read, or an empty
+// string if there is none.
+func configPath() string {
+	if configFile != "" {
+		return configFile
+	}
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, defaultConfigFile)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return ""
+	}
+	return path
+}
+
+// readConfig reads a config file. An empty path results in an empty config.
+func readConfig(path string) (settings, error) {
+	if path == "" {
+		return settings{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Unmarshal into a plain map, so that nested sections are plain maps,
+	// too.
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return settings(values), nil
+}
+
+// section returns the values for a command, or nil if there are none.
+func (c settings) section(cmd *cobra.Command) (settings, error) {
+	names := commandNames(cmd)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	s := c
+	for i, name := range names {
+		value, exists := s[name]
+		if !exists || value == nil {
+			return nil, nil
+		}
+		next, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("section %q must be a map", strings.Join(names[:i+1], " "))
+		}
+		s = next
+	}
+	return s, nil
+}
+
+// apply sets the flags of cmd not given on the command line to the values
+// of the config.
+func (c settings) apply(cmd *cobra.Command) error {
+	section, err := c.section(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Flags of the command section must exist. Global values are only
+	// applied to commands with a flag of that name.
+	for _, key := range sortedConfigKeys(section) {
+		if key == configArgsKey || isSubcommand(cmd, key) {
+			continue
+		}
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil {
+			return fmt.Errorf("unknown flag %q for command %q", key, strings.Join(commandNames(cmd), " "))
+		}
+		if err := setFlag(flag, section[key]); err != nil {
+			return err
+		}
+	}
+	for _, key := range sortedConfigKeys(c) {
+		if _, exists := section[key]; exists {
+			continue
+		}
+		if _, isSection := c[key].(map[string]any); isSection {
+			continue
+		}
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil {
+			continue
+		}
+		if err := setFlag(flag, c[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// args returns the positional arguments for cmd, which are those given on
+// the command line, or otherwise those of the command section.
+func (c settings) args(cmd *cobra.Command, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	section, err := c.section(cmd)
+	if err != nil {
+		return nil, err
+	}
+	value, exists := section[configArgsKey]
+	if !exists {
+		return args, nil
+	}
+	return configStrings(value)
+}
+
+// setFlag sets a flag not given on the command line to a config value.
+func setFlag(flag *pflag.Flag, value any) error {
+	if flag.Changed {
+		return nil
+	}
+
+	values, err := configStrings(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %q: %w", flag.Name, err)
+	}
+
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		err = sliceValue.Replace(values)
+	} else {
+		err = flag.Value.Set(strings.Join(values, ","))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value for %q: %w", flag.Name, err)
+	}
+	flag.Changed = true
+	return nil
+}
+
+// configStrings returns a scalar config value, or the items of a list, as
+// strings.
+func configStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []any:
+		var values []string
+		for _, item := range v {
+			s, err := configStrings(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s...)
+		}
+		return values, nil
+	case map[string]any:
+		return nil, fmt.Errorf("expected a value or a list, got a map")
+	case nil:
+		return nil, nil
+	}
+	return []string{fmt.Sprint(value)}, nil
+}
+
+// commandNames returns the names of cmd and its parents below the root
+// command, e.g. [data update].
+func commandNames(cmd *cobra.Command) []string {
+	var names []string
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		names = append([]string{cmd.Name()}, names...)
+	}
+	return names
+}
+
+func isSubcommand(cmd *cobra.Command, name string) bool {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedConfigKeys(c settings) []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyConfig sets the flags of cmd from the config file.
+func applyConfig(cmd *cobra.Command) error {
+	c, err := loadedConfig()
+	if err != nil {
+		return err
+	}
+	return c.apply(cmd)
+}
+
+// configArgs validates the positional arguments of a command with validate,
+// after taking them from the config file if none were given. Commands using
+// it must call argsWithConfig to get the arguments.
+func configArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		args, err := argsWithConfig(cmd, args)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read config: %w", err)
+		}
+		if err := validate(cmd, args); err != nil {
+			return &exitError{code: exitUsage, err: err}
+		}
+		return nil
+	}
+}
+
+// argsWithConfig returns the positional arguments of a command, taken from
+// the config file if none were given.
+func argsWithConfig(cmd *cobra.Command, args []string) ([]string, error) {
+	c, err := loadedConfig()
+	if err != nil {
+		return nil, err
+	}
+	return c.args(cmd, args)
+}
+
+// commandArgs returns the positional arguments of a command validated by
+// configArgs.
+func commandArgs(cmd *cobra.Command, args []string) []string {
+	// Errors were already returned by configArgs.
+	args, _ = argsWithConfig(cmd, args)
+	return args
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// testCommands returns a root command with a subcommand "sub", which has a
+// subcommand "leaf", and the flag values of "leaf".
+func testCommands(t *testing.T) (*cobra.Command, *cobra.Command, *string, *float64, *[]string) {
+	t.Helper()
+
+	var name string
+	var utilization float64
+	var moves []string
+
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("instances-csv", "", "")
+	sub := &cobra.Command{Use: "sub"}
+	leaf := &cobra.Command{Use: "leaf", Run: func(cmd *cobra.Command, args []string) {}}
+	leaf.Flags().StringVar(&name, "name", "default", "")
+	leaf.Flags().Float64Var(&utilization, "utilization", 50, "")
+	leaf.Flags().StringArrayVar(&moves, "move-region", nil, "")
+	root.AddCommand(sub)
+	sub.AddCommand(leaf)
+
+	return root, leaf, &name, &utilization, &moves
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_readConfig(t *testing.T) {
+	c, err := readConfig(writeConfig(t, "utilization: 40\nanalyse:\n  group-by: [account, region]\n"))
+	if err != nil {
+		t.Fatalf("readConfig() error = %v", err)
+	}
+	if c["utilization"] != 40 {
+		t.Errorf("utilization = %v, want 40", c["utilization"])
+	}
+	if _, ok := c["analyse"].(map[string]any); !ok {
+		t.Errorf("analyse = %T, want map", c["analyse"])
+	}
+
+	if c, err := readConfig(""); err != nil || len(c) != 0 {
+		t.Errorf("readConfig() without path = %v, %v, want empty config", c, err)
+	}
+	if _, err := readConfig(writeConfig(t, "- not a map")); err == nil {
+		t.Error("readConfig() of a list returned no error")
+	}
+	if _, err := readConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("readConfig() of missing file returned no error")
+	}
+}
+
+func Test_settings_apply(t *testing.T) {
+	c, err := readConfig(writeConfig(t, `
+utilization: 40
+name: global
+instances-csv: instances.csv
+sub:
+  leaf:
+    name: leaf
+    move-region: [a=b, c=d]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, leaf, name, utilization, moves := testCommands(t)
+	root.SetArgs([]string{"sub", "leaf", "--utilization", "30"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.apply(leaf); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if *name != "leaf" {
+		t.Errorf("name = %q, want value of command section", *name)
+	}
+	if *utilization != 30 {
+		t.Errorf("utilization = %v, want value of command line", *utilization)
+	}
+	if want := []string{"a=b", "c=d"}; !reflect.DeepEqual(*moves, want) {
+		t.Errorf("move-region = %v, want %v", *moves, want)
+	}
+	if got := leaf.Flags().Lookup("instances-csv").Value.String(); got != "instances.csv" {
+		t.Errorf("instances-csv = %q, want global value", got)
+	}
+}
+
+func Test_settings_apply_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown flag", content: "sub:\n  leaf:\n    unknown: 1\n"},
+		{name: "invalid value", content: "sub:\n  leaf:\n    utilization: high\n"},
+		{name: "section is no map", content: "sub: [leaf]\n"},
+		{name: "map value", content: "sub:\n  leaf:\n    name: {a: b}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := readConfig(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, leaf, _, _, _ := testCommands(t)
+			if err := c.apply(leaf); err == nil {
+				t.Error("apply() returned no error")
+			}
+		})
+	}
+}
+
+func Test_settings_args(t *testing.T) {
+	c, err := readConfig(writeConfig(t, "sub:\n  leaf:\n    args: [s3://bucket/a, s3://bucket/b]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, leaf, _, _, _ := testCommands(t)
+
+	got, err := c.args(leaf, nil)
+	if err != nil {
+		t.Fatalf("args() error = %v", err)
+	}
+	if want := []string{"s3://bucket/a", "s3://bucket/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args() = %v, want %v", got, want)
+	}
+
+	got, _ = c.args(leaf, []string{"local"})
+	if want := []string{"local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args() with arguments = %v, want %v", got, want)
+	}
+}
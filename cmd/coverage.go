@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// coverage records which usage could be estimated with the footprint
+// datasets, which was estimated with a fallback for instance types missing
+// from them, and which was skipped.
+type coverage struct {
+	// Instance hours estimated from the datasets, estimated with a
+	// fallback, and skipped.
+	datasetHours   float64
+	estimatedHours float64
+	skippedHours   float64
+
+	// estimated maps the instance types estimated with a fallback to a
+	// description of the estimate.
+	estimated map[string]string
+
+	// skipped maps a description of the usage skipped, e.g. "EC2 usage in
+	// region eu-central-1, type m5.large", to the reason.
+	skipped map[string]string
+}
+
+func newCoverage() *coverage {
+	return &coverage{
+		estimated: make(map[string]string),
+		skipped:   make(map[string]string),
+	}
+}
+
+// add records the usage of a row estimated from the datasets, or with a
+// fallback if description is set.
+func (c *coverage) add(row AggregateReportRow, description string) {
+	if description == "" {
+		c.datasetHours += row.Duration.Hours()
+		return
+	}
+	c.estimatedHours += row.Duration.Hours()
+	c.estimated[row.InstanceType] = description
+}
+
+// addSkipped records the usage of a row that could not be estimated.
+func (c *coverage) addSkipped(row AggregateReportRow, err error) {
+	c.skippedHours += row.Duration.Hours()
+	usage := fmt.Sprintf("%s usage in region %s, type %s", row.Category, row.Region, row.InstanceType+row.StorageType)
+	c.skipped[usage] = err.Error()
+}
+
+// write prints a summary of the coverage, if any usage was estimated with a
+// fallback or skipped.
+func (c *coverage) write(w io.Writer) {
+	if len(c.estimated) == 0 && len(c.skipped) == 0 {
+		return
+	}
+
+	total := c.datasetHours + c.estimatedHours + c.skippedHours
+	if total > 0 {
+		fmt.Fprintf(w, "\nCoverage of %.1f instance hours: %.1f%% from the dataset, %.1f%% estimated, %.1f%% skipped.\n",
+			total, c.datasetHours/total*100, c.estimatedHours/total*100, c.skippedHours/total*100)
+	} else {
+		fmt.Fprintln(w)
+	}
+
+	if len(c.estimated) > 0 {
+		fmt.Fprintln(w, "Instance types estimated for missing footprint data:")
+		for _, instanceType := range sortedKeys(c.estimated) {
+			fmt.Fprintf(w, "  - %s: %s\n", instanceType, c.estimated[instanceType])
+		}
+	}
+	if len(c.skipped) > 0 {
+		fmt.Fprintln(w, "Usage skipped:")
+		for _, usage := range sortedKeys(c.skipped) {
+			fmt.Fprintf(w, "  - %s: %s\n", usage, c.skipped[usage])
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
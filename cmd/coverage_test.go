@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_computeEmissions_coverage(t *testing.T) {
+	summary := newReportSummary(testDimensions(t, defaultGroupBy))
+	for _, row := range []AggregateReportRow{
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.large", Duration: 10 * time.Hour},
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.32xlarge", Duration: 5 * time.Hour},
+		{Category: categoryEC2, Region: "xx-west-1", InstanceType: "m5.large", Duration: 5 * time.Hour},
+	} {
+		row.Labels = []string{row.Category, row.Region, row.InstanceType}
+		summary.addAggregate(row)
+	}
+
+	tests := []struct {
+		name     string
+		fallback string
+		wantRows int
+		want     string
+	}{
+		{
+			name:     "family",
+			fallback: footprint.FallbackFamily,
+			wantRows: 2,
+			want: `
+Coverage of 20.0 instance hours: 50.0% from the dataset, 25.0% estimated, 25.0% skipped.
+Instance types estimated for missing footprint data:
+  - m5.32xlarge: scaled from m5.24xlarge
+Usage skipped:
+  - EC2 usage in region xx-west-1, type m5.large: unknown region code "xx-west-1"
+`,
+		},
+		{
+			name:     "none",
+			fallback: footprint.FallbackNone,
+			wantRows: 1,
+			want: `
+Coverage of 20.0 instance hours: 50.0% from the dataset, 0.0% estimated, 50.0% skipped.
+Usage skipped:
+  - EC2 usage in region eu-west-1, type m5.32xlarge: unknown instance type "m5.32xlarge"
+  - EC2 usage in region xx-west-1, type m5.large: unknown region code "xx-west-1"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := defaultEmissionOptions()
+			options.fallback = tt.fallback
+			options.coverage = newCoverage()
+
+			rows, _ := computeEmissions(context.Background(), summary, options)
+			if len(rows) != tt.wantRows {
+				t.Errorf("computeEmissions() returned %d rows, want %d", len(rows), tt.wantRows)
+			}
+
+			var b bytes.Buffer
+			options.coverage.write(&b)
+			if b.String() != tt.want {
+				t.Errorf("coverage.write() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_coverage_write_complete(t *testing.T) {
+	c := newCoverage()
+	c.add(AggregateReportRow{Category: categoryEC2, InstanceType: "m5.large", Duration: time.Hour}, "")
+
+	var b bytes.Buffer
+	c.write(&b)
+	if b.Len() != 0 {
+		t.Errorf("coverage.write() = %q, want no output when all usage is covered", b.String())
+	}
+}
+
+func Test_coverage_write_withoutHours(t *testing.T) {
+	c := newCoverage()
+	c.addSkipped(AggregateReportRow{Category: categoryEBS, Region: "xx-west-1", StorageType: "gp3"}, footprint.ErrUnknownRegion)
+
+	var b bytes.Buffer
+	c.write(&b)
+	if strings.Contains(b.String(), "instance hours") {
+		t.Errorf("coverage.write() = %q, want no instance hours", b.String())
+	}
+	if !strings.Contains(b.String(), "EBS usage in region xx-west-1, type gp3: unknown region code") {
+		t.Errorf("coverage.write() = %q, want skipped EBS usage", b.String())
+	}
+}
@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Environment variables setting the defaults of --instances-csv and
+// --regions-csv.
+const (
+	envInstancesCSV = "CLOUD_CARBON_INSTANCES_CSV"
+	envRegionsCSV   = "CLOUD_CARBON_REGIONS_CSV"
+)
+
+// teadsDatasetURL is the CSV export of the EC2 instance sheet of the Teads
+// dataset.
+const teadsDatasetURL = "https://docs.google.com/spreadsheets/d/1DqYgQnEDLQVQm5acMAhLgHLD8xXCG9BIrk-_Nv6jF3k/export?format=csv&gid=504755275"
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Manage the datasets used for estimates",
+}
+
+var dataUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Download the latest snapshot of the EC2 instance dataset",
+	Long: `Download the latest snapshot of the EC2 instance dataset.
+
+The dataset by Teads engineering, with the power consumption and
+manufacturing emissions of EC2 instance types, is embedded in the tool as of
+its release. This command downloads the current version, checks that it can
+be read, and saves it. Use it with --instances-csv or the
+` + envInstancesCSV + ` environment variable.
+`,
+	RunE: dataUpdate,
+	Args: cobra.NoArgs,
+}
+
+var dataInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the provenance of the datasets used for estimates",
+	Long: `Show the provenance of the datasets used for estimates.
+
+For each dataset embedded in the tool, the snapshot date of the data, if
+known, the number of rows, the source the data or its methodology was
+published at, and the license of the data are shown, so that reports can cite
+them. Datasets replaced with --instances-csv or --regions-csv, or their
+environment variables, are shown with the path of the file instead.
+`,
+	RunE: dataInfo,
+	Args: cobra.NoArgs,
+}
+
+var (
+	instancesCSV   string
+	regionsCSV     string
+	dataUpdateURL  string
+	dataUpdateFile string
+	dataInfoFormat string
+	pue            float64
+	regionPUE      []string
+	wue            float64
+	regionWUE      []string
+
+	// amortizationYears is the hardware lifetime manufacturing emissions
+	// are spread over.
+	amortizationYears float64
+
+	// wueFlag tells whether --wue was given, as zero is a valid WUE, e.g.
+	// for air-cooled data centers.
+	wueFlag *pflag.Flag
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&instancesCSV, "instances-csv", "", fmt.Sprintf("CSV file with EC2 instance data in the format of the Teads dataset, replacing the embedded dataset. Defaults to $%s", envInstancesCSV))
+	rootCmd.PersistentFlags().StringVar(&regionsCSV, "regions-csv", "", fmt.Sprintf("CSV file with AWS region data in the format of the embedded aws-regions.csv, replacing the embedded dataset. Defaults to $%s", envRegionsCSV))
+
+	rootCmd.PersistentFlags().Float64Var(&pue, "pue", 0, "Power usage effectiveness of the data centers in all regions, overriding the datasets")
+	rootCmd.PersistentFlags().StringArrayVar(&regionPUE, "region-pue", nil, "Power usage effectiveness of the data centers in one region, given as REGION=PUE, overriding --pue and the datasets. Can be repeated")
+	rootCmd.PersistentFlags().Float64Var(&wue, "wue", 0, "Water usage effectiveness of the data centers in all regions in liters per kWh, overriding the providers' averages")
+	wueFlag = rootCmd.PersistentFlags().Lookup("wue")
+	rootCmd.PersistentFlags().StringArrayVar(&regionWUE, "region-wue", nil, "Water usage effectiveness of the data centers in one region in liters per kWh, given as REGION=WUE, overriding --wue. Can be repeated")
+	rootCmd.PersistentFlags().Float64Var(&amortizationYears, "amortization-years", footprint.DefaultAmortizationYears, "Hardware lifetime in years the manufacturing emissions of instances are spread over")
+
+	dataUpdateCmd.Flags().StringVarP(&dataUpdateFile, "output", "o", "aws-ec2-instances.csv", "Path of the CSV file to write")
+	dataUpdateCmd.Flags().StringVar(&dataUpdateURL, "url", teadsDatasetURL, "URL to download the dataset from")
+	dataCmd.AddCommand(dataUpdateCmd)
+
+	dataInfoCmd.Flags().StringVarP(&dataInfoFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	dataCmd.AddCommand(dataInfoCmd)
+}
+
+// DatasetInfo describes a dataset used for estimates, either embedded or
+// replaced by a file.
+type DatasetInfo struct {
+	Name         string `json:"name"`
+	Path         string `json:"path,omitempty"`
+	SnapshotDate string `json:"snapshot_date,omitempty"`
+	Rows         int    `json:"rows,omitempty"`
+	Checksum     string `json:"checksum"`
+	Source       string `json:"source,omitempty"`
+	License      string `json:"license,omitempty"`
+}
+
+// datasetInfos returns the datasets used, with the embedded datasets
+// replaced by --instances-csv and --regions-csv listed as their files.
+func datasetInfos() ([]DatasetInfo, error) {
+	replaced := map[string]string{
+		"aws-ec2-instances.csv": datasetFile(instancesCSV, envInstancesCSV),
+		"aws-regions.csv":       datasetFile(regionsCSV, envRegionsCSV),
+	}
+
+	var datasets []DatasetInfo
+	for _, d := range footprint.DatasetInfo() {
+		dataset := DatasetInfo{
+			Name:         d.Name,
+			SnapshotDate: d.SnapshotDate,
+			Rows:         d.Rows,
+			Checksum:     d.Checksum,
+			Source:       d.Source,
+			License:      d.License,
+		}
+		if path := replaced[d.Name]; path != "" {
+			checksum, err := fileChecksum(path)
+			if err != nil {
+				return nil, fmt.Errorf("dataset %s: %w", path, err)
+			}
+			dataset = DatasetInfo{Name: d.Name, Path: path, Checksum: checksum}
+		}
+		datasets = append(datasets, dataset)
+	}
+	return datasets, nil
+}
+
+func dataInfo(cmd *cobra.Command, args []string) error {
+	if dataInfoFormat != outputTable && dataInfoFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", dataInfoFormat, outputTable, outputJSON)
+	}
+
+	datasets, err := datasetInfos()
+	if err != nil {
+		return exitErrorf(exitIO, "could not read datasets: %w", err)
+	}
+
+	switch dataInfoFormat {
+	case outputTable:
+		writeDatasetsTable(os.Stdout, datasets)
+	case outputJSON:
+		err = writeJSON(os.Stdout, datasets)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// writeDatasetsTable writes the datasets as a table, with replaced datasets
+// showing their file as the source.
+func writeDatasetsTable(w io.Writer, datasets []DatasetInfo) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Dataset", "Snapshot", "Rows", "Source", "License"})
+
+	for _, d := range datasets {
+		snapshot, rows, source, license := d.SnapshotDate, strconv.Itoa(d.Rows), d.Source, d.License
+		if d.Path != "" {
+			rows, source = "-", d.Path
+		}
+		if snapshot == "" {
+			snapshot = "-"
+		}
+		if license == "" {
+			license = "-"
+		}
+		table.Append([]string{d.Name, snapshot, rows, source, license})
+	}
+
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
+
+// calculator estimates footprints for all commands. It is set up by
+// newCalculator before a command runs.
+var calculator *footprint.Calculator
+
+// newCalculator returns a calculator with the embedded datasets replaced by
+// the files given by --instances-csv and --regions-csv, or their
+// environment variables, the PUE overridden by --pue and --region-pue, the
+// WUE overridden by --wue and --region-wue, manufacturing emissions spread
+// over --amortization-years, and the carbon intensity overridden by
+// --intensity-overrides.
+func newCalculator() (*footprint.Calculator, error) {
+	var opts []footprint.Option
+	for _, d := range []struct {
+		flag, env, path string
+		option          func(r io.Reader) footprint.Option
+	}{
+		{"--instances-csv", envInstancesCSV, instancesCSV, footprint.WithEC2Instances},
+		{"--regions-csv", envRegionsCSV, regionsCSV, footprint.WithAWSRegions},
+	} {
+		path := datasetFile(d.path, d.env)
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", d.flag, err)
+		}
+		opts = append(opts, d.option(bytes.NewReader(data)))
+	}
+
+	if pue != 0 {
+		opts = append(opts, footprint.WithPUE(pue))
+	}
+	overrides, err := parseRegionValues(regionPUE, "PUE")
+	if err != nil {
+		return nil, usageErrorf("invalid --region-pue value: %w", err)
+	}
+	for code, value := range overrides {
+		opts = append(opts, footprint.WithRegionPUE(code, value))
+	}
+
+	if wueFlag.Changed {
+		opts = append(opts, footprint.WithWUE(wue))
+	}
+	overrides, err = parseRegionValues(regionWUE, "WUE")
+	if err != nil {
+		return nil, usageErrorf("invalid --region-wue value: %w", err)
+	}
+	for code, value := range overrides {
+		opts = append(opts, footprint.WithRegionWUE(code, value))
+	}
+
+	if amortizationYears <= 0 {
+		return nil, usageErrorf("invalid --amortization-years value %g, must be positive", amortizationYears)
+	}
+	opts = append(opts, footprint.WithAmortizationYears(amortizationYears))
+
+	if intensityOverridesFile != "" {
+		overrides, err := readIntensityOverrides(intensityOverridesFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --intensity-overrides value: %w", err)
+		}
+		for code, ci := range overrides {
+			opts = append(opts, footprint.WithCarbonIntensity(code, ci))
+		}
+	}
+
+	if outpostsFile != "" {
+		outposts, err := readOutposts(outpostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --outposts value: %w", err)
+		}
+		for location, site := range outposts {
+			opts = append(opts, footprint.WithOutpost(outpostRegion(location), *site.CarbonIntensity, site.PUE, site.WUE))
+		}
+	}
+
+	return footprint.NewCalculator(opts...)
+}
+
+// datasetFile returns the file replacing an embedded dataset, given by a
+// flag or else by an environment variable, or an empty string if the
+// embedded dataset is used.
+func datasetFile(path, env string) string {
+	if path == "" {
+		path = os.Getenv(env)
+	}
+	return path
+}
+
+// parseRegionValues parses values of flags like --region-pue of the form
+// REGION=VALUE into a map from region code to value. name names the value in
+// errors, e.g. PUE.
+func parseRegionValues(values []string, name string) (map[string]float64, error) {
+	overrides := make(map[string]float64)
+	for _, value := range values {
+		code, s, found := strings.Cut(value, "=")
+		if !found || code == "" {
+			return nil, fmt.Errorf("%q is not of the form REGION=%s", value, name)
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q for region %s", name, s, code)
+		}
+		overrides[code] = v
+	}
+	return overrides, nil
+}
+
+func dataUpdate(cmd *cobra.Command, args []string) error {
+	data, err := downloadDataset(cmd.Context(), http.DefaultClient, dataUpdateURL)
+	if err != nil {
+		return exitErrorf(exitIO, "could not download dataset: %w", err)
+	}
+
+	instances, err := footprint.ParseEC2Instances(bytes.NewReader(data))
+	if err != nil {
+		return exitErrorf(exitParse, "downloaded dataset is not in the expected format: %w", err)
+	}
+
+	if err := writeFileAtomic(dataUpdateFile, data); err != nil {
+		return exitErrorf(exitIO, "could not write %s: %w", dataUpdateFile, err)
+	}
+	fmt.Printf("Saved data of %d instance types to %s.\n", len(instances), dataUpdateFile)
+	fmt.Printf("Use it with --instances-csv %s or %s=%s.\n", dataUpdateFile, envInstancesCSV, dataUpdateFile)
+	return nil
+}
+
+// downloadDataset returns the content at url.
+func downloadDataset(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// writeFileAtomic writes data to path, replacing an existing file only once
+// all data is written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dataset-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
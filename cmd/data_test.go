@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_downloadDataset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dataset.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("Instance type\n"))
+	}))
+	defer server.Close()
+
+	data, err := downloadDataset(context.Background(), server.Client(), server.URL+"/dataset.csv")
+	if err != nil {
+		t.Fatalf("downloadDataset() error = %v", err)
+	}
+	if string(data) != "Instance type\n" {
+		t.Errorf("downloadDataset() = %q", data)
+	}
+
+	if _, err := downloadDataset(context.Background(), server.Client(), server.URL+"/missing.csv"); err == nil {
+		t.Error("downloadDataset() for missing file returned no error")
+	}
+}
+
+func Test_newCalculator(t *testing.T) {
+	t.Cleanup(func() { regionsCSV = "" })
+
+	path := filepath.Join(t.TempDir(), "regions.csv")
+	data := "Region,Region Name,Country,NERC Region,CO2e (metric gram/kWh),Source,PUE,Dataset Source,Market-based CO2e (metric gram/kWh)\nxx-test-1,Test,Test,,100,,1.5,,0\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(envRegionsCSV, path)
+	c, err := newCalculator()
+	if err != nil {
+		t.Fatalf("newCalculator() error = %v", err)
+	}
+	if ci, err := c.CarbonIntensity("xx-test-1"); err != nil || ci != 100 {
+		t.Errorf("CarbonIntensity() = %v, %v, want 100, nil", ci, err)
+	}
+
+	// The flag takes precedence over the environment variable.
+	regionsCSV = filepath.Join(t.TempDir(), "missing.csv")
+	if _, err := newCalculator(); err == nil {
+		t.Error("newCalculator() with missing file returned no error")
+	}
+}
+
+func Test_newCalculator_pue(t *testing.T) {
+	t.Cleanup(func() { pue, regionPUE = 0, nil })
+
+	pue, regionPUE = 1.4, []string{"eu-west-1=1.1"}
+	c, err := newCalculator()
+	if err != nil {
+		t.Fatalf("newCalculator() error = %v", err)
+	}
+	if got, _ := c.PUE("eu-west-1"); got != 1.1 {
+		t.Errorf("PUE(eu-west-1) = %v, want 1.1", got)
+	}
+	if got, _ := c.GCPPUE("europe-west1"); got != 1.4 {
+		t.Errorf("GCPPUE(europe-west1) = %v, want 1.4", got)
+	}
+
+	for _, values := range [][]string{{"eu-west-1"}, {"eu-west-1=high"}, {"=1.1"}, {"xx-west-1=1.1"}} {
+		pue, regionPUE = 0, values
+		if _, err := newCalculator(); err == nil {
+			t.Errorf("newCalculator() with --region-pue %v returned no error", values)
+		}
+	}
+}
+
+func Test_newCalculator_wue(t *testing.T) {
+	t.Cleanup(func() {
+		wue, regionWUE = 0, nil
+		wueFlag.Changed = false
+	})
+
+	if err := rootCmd.PersistentFlags().Set("wue", "0"); err != nil {
+		t.Fatal(err)
+	}
+	regionWUE = []string{"eu-west-1=0.4"}
+	c, err := newCalculator()
+	if err != nil {
+		t.Fatalf("newCalculator() error = %v", err)
+	}
+	if got, _ := c.WUE("eu-west-1"); got != 0.4 {
+		t.Errorf("WUE(eu-west-1) = %v, want 0.4", got)
+	}
+	if got, _ := c.GCPWUE("europe-west1"); got != 0 {
+		t.Errorf("GCPWUE(europe-west1) = %v, want 0", got)
+	}
+
+	for _, values := range [][]string{{"eu-west-1"}, {"eu-west-1=-1"}, {"xx-west-1=0.2"}} {
+		regionWUE = values
+		if _, err := newCalculator(); err == nil {
+			t.Errorf("newCalculator() with --region-wue %v returned no error", values)
+		}
+	}
+}
+
+func Test_newCalculator_amortizationYears(t *testing.T) {
+	t.Cleanup(func() { amortizationYears = footprint.DefaultAmortizationYears })
+
+	amortizationYears = 6
+	c, err := newCalculator()
+	if err != nil {
+		t.Fatalf("newCalculator() error = %v", err)
+	}
+	if got := c.AmortizationYears(); got != 6 {
+		t.Errorf("AmortizationYears() = %v, want 6", got)
+	}
+
+	amortizationYears = 0
+	if _, err := newCalculator(); err == nil {
+		t.Error("newCalculator() with --amortization-years 0 returned no error")
+	}
+}
+
+func Test_writeFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.csv")
+	for _, content := range []string{"old", "new"} {
+		if err := writeFileAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("writeFileAtomic() error = %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("file content = %q, %v, want \"new\"", got, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+}
+
+func Test_datasetInfos(t *testing.T) {
+	t.Cleanup(func() { instancesCSV = "" })
+	t.Setenv(envInstancesCSV, "")
+	t.Setenv(envRegionsCSV, "")
+
+	datasets, err := datasetInfos()
+	if err != nil {
+		t.Fatalf("datasetInfos() error = %v", err)
+	}
+	if d := datasets[0]; d.Name != "aws-ec2-instances.csv" || d.Path != "" || d.Rows == 0 || d.License != "CC-BY-4.0" {
+		t.Errorf("datasetInfos()[0] = %+v, want embedded Teads dataset", d)
+	}
+
+	instancesCSV = filepath.Join(t.TempDir(), "instances.csv")
+	if err := os.WriteFile(instancesCSV, []byte("Instance type\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	datasets, err = datasetInfos()
+	if err != nil {
+		t.Fatalf("datasetInfos() error = %v", err)
+	}
+	if d := datasets[0]; d.Path != instancesCSV || d.Source != "" || d.Rows != 0 {
+		t.Errorf("datasetInfos()[0] = %+v, want replaced dataset", d)
+	}
+
+	var buf bytes.Buffer
+	writeDatasetsTable(&buf, datasets)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(datasets)+1 || !strings.Contains(lines[1], instancesCSV) || !strings.Contains(lines[2], "https://aws.amazon.com/ec2/instance-types/") {
+		t.Errorf("writeDatasetsTable() wrote:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// maxInvalidDates is the number of lines with invalid dates listed per
+// report with --strict-dates=false.
+const maxInvalidDates = 5
+
+var strictDates bool
+
+func init() {
+	analyseCmd.Flags().BoolVar(&strictDates, "strict-dates", true, "Fail on lines with invalid usage dates, reporting the line and value. Set to false to skip such lines and summarize them instead")
+}
+
+// invalidDate is a line skipped for an invalid date.
+type invalidDate struct {
+	line int
+	err  error
+}
+
+// skipInvalidDate returns whether the line failing with err is skipped, as
+// the summary is lenient about dates and the error is a *cur.DateError.
+// Skipped lines are counted, and the first ones kept for the summary.
+func (s *ReportSummary) skipInvalidDate(line int, err error) bool {
+	var dateErr *cur.DateError
+	if !s.LenientDates || !errors.As(err, &dateErr) {
+		return false
+	}
+	s.InvalidDateCount++
+	s.addInvalidDates(invalidDate{line: line, err: err})
+	return true
+}
+
+// addInvalidDates adds lines to the lines with invalid dates, keeping the
+// first maxInvalidDates of them.
+func (s *ReportSummary) addInvalidDates(dates ...invalidDate) {
+	s.InvalidDates = append(s.InvalidDates, dates...)
+	sort.Slice(s.InvalidDates, func(i, j int) bool { return s.InvalidDates[i].line < s.InvalidDates[j].line })
+	if len(s.InvalidDates) > maxInvalidDates {
+		s.InvalidDates = s.InvalidDates[:maxInvalidDates]
+	}
+}
+
+// logInvalidDates warns about the lines of a report skipped for invalid
+// dates, listing the first ones.
+func logInvalidDates(name string, summary *ReportSummary) {
+	if summary.InvalidDateCount == 0 {
+		return
+	}
+	lines := make([]string, len(summary.InvalidDates))
+	for i, d := range summary.InvalidDates {
+		lines[i] = fmt.Sprintf("line %d: %s", d.line, d.err)
+	}
+	if more := summary.InvalidDateCount - len(lines); more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+	log.Printf("Warning: skipped %d lines of %s with invalid dates: %s", summary.InvalidDateCount, name, strings.Join(lines, "; "))
+}
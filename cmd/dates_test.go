@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// dateRecord parses an hour of usage starting at the date in the first
+// field, and fails with a *cur.DateError for invalid dates.
+func dateRecord(fields []string, summary *ReportSummary) (cur.LineItem, bool, error) {
+	start, err := time.Parse(dateTimeLayout, fields[0])
+	if err != nil {
+		return cur.LineItem{}, false, &cur.DateError{Column: "lineItem/UsageStartDate", Value: fields[0]}
+	}
+	return cur.LineItem{Region: "eu-west-1", UsageStartTime: start, UsageEndTime: start.Add(time.Hour), Duration: time.Hour}, true, nil
+}
+
+func Test_parseRecords_invalidDates(t *testing.T) {
+	var b strings.Builder
+	for i := 1; i <= 3*parseBatchSize; i++ {
+		date := "2022-08-01T00:00:00Z"
+		if i%100 == 0 {
+			date = "08/01/2022"
+		}
+		fmt.Fprintln(&b, date)
+	}
+	invalid := 3 * parseBatchSize / 100
+
+	summary := newReportSummary(nil)
+	summary.Workers = 4
+	err := parseRecords(csv.NewReader(strings.NewReader(b.String())), summary, dateRecord)
+	if err == nil || err.Error() != `line 100: invalid lineItem/UsageStartDate "08/01/2022"` {
+		t.Errorf("parseRecords() error = %v, want error about line 100", err)
+	}
+	if code := exitCode(err); code != exitParse {
+		t.Errorf("parseRecords() exit code = %d, want %d", code, exitParse)
+	}
+
+	summary = newReportSummary(nil)
+	summary.Workers = 4
+	summary.LenientDates = true
+	err = parseRecords(csv.NewReader(strings.NewReader(b.String())), summary, dateRecord)
+	if err != nil {
+		t.Fatalf("parseRecords() with lenient dates error = %v", err)
+	}
+	if summary.InvalidDateCount != invalid || summary.LineCount != 3*parseBatchSize-invalid {
+		t.Errorf("parseRecords() with lenient dates counted %d invalid and %d valid lines", summary.InvalidDateCount, summary.LineCount)
+	}
+	if len(summary.InvalidDates) != maxInvalidDates || summary.InvalidDates[0].line != 100 || summary.InvalidDates[maxInvalidDates-1].line != 500 {
+		t.Errorf("parseRecords() with lenient dates kept lines %+v, want lines 100 to 500", summary.InvalidDates)
+	}
+	if want := mustParseDate("2022-08-01T00:00:00Z"); !summary.EarliestDate.Equal(want) {
+		t.Errorf("parseRecords() with lenient dates EarliestDate = %s, want %s", summary.EarliestDate, want)
+	}
+}
+
+func TestReportSummary_skipInvalidDate(t *testing.T) {
+	dateErr := fmt.Errorf("wrapped: %w", &cur.DateError{Column: "date", Value: "n/a"})
+
+	summary := newReportSummary(nil)
+	if summary.skipInvalidDate(1, dateErr) {
+		t.Error("skipInvalidDate() skipped a line with strict dates")
+	}
+
+	summary.LenientDates = true
+	if summary.skipInvalidDate(2, errors.New("invalid usage amount")) {
+		t.Error("skipInvalidDate() skipped a line without a date error")
+	}
+	if !summary.skipInvalidDate(3, dateErr) || summary.InvalidDateCount != 1 {
+		t.Errorf("skipInvalidDate() did not skip a line with an invalid date, count %d", summary.InvalidDateCount)
+	}
+}
+
+func Test_analyseAzureReport_invalidDates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure.csv")
+	report := strings.Replace(testAzureReport, "08/02/2022", "2022-13-02", 1)
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := analyseSource(context.Background(), localSource(path), analyseAzureReport, nil, summaryOptions{})
+	if err == nil || !strings.Contains(err.Error(), `line 3: invalid date "2022-13-02"`) {
+		t.Errorf("analyseSource() error = %v, want error about line 3", err)
+	}
+
+	summary, err := analyseSource(context.Background(), localSource(path), analyseAzureReport, nil, summaryOptions{lenientDates: true})
+	if err != nil {
+		t.Fatalf("analyseSource() with lenient dates error = %v", err)
+	}
+	if summary.LineCount != 2 || summary.InvalidDateCount != 1 || summary.InvalidDates[0].line != 3 {
+		t.Errorf("analyseSource() with lenient dates counted %d lines, skipped %+v", summary.LineCount, summary.InvalidDates)
+	}
+}
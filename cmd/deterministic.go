@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// deterministicPrecision is the number of decimal places values are
+// rounded to with --deterministic.
+const deterministicPrecision = 6
+
+var deterministic bool
+
+func init() {
+	analyseCmd.Flags().BoolVar(&deterministic, "deterministic", false, fmt.Sprintf("Write the same output for the same input in every run, so that results can be committed and diffed: values are rounded to %d decimal places and the manifest has no timestamp", deterministicPrecision))
+}
+
+// roundValue rounds v to deterministicPrecision decimal places. Sums of
+// usage differ in the last bits between runs, as the workers parsing a
+// report add up lines in varying order; rounding hides the difference.
+func roundValue(v float64) float64 {
+	scale := math.Pow10(deterministicPrecision)
+	return math.Round(v*scale) / scale
+}
+
+// roundRows returns a copy of rows with the usage and footprint rounded by
+// roundValue.
+func roundRows(rows []AggregateReportRow) []AggregateReportRow {
+	rounded := make([]AggregateReportRow, len(rows))
+	for i, row := range rows {
+		row.GBHours = roundValue(row.GBHours)
+		row.VCPUHours = roundValue(row.VCPUHours)
+		row.TransferGB = roundValue(row.TransferGB)
+		row.Cost = roundValue(row.Cost)
+		row.EnergyKiloWattHours = roundValue(row.EnergyKiloWattHours)
+		row.EmbodiedGrams = roundValue(row.EmbodiedGrams)
+		row.EmissionGrams = roundValue(row.EmissionGrams)
+		row.InstanceVCPUHours = roundValue(row.InstanceVCPUHours)
+		row.WaterLiters = roundValue(row.WaterLiters)
+		rounded[i] = row
+	}
+	return rounded
+}
+
+// roundResult returns the footprint rounded by roundValue.
+func roundResult(r footprint.Result) footprint.Result {
+	return footprint.Result{
+		EnergyKiloWattHours: roundValue(r.EnergyKiloWattHours),
+		OperationalGrams:    roundValue(r.OperationalGrams),
+		EmbodiedGrams:       roundValue(r.EmbodiedGrams),
+		WaterLiters:         roundValue(r.WaterLiters),
+	}
+}
+
+// roundSeries returns a copy of points with the values rounded by
+// roundValue, as sums of rounded rows may again have more decimal places.
+func roundSeries(points []SeriesPoint) []SeriesPoint {
+	rounded := make([]SeriesPoint, len(points))
+	for i, p := range points {
+		p.EnergyKiloWattHours = roundValue(p.EnergyKiloWattHours)
+		p.OperationalGrams = roundValue(p.OperationalGrams)
+		p.EmbodiedGrams = roundValue(p.EmbodiedGrams)
+		p.EmissionGrams = roundValue(p.EmissionGrams)
+		p.Cost = roundValue(p.Cost)
+		p.VCPUHours = roundValue(p.VCPUHours)
+		p.WaterLiters = roundValue(p.WaterLiters)
+		rounded[i] = p
+	}
+	return rounded
+}
+
+// outputSeries returns the time series written with -o csv and -o json,
+// rounded with --deterministic.
+func outputSeries(dimensions []Dimension, rows []AggregateReportRow, period periodFunc) []SeriesPoint {
+	points := timeSeries(dimensions, rows, period)
+	if deterministic {
+		return roundSeries(points)
+	}
+	return points
+}
+
+// formatValue formats a value for CSV output, with deterministicPrecision
+// decimal places with --deterministic, or else as precisely as needed.
+func formatValue(v float64) string {
+	if deterministic {
+		return strconv.FormatFloat(v, 'f', deterministicPrecision, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_roundValue(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want float64
+	}{
+		{v: 0.1 + 0.2, want: 0.3},
+		{v: 1.7342913022046351, want: 1.734291},
+		{v: 1.0000005, want: 1.000001},
+		{v: 12345, want: 12345},
+	}
+
+	for _, tt := range tests {
+		if got := roundValue(tt.v); got != tt.want {
+			t.Errorf("roundValue(%v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func Test_roundRows(t *testing.T) {
+	rows := []AggregateReportRow{{Labels: []string{"eu-west-1"}, Cost: 0.1 + 0.2, EmissionGrams: 1.23456789, EmbodiedGrams: 0.0000001}}
+
+	got := roundRows(rows)
+	if got[0].Cost != 0.3 || got[0].EmissionGrams != 1.234568 || got[0].EmbodiedGrams != 0 || got[0].Labels[0] != "eu-west-1" {
+		t.Errorf("roundRows() = %+v", got[0])
+	}
+	if rows[0].EmissionGrams != 1.23456789 {
+		t.Error("roundRows() modified its argument")
+	}
+
+	total := roundResult(footprint.Result{OperationalGrams: 0.1 + 0.2, WaterLiters: 2.0000004})
+	if total.OperationalGrams != 0.3 || total.WaterLiters != 2 {
+		t.Errorf("roundResult() = %+v", total)
+	}
+}
+
+func Test_outputSeries(t *testing.T) {
+	t.Cleanup(func() { deterministic = false })
+
+	dimensions := []Dimension{{Name: "region"}}
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, EmissionGrams: 0.1},
+		{Labels: []string{"eu-west-1"}, EmissionGrams: 0.2},
+	}
+
+	if got := outputSeries(dimensions, rows, nil); got[0].EmissionGrams == 0.3 {
+		t.Errorf("outputSeries() = %v, want unrounded sum", got[0].EmissionGrams)
+	}
+	deterministic = true
+	if got := outputSeries(dimensions, rows, nil); got[0].EmissionGrams != 0.3 {
+		t.Errorf("outputSeries() with --deterministic = %v, want 0.3", got[0].EmissionGrams)
+	}
+}
+
+func Test_formatValue(t *testing.T) {
+	t.Cleanup(func() { deterministic = false })
+
+	if got := formatValue(1.5); got != "1.5" {
+		t.Errorf("formatValue() = %q, want 1.5", got)
+	}
+	deterministic = true
+	if got := formatValue(1.5); got != "1.500000" {
+		t.Errorf("formatValue() with --deterministic = %q, want 1.500000", got)
+	}
+}
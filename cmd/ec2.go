@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/spf13/cobra"
+)
+
+var estimateAWSCmd = &cobra.Command{
+	Use:   "estimate-aws",
+	Short: "Project the emissions of the EC2 instances currently running",
+	Long: `Project the emissions of the EC2 instances currently running.
+
+Running instances are listed with the EC2 DescribeInstances API in the
+given regions, or in all regions enabled for the account. To cover several
+accounts, give one AWS configuration profile per account with --profiles.
+
+The output shows the emissions of running the current instances for an
+hour, a day and a month. This allows forward-looking estimates, while the
+analyse command covers past usage. Only EC2 instances are included.
+`,
+	RunE: estimateAWS,
+	Args: cobra.NoArgs,
+}
+
+var (
+	estimateProfiles    string
+	estimateRegions     string
+	estimateUtilization float64
+)
+
+func init() {
+	estimateAWSCmd.Flags().StringVar(&estimateProfiles, "profiles", "", "Comma-separated list of AWS configuration profiles, one per account. Defaults to the default configuration")
+	estimateAWSCmd.Flags().StringVar(&estimateRegions, "regions", "", "Comma-separated list of regions. Defaults to all regions enabled for the account")
+	estimateAWSCmd.Flags().Float64Var(&estimateUtilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of the instances in percent")
+}
+
+// ec2API is the part of the EC2 client used to list instances.
+type ec2API interface {
+	ec2.DescribeInstancesAPIClient
+	DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
+}
+
+func estimateAWS(cmd *cobra.Command, args []string) error {
+	if estimateUtilization < 0 || estimateUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", estimateUtilization)
+	}
+
+	profiles := splitList(estimateProfiles)
+	if len(profiles) == 0 {
+		profiles = []string{""}
+	}
+
+	var instances []runningInstance
+	for _, profile := range profiles {
+		found, err := profileInstances(cmd.Context(), profile, splitList(estimateRegions))
+		if err != nil {
+			return exitErrorf(exitIO, "could not list instances: %w", err)
+		}
+		instances = append(instances, found...)
+	}
+
+	fmt.Printf("Found %d running instances.\n\n", len(instances))
+
+	rows, total := projectEmissions(instances, estimateUtilization)
+	writeProjectionTable(os.Stdout, rows, total)
+	return nil
+}
+
+// profileInstances returns the running instances of the account of an AWS
+// configuration profile in the given regions, or in all enabled regions if
+// none are given. An empty profile refers to the default configuration.
+func profileInstances(ctx context.Context, profile string, regions []string) ([]runningInstance, error) {
+	var options []func(*config.LoadOptions) error
+	if profile != "" {
+		options = append(options, config.WithSharedConfigProfile(profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	if len(regions) == 0 {
+		regions, err = enabledRegions(ctx, ec2.NewFromConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var instances []runningInstance
+	for _, region := range regions {
+		client := ec2.NewFromConfig(cfg, func(o *ec2.Options) { o.Region = region })
+		found, err := runningEC2Instances(ctx, client, region)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, found...)
+	}
+	return instances, nil
+}
+
+// enabledRegions returns the regions enabled for the account.
+func enabledRegions(ctx context.Context, client ec2API) ([]string, error) {
+	out, err := client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list regions: %w", err)
+	}
+
+	var regions []string
+	for _, r := range out.Regions {
+		regions = append(regions, aws.ToString(r.RegionName))
+	}
+	return regions, nil
+}
+
+// runningEC2Instances returns the instances running in a region.
+func runningEC2Instances(ctx context.Context, client ec2API, region string) ([]runningInstance, error) {
+	var instances []runningInstance
+
+	paginator := ec2.NewDescribeInstancesPaginator(client, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("instance-state-name"), Values: []string{string(types.InstanceStateNameRunning)}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe instances in region %s: %w", region, err)
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instances = append(instances, runningInstance{
+					Category:     categoryEC2,
+					Region:       region,
+					InstanceType: string(instance.InstanceType),
+				})
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// splitList splits a comma-separated list, ignoring empty entries.
+func splitList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
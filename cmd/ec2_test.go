@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// fakeEC2 returns the given pages of reservations and regions.
+type fakeEC2 struct {
+	pages   [][]types.Reservation
+	regions []string
+}
+
+func (f *fakeEC2) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	page := 0
+	if params.NextToken != nil {
+		page = len(aws.ToString(params.NextToken))
+	}
+
+	out := &ec2.DescribeInstancesOutput{Reservations: f.pages[page]}
+	if page+1 < len(f.pages) {
+		out.NextToken = aws.String(string(make([]byte, page+1)))
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	out := &ec2.DescribeRegionsOutput{}
+	for _, r := range f.regions {
+		out.Regions = append(out.Regions, types.Region{RegionName: aws.String(r)})
+	}
+	return out, nil
+}
+
+func Test_runningEC2Instances(t *testing.T) {
+	client := &fakeEC2{pages: [][]types.Reservation{
+		{{Instances: []types.Instance{{InstanceType: types.InstanceTypeT3Micro}, {InstanceType: types.InstanceTypeM5Xlarge}}}},
+		{{Instances: []types.Instance{{InstanceType: types.InstanceTypeT3Micro}}}},
+	}}
+
+	got, err := runningEC2Instances(context.Background(), client, "eu-west-1")
+	if err != nil {
+		t.Fatalf("runningEC2Instances() error = %v", err)
+	}
+
+	want := []runningInstance{
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro"},
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.xlarge"},
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("runningEC2Instances() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("runningEC2Instances()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_enabledRegions(t *testing.T) {
+	got, err := enabledRegions(context.Background(), &fakeEC2{regions: []string{"eu-west-1", "us-east-1"}})
+	if err != nil {
+		t.Fatalf("enabledRegions() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "eu-west-1" || got[1] != "us-east-1" {
+		t.Errorf("enabledRegions() = %v", got)
+	}
+}
+
+func Test_splitList(t *testing.T) {
+	got := splitList(" eu-west-1, ,us-east-1,")
+	if len(got) != 2 || got[0] != "eu-west-1" || got[1] != "us-east-1" {
+		t.Errorf("splitList() = %q", got)
+	}
+	if got := splitList(""); len(got) != 0 {
+		t.Errorf("splitList(\"\") = %q, want empty", got)
+	}
+}
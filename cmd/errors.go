@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Exit codes of the commands, telling automation what kind of error
+// stopped a command.
+const (
+	// exitFailure is the exit code of other errors, and of failed checks
+	// like an exceeded emissions budget.
+	exitFailure = 1
+
+	// exitUsage is the exit code of invalid flags and arguments.
+	exitUsage = 2
+
+	// exitIO is the exit code of errors reading or writing files, or of
+	// network requests.
+	exitIO = 3
+
+	// exitParse is the exit code of malformed reports and input files.
+	exitParse = 4
+
+	// exitData is the exit code of invalid datasets, and of usage the
+	// datasets have no data for.
+	exitData = 5
+)
+
+// exitError is an error with the exit code of its kind.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitErrorf returns an error formatted like fmt.Errorf, with the exit code
+// of its kind. The code of a wrapped exitError takes precedence, as it is
+// more specific. Errors opening a file are I/O errors whatever code is
+// given, as the file may be missing rather than invalid.
+func exitErrorf(code int, format string, a ...any) error {
+	err := fmt.Errorf(format, a...)
+	var exitErr *exitError
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.code
+	case errors.As(err, &pathErr):
+		code = exitIO
+	}
+	return &exitError{code: code, err: err}
+}
+
+// usageErrorf returns an error about invalid flags or arguments.
+func usageErrorf(format string, a ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, a...)}
+}
+
+// parseErrorf returns an error about malformed input.
+func parseErrorf(format string, a ...any) error {
+	return &exitError{code: exitParse, err: fmt.Errorf(format, a...)}
+}
+
+// readErrorCode returns the exit code of an error reading a report: a parse
+// error if the content is malformed, or else an I/O error.
+func readErrorCode(err error) int {
+	var exitErr *exitError
+	var csvErr *csv.ParseError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.As(err, &csvErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr),
+		errors.Is(err, gzip.ErrHeader), errors.Is(err, gzip.ErrChecksum):
+		return exitParse
+	default:
+		return exitIO
+	}
+}
+
+// exitCode returns the exit code of an error returned by a command.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func Test_exitCode(t *testing.T) {
+	_, pathErr := os.Open("/nonexistent/report.csv")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "plain", err: errors.New("failed"), want: exitFailure},
+		{name: "usage", err: usageErrorf("invalid --top value %d", -1), want: exitUsage},
+		{name: "wrapped usage", err: fmt.Errorf("could not load: %w", usageErrorf("invalid")), want: exitUsage},
+		{name: "data", err: exitErrorf(exitData, "could not estimate emissions"), want: exitData},
+		{name: "missing file", err: exitErrorf(exitParse, "could not read utilization file: %w", pathErr), want: exitIO},
+		{name: "inner code", err: exitErrorf(exitData, "could not load datasets: %w", usageErrorf("invalid --region-pue value")), want: exitUsage},
+		{name: "budget", err: errBudgetExceeded, want: exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readErrorCode(t *testing.T) {
+	_, pathErr := os.Open("/nonexistent/report.csv")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "CSV", err: fmt.Errorf("could not read CSV: %w", &csv.ParseError{Line: 2, Err: csv.ErrQuote}), want: exitParse},
+		{name: "gzip", err: gzip.ErrHeader, want: exitParse},
+		{name: "line", err: parseErrorf("line %d: invalid usage amount", 3), want: exitParse},
+		{name: "missing file", err: pathErr, want: exitIO},
+		{name: "network", err: errors.New("connection reset by peer"), want: exitIO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readErrorCode(tt.err); got != tt.want {
+				t.Errorf("readErrorCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
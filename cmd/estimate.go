@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/spf13/cobra"
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate the emissions of running some instances",
+	Long: `Estimate the emissions of running some instances.
+
+Prints the energy and emissions of running --count instances of
+--instance-type in --region for --duration, without the need of a usage
+report. With --provider aws, RDS instance types like db.m5.large are
+supported, too.
+
+Example:
+
+  cloud-carbon estimate --region eu-west-1 --instance-type m5.2xlarge --count 12 --duration 720h
+`,
+	RunE: estimate,
+	Args: cobra.NoArgs,
+}
+
+var (
+	quickProvider     string
+	quickRegion       string
+	quickInstanceType string
+	quickCount        int
+	quickDuration     time.Duration
+	quickUtilization  float64
+	quickFallback     string
+	quickOutputFormat string
+)
+
+func init() {
+	estimateCmd.Flags().StringVar(&quickProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider of the instances, one of: %s", strings.Join(providers, ", ")))
+	estimateCmd.Flags().StringVar(&quickRegion, "region", "", "Region the instances run in")
+	estimateCmd.Flags().StringVar(&quickInstanceType, "instance-type", "", "Instance type, machine type or VM size of the instances")
+	estimateCmd.Flags().IntVar(&quickCount, "count", 1, "Number of instances")
+	estimateCmd.Flags().DurationVar(&quickDuration, "duration", hoursPerMonth*time.Hour, "Duration the instances run for")
+	estimateCmd.Flags().Float64Var(&quickUtilization, "utilization", footprint.DefaultUtilization, "Average CPU utilization of the instances in percent")
+	estimateCmd.Flags().StringVar(&quickFallback, "instance-fallback", footprint.FallbackFamily, fmt.Sprintf("How to estimate EC2 instance types missing from the dataset, one of: %s", strings.Join(footprint.FallbackMethods, ", ")))
+	estimateCmd.Flags().StringVarP(&quickOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	estimateCmd.MarkFlagRequired("region")
+	estimateCmd.MarkFlagRequired("instance-type")
+}
+
+// Estimate holds the footprint of running a number of instances for some
+// time.
+type Estimate struct {
+	Region        string  `json:"region"`
+	InstanceType  string  `json:"instance_type"`
+	Count         int     `json:"count"`
+	DurationHours float64 `json:"duration_hours"`
+	Utilization   float64 `json:"utilization"`
+
+	// EstimatedFrom describes how the footprint of an instance type
+	// missing from the dataset was estimated.
+	EstimatedFrom string `json:"estimated_from,omitempty"`
+
+	EnergyKiloWattHours float64 `json:"energy_kwh"`
+	OperationalGrams    float64 `json:"operational_grams"`
+	EmbodiedGrams       float64 `json:"embodied_grams"`
+	EmissionGrams       float64 `json:"emission_grams"`
+
+	// Scope2Grams and Scope3Grams split the emissions by GHG Protocol
+	// scope, into operational and embodied emissions.
+	Scope2Grams float64 `json:"scope2_grams"`
+	Scope3Grams float64 `json:"scope3_grams"`
+
+	// WaterLiters is the water consumed on site by the data center.
+	WaterLiters float64 `json:"water_liters"`
+}
+
+func estimate(cmd *cobra.Command, args []string) error {
+	if quickOutputFormat != outputTable && quickOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", quickOutputFormat, outputTable, outputJSON)
+	}
+	if quickCount < 1 {
+		return usageErrorf("invalid --count value %d, must be at least 1", quickCount)
+	}
+	if quickDuration <= 0 {
+		return usageErrorf("invalid --duration value %s, must be positive", quickDuration)
+	}
+	if quickUtilization < 0 || quickUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", quickUtilization)
+	}
+	if !footprint.IsFallbackMethod(quickFallback) {
+		return usageErrorf("invalid --instance-fallback value %q, must be one of: %s", quickFallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+
+	e, err := estimateInstances(quickProvider, quickRegion, quickInstanceType, quickCount, quickDuration, quickUtilization, quickFallback)
+	if err != nil {
+		return exitErrorf(exitData, "could not estimate emissions: %w", err)
+	}
+
+	switch quickOutputFormat {
+	case outputTable:
+		writeEstimate(os.Stdout, e)
+	case outputJSON:
+		err = writeJSON(os.Stdout, e)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// estimateInstances returns the footprint of running count instances of a
+// cloud provider for the given duration.
+func estimateInstances(provider, region, instanceType string, count int, duration time.Duration, utilization float64, fallback string) (Estimate, error) {
+	row := AggregateReportRow{
+		Region:       region,
+		InstanceType: instanceType,
+		Duration:     time.Duration(count) * duration,
+	}
+	switch provider {
+	case providerAWS:
+		row.Category = categoryEC2
+		if strings.HasPrefix(instanceType, "db.") {
+			row.Category = categoryRDS
+		}
+	case providerGCP:
+		row.Category = categoryGCE
+	case providerAzure:
+		row.Category = categoryAzureVM
+	default:
+		return Estimate{}, fmt.Errorf("invalid provider %q, must be one of: %s", provider, strings.Join(providers, ", "))
+	}
+
+	result, description, err := estimateWithFallback(row, utilization, fallback)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	return Estimate{
+		Region:              region,
+		InstanceType:        instanceType,
+		Count:               count,
+		DurationHours:       duration.Hours(),
+		Utilization:         utilization,
+		EstimatedFrom:       description,
+		EnergyKiloWattHours: result.EnergyKiloWattHours,
+		OperationalGrams:    result.OperationalGrams,
+		EmbodiedGrams:       result.EmbodiedGrams,
+		EmissionGrams:       result.Total(),
+		Scope2Grams:         result.OperationalGrams,
+		Scope3Grams:         result.EmbodiedGrams,
+		WaterLiters:         result.WaterLiters,
+	}, nil
+}
+
+// writeEstimate writes an estimate as text.
+func writeEstimate(w io.Writer, e Estimate) {
+	fmt.Fprintf(w, "%d × %s in %s for %g hours at %g%% CPU utilization:\n\n", e.Count, e.InstanceType, e.Region, e.DurationHours, e.Utilization)
+	fmt.Fprintf(w, "  Energy        %s\n", formatKiloWattHours(e.EnergyKiloWattHours))
+	fmt.Fprintf(w, "  Scope 2       %s (operational)\n", formatGrams(e.OperationalGrams))
+	fmt.Fprintf(w, "  Scope 3       %s (embodied)\n", formatGrams(e.EmbodiedGrams))
+	fmt.Fprintf(w, "  Emissions     %s\n", formatGrams(e.EmissionGrams))
+	fmt.Fprintf(w, "  Water         %s\n", formatLiters(e.WaterLiters))
+	if e.EstimatedFrom != "" {
+		fmt.Fprintf(w, "\nInstance type %s is missing from the dataset, estimate: %s.\n", e.InstanceType, e.EstimatedFrom)
+	}
+}
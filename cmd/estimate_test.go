@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_estimateInstances(t *testing.T) {
+	got, err := estimateInstances(providerAWS, "eu-west-1", "m5.2xlarge", 12, 720*time.Hour, 50, footprint.FallbackFamily)
+	if err != nil {
+		t.Fatalf("estimateInstances() error = %v", err)
+	}
+	want, _ := calculator.AWS("eu-west-1", "m5.2xlarge", 12*720*time.Hour)
+	if math.Abs(got.EmissionGrams-want.Total()) > 1e-6 {
+		t.Errorf("estimateInstances() emissions = %v, want %v", got.EmissionGrams, want.Total())
+	}
+	if got.Count != 12 || got.DurationHours != 720 || got.EstimatedFrom != "" {
+		t.Errorf("estimateInstances() = %+v", got)
+	}
+}
+
+func Test_estimateInstances_providers(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      string
+		region        string
+		instanceType  string
+		fallback      string
+		wantEstimated bool
+		wantErr       bool
+	}{
+		{name: "RDS", provider: providerAWS, region: "eu-west-1", instanceType: "db.m5.large", fallback: footprint.FallbackFamily},
+		{name: "GCP", provider: providerGCP, region: "europe-west1", instanceType: "n1-standard-4", fallback: footprint.FallbackFamily},
+		{name: "Azure", provider: providerAzure, region: "westeurope", instanceType: "Standard_D2s_v3", fallback: footprint.FallbackFamily},
+		{name: "estimated", provider: providerAWS, region: "eu-west-1", instanceType: "m5.32xlarge", fallback: footprint.FallbackFamily, wantEstimated: true},
+		{name: "no fallback", provider: providerAWS, region: "eu-west-1", instanceType: "m5.32xlarge", fallback: footprint.FallbackNone, wantErr: true},
+		{name: "unknown region", provider: providerAWS, region: "xx-west-1", instanceType: "m5.large", fallback: footprint.FallbackFamily, wantErr: true},
+		{name: "unknown provider", provider: "unknown", region: "eu-west-1", instanceType: "m5.large", fallback: footprint.FallbackFamily, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := estimateInstances(tt.provider, tt.region, tt.instanceType, 1, time.Hour, 50, tt.fallback)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("estimateInstances() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.EmissionGrams <= 0 {
+				t.Errorf("estimateInstances() emissions = %v, want positive", got.EmissionGrams)
+			}
+			if got.Scope2Grams != got.OperationalGrams || got.Scope3Grams != got.EmbodiedGrams {
+				t.Errorf("estimateInstances() scopes = %v, %v, want operational and embodied emissions", got.Scope2Grams, got.Scope3Grams)
+			}
+			if (got.EstimatedFrom != "") != tt.wantEstimated {
+				t.Errorf("estimateInstances() estimated from = %q, want estimated %v", got.EstimatedFrom, tt.wantEstimated)
+			}
+		})
+	}
+}
+
+func Test_writeEstimate(t *testing.T) {
+	e := Estimate{
+		Region:              "eu-west-1",
+		InstanceType:        "m5.32xlarge",
+		Count:               12,
+		DurationHours:       720,
+		Utilization:         50,
+		EstimatedFrom:       "scaled from m5.24xlarge",
+		EnergyKiloWattHours: 585.8,
+		OperationalGrams:    185100,
+		EmbodiedGrams:       33700,
+		EmissionGrams:       218800,
+	}
+
+	var buf bytes.Buffer
+	writeEstimate(&buf, e)
+
+	for _, want := range []string{"12 × m5.32xlarge in eu-west-1 for 720 hours", "585.8 kWh", "Scope 2       185.1 kgCO2e", "Scope 3       33.7 kgCO2e", "218.8 kgCO2e", "scaled from m5.24xlarge"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeEstimate() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
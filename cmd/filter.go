@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+// dateLayout is the format of dates without time accepted by --start and
+// --end.
+const dateLayout = "2006-01-02"
+
+// timeRangeFilter returns a filter including line items whose usage started
+// at or after start and before end. A zero start or end leaves the range
+// open on that side.
+func timeRangeFilter(start, end time.Time) pipeline.Filter {
+	return func(item cur.LineItem) bool {
+		if !start.IsZero() && item.UsageStartTime.Before(start) {
+			return false
+		}
+		if !end.IsZero() && !item.UsageStartTime.Before(end) {
+			return false
+		}
+		return true
+	}
+}
+
+// uncoveredUsageFilter includes line items of usage not covered by a
+// Reserved Instance or a Savings Plan.
+func uncoveredUsageFilter(item cur.LineItem) bool {
+	return item.PurchaseOption != purchaseReserved && item.PurchaseOption != purchaseSavingsPlan
+}
+
+// patternFilter returns a filter including line items for which value
+// returns a string matching one of the comma-separated glob patterns, e. g.
+// "eu-*,us-east-1".
+func patternFilter(patterns string, value func(item cur.LineItem) string) (pipeline.Filter, error) {
+	var list []string
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		list = append(list, p)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no patterns given")
+	}
+
+	return func(item cur.LineItem) bool {
+		v := value(item)
+		for _, p := range list {
+			if matched, _ := path.Match(p, v); matched {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// allFilters returns a filter including line items included by all of the
+// given filters, or nil if there are none.
+func allFilters(filters []pipeline.Filter) pipeline.Filter {
+	if len(filters) == 0 {
+		return nil
+	}
+	return func(item cur.LineItem) bool {
+		for _, f := range filters {
+			if !f(item) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// parseRangeTime parses the value of --start or --end, either a date or a
+// time in RFC 3339 format. Dates are taken as UTC. For the end of a range,
+// a date includes the whole day.
+func parseRangeTime(value string, isEnd bool) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		if isEnd {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date like %s nor a time like %s", value, dateLayout, time.RFC3339)
+	}
+	return t, nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+func Test_parseRangeTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		isEnd   bool
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2022-08-01", want: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2022-08-01", isEnd: true, want: time.Date(2022, 8, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "2022-08-01T02:00:00Z", isEnd: true, want: time.Date(2022, 8, 1, 2, 0, 0, 0, time.UTC)},
+		{value: "2022-08-01T02:00:00+02:00", want: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "01.08.2022", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseRangeTime(tt.value, tt.isEnd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRangeTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseRangeTime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_analyseSource_timeRange(t *testing.T) {
+	start := time.Date(2022, 8, 1, 2, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 8, 1, 4, 0, 0, 0, time.UTC)
+	options := summaryOptions{filters: []pipeline.Filter{timeRangeFilter(start, end)}}
+
+	all, err := analyseSource(context.Background(), localSource("testdata/replay-usage.csv"), analyseReport, testDimensions(t, defaultGroupBy), summaryOptions{})
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+	got, err := analyseSource(context.Background(), localSource("testdata/replay-usage.csv"), analyseReport, testDimensions(t, defaultGroupBy), options)
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+
+	if got.LineCount+got.SkippedCount != all.LineCount {
+		t.Errorf("analyseSource() LineCount %d + SkippedCount %d, want %d lines", got.LineCount, got.SkippedCount, all.LineCount)
+	}
+	if got.LineCount == 0 || got.SkippedCount == 0 {
+		t.Errorf("analyseSource() LineCount = %d, SkippedCount = %d, want both > 0", got.LineCount, got.SkippedCount)
+	}
+	if got.EarliestDate.Before(start) || got.LatestDate.After(end.Add(time.Hour)) {
+		t.Errorf("analyseSource() time range = %s - %s, want within %s - %s", got.EarliestDate, got.LatestDate, start, end)
+	}
+	if row := aggregateByKey(got)[defaultKey("eu-west-1", "t2.micro")]; row.Duration != 2*time.Hour {
+		t.Errorf("analyseSource() t2.micro duration = %s, want 2h", row.Duration)
+	}
+}
+
+func Test_patternFilter(t *testing.T) {
+	region := func(item cur.LineItem) string { return item.Region }
+
+	tests := []struct {
+		patterns string
+		region   string
+		want     bool
+		wantErr  bool
+	}{
+		{patterns: "eu-west-1", region: "eu-west-1", want: true},
+		{patterns: "eu-west-1", region: "eu-west-2", want: false},
+		{patterns: "us-east-1, eu-*", region: "eu-central-1", want: true},
+		{patterns: "us-*,ap-*", region: "eu-central-1", want: false},
+		{patterns: "eu-west-?", region: "eu-west-3", want: true},
+		{patterns: "eu-[", wantErr: true},
+		{patterns: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patterns, func(t *testing.T) {
+			filter, err := patternFilter(tt.patterns, region)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patternFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := filter(cur.LineItem{Region: tt.region}); got != tt.want {
+				t.Errorf("patternFilter()(%s) = %v, want %v", tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uncoveredUsageFilter(t *testing.T) {
+	for option, want := range map[string]bool{
+		purchaseOnDemand:    true,
+		purchaseSpot:        true,
+		purchaseReserved:    false,
+		purchaseSavingsPlan: false,
+		"":                  true,
+	} {
+		if got := uncoveredUsageFilter(cur.LineItem{PurchaseOption: option}); got != want {
+			t.Errorf("uncoveredUsageFilter(%q) = %v, want %v", option, got, want)
+		}
+	}
+}
+
+func Test_allFilters(t *testing.T) {
+	if allFilters(nil) != nil {
+		t.Error("allFilters(nil) != nil")
+	}
+
+	region, _ := patternFilter("eu-*", func(item cur.LineItem) string { return item.Region })
+	instanceType, _ := patternFilter("t3.*", func(item cur.LineItem) string { return item.InstanceType })
+	filter := allFilters([]pipeline.Filter{region, instanceType})
+
+	if !filter(cur.LineItem{Region: "eu-west-1", InstanceType: "t3.micro"}) {
+		t.Error("allFilters() excludes row matching all filters")
+	}
+	if filter(cur.LineItem{Region: "eu-west-1", InstanceType: "m5.large"}) {
+		t.Error("allFilters() includes row not matching all filters")
+	}
+}
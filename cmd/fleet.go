@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var estimateFleetCmd = &cobra.Command{
+	Use:   "estimate-fleet FILE",
+	Short: "Estimate the emissions of a planned fleet of instances",
+	Long: `Estimate the emissions of a planned fleet of instances.
+
+FILE is a YAML fleet spec listing pools of instances, each with a region,
+an instance type, a number of instances and the hours they run for. This
+allows estimating the footprint of new clusters or capacity changes before
+any usage report exists. Autoscaling can be simulated with several pools of
+the same instance type, e.g. a base pool running all month and a pool of the
+additional instances running during peak hours.
+
+Example spec:
+
+  provider: aws
+  hours: 730
+  pools:
+    - name: workers
+      region: eu-west-1
+      instance-type: m5.2xlarge
+      count: 12
+    - name: workers-peak
+      region: eu-west-1
+      instance-type: m5.2xlarge
+      count: 6
+      hours: 200
+
+The provider, hours and utilization set at the top apply to all pools not
+setting them. Hours default to a month of 730 hours.
+`,
+	RunE: estimateFleet,
+	Args: configArgs(cobra.ExactArgs(1)),
+}
+
+var (
+	fleetFallback     string
+	fleetOutputFormat string
+)
+
+func init() {
+	estimateFleetCmd.Flags().StringVar(&fleetFallback, "instance-fallback", footprint.FallbackFamily, fmt.Sprintf("How to estimate EC2 instance types missing from the dataset, one of: %s", strings.Join(footprint.FallbackMethods, ", ")))
+	estimateFleetCmd.Flags().StringVarP(&fleetOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+}
+
+// fleetSpec describes a planned fleet of instances. Provider, hours and
+// utilization are the defaults of pools not setting them.
+type fleetSpec struct {
+	Provider    string      `yaml:"provider"`
+	Hours       float64     `yaml:"hours"`
+	Utilization *float64    `yaml:"utilization"`
+	Pools       []fleetPool `yaml:"pools"`
+}
+
+// fleetPool is a number of instances of the same type running in a region
+// for some hours.
+type fleetPool struct {
+	Name         string   `yaml:"name"`
+	Provider     string   `yaml:"provider"`
+	Region       string   `yaml:"region"`
+	InstanceType string   `yaml:"instance-type"`
+	Count        int      `yaml:"count"`
+	Hours        float64  `yaml:"hours"`
+	Utilization  *float64 `yaml:"utilization"`
+}
+
+// FleetEstimate holds the footprint of a fleet of instances per pool, and
+// its total.
+type FleetEstimate struct {
+	Pools []PoolEstimate `json:"pools"`
+
+	EnergyKiloWattHours float64 `json:"energy_kwh"`
+	OperationalGrams    float64 `json:"operational_grams"`
+	EmbodiedGrams       float64 `json:"embodied_grams"`
+	EmissionGrams       float64 `json:"emission_grams"`
+	Scope2Grams         float64 `json:"scope2_grams"`
+	Scope3Grams         float64 `json:"scope3_grams"`
+	WaterLiters         float64 `json:"water_liters"`
+}
+
+// PoolEstimate holds the footprint of a pool of a fleet.
+type PoolEstimate struct {
+	Name string `json:"name"`
+	Estimate
+}
+
+func estimateFleet(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	if fleetOutputFormat != outputTable && fleetOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", fleetOutputFormat, outputTable, outputJSON)
+	}
+	if !footprint.IsFallbackMethod(fleetFallback) {
+		return usageErrorf("invalid --instance-fallback value %q, must be one of: %s", fleetFallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+
+	spec, err := readFleetSpec(args[0])
+	if err != nil {
+		return exitErrorf(readErrorCode(err), "could not read fleet spec: %w", err)
+	}
+
+	e, err := estimateFleetSpec(spec, fleetFallback)
+	if err != nil {
+		return exitErrorf(exitData, "could not estimate emissions: %w", err)
+	}
+
+	switch fleetOutputFormat {
+	case outputTable:
+		writeFleetEstimate(os.Stdout, e)
+	case outputJSON:
+		err = writeJSON(os.Stdout, e)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// readFleetSpec reads a fleet spec from a YAML file and validates it.
+// Unknown keys are rejected, to catch typos.
+func readFleetSpec(path string) (fleetSpec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fleetSpec{}, err
+	}
+	defer f.Close()
+
+	var spec fleetSpec
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil && err != io.EOF {
+		return fleetSpec{}, parseErrorf("could not parse %s: %w", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return fleetSpec{}, parseErrorf("invalid %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// validate checks the values of a fleet spec and sets the defaults of its
+// pools.
+func (s *fleetSpec) validate() error {
+	if len(s.Pools) == 0 {
+		return errors.New("no pools")
+	}
+	if s.Provider == "" {
+		s.Provider = providerAWS
+	}
+	if s.Hours == 0 {
+		s.Hours = hoursPerMonth
+	}
+	if s.Hours < 0 {
+		return fmt.Errorf("invalid hours %g, must be positive", s.Hours)
+	}
+	if s.Utilization == nil {
+		u := float64(footprint.DefaultUtilization)
+		s.Utilization = &u
+	}
+
+	for i := range s.Pools {
+		p := &s.Pools[i]
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("pool-%d", i+1)
+		}
+		if p.Provider == "" {
+			p.Provider = s.Provider
+		}
+		if p.Hours == 0 {
+			p.Hours = s.Hours
+		}
+		if p.Utilization == nil {
+			p.Utilization = s.Utilization
+		}
+
+		switch {
+		case p.Region == "" || p.InstanceType == "":
+			return fmt.Errorf("pool %s: region and instance-type are required", p.Name)
+		case p.Count < 1:
+			return fmt.Errorf("pool %s: invalid count %d, must be at least 1", p.Name, p.Count)
+		case p.Hours < 0:
+			return fmt.Errorf("pool %s: invalid hours %g, must be positive", p.Name, p.Hours)
+		case *p.Utilization < 0 || *p.Utilization > 100:
+			return fmt.Errorf("pool %s: invalid utilization %g, must be between 0 and 100", p.Name, *p.Utilization)
+		}
+	}
+	return nil
+}
+
+// estimateFleetSpec returns the footprint of each pool of a validated fleet
+// spec, and the total.
+func estimateFleetSpec(spec fleetSpec, fallback string) (FleetEstimate, error) {
+	var fleet FleetEstimate
+	for _, p := range spec.Pools {
+		duration := time.Duration(p.Hours * float64(time.Hour))
+		e, err := estimateInstances(p.Provider, p.Region, p.InstanceType, p.Count, duration, *p.Utilization, fallback)
+		if err != nil {
+			return FleetEstimate{}, fmt.Errorf("pool %s: %w", p.Name, err)
+		}
+
+		fleet.Pools = append(fleet.Pools, PoolEstimate{Name: p.Name, Estimate: e})
+		fleet.EnergyKiloWattHours += e.EnergyKiloWattHours
+		fleet.OperationalGrams += e.OperationalGrams
+		fleet.EmbodiedGrams += e.EmbodiedGrams
+		fleet.EmissionGrams += e.EmissionGrams
+		fleet.WaterLiters += e.WaterLiters
+	}
+	fleet.Scope2Grams = fleet.OperationalGrams
+	fleet.Scope3Grams = fleet.EmbodiedGrams
+	return fleet, nil
+}
+
+// writeFleetEstimate writes the footprint of a fleet as a table with a row
+// per pool.
+func writeFleetEstimate(w io.Writer, fleet FleetEstimate) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Pool", "Region", "Instance type", "Instances", "Hours", "Energy", "Scope 2", "Scope 3", "Emissions"})
+
+	var count int
+	var estimated []string
+	for _, p := range fleet.Pools {
+		count += p.Count
+		table.Append([]string{
+			p.Name,
+			p.Region,
+			p.InstanceType,
+			fmt.Sprintf("%d", p.Count),
+			fmt.Sprintf("%g", p.DurationHours),
+			formatKiloWattHours(p.EnergyKiloWattHours),
+			formatGrams(p.OperationalGrams),
+			formatGrams(p.EmbodiedGrams),
+			formatGrams(p.EmissionGrams),
+		})
+		if p.EstimatedFrom != "" {
+			estimated = append(estimated, fmt.Sprintf("  - %s: %s", p.InstanceType, p.EstimatedFrom))
+		}
+	}
+
+	table.SetFooter([]string{
+		"", "", "Total",
+		fmt.Sprintf("%d", count),
+		"",
+		formatKiloWattHours(fleet.EnergyKiloWattHours),
+		formatGrams(fleet.OperationalGrams),
+		formatGrams(fleet.EmbodiedGrams),
+		formatGrams(fleet.EmissionGrams),
+	})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+
+	fmt.Fprintf(w, "\nWater: %s\n", formatLiters(fleet.WaterLiters))
+	if len(estimated) > 0 {
+		fmt.Fprintf(w, "\nInstance types missing from the dataset, estimated:\n%s\n", strings.Join(estimated, "\n"))
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_readFleetSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "pools:\n  - region: eu-west-1\n    instance-type: m5.large\n    count: 2\n"},
+		{name: "no pools", content: "hours: 24\n", wantErr: "no pools"},
+		{name: "unknown key", content: "pools:\n  - region: eu-west-1\n    instance-typ: m5.large\n", wantErr: "field instance-typ not found"},
+		{name: "missing instance type", content: "pools:\n  - region: eu-west-1\n    count: 2\n", wantErr: "pool pool-1: region and instance-type are required"},
+		{name: "invalid count", content: "pools:\n  - name: web\n    region: eu-west-1\n    instance-type: m5.large\n", wantErr: "pool web: invalid count 0"},
+		{name: "invalid utilization", content: "utilization: 120\npools:\n  - region: eu-west-1\n    instance-type: m5.large\n    count: 1\n", wantErr: "invalid utilization 120"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fleet.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := readFleetSpec(path)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("readFleetSpec() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("readFleetSpec() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitParse {
+				t.Errorf("readFleetSpec() exit code = %d, want %d", exitCode(err), exitParse)
+			}
+		})
+	}
+}
+
+func Test_estimateFleetSpec(t *testing.T) {
+	utilization := 20.0
+	spec := fleetSpec{
+		Hours: 100,
+		Pools: []fleetPool{
+			{Name: "workers", Region: "eu-west-1", InstanceType: "m5.2xlarge", Count: 12},
+			{Region: "europe-west1", InstanceType: "n1-standard-4", Count: 2, Hours: 10, Provider: providerGCP, Utilization: &utilization},
+		},
+	}
+	if err := spec.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := estimateFleetSpec(spec, footprint.FallbackFamily)
+	if err != nil {
+		t.Fatalf("estimateFleetSpec() error = %v", err)
+	}
+	workers, _ := estimateInstances(providerAWS, "eu-west-1", "m5.2xlarge", 12, 100*time.Hour, footprint.DefaultUtilization, footprint.FallbackFamily)
+	gce, _ := estimateInstances(providerGCP, "europe-west1", "n1-standard-4", 2, 10*time.Hour, 20, footprint.FallbackFamily)
+	if len(got.Pools) != 2 || got.Pools[0].Estimate != workers || got.Pools[1].Estimate != gce || got.Pools[1].Name != "pool-2" {
+		t.Fatalf("estimateFleetSpec() pools = %+v", got.Pools)
+	}
+	if math.Abs(got.EmissionGrams-workers.EmissionGrams-gce.EmissionGrams) > 1e-6 || got.Scope3Grams != got.EmbodiedGrams {
+		t.Errorf("estimateFleetSpec() total = %+v", got)
+	}
+
+	spec.Pools[0].Region = "xx-west-1"
+	if _, err := estimateFleetSpec(spec, footprint.FallbackFamily); err == nil || !strings.HasPrefix(err.Error(), "pool workers: ") {
+		t.Errorf("estimateFleetSpec() with unknown region error = %v", err)
+	}
+}
+
+func Test_writeFleetEstimate(t *testing.T) {
+	fleet := FleetEstimate{
+		Pools: []PoolEstimate{{Name: "workers", Estimate: Estimate{Region: "eu-central-1", InstanceType: "m5.32xlarge", Count: 1, DurationHours: 730, EstimatedFrom: "scaled from m5.24xlarge"}}},
+	}
+	var buf bytes.Buffer
+	writeFleetEstimate(&buf, fleet)
+	if !strings.Contains(buf.String(), "workers") || !strings.Contains(buf.String(), "  - m5.32xlarge: scaled from m5.24xlarge") {
+		t.Errorf("writeFleetEstimate() wrote:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// categoryGCE is the usage category of Compute Engine VMs.
+const categoryGCE = "ComputeEngine"
+
+// Columns of a GCP billing export, flattened into CSV by the query shown in
+// the analyse command help.
+const (
+	gcpHeaderLocationRegion = "location_region"
+	gcpHeaderLocationZone   = "location_zone"
+	gcpHeaderMachineSpec    = "machine_spec"
+	gcpHeaderProjectID      = "project_id"
+	gcpHeaderService        = "service_description"
+	gcpHeaderSKU            = "sku_description"
+	gcpHeaderUsageAmount    = "usage_amount"
+	gcpHeaderUsageEndTime   = "usage_end_time"
+	gcpHeaderUsageStartTime = "usage_start_time"
+	gcpHeaderUsageUnit      = "usage_unit"
+
+	// gcpSKUCore is contained in the description of SKUs billing the vCPUs
+	// of VMs, e.g. "N1 Predefined Instance Core running in Americas".
+	gcpSKUCore = "Core running"
+
+	// gcpTimeLayout is the format of timestamps in CSV files exported from
+	// BigQuery.
+	gcpTimeLayout = "2006-01-02 15:04:05 MST"
+)
+
+// gcpBillingExportQuery creates CSV files readable by analyseGCPReport from
+// a BigQuery billing export table.
+const gcpBillingExportQuery = `SELECT
+  service.description AS service_description,
+  sku.description AS sku_description,
+  usage_start_time,
+  usage_end_time,
+  project.id AS project_id,
+  location.region AS location_region,
+  location.zone AS location_zone,
+  usage.amount AS usage_amount,
+  usage.unit AS usage_unit,
+  (SELECT value FROM UNNEST(system_labels)
+   WHERE key = "compute.googleapis.com/machine_spec") AS machine_spec
+FROM ` + "`PROJECT.DATASET.gcp_billing_export_v1_XXXXXX`" + `
+WHERE service.description = "Compute Engine"`
+
+// analyseGCPReport reads CSV data from a GCP billing export, optionally gzip
+// compressed, from r and adds the Compute Engine VM usage found to summary.
+func analyseGCPReport(r io.Reader, summary *ReportSummary) error {
+	csvFile, err := maybeDecompress(r)
+	if err != nil {
+		return fmt.Errorf("could not uncompress file: %w", err)
+	}
+	defer csvFile.Close()
+
+	fcsv := csv.NewReader(csvFile)
+	csvRecord, err := fcsv.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read CSV: %w", err)
+	}
+
+	if len(summary.tagKeys()) > 0 {
+		log.Printf("Warning: grouping by tags is not supported for GCP reports, all usage will be shown as %s.", untaggedLabel)
+	}
+
+	return summary.run(&gcpReader{csv: fcsv, headers: newReportHeaders(csvRecord), summary: summary})
+}
+
+// gcpReader provides the line items of the Compute Engine VM usage in a GCP
+// billing export. Lines with invalid dates are skipped if
+// summary.LenientDates is set.
+type gcpReader struct {
+	csv     *csv.Reader
+	headers reportHeaders
+	summary *ReportSummary
+}
+
+func (r *gcpReader) Next() (cur.LineItem, error) {
+	for {
+		csvRecord, err := r.csv.Read()
+		if err == io.EOF {
+			return cur.LineItem{}, io.EOF
+		}
+		if err != nil {
+			return cur.LineItem{}, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		// Filtering out everything that is not about the vCPUs of VMs. Each
+		// VM is billed with one SKU for its vCPUs, and one for its memory.
+		if r.headers.value(csvRecord, gcpHeaderService) != "Compute Engine" {
+			continue
+		}
+		if !strings.Contains(r.headers.value(csvRecord, gcpHeaderSKU), gcpSKUCore) {
+			continue
+		}
+		if r.headers.value(csvRecord, gcpHeaderMachineSpec) == "" {
+			continue
+		}
+
+		row, err := readGCPReportRow(r.headers, csvRecord)
+		if err != nil {
+			line, _ := r.csv.FieldPos(0)
+			if r.summary.skipInvalidDate(line, err) {
+				continue
+			}
+			return cur.LineItem{}, parseErrorf("line %d: %w", line, err)
+		}
+		return lineItem(row), nil
+	}
+}
+
+func readGCPReportRow(headers reportHeaders, fields []string) (ReportRow, error) {
+	r := ReportRow{
+		Category:         categoryGCE,
+		UsageAccountID:   headers.value(fields, gcpHeaderProjectID),
+		Region:           headers.value(fields, gcpHeaderLocationRegion),
+		AvailabilityZone: headers.value(fields, gcpHeaderLocationZone),
+		InstanceType:     headers.value(fields, gcpHeaderMachineSpec),
+	}
+
+	var err error
+	startTime := headers.value(fields, gcpHeaderUsageStartTime)
+	r.UsageStartTime, err = parseGCPTime(startTime)
+	if err != nil {
+		return r, &cur.DateError{Column: gcpHeaderUsageStartTime, Value: startTime}
+	}
+	endTime := headers.value(fields, gcpHeaderUsageEndTime)
+	r.UsageEndTime, err = parseGCPTime(endTime)
+	if err != nil {
+		return r, &cur.DateError{Column: gcpHeaderUsageEndTime, Value: endTime}
+	}
+
+	// The usage of core SKUs is given in vCPU-seconds. For machine types
+	// not in the dataset, the VM is assumed to run for the whole interval.
+	r.Duration = r.UsageEndTime.Sub(r.UsageStartTime)
+	if unit := headers.value(fields, gcpHeaderUsageUnit); unit != "seconds" {
+		return r, fmt.Errorf("unexpected usage unit %q for vCPU usage", unit)
+	}
+	vCPUSeconds, err := readUsageAmountColumn(headers, fields, gcpHeaderUsageAmount)
+	if err != nil {
+		return r, err
+	}
+	if vCPUs, err := calculator.GCPMachineTypeVCPUs(r.InstanceType); err == nil {
+		r.Duration = time.Duration(vCPUSeconds / vCPUs * float64(time.Second))
+	}
+
+	return r, nil
+}
+
+// parseGCPTime parses a timestamp from a GCP billing export, as written by
+// BigQuery, e.g. "2022-08-01 07:00:00 UTC", or in RFC 3339 format.
+func parseGCPTime(s string) (time.Time, error) {
+	t, err := time.Parse(gcpTimeLayout, s)
+	if err == nil {
+		return t.UTC(), nil
+	}
+	t, err = time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing time %q", s)
+	}
+	return t.UTC(), nil
+}
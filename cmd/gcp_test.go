@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testGCPReport = `service_description,sku_description,usage_start_time,usage_end_time,project_id,location_region,location_zone,usage_amount,usage_unit,machine_spec
+Compute Engine,N1 Predefined Instance Core running in Belgium,2022-08-01 00:00:00 UTC,2022-08-01 01:00:00 UTC,my-project,europe-west1,europe-west1-b,14400,seconds,n1-standard-4
+Compute Engine,N1 Predefined Instance Ram running in Belgium,2022-08-01 00:00:00 UTC,2022-08-01 01:00:00 UTC,my-project,europe-west1,europe-west1-b,216000,byte-seconds,n1-standard-4
+Compute Engine,N1 Predefined Instance Core running in Belgium,2022-08-01 01:00:00 UTC,2022-08-01 02:00:00 UTC,my-project,europe-west1,europe-west1-b,7200,seconds,n1-standard-4
+Compute Engine,Custom Instance Core running in Americas,2022-08-01 00:00:00 UTC,2022-08-01 01:00:00 UTC,other-project,us-central1,us-central1-a,7200,seconds,n1-custom-2-4096
+Compute Engine,Storage PD Capacity in Belgium,2022-08-01 00:00:00 UTC,2022-08-01 01:00:00 UTC,my-project,europe-west1,,100,byte-seconds,
+Cloud Storage,Standard Storage Europe Multi-region,2022-08-01 00:00:00 UTC,2022-08-01 01:00:00 UTC,my-project,europe-west1,,100,byte-seconds,
+`
+
+func Test_analyseGCPReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gcp.csv")
+	err := os.WriteFile(path, []byte(testGCPReport), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := analyseSource(context.Background(), localSource(path), analyseGCPReport, testDimensions(t, "category,account,instance-type"), summaryOptions{})
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+
+	if summary.LineCount != 3 {
+		t.Errorf("analyseSource() LineCount = %d, want 3", summary.LineCount)
+	}
+
+	want := map[string]time.Duration{
+		// 4 vCPUs for 1 hour, then 4 vCPUs for half an hour
+		"my-project n1-standard-4": 90 * time.Minute,
+		// Unknown machine type, running for the whole interval
+		"other-project n1-custom-2-4096": time.Hour,
+	}
+	rows, _ := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+	if len(summary.Aggregate) != len(want) {
+		t.Fatalf("analyseSource() got %d aggregate rows, want %d", len(summary.Aggregate), len(want))
+	}
+	for _, row := range summary.Aggregate {
+		key := row.Labels[1] + " " + row.Labels[2]
+		if row.Category != categoryGCE {
+			t.Errorf("analyseSource() row %s category = %s, want %s", key, row.Category, categoryGCE)
+		}
+		if row.Duration != want[key] {
+			t.Errorf("analyseSource() row %s duration = %s, want %s", key, row.Duration, want[key])
+		}
+	}
+
+	// Emissions can only be estimated for the known machine type.
+	if len(rows) != 1 || rows[0].EmissionGrams <= 0 {
+		t.Errorf("computeEmissions() = %+v, want one row with emissions", rows)
+	}
+}
+
+func Test_parseGCPTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{name: "BigQuery CSV", s: "2022-08-01 07:00:00 UTC", want: "2022-08-01T07:00:00Z"},
+		{name: "RFC 3339 with offset", s: "2022-08-01T00:00:00-07:00", want: "2022-08-01T07:00:00Z"},
+		{name: "invalid", s: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGCPTime(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGCPTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(mustParseDate(tt.want)) {
+				t.Errorf("parseGCPTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
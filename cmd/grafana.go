@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// grafanaMetrics holds the values of series points that can be queried by
+// Grafana, by metric name.
+var grafanaMetrics = map[string]func(p SeriesPoint) float64{
+	"emission_grams":    func(p SeriesPoint) float64 { return p.EmissionGrams },
+	"operational_grams": func(p SeriesPoint) float64 { return p.OperationalGrams },
+	"embodied_grams":    func(p SeriesPoint) float64 { return p.EmbodiedGrams },
+	"energy_kwh":        func(p SeriesPoint) float64 { return p.EnergyKiloWattHours },
+	"water_liters":      func(p SeriesPoint) float64 { return p.WaterLiters },
+	"cost":              func(p SeriesPoint) float64 { return p.Cost },
+	"vcpu_hours":        func(p SeriesPoint) float64 { return p.VCPUHours },
+}
+
+// grafanaSearchDimensions are the dimensions suggested for grouping when
+// Grafana searches for targets. All serveDimensions can be queried.
+var grafanaSearchDimensions = []string{"region", "instance-type", accountDimension}
+
+// grafanaQuery is a query of the Grafana JSON datasource.
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs int64 `json:"intervalMs"`
+	Targets    []struct {
+		Target string `json:"target"`
+		Type   string `json:"type"`
+		Hide   bool   `json:"hide"`
+	} `json:"targets"`
+}
+
+// grafanaSeries is a time series of a query response, with data points of
+// a value and a Unix time in milliseconds.
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// grafanaTable is a table of a query response.
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// registerGrafana registers the endpoints of the JSON datasource contract
+// of Grafana below /grafana/, so that the emissions can be queried with the
+// simple JSON datasource plugins.
+func (s *emissionsServer) registerGrafana(mux *http.ServeMux) {
+	mux.HandleFunc("GET /grafana/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "OK")
+	})
+	mux.HandleFunc("POST /grafana/search", s.handleGrafanaSearch)
+	mux.HandleFunc("POST /grafana/query", s.handleGrafanaQuery)
+	mux.HandleFunc("POST /grafana/annotations", func(w http.ResponseWriter, r *http.Request) {
+		writeGrafanaResponse(w, []any{})
+	})
+}
+
+// handleGrafanaSearch lists the targets containing the requested text,
+// i.e. each metric alone and grouped by grafanaSearchDimensions.
+func (s *emissionsServer) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var search struct {
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&search); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("invalid search: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	targets := []string{}
+	for _, metric := range grafanaMetricNames() {
+		for _, target := range append([]string{metric}, groupedTargets(metric)...) {
+			if strings.Contains(target, search.Target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	writeGrafanaResponse(w, targets)
+}
+
+// groupedTargets returns the targets of a metric grouped by each of
+// grafanaSearchDimensions.
+func groupedTargets(metric string) []string {
+	var targets []string
+	for _, d := range grafanaSearchDimensions {
+		targets = append(targets, metric+" by "+d)
+	}
+	return targets
+}
+
+// grafanaMetricNames returns the names of grafanaMetrics in alphabetical
+// order.
+func grafanaMetricNames() []string {
+	var names []string
+	for name := range grafanaMetrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// handleGrafanaQuery answers a query with a time series per group for
+// targets of type timeserie, and a table of the totals per group for
+// targets of type table.
+func (s *emissionsServer) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var query grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, fmt.Sprintf("invalid query: %s", err), http.StatusBadRequest)
+		return
+	}
+	period := grafanaPeriod(time.Duration(query.IntervalMs) * time.Millisecond)
+
+	response := []any{}
+	for _, target := range query.Targets {
+		if target.Hide || target.Target == "" {
+			continue
+		}
+		metric, dimensions, indexes, err := parseGrafanaTarget(target.Target)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid target %q: %s", target.Target, err), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.RLock()
+		rows := selectRows(s.rows, indexes, query.Range.From, query.Range.To)
+		s.mu.RUnlock()
+
+		if target.Type == "table" {
+			response = append(response, grafanaTotals(metric, dimensions, timeSeries(dimensions, rows, nil)))
+			continue
+		}
+		for _, series := range grafanaTimeSeries(metric, dimensions, timeSeries(dimensions, rows, period)) {
+			response = append(response, series)
+		}
+	}
+	writeGrafanaResponse(w, response)
+}
+
+// parseGrafanaTarget parses a target like "emission_grams by region", with
+// an optional comma-separated list of dimensions to group by.
+func parseGrafanaTarget(target string) (string, []Dimension, []int, error) {
+	metric, groupBy, _ := strings.Cut(target, " by ")
+	metric = strings.TrimSpace(metric)
+	if _, exists := grafanaMetrics[metric]; !exists {
+		return "", nil, nil, fmt.Errorf("unknown metric %q, must be one of: %s", metric, strings.Join(grafanaMetricNames(), ", "))
+	}
+	if groupBy == "" {
+		return metric, nil, nil, nil
+	}
+
+	dimensions, err := parseGroupBy(strings.ReplaceAll(groupBy, " ", ""))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	indexes, err := dimensionIndexes(dimensions)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return metric, dimensions, indexes, nil
+}
+
+// grafanaPeriod returns the longest period not exceeding the interval
+// between the data points of a Grafana panel, so that panels of long time
+// ranges don't get more points than they can show.
+func grafanaPeriod(interval time.Duration) periodFunc {
+	switch {
+	case interval < 24*time.Hour:
+		return hourPeriod
+	case interval < 28*24*time.Hour:
+		return dayPeriod
+	}
+	return monthPeriod
+}
+
+// grafanaTimeSeries returns a series of a metric for each group of points,
+// named after the labels of the group. Points must be sorted by period, as
+// returned by timeSeries.
+func grafanaTimeSeries(metric string, dimensions []Dimension, points []SeriesPoint) []grafanaSeries {
+	value := grafanaMetrics[metric]
+	byName := make(map[string]*grafanaSeries)
+	var names []string
+	for _, p := range points {
+		name := grafanaSeriesName(metric, dimensions, p)
+		series, exists := byName[name]
+		if !exists {
+			series = &grafanaSeries{Target: name, Datapoints: [][2]float64{}}
+			byName[name] = series
+			names = append(names, name)
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{value(p), float64(p.Period.UnixMilli())})
+	}
+
+	slices.Sort(names)
+	result := make([]grafanaSeries, 0, len(names))
+	for _, name := range names {
+		result = append(result, *byName[name])
+	}
+	return result
+}
+
+// grafanaSeriesName returns the name of the series of a group of points,
+// the metric for ungrouped points, or else the labels of the group.
+func grafanaSeriesName(metric string, dimensions []Dimension, p SeriesPoint) string {
+	if len(dimensions) == 0 {
+		return metric
+	}
+	labels := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		labels[i] = p.Labels[d.Name]
+	}
+	return strings.Join(labels, ", ")
+}
+
+// grafanaTotals returns a table of the totals of a metric per group, with a
+// column for each dimension.
+func grafanaTotals(metric string, dimensions []Dimension, points []SeriesPoint) grafanaTable {
+	table := grafanaTable{Type: "table", Rows: [][]any{}}
+	for _, d := range dimensions {
+		table.Columns = append(table.Columns, grafanaColumn{Text: d.Header, Type: "string"})
+	}
+	table.Columns = append(table.Columns, grafanaColumn{Text: metric, Type: "number"})
+
+	value := grafanaMetrics[metric]
+	for _, p := range points {
+		var row []any
+		for _, d := range dimensions {
+			row = append(row, p.Labels[d.Name])
+		}
+		table.Rows = append(table.Rows, append(row, value(p)))
+	}
+	return table
+}
+
+func writeGrafanaResponse(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Could not write response: %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_emissionsServer_grafana(t *testing.T) {
+	rows, err := loadEmissions(context.Background(), []string{"testdata/replay-usage.csv"}, analyseReport)
+	if err != nil {
+		t.Fatalf("loadEmissions() error = %v", err)
+	}
+	server := &emissionsServer{}
+	server.set(rows)
+	var total float64
+	for _, row := range rows {
+		total += row.EmissionGrams
+	}
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := request(http.MethodGet, "/grafana/", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /grafana/ = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var targets []string
+	rec := request(http.MethodPost, "/grafana/search", `{"target": "water"}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &targets); err != nil {
+		t.Fatalf("invalid search response: %v", err)
+	}
+	if want := "water_liters,water_liters by region,water_liters by instance-type,water_liters by account"; strings.Join(targets, ",") != want {
+		t.Errorf("search = %v, want %s", targets, want)
+	}
+
+	rec = request(http.MethodPost, "/grafana/query", `{
+		"range": {"from": "2022-08-01T00:00:00Z", "to": "2022-08-02T00:00:00Z"},
+		"intervalMs": 3600000,
+		"targets": [
+			{"target": "emission_grams by region", "type": "timeserie"},
+			{"target": "emission_grams", "type": "timeserie"},
+			{"target": "energy_kwh by region, instance-type", "type": "table"},
+			{"target": "cost", "hide": true}
+		]
+	}`)
+	var response []struct {
+		Target     string       `json:"target"`
+		Datapoints [][2]float64 `json:"datapoints"`
+		Type       string       `json:"type"`
+		Columns    []grafanaColumn
+		Rows       [][]any
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid query response: %v: %s", err, rec.Body.String())
+	}
+	if len(response) != 6 {
+		t.Fatalf("query returned %d series and tables, want 4 regions, the total and a table", len(response))
+	}
+	var regionTotal float64
+	for _, series := range response[:4] {
+		for _, point := range series.Datapoints {
+			regionTotal += point[0]
+		}
+	}
+	if response[0].Target != "ap-southeast-2" || math.Abs(regionTotal-total) > 1e-6 {
+		t.Errorf("query returned series %q first with a total of %g, want ap-southeast-2 and %g", response[0].Target, regionTotal, total)
+	}
+	if total := response[4]; total.Target != "emission_grams" || len(total.Datapoints) != 6 || total.Datapoints[1][1]-total.Datapoints[0][1] != float64(time.Hour.Milliseconds()) {
+		t.Errorf("query returned total series %q with %d points, want emission_grams hourly", total.Target, len(total.Datapoints))
+	}
+	if table := response[5]; table.Type != "table" || len(table.Columns) != 3 || table.Columns[2].Text != "energy_kwh" || len(table.Rows) == 0 {
+		t.Errorf("query returned table %+v", table)
+	}
+
+	for _, target := range []string{"carbon", "emission_grams by color", "emission_grams by tag:team"} {
+		rec := request(http.MethodPost, "/grafana/query", `{"targets": [{"target": "`+target+`"}]}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query of %q = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func Test_grafanaPeriod(t *testing.T) {
+	start := time.Date(2022, 8, 17, 13, 30, 0, 0, time.UTC)
+	tests := []struct {
+		interval time.Duration
+		want     time.Time
+	}{
+		{0, time.Date(2022, 8, 17, 13, 0, 0, 0, time.UTC)},
+		{12 * time.Hour, time.Date(2022, 8, 17, 13, 0, 0, 0, time.UTC)},
+		{24 * time.Hour, time.Date(2022, 8, 17, 0, 0, 0, 0, time.UTC)},
+		{30 * 24 * time.Hour, time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := grafanaPeriod(tt.interval)(start); !got.Equal(tt.want) {
+			t.Errorf("grafanaPeriod(%s) = %s, want %s", tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+const (
+	defaultGroupBy = "category,region,instance-type"
+
+	// tagDimensionPrefix is the prefix of group-by dimensions referring to
+	// a cost allocation tag, followed by the tag key.
+	tagDimensionPrefix = "tag:"
+
+	// untaggedLabel is shown for usage without a value for a tag dimension.
+	untaggedLabel = "(untagged)"
+
+	// accountDimension is the name of the dimension grouping by account.
+	accountDimension = "account"
+)
+
+// Dimension is a property of report rows that usage can be grouped by.
+type Dimension struct {
+	// Name identifies the dimension in the --group-by flag.
+	Name string
+
+	// Header is the column header used in output.
+	Header string
+
+	// Description describes the dimension in the help of the analyse
+	// command.
+	Description string
+
+	// Value returns the dimension's value for a report row.
+	Value func(r ReportRow) string
+
+	// TagKey is the key of the cost allocation tag the dimension refers to.
+	// Empty for dimensions not based on tags.
+	TagKey string
+
+	// Redacted is set for dimensions whose values may reveal the internal
+	// structure of an organization, like account IDs and tag values. They
+	// are replaced with hashes by --redact.
+	Redacted bool
+}
+
+// availableDimensions are the dimensions available for grouping, except for tags.
+var availableDimensions = []Dimension{
+	{
+		Name:        "category",
+		Header:      "Category",
+		Description: "usage category, EC2, EBS, Network, S3, RDS, Lambda or Fargate",
+		Value:       func(r ReportRow) string { return r.Category },
+	},
+	{
+		Name:        "region",
+		Header:      "Region",
+		Description: "AWS region code",
+		Value:       func(r ReportRow) string { return r.Region },
+	},
+	{
+		Name:        "instance-type",
+		Header:      "Instance type",
+		Description: "EC2 or RDS instance type",
+		Value:       func(r ReportRow) string { return r.InstanceType },
+	},
+	{
+		Name:        "instance-family",
+		Header:      "Instance family",
+		Description: "family of the instance type, e.g. m5 for m5.xlarge and db.m5.xlarge, e2 for e2-standard-4 or Dsv3 for Standard_D2s_v3",
+		Value:       func(r ReportRow) string { return instanceFamily(r.Category, r.InstanceType) },
+	},
+	{
+		Name:        "storage-type",
+		Header:      "Storage type",
+		Description: "EBS volume type or S3 storage class",
+		Value:       func(r ReportRow) string { return r.StorageType },
+	},
+	{
+		Name:        accountDimension,
+		Header:      "Account",
+		Description: "ID of the AWS account the usage belongs to, or its name with --account-names",
+		Value:       func(r ReportRow) string { return r.UsageAccountID },
+		Redacted:    true,
+	},
+	{
+		Name:        "availability-zone",
+		Header:      "Availability zone",
+		Description: "availability zone of the usage, empty for usage not bound to a zone",
+		Value:       func(r ReportRow) string { return r.AvailabilityZone },
+	},
+	{
+		Name:        "purchase-option",
+		Header:      "Purchase option",
+		Description: "how the usage was paid for, On-Demand, Spot, Reserved for usage covered by Reserved Instances or Savings Plan",
+		Value:       func(r ReportRow) string { return r.PurchaseOption },
+	},
+	{
+		Name:        clusterDimension,
+		Header:      "Cluster",
+		Description: "Kubernetes cluster of the EC2 instance, from --node-mapping, --opencost-allocation or the tags of EKS nodes",
+		Value:       func(r ReportRow) string { return attributionLabel(r.Cluster) },
+		Redacted:    true,
+	},
+	{
+		Name:        namespaceDimension,
+		Header:      "Namespace",
+		Description: "Kubernetes namespace the EC2 instance is attributed to by the mapping file given with --node-mapping or --opencost-allocation, (unattributed) without one",
+		Value:       func(r ReportRow) string { return attributionLabel(r.Namespace) },
+		Redacted:    true,
+	},
+	{
+		Name:        nodeGroupDimension,
+		Header:      "Node group",
+		Description: "EKS managed node group of the EC2 instance, from the tags of EKS nodes",
+		Value:       func(r ReportRow) string { return attributionLabel(r.NodeGroup) },
+		Redacted:    true,
+	},
+}
+
+// dimensionAliases maps short names accepted in --group-by to dimension names.
+var dimensionAliases = map[string]string{
+	"az": "availability-zone",
+}
+
+// instanceFamily returns the family of an instance type or VM size, e.g.
+// "m5" for "m5.xlarge" or "db.m5.xlarge", "e2" for the GCP machine type
+// "e2-standard-4" and "Dsv3" for the Azure VM size "Standard_D2s_v3".
+func instanceFamily(category, instanceType string) string {
+	switch category {
+	case categoryGCE:
+		family, _, _ := strings.Cut(instanceType, "-")
+		return family
+	case categoryAzureVM:
+		parts := strings.Split(strings.TrimPrefix(instanceType, "Standard_"), "_")
+		parts[0] = strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) || r == '-' {
+				return -1
+			}
+			return r
+		}, parts[0])
+		return strings.Join(parts, "")
+	}
+	family, _, _ := strings.Cut(strings.TrimPrefix(instanceType, "db."), ".")
+	return family
+}
+
+// dimensionNames returns the names of the available dimensions, followed by
+// those registered with the pipeline package.
+func dimensionNames() []string {
+	var names []string
+	for _, d := range availableDimensions {
+		names = append(names, d.Name)
+	}
+	return append(names, pipeline.DimensionNames()...)
+}
+
+// dimensionsHelp lists the dimensions in the help of the analyse command.
+func dimensionsHelp() string {
+	var items []helpItem
+	for _, d := range availableDimensions {
+		name := d.Name
+		for alias, target := range dimensionAliases {
+			if target == d.Name {
+				name += ", or short " + alias
+			}
+		}
+		items = append(items, helpItem{name: name, description: d.Description})
+	}
+	items = append(items, helpItem{
+		name:        tagDimensionPrefix + "KEY",
+		description: "value of the cost allocation tag KEY, a user-defined tag unless KEY starts with aws:",
+	})
+	for _, name := range pipeline.DimensionNames() {
+		if slices.ContainsFunc(availableDimensions, func(d Dimension) bool { return d.Name == name }) {
+			continue
+		}
+		items = append(items, helpItem{name: name, description: "registered by an extension"})
+	}
+	return helpList(items)
+}
+
+// tagDimension returns a dimension grouping by the value of the cost
+// allocation tag with the given key. See cur.TagColumn for the key format.
+func tagDimension(key string) Dimension {
+	return Dimension{
+		Name:   tagDimensionPrefix + key,
+		Header: key,
+		Value: func(r ReportRow) string {
+			value, exists := r.Tags[key]
+			if !exists {
+				return untaggedLabel
+			}
+			return value
+		},
+		TagKey:   key,
+		Redacted: true,
+	}
+}
+
+// parseGroupBy parses a comma-separated list of dimension names.
+func parseGroupBy(s string) ([]Dimension, error) {
+	var result []Dimension
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if alias, exists := dimensionAliases[name]; exists {
+			name = alias
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("dimension %q given more than once", name)
+		}
+		seen[name] = true
+
+		if strings.HasPrefix(name, tagDimensionPrefix) {
+			key := strings.TrimPrefix(name, tagDimensionPrefix)
+			if key == "" {
+				return nil, fmt.Errorf("dimension %q is missing the tag key", name)
+			}
+			result = append(result, tagDimension(key))
+			continue
+		}
+
+		d, err := lookupDimension(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no dimensions given")
+	}
+
+	return result, nil
+}
+
+// hasDimension returns whether the dimension with the given name is among
+// dimensions.
+func hasDimension(dimensions []Dimension, name string) bool {
+	for _, d := range dimensions {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupDimension(name string) (Dimension, error) {
+	for _, d := range availableDimensions {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	if d, exists := registeredDimension(name); exists {
+		return d, nil
+	}
+	return Dimension{}, fmt.Errorf("unknown dimension %q, must be one of: %s, %s<key>", name, strings.Join(dimensionNames(), ", "), tagDimensionPrefix)
+}
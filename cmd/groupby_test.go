@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseGroupBy(t *testing.T) {
+	tests := []struct {
+		groupBy     string
+		wantHeaders []string
+		wantErr     bool
+	}{
+		{groupBy: "region,instance-type", wantHeaders: []string{"Region", "Instance type"}},
+		{groupBy: " account , tag:giantswarm.io/cluster", wantHeaders: []string{"Account", "giantswarm.io/cluster"}},
+		{groupBy: "availability-zone", wantHeaders: []string{"Availability zone"}},
+		{groupBy: "region,az", wantHeaders: []string{"Region", "Availability zone"}},
+		{groupBy: "az,availability-zone", wantErr: true},
+		{groupBy: "tag:aws:createdBy,tag:user:team", wantHeaders: []string{"aws:createdBy", "user:team"}},
+		{groupBy: "region,region", wantErr: true},
+		{groupBy: "tag:", wantErr: true},
+		{groupBy: "colour", wantErr: true},
+		{groupBy: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			got, err := parseGroupBy(tt.groupBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGroupBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var headers []string
+			for _, d := range got {
+				headers = append(headers, d.Header)
+			}
+			if strings.Join(headers, ",") != strings.Join(tt.wantHeaders, ",") {
+				t.Errorf("parseGroupBy() headers = %v, want %v", headers, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func Test_groupRows(t *testing.T) {
+	summary := newReportSummary(testDimensions(t, "account,tag:team"))
+
+	records := [][]string{
+		testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T00:00:00Z"),
+		testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T01:00:00Z"),
+		testUsageRecord("eu-central-1", "m5.xlarge", "2022-08-01T00:00:00Z"),
+	}
+	untagged := testUsageRecord("eu-west-1", "t2.micro", "2022-08-01T00:00:00Z")
+	untagged[len(untagged)-1] = ""
+	records = append(records, untagged)
+
+	for _, record := range records {
+		summary.add(testReportRow(record))
+	}
+
+	// Emissions are estimated per region and instance type, so the
+	// aggregate keeps them apart within the same group.
+	if len(summary.Aggregate) != 3 {
+		t.Fatalf("got %d aggregate rows, want 3", len(summary.Aggregate))
+	}
+
+	rows, total := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+	got := groupRows(rows, nil)
+
+	want := []AggregateReportRow{
+		{Labels: []string{"222222222222", untaggedLabel}, Region: "eu-west-1", InstanceType: "t2.micro", Duration: time.Hour},
+		{Labels: []string{"222222222222", "platform"}, Duration: 3 * time.Hour},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("groupRows() returned %d rows, want %d", len(got), len(want))
+	}
+
+	var sum float64
+	for i := range want {
+		if !equalLabels(got[i].Labels, want[i].Labels) || got[i].Region != want[i].Region || got[i].InstanceType != want[i].InstanceType || got[i].Duration != want[i].Duration {
+			t.Errorf("groupRows()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+		sum += got[i].EmissionGrams
+	}
+	if !withinTolerance(sum, total.Total(), 1e-9) {
+		t.Errorf("groupRows() emissions sum up to %v, want total %v", sum, total.Total())
+	}
+}
+
+func Test_tagDimension(t *testing.T) {
+	d := tagDimension("env")
+
+	if got := d.Value(ReportRow{Tags: map[string]string{"env": "production"}}); got != "production" {
+		t.Errorf("tag dimension value = %q, want production", got)
+	}
+	if got := d.Value(ReportRow{Tags: map[string]string{"team": "platform"}}); got != untaggedLabel {
+		t.Errorf("tag dimension value for missing tag = %q, want %q", got, untaggedLabel)
+	}
+}
+
+func Test_instanceFamily(t *testing.T) {
+	tests := []struct {
+		category     string
+		instanceType string
+		want         string
+	}{
+		{categoryEC2, "m5.xlarge", "m5"},
+		{categoryEC2, "c6g.large", "c6g"},
+		{categoryRDS, "db.r6i.2xlarge", "r6i"},
+		{categoryEBS, "", ""},
+		{categoryGCE, "e2-standard-4", "e2"},
+		{categoryGCE, "n2d-highmem-8", "n2d"},
+		{categoryAzureVM, "Standard_D2s_v3", "Dsv3"},
+		{categoryAzureVM, "Standard_E64-32s_v3", "Esv3"},
+		{categoryAzureVM, "Standard_B1ms", "Bms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.instanceType, func(t *testing.T) {
+			if got := instanceFamily(tt.category, tt.instanceType); got != tt.want {
+				t.Errorf("instanceFamily(%q, %q) = %q, want %q", tt.category, tt.instanceType, got, tt.want)
+			}
+		})
+	}
+}
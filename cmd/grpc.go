@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers gzip compression.
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	footprintv1 "github.com/giantswarm/cloud-carbon/proto/cloudcarbon/footprint/v1"
+)
+
+var grpcServerCmd = &cobra.Command{
+	Use:   "grpc-server",
+	Short: "Serve footprint estimates via gRPC",
+	Long: `Serve footprint estimates via gRPC.
+
+Exposes the footprint calculator to other services, so that they get
+estimates consistent with the other commands without embedding the datasets.
+The service cloudcarbon.footprint.v1.FootprintService is defined in
+proto/cloudcarbon/footprint/v1/footprint.proto, with the methods
+
+  EstimateInstance  Footprint of running instances, as for estimate.
+  EstimateEnergy    Footprint of consuming IT energy in a region.
+  GetRegion         Carbon intensity, PUE and WUE of a region.
+
+The server speaks gRPC without TLS, so TLS should be terminated by a proxy
+or service mesh in front of it. It supports gzip compression, and serves the
+gRPC health checking protocol and server reflection. The datasets and
+overrides given by the global flags, like --regions-csv and --pue, apply to
+all estimates.
+
+Example:
+
+  cloud-carbon grpc-server --listen :9090
+`,
+	RunE: grpcServer,
+	Args: cobra.NoArgs,
+}
+
+var grpcAddress string
+
+func init() {
+	grpcServerCmd.Flags().StringVar(&grpcAddress, "listen", ":9090", "Address to listen on")
+}
+
+func grpcServer(cmd *cobra.Command, args []string) error {
+	listener, err := net.Listen("tcp", grpcAddress)
+	if err != nil {
+		return exitErrorf(exitIO, "could not listen on %s: %w", grpcAddress, err)
+	}
+
+	log.Printf("Serving %s on %s", footprintv1.FootprintService_ServiceDesc.ServiceName, grpcAddress)
+	err = newGRPCServer().Serve(listener)
+	return exitErrorf(exitIO, "could not serve on %s: %w", grpcAddress, err)
+}
+
+// newGRPCServer returns a gRPC server of the footprint service, the health
+// checking service and server reflection.
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcStatusInterceptor))
+	footprintv1.RegisterFootprintServiceServer(server, footprintService{})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(footprintv1.FootprintService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
+
+// grpcStatusInterceptor turns the errors of the footprint service into gRPC
+// status errors: unknown regions and instance types are not found, other
+// errors of the calculator are internal.
+func grpcStatusInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	if errors.Is(err, footprint.ErrUnknownRegion) || errors.Is(err, footprint.ErrUnknownInstanceType) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return nil, status.Error(codes.Internal, err.Error())
+}
+
+// footprintService implements the footprint service of the published proto.
+type footprintService struct {
+	footprintv1.UnimplementedFootprintServiceServer
+}
+
+// grpcProvider returns the provider of a request, defaulting to AWS.
+func grpcProvider(provider string) (string, error) {
+	if provider == "" {
+		return providerAWS, nil
+	}
+	if !slices.Contains(providers, provider) {
+		return "", status.Errorf(codes.InvalidArgument, "invalid provider %q, must be one of: %s", provider, strings.Join(providers, ", "))
+	}
+	return provider, nil
+}
+
+// footprintMessage returns the Footprint message of an estimate.
+func footprintMessage(e Estimate) *footprintv1.Footprint {
+	return &footprintv1.Footprint{
+		EnergyKwh:        e.EnergyKiloWattHours,
+		OperationalGrams: e.OperationalGrams,
+		EmbodiedGrams:    e.EmbodiedGrams,
+		EmissionGrams:    e.EmissionGrams,
+		WaterLiters:      e.WaterLiters,
+		EstimatedFrom:    e.EstimatedFrom,
+	}
+}
+
+func (footprintService) EstimateInstance(ctx context.Context, req *footprintv1.EstimateInstanceRequest) (*footprintv1.Footprint, error) {
+	provider, err := grpcProvider(req.GetProvider())
+	if err != nil {
+		return nil, err
+	}
+	count := req.GetCount()
+	if count == 0 {
+		count = 1
+	}
+	utilization := float64(footprint.DefaultUtilization)
+	if req.Utilization != nil {
+		utilization = req.GetUtilization()
+	}
+	fallback := req.GetInstanceFallback()
+	if fallback == "" {
+		fallback = footprint.FallbackFamily
+	}
+	hours := req.GetDurationHours()
+	switch {
+	case req.GetRegion() == "" || req.GetInstanceType() == "":
+		return nil, status.Error(codes.InvalidArgument, "region and instance_type are required")
+	case count < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, must be at least 1", count)
+	case hours <= 0:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid duration_hours %g, must be positive", hours)
+	case utilization < 0 || utilization > 100:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid utilization %g, must be between 0 and 100", utilization)
+	case !footprint.IsFallbackMethod(fallback):
+		return nil, status.Errorf(codes.InvalidArgument, "invalid instance_fallback %q, must be one of: %s", fallback, strings.Join(footprint.FallbackMethods, ", "))
+	}
+
+	duration := time.Duration(hours * float64(time.Hour))
+	e, err := estimateInstances(provider, req.GetRegion(), req.GetInstanceType(), int(count), duration, utilization, fallback)
+	if err != nil {
+		return nil, err
+	}
+	return footprintMessage(e), nil
+}
+
+func (footprintService) EstimateEnergy(ctx context.Context, req *footprintv1.EstimateEnergyRequest) (*footprintv1.Footprint, error) {
+	provider, err := grpcProvider(req.GetProvider())
+	if err != nil {
+		return nil, err
+	}
+	kiloWattHours := req.GetEnergyKwh()
+	if kiloWattHours < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid energy_kwh %g, must not be negative", kiloWattHours)
+	}
+	f, err := lookupRegionFactors(provider, req.GetRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	energy := kiloWattHours * f.pue
+	operational := energy * f.carbonIntensity
+	return footprintMessage(Estimate{
+		EnergyKiloWattHours: energy,
+		OperationalGrams:    operational,
+		EmissionGrams:       operational,
+		WaterLiters:         kiloWattHours * f.wue,
+	}), nil
+}
+
+func (footprintService) GetRegion(ctx context.Context, req *footprintv1.GetRegionRequest) (*footprintv1.Region, error) {
+	provider, err := grpcProvider(req.GetProvider())
+	if err != nil {
+		return nil, err
+	}
+	f, err := lookupRegionFactors(provider, req.GetRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	region := &footprintv1.Region{
+		Provider:        provider,
+		Region:          req.GetRegion(),
+		CarbonIntensity: f.carbonIntensity,
+		Pue:             f.pue,
+		Wue:             f.wue,
+	}
+	if f.marketCarbonIntensity != nil {
+		// The field has presence, as AWS regions matched with renewable
+		// energy have a market-based carbon intensity of zero.
+		region.MarketCarbonIntensity = proto.Float64(*f.marketCarbonIntensity)
+	}
+	return region, nil
+}
+
+// regionFactors holds the factors the operational footprint of usage in a
+// region is calculated with.
+type regionFactors struct {
+	carbonIntensity float64
+
+	// marketCarbonIntensity is only known for AWS regions.
+	marketCarbonIntensity *float64
+
+	pue float64
+	wue float64
+}
+
+// lookupRegionFactors returns the factors of a region of a cloud provider.
+func lookupRegionFactors(provider, region string) (regionFactors, error) {
+	var carbonIntensity, pue, wue func(regionCode string) (float64, error)
+	switch provider {
+	case providerAWS:
+		carbonIntensity, pue, wue = calculator.CarbonIntensity, calculator.PUE, calculator.WUE
+	case providerGCP:
+		carbonIntensity, pue, wue = calculator.GCPCarbonIntensity, calculator.GCPPUE, calculator.GCPWUE
+	case providerAzure:
+		carbonIntensity, pue, wue = calculator.AzureCarbonIntensity, calculator.AzurePUE, calculator.AzureWUE
+	default:
+		return regionFactors{}, fmt.Errorf("invalid provider %q, must be one of: %s", provider, strings.Join(providers, ", "))
+	}
+
+	var f regionFactors
+	var err error
+	if f.carbonIntensity, err = carbonIntensity(region); err != nil {
+		return regionFactors{}, err
+	}
+	if f.pue, err = pue(region); err != nil {
+		return regionFactors{}, err
+	}
+	if f.wue, err = wue(region); err != nil {
+		return regionFactors{}, err
+	}
+	if provider == providerAWS {
+		market, err := calculator.MarketCarbonIntensity(region)
+		if err != nil {
+			return regionFactors{}, err
+		}
+		f.marketCarbonIntensity = &market
+	}
+	return f, nil
+}
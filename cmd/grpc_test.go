@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	footprintv1 "github.com/giantswarm/cloud-carbon/proto/cloudcarbon/footprint/v1"
+)
+
+// grpcTestClient serves newGRPCServer in memory and returns a client
+// connection to it.
+func grpcTestClient(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func Test_grpcServer(t *testing.T) {
+	conn := grpcTestClient(t)
+	client := footprintv1.NewFootprintServiceClient(conn)
+	ctx := context.Background()
+
+	got, err := client.EstimateInstance(ctx, &footprintv1.EstimateInstanceRequest{
+		Region:        "eu-west-1",
+		InstanceType:  "m5.large",
+		DurationHours: 10,
+	}, grpc.UseCompressor(gzip.Name))
+	if err != nil {
+		t.Fatalf("EstimateInstance error = %v", err)
+	}
+	e, err := estimateInstances(providerAWS, "eu-west-1", "m5.large", 1, 10*time.Hour, footprint.DefaultUtilization, footprint.FallbackFamily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, footprintMessage(e)) {
+		t.Errorf("EstimateInstance = %v, want %+v", got, e)
+	}
+
+	got, err = client.EstimateEnergy(ctx, &footprintv1.EstimateEnergyRequest{Region: "eu-west-1", EnergyKwh: 100})
+	ci, _ := calculator.CarbonIntensity("eu-west-1")
+	pue, _ := calculator.PUE("eu-west-1")
+	if err != nil || got.GetEnergyKwh() != 100*pue || got.GetOperationalGrams() != 100*pue*ci || got.GetEmbodiedGrams() != 0 {
+		t.Errorf("EstimateEnergy = %v, %v", got, err)
+	}
+
+	region, err := client.GetRegion(ctx, &footprintv1.GetRegionRequest{Provider: providerGCP, Region: "europe-west1"})
+	ci, _ = calculator.GCPCarbonIntensity("europe-west1")
+	if err != nil || region.GetCarbonIntensity() != ci || region.MarketCarbonIntensity != nil {
+		t.Errorf("GetRegion = %v, %v, want carbon intensity %g and no market-based intensity", region, err, ci)
+	}
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: footprintv1.FootprintService_ServiceDesc.ServiceName})
+	if err != nil || health.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health check = %v, %v, want SERVING", health, err)
+	}
+
+	tests := []struct {
+		name        string
+		call        func() error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name: "unknown region",
+			call: func() error {
+				_, err := client.GetRegion(ctx, &footprintv1.GetRegionRequest{Region: "xx-none-1"})
+				return err
+			},
+			wantCode:    codes.NotFound,
+			wantMessage: `unknown region code "xx-none-1"`,
+		},
+		{
+			name: "unknown instance type",
+			call: func() error {
+				_, err := client.EstimateInstance(ctx, &footprintv1.EstimateInstanceRequest{Region: "eu-west-1", InstanceType: "x9.unknown", DurationHours: 1, InstanceFallback: footprint.FallbackNone})
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "invalid provider",
+			call: func() error {
+				_, err := client.GetRegion(ctx, &footprintv1.GetRegionRequest{Provider: "ibm"})
+				return err
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: `invalid provider "ibm", must be one of: aws, gcp, azure`,
+		},
+		{
+			name: "missing instance type",
+			call: func() error {
+				_, err := client.EstimateInstance(ctx, &footprintv1.EstimateInstanceRequest{Region: "eu-west-1"})
+				return err
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "region and instance_type are required",
+		},
+		{
+			name: "invalid utilization",
+			call: func() error {
+				_, err := client.EstimateInstance(ctx, &footprintv1.EstimateInstanceRequest{Region: "eu-west-1", InstanceType: "m5.large", DurationHours: 1, Utilization: proto.Float64(150)})
+				return err
+			},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "invalid utilization 150, must be between 0 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := status.Convert(tt.call())
+			if s.Code() != tt.wantCode || (tt.wantMessage != "" && s.Message() != tt.wantMessage) {
+				t.Errorf("status = %s %q, want %s %q", s.Code(), s.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// helpWidth is the width long help texts are wrapped at.
+const helpWidth = 76
+
+// helpItem is an entry of a list in a long help text.
+type helpItem struct {
+	name        string
+	description string
+}
+
+// helpList formats items as a list for a long help text, with each item
+// wrapped at helpWidth and continued with an indentation.
+func helpList(items []helpItem) string {
+	var b strings.Builder
+	for _, item := range items {
+		line := "- " + item.name
+		if item.description != "" {
+			line += ":"
+		}
+		for _, word := range strings.Fields(item.description) {
+			if len(line)+1+len(word) > helpWidth {
+				b.WriteString(line + "\n")
+				line = " "
+			}
+			line += " " + word
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// withGeneratedHelp makes cmd replace the placeholders in its long help
+// text when the help is shown, rather than when the command is set up, so
+// that lists include what extensions register in their init functions.
+func withGeneratedHelp(cmd *cobra.Command, placeholders map[string]func() string) {
+	long := cmd.Long
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		var oldnew []string
+		for placeholder, generate := range placeholders {
+			oldnew = append(oldnew, placeholder, generate())
+		}
+		c.Long = strings.NewReplacer(oldnew...).Replace(long)
+		c.Parent().HelpFunc()(c, args)
+	})
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_helpList(t *testing.T) {
+	got := helpList([]helpItem{
+		{name: "table", description: "a human-readable table"},
+		{name: "vega-lite", description: strings.Repeat("word ", 20)},
+		{name: "markdown"},
+	})
+
+	want := `- table: a human-readable table
+- vega-lite: word word word word word word word word word word word word
+  word word word word word word word word
+- markdown`
+	if got != want {
+		t.Errorf("helpList() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Test_outputFormatsHelp(t *testing.T) {
+	got := outputFormatsHelp()
+
+	for _, format := range allOutputFormats() {
+		if !strings.Contains(got, "- "+format) {
+			t.Errorf("outputFormatsHelp() is missing %s:\n%s", format, got)
+		}
+	}
+	for _, format := range outputFormats {
+		if _, exists := outputFormatDescriptions[format]; !exists {
+			t.Errorf("output format %s has no description", format)
+		}
+	}
+	if !strings.Contains(got, "- test-rows: registered by an extension") {
+		t.Errorf("outputFormatsHelp() does not describe registered formats:\n%s", got)
+	}
+}
+
+func Test_dimensionsHelp(t *testing.T) {
+	got := dimensionsHelp()
+
+	for _, name := range append(dimensionNames(), "tag:KEY", "az") {
+		if !strings.Contains(got, name) {
+			t.Errorf("dimensionsHelp() is missing %s:\n%s", name, got)
+		}
+	}
+	for _, d := range availableDimensions {
+		if d.Description == "" {
+			t.Errorf("dimension %s has no description", d.Name)
+		}
+	}
+	if !strings.Contains(got, "- test-payer: registered by an extension") {
+		t.Errorf("dimensionsHelp() does not describe registered dimensions:\n%s", got)
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// Dimensions of the bar chart in HTML reports, in pixels.
+const (
+	chartLabelWidth = 160
+	chartBarWidth   = 480
+	chartValueWidth = 120
+	chartBarHeight  = 24
+)
+
+// htmlReport holds the content of a standalone HTML report.
+type htmlReport struct {
+	TimeRange string
+	Total     string
+	Table     tableData
+	Chart     barChart
+}
+
+// barChart is a horizontal bar chart, rendered as SVG.
+type barChart struct {
+	Width  int
+	Height int
+	Bars   []chartBar
+}
+
+// chartBar is one bar of a barChart, positioned in pixels.
+type chartBar struct {
+	Label string
+	Value string
+	Y     int
+	Width int
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Cloud carbon footprint</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; margin: 2em auto; max-width: 1100px; padding: 0 1em; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; }
+.total { font-size: 1.4em; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { text-align: left; padding: 0.4em 0.8em; border-bottom: 1px solid #d0d7de; }
+th { background: #f6f8fa; }
+tfoot td { font-weight: bold; border-bottom: none; }
+svg text { font-size: 13px; fill: #24292f; }
+svg rect { fill: #2da44e; }
+</style>
+</head>
+<body>
+<h1>Cloud carbon footprint</h1>
+<p>Time range covered: {{.TimeRange}}</p>
+<p class="total">Total emissions: {{.Total}}</p>
+{{- with .Chart}}{{if .Bars}}
+<h2>Emissions per region</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" role="img" aria-label="Emissions per region">
+{{- range .Bars}}
+<text x="0" y="{{.Y}}" dy="16">{{.Label}}</text>
+<rect x="` + fmt.Sprint(chartLabelWidth) + `" y="{{.Y}}" width="{{.Width}}" height="` + fmt.Sprint(chartBarHeight-6) + `"></rect>
+<text x="{{.Width}}" y="{{.Y}}" dx="` + fmt.Sprint(chartLabelWidth+6) + `" dy="16">{{.Value}}</text>
+{{- end}}
+</svg>
+{{- end}}{{end}}
+<h2>Details</h2>
+<table>
+<thead>
+<tr>{{range .Table.Header}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Table.Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr>{{range .Table.Footer}}<td>{{.}}</td>{{end}}</tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+// newHTMLReport returns the content of an HTML report on the summary, with
+// the rows of table shown as details and a chart of the emissions of rows
+// per region.
+func newHTMLReport(summary *ReportSummary, table tableData, rows []AggregateReportRow, total footprint.Result) htmlReport {
+	return htmlReport{
+		TimeRange: fmt.Sprintf("%s - %s", summary.EarliestDate, summary.LatestDate),
+		Total:     formatGrams(total.Total()),
+		Table:     table,
+		Chart:     regionChart(rows),
+	}
+}
+
+// regionChart returns a bar chart of the emissions per region, largest
+// first.
+func regionChart(rows []AggregateReportRow) barChart {
+	byRegion := make(map[string]float64)
+	for _, row := range rows {
+		byRegion[row.Region] += row.EmissionGrams
+	}
+
+	var regions []string
+	var max float64
+	for region, grams := range byRegion {
+		regions = append(regions, region)
+		if grams > max {
+			max = grams
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		a, b := byRegion[regions[i]], byRegion[regions[j]]
+		if a != b {
+			return a > b
+		}
+		return regions[i] < regions[j]
+	})
+
+	chart := barChart{
+		Width:  chartLabelWidth + chartBarWidth + chartValueWidth,
+		Height: len(regions) * chartBarHeight,
+	}
+	for i, region := range regions {
+		var width int
+		if max > 0 {
+			width = int(byRegion[region] / max * chartBarWidth)
+		}
+		chart.Bars = append(chart.Bars, chartBar{
+			Label: region,
+			Value: formatGrams(byRegion[region]),
+			Y:     i * chartBarHeight,
+			Width: width,
+		})
+	}
+	return chart
+}
+
+// writeHTML writes a standalone HTML report with embedded styles and chart.
+func writeHTML(w io.Writer, report htmlReport) error {
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("could not render HTML: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_regionChart(t *testing.T) {
+	rows := []AggregateReportRow{
+		{Region: "eu-west-1", EmissionGrams: 10},
+		{Region: "us-east-1", EmissionGrams: 40},
+		{Region: "eu-west-1", EmissionGrams: 10},
+	}
+
+	got := regionChart(rows)
+
+	want := []chartBar{
+		{Label: "us-east-1", Value: "40 gCO2e", Y: 0, Width: chartBarWidth},
+		{Label: "eu-west-1", Value: "20 gCO2e", Y: chartBarHeight, Width: chartBarWidth / 2},
+	}
+	if len(got.Bars) != len(want) {
+		t.Fatalf("regionChart() returned %d bars, want %d", len(got.Bars), len(want))
+	}
+	for i := range want {
+		if got.Bars[i] != want[i] {
+			t.Errorf("regionChart().Bars[%d] = %v, want %v", i, got.Bars[i], want[i])
+		}
+	}
+	if got.Height != 2*chartBarHeight {
+		t.Errorf("regionChart().Height = %d, want %d", got.Height, 2*chartBarHeight)
+	}
+}
+
+func Test_writeHTML(t *testing.T) {
+	report := htmlReport{
+		Total: "20 gCO2e",
+		Table: tableData{
+			Header: []string{"Tag", "Emissions"},
+			Rows:   [][]string{{"<script>", "20 gCO2e"}},
+			Footer: []string{"Total", "20 gCO2e"},
+		},
+		Chart: regionChart([]AggregateReportRow{{Region: "eu-west-1", EmissionGrams: 20}}),
+	}
+
+	var buf bytes.Buffer
+	if err := writeHTML(&buf, report); err != nil {
+		t.Fatalf("writeHTML() error = %v", err)
+	}
+
+	for _, want := range []string{"<svg", "eu-west-1", "&lt;script&gt;", "<td>Total</td>"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeHTML() output is missing %q", want)
+		}
+	}
+}
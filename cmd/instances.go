@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var instancesCmd = &cobra.Command{
+	Use:   "instances",
+	Short: "List EC2 instance types with their power consumption and emissions",
+	Long: `List EC2 instance types with their power consumption and emissions.
+
+For each EC2 instance type of the dataset, the power consumption at the
+given CPU utilization, the embodied emissions per hour, and the total
+emissions of running the instance for an hour in the given region are shown.
+Use --family to compare the sizes of some instance families.
+`,
+	RunE: instances,
+	Args: cobra.NoArgs,
+}
+
+var (
+	instancesFamily       string
+	instancesRegion       string
+	instancesUtilization  float64
+	instancesOutputFormat string
+)
+
+func init() {
+	instancesCmd.Flags().StringVar(&instancesFamily, "family", "", "Comma-separated list of instance families to include, e.g. m5,m6i. Defaults to all families")
+	instancesCmd.Flags().StringVar(&instancesRegion, "region", "us-east-1", "AWS region to compute the emissions for")
+	instancesCmd.Flags().Float64Var(&instancesUtilization, "utilization", footprint.DefaultUtilization, "CPU utilization of the instances in percent")
+	instancesCmd.Flags().StringVarP(&instancesOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+}
+
+// InstanceInfo holds the power consumption and emissions of an instance
+// type.
+type InstanceInfo struct {
+	InstanceType string  `json:"instance_type"`
+	VCPUs        float64 `json:"vcpus"`
+	PowerWatts   float64 `json:"power_watts"`
+
+	// GPUs and GPUPowerWatts are the number of GPUs and their share of the
+	// power consumption, set for GPU instance types only.
+	GPUs          float64 `json:"gpus,omitempty"`
+	GPUPowerWatts float64 `json:"gpu_power_watts,omitempty"`
+
+	// EmbodiedGramsHourly is the share of the manufacturing emissions
+	// attributed to an hour of usage.
+	EmbodiedGramsHourly float64 `json:"embodied_grams_hourly"`
+
+	// EmissionGramsHourly is the total emissions of running the instance
+	// for an hour, including the embodied emissions.
+	EmissionGramsHourly float64 `json:"emission_grams_hourly"`
+}
+
+func instances(cmd *cobra.Command, args []string) error {
+	if instancesOutputFormat != outputTable && instancesOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", instancesOutputFormat, outputTable, outputJSON)
+	}
+	if instancesUtilization < 0 || instancesUtilization > 100 {
+		return usageErrorf("invalid --utilization value %g, must be between 0 and 100", instancesUtilization)
+	}
+	if _, err := calculator.Region(instancesRegion); err != nil {
+		return usageErrorf("invalid --region value: %w", err)
+	}
+
+	infos, err := instanceInfos(splitList(instancesFamily), instancesRegion, instancesUtilization)
+	if err != nil {
+		return exitErrorf(exitData, "could not list instance types: %w", err)
+	}
+	if len(infos) == 0 {
+		return exitErrorf(exitData, "no instance types found for families %s", instancesFamily)
+	}
+
+	switch instancesOutputFormat {
+	case outputTable:
+		writeInstancesTable(os.Stdout, infos, instancesRegion, instancesUtilization)
+	case outputJSON:
+		err = writeJSON(os.Stdout, infos)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// instanceInfos returns the data of the EC2 instance types of the given
+// families, or of all EC2 instance types if families is empty. They are
+// sorted by family and, within a family, by size. Instance types of other
+// services in the dataset, like cache.m5.large or m5.large.elasticsearch,
+// are left out.
+func instanceInfos(families []string, region string, utilization float64) ([]InstanceInfo, error) {
+	var infos []InstanceInfo
+	for _, instanceType := range calculator.InstanceTypes() {
+		if strings.Count(instanceType, ".") != 1 {
+			continue
+		}
+		if len(families) > 0 && !containsString(families, instanceFamily(categoryEC2, instanceType)) {
+			continue
+		}
+
+		instance, err := calculator.Instance(instanceType)
+		if err != nil {
+			return nil, err
+		}
+		power, err := calculator.PowerAtUtilization(instanceType, utilization)
+		if err != nil {
+			return nil, err
+		}
+		gpuPower, err := calculator.GPUPowerAtUtilization(instanceType, utilization)
+		if err != nil {
+			return nil, err
+		}
+		result, err := calculator.AWSAtUtilization(region, instanceType, utilization, time.Hour)
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, InstanceInfo{
+			InstanceType:        instanceType,
+			VCPUs:               instance.VCPUs,
+			PowerWatts:          power,
+			GPUs:                instance.GPUs,
+			GPUPowerWatts:       gpuPower,
+			EmbodiedGramsHourly: result.EmbodiedGrams,
+			EmissionGramsHourly: result.Total(),
+		})
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		fi, fj := instanceFamily(categoryEC2, infos[i].InstanceType), instanceFamily(categoryEC2, infos[j].InstanceType)
+		if fi != fj {
+			return fi < fj
+		}
+		return infos[i].VCPUs < infos[j].VCPUs
+	})
+
+	return infos, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeInstancesTable writes the instance types as a table, in the order
+// given. The GPU columns are shown if any instance type has GPUs.
+func writeInstancesTable(w io.Writer, infos []InstanceInfo, region string, utilization float64) {
+	fmt.Fprintf(w, "Emissions of running each instance for an hour in %s at %g%% CPU utilization:\n\n", region, utilization)
+
+	table := tablewriter.NewWriter(w)
+	withGPU := false
+	for _, info := range infos {
+		withGPU = withGPU || info.GPUs > 0
+	}
+
+	header := []string{"Instance type", "vCPUs", "Power"}
+	if withGPU {
+		header = append(header, "GPUs", "GPU power")
+	}
+	table.SetHeader(append(header, "Embodied", "Emissions"))
+
+	for _, info := range infos {
+		cells := []string{
+			info.InstanceType,
+			fmt.Sprintf("%g", info.VCPUs),
+			fmt.Sprintf("%.1f W", info.PowerWatts),
+		}
+		if withGPU {
+			cells = append(cells, fmt.Sprintf("%g", info.GPUs), fmt.Sprintf("%.1f W", info.GPUPowerWatts))
+		}
+		table.Append(append(cells,
+			fmt.Sprintf("%.1f gCO2e", info.EmbodiedGramsHourly),
+			fmt.Sprintf("%.1f gCO2e", info.EmissionGramsHourly),
+		))
+	}
+
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
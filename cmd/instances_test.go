@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_instanceInfos(t *testing.T) {
+	infos, err := instanceInfos([]string{"m5", "c5"}, "eu-west-1", 50)
+	if err != nil {
+		t.Fatalf("instanceInfos() error = %v", err)
+	}
+	if len(infos) == 0 {
+		t.Fatal("instanceInfos() returned no instance types")
+	}
+
+	if infos[0].InstanceType != "c5.large" {
+		t.Errorf("first instance type = %s, want c5.large", infos[0].InstanceType)
+	}
+	for i, info := range infos {
+		family := instanceFamily(categoryEC2, info.InstanceType)
+		if family != "m5" && family != "c5" {
+			t.Errorf("instance type %s is not of family m5 or c5", info.InstanceType)
+		}
+		if info.EmissionGramsHourly <= info.EmbodiedGramsHourly {
+			t.Errorf("%s: emissions %v not above embodied emissions %v", info.InstanceType, info.EmissionGramsHourly, info.EmbodiedGramsHourly)
+		}
+		if i > 0 && family == instanceFamily(categoryEC2, infos[i-1].InstanceType) && info.VCPUs < infos[i-1].VCPUs {
+			t.Errorf("%s is listed after the larger %s", info.InstanceType, infos[i-1].InstanceType)
+		}
+	}
+
+	want, _ := calculator.AWS("eu-west-1", "m5.large", time.Hour)
+	for _, info := range infos {
+		if info.InstanceType == "m5.large" && info.EmissionGramsHourly != want.Total() {
+			t.Errorf("m5.large: emissions = %v, want %v", info.EmissionGramsHourly, want.Total())
+		}
+	}
+}
+
+func Test_instanceInfos_otherServices(t *testing.T) {
+	infos, err := instanceInfos(nil, "eu-west-1", 50)
+	if err != nil {
+		t.Fatalf("instanceInfos() error = %v", err)
+	}
+	for _, info := range infos {
+		if strings.HasPrefix(info.InstanceType, "db.") || strings.HasPrefix(info.InstanceType, "cache.") || strings.HasSuffix(info.InstanceType, ".elasticsearch") {
+			t.Errorf("instanceInfos() contains %s", info.InstanceType)
+		}
+	}
+}
+
+func Test_writeInstancesTable(t *testing.T) {
+	infos := []InstanceInfo{
+		{InstanceType: "m5.large", VCPUs: 2, PowerWatts: 9.94, EmbodiedGramsHourly: 1.2, EmissionGramsHourly: 4.56},
+	}
+
+	var buf bytes.Buffer
+	writeInstancesTable(&buf, infos, "eu-west-1", 30)
+
+	for _, want := range []string{"eu-west-1 at 30% CPU utilization", "m5.large", "9.9 W", "1.2 gCO2e", "4.6 gCO2e"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeInstancesTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "GPU") {
+		t.Errorf("writeInstancesTable() shows GPU columns without GPU instance types:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	infos = append(infos, InstanceInfo{InstanceType: "g4dn.xlarge", VCPUs: 4, PowerWatts: 35.6, GPUs: 1, GPUPowerWatts: 52.7})
+	writeInstancesTable(&buf, infos, "eu-west-1", 50)
+	for _, want := range []string{"GPU POWER", "52.7 W"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeInstancesTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func Test_instanceInfos_gpu(t *testing.T) {
+	infos, err := instanceInfos([]string{"g4dn"}, "us-east-1", 50)
+	if err != nil {
+		t.Fatalf("instanceInfos() error = %v", err)
+	}
+	for _, info := range infos {
+		if info.GPUs == 0 || info.GPUPowerWatts <= 0 || info.GPUPowerWatts >= info.PowerWatts {
+			t.Errorf("%s: GPUs = %v, GPU power = %v of %v", info.InstanceType, info.GPUs, info.GPUPowerWatts, info.PowerWatts)
+		}
+	}
+}
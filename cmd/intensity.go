@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var intensityOverridesFile string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&intensityOverridesFile, "intensity-overrides", "", "YAML or CSV file with the carbon intensity of regions in gCO2e/kWh, overriding the datasets")
+}
+
+// readIntensityOverrides reads a file mapping region codes to carbon
+// intensities in grams of CO2e per kilowatt hour. Files ending in .csv must
+// have a header row and the columns region and carbon intensity, all others
+// are read as YAML mapping.
+func readIntensityOverrides(path string) (map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseIntensityCSV(f)
+	}
+
+	var overrides map[string]float64
+	if err := yaml.NewDecoder(f).Decode(&overrides); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// parseIntensityCSV reads carbon intensity overrides from CSV, skipping the
+// header row.
+func parseIntensityCSV(r io.Reader) (map[string]float64, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]float64)
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		ci, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid carbon intensity %q for region %s", i+1, record[1], record[0])
+		}
+		overrides[record[0]] = ci
+	}
+	return overrides, nil
+}
+
+// intensityOverrideNotes returns the regions of rows with an overridden
+// carbon intensity, with the intensity used, sorted by region.
+func intensityOverrideNotes(rows []AggregateReportRow) []string {
+	overrides := calculator.CarbonIntensityOverrides()
+
+	used := make(map[string]bool)
+	for _, row := range rows {
+		if _, exists := overrides[row.Region]; exists {
+			used[row.Region] = true
+		}
+	}
+
+	var notes []string
+	for region := range used {
+		notes = append(notes, fmt.Sprintf("%s (%g gCO2e/kWh)", region, overrides[region]))
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// writeIntensityOverrides notes the regions of rows with an overridden
+// carbon intensity, if any.
+func writeIntensityOverrides(w io.Writer, rows []AggregateReportRow) {
+	notes := intensityOverrideNotes(rows)
+	if len(notes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nCarbon intensity overridden by %s for regions:\n", intensityOverridesFile)
+	for _, note := range notes {
+		fmt.Fprintf(w, "  - %s\n", note)
+	}
+}
+
+// estimatedRegionNotes returns the AWS regions of rows missing from the
+// region dataset, with what their data was estimated from.
+func estimatedRegionNotes(rows []AggregateReportRow) []string {
+	used := make(map[string]string)
+	for _, row := range rows {
+		if r, err := calculator.Region(row.Region); err == nil && r.EstimatedFrom != "" {
+			used[row.Region] = r.EstimatedFrom
+		}
+	}
+
+	var notes []string
+	for region, from := range used {
+		notes = append(notes, fmt.Sprintf("%s (%s)", region, from))
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// writeEstimatedRegions notes the regions of rows missing from the region
+// dataset, if any.
+func writeEstimatedRegions(w io.Writer, rows []AggregateReportRow) {
+	notes := estimatedRegionNotes(rows)
+	if len(notes) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nCarbon intensity estimated for regions missing from the dataset:")
+	for _, note := range notes {
+		fmt.Fprintf(w, "  - %s\n", note)
+	}
+}
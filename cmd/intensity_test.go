@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_readIntensityOverrides(t *testing.T) {
+	want := map[string]float64{"eu-west-1": 250, "europe-west1": 100.5}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{name: "YAML", file: "overrides.yaml", content: "eu-west-1: 250\neurope-west1: 100.5\n", want: want},
+		{name: "CSV", file: "overrides.csv", content: "region,carbon_intensity\neu-west-1,250\neurope-west1, 100.5\n", want: want},
+		{name: "empty YAML", file: "overrides.yaml", content: "", want: nil},
+		{name: "invalid YAML value", file: "overrides.yaml", content: "eu-west-1: high\n", wantErr: true},
+		{name: "invalid CSV value", file: "overrides.csv", content: "region,carbon_intensity\neu-west-1,high\n", wantErr: true},
+		{name: "missing CSV column", file: "overrides.csv", content: "region\neu-west-1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readIntensityOverrides(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readIntensityOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readIntensityOverrides() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := readIntensityOverrides(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("readIntensityOverrides() of missing file returned no error")
+	}
+}
+
+func Test_writeIntensityOverrides(t *testing.T) {
+	original := calculator
+	t.Cleanup(func() { calculator, intensityOverridesFile = original, "" })
+
+	var err error
+	calculator, err = footprint.NewCalculator(footprint.WithCarbonIntensity("eu-west-1", 250), footprint.WithCarbonIntensity("us-east-1", 380.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	intensityOverridesFile = "ember.yaml"
+
+	rows := []AggregateReportRow{{Region: "eu-west-1"}, {Region: "eu-central-1"}, {Region: "eu-west-1"}}
+
+	var buf bytes.Buffer
+	writeIntensityOverrides(&buf, rows)
+	want := "\nCarbon intensity overridden by ember.yaml for regions:\n  - eu-west-1 (250 gCO2e/kWh)\n"
+	if buf.String() != want {
+		t.Errorf("writeIntensityOverrides() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeIntensityOverrides(&buf, rows[1:2])
+	if buf.Len() != 0 {
+		t.Errorf("writeIntensityOverrides() without overridden regions = %q, want nothing", buf.String())
+	}
+
+	data := newReportData("Title", newReportSummary(nil), []AggregateReportRow{{Labels: []string{"us-east-1", "111"}, Region: "us-east-1"}})
+	if want := []string{"us-east-1 (380.5 gCO2e/kWh)"}; !reflect.DeepEqual(data.IntensityOverrides, want) {
+		t.Errorf("newReportData().IntensityOverrides = %v, want %v", data.IntensityOverrides, want)
+	}
+
+	var markup bytes.Buffer
+	if err := template.Must(template.New("report").Parse(defaultReportTemplate)).Execute(&markup, data); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(markup.String(), "- us-east-1 (380.5 gCO2e/kWh)") {
+		t.Errorf("report does not list the overridden region:\n%s", markup.String())
+	}
+}
+
+func Test_writeEstimatedRegions(t *testing.T) {
+	rows := []AggregateReportRow{{Region: "il-central-1"}, {Region: "eu-west-1"}, {Region: "il-central-1"}}
+
+	var buf bytes.Buffer
+	writeEstimatedRegions(&buf, rows)
+	want := "\nCarbon intensity estimated for regions missing from the dataset:\n  - il-central-1 (national grid of Israel)\n"
+	if buf.String() != want {
+		t.Errorf("writeEstimatedRegions() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeEstimatedRegions(&buf, rows[1:2])
+	if buf.Len() != 0 {
+		t.Errorf("writeEstimatedRegions() without estimated regions = %q, want nothing", buf.String())
+	}
+
+	data := newReportData("Title", newReportSummary(nil), []AggregateReportRow{{Labels: []string{"il-central-1", "111"}, Region: "il-central-1"}})
+	if want := []string{"il-central-1 (national grid of Israel)"}; !reflect.DeepEqual(data.EstimatedRegions, want) {
+		t.Errorf("newReportData().EstimatedRegions = %v, want %v", data.EstimatedRegions, want)
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Names of the dimensions grouping by Kubernetes cluster, namespace and
+	// node group.
+	clusterDimension   = "cluster"
+	namespaceDimension = "namespace"
+	nodeGroupDimension = "nodegroup"
+
+	// unattributedLabel is shown for usage not attributed to a cluster or
+	// namespace.
+	unattributedLabel = "(unattributed)"
+)
+
+// Columns of a node mapping file.
+const (
+	nodeHeaderInstanceID = "instance_id"
+	nodeHeaderCluster    = "cluster"
+	nodeHeaderNamespace  = "namespace"
+	nodeHeaderShare      = "share"
+)
+
+// nodeShare is the part of an instance's usage attributed to a namespace.
+type nodeShare struct {
+	cluster   string
+	namespace string
+	share     float64
+}
+
+// nodeMapping maps EC2 instance IDs to the Kubernetes cluster the instance
+// is a node of, and to the namespaces sharing it.
+type nodeMapping map[string][]nodeShare
+
+// readNodeMappingFile reads a node mapping from a CSV file with the columns
+// instance_id, cluster, and optionally namespace and share. See
+// readNodeMapping.
+func readNodeMappingFile(path string) (nodeMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readNodeMapping(f)
+}
+
+// readNodeMapping reads a node mapping in CSV format. Each line attributes
+// a share between 0 and 1 of an instance's usage to a namespace. Lines
+// without a share split the remainder of the instance evenly. If the shares
+// of an instance add up to less than 1, the rest is attributed to the
+// cluster without a namespace. Instance IDs may also be given as
+// Kubernetes provider IDs like aws:///eu-west-1a/i-0123456789abcdef0.
+func readNodeMapping(r io.Reader) (nodeMapping, error) {
+	processedHeaders := false
+	var headers reportHeaders
+
+	// Lines without a share, per instance ID.
+	unshared := make(map[string][]nodeShare)
+	mapping := make(nodeMapping)
+
+	fcsv := csv.NewReader(r)
+	for {
+		record, err := fcsv.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		if !processedHeaders {
+			headers = newReportHeaders(record)
+			for _, column := range []string{nodeHeaderInstanceID, nodeHeaderCluster} {
+				if _, exists := headers.index[column]; !exists {
+					return nil, fmt.Errorf("missing column %q", column)
+				}
+			}
+			processedHeaders = true
+			continue
+		}
+
+		line, _ := fcsv.FieldPos(0)
+
+		instanceID := nodeInstanceID(headers.value(record, nodeHeaderInstanceID))
+		if instanceID == "" {
+			return nil, fmt.Errorf("line %d: missing instance ID", line)
+		}
+		share := nodeShare{
+			cluster:   strings.TrimSpace(headers.value(record, nodeHeaderCluster)),
+			namespace: strings.TrimSpace(headers.value(record, nodeHeaderNamespace)),
+		}
+		if share.cluster == "" {
+			return nil, fmt.Errorf("line %d: missing cluster", line)
+		}
+		for _, s := range append(mapping[instanceID], unshared[instanceID]...) {
+			if s.cluster != share.cluster {
+				return nil, fmt.Errorf("line %d: instance %s is mapped to clusters %s and %s", line, instanceID, s.cluster, share.cluster)
+			}
+		}
+
+		value := strings.TrimSpace(headers.value(record, nodeHeaderShare))
+		if value == "" {
+			unshared[instanceID] = append(unshared[instanceID], share)
+			continue
+		}
+		share.share, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: error parsing share %q as float", line, value)
+		}
+		if share.share < 0 || share.share > 1 {
+			return nil, fmt.Errorf("line %d: share %g must be between 0 and 1", line, share.share)
+		}
+		mapping[instanceID] = append(mapping[instanceID], share)
+	}
+
+	for instanceID, shares := range unshared {
+		remainder := 1 - sumShares(mapping[instanceID])
+		for _, s := range shares {
+			s.share = math.Max(remainder, 0) / float64(len(shares))
+			mapping[instanceID] = append(mapping[instanceID], s)
+		}
+	}
+
+	for instanceID, shares := range mapping {
+		sum := sumShares(shares)
+		if sum > 1+1e-9 {
+			return nil, fmt.Errorf("shares of instance %s add up to %g, more than 1", instanceID, sum)
+		}
+		if sum < 1-1e-9 {
+			mapping[instanceID] = append(shares, nodeShare{cluster: shares[0].cluster, share: 1 - sum})
+		}
+	}
+
+	return mapping, nil
+}
+
+// nodeInstanceID returns the EC2 instance ID from an instance ID or a
+// Kubernetes provider ID.
+func nodeInstanceID(value string) string {
+	value = strings.TrimSpace(value)
+	return value[strings.LastIndex(value, "/")+1:]
+}
+
+func sumShares(shares []nodeShare) float64 {
+	var sum float64
+	for _, s := range shares {
+		sum += s.share
+	}
+	return sum
+}
+
+// attribute splits the usage of an EC2 instance that is a Kubernetes node
+// into one row per namespace, according to the namespace's share. Other
+// rows are returned unchanged.
+func (m nodeMapping) attribute(r ReportRow) []ReportRow {
+	shares, exists := m[r.ResourceID]
+	if r.Category != categoryEC2 || !exists {
+		return []ReportRow{r}
+	}
+
+	rows := make([]ReportRow, 0, len(shares))
+	for _, s := range shares {
+		row := r.scaled(s.share)
+		row.Cluster = s.cluster
+		row.Namespace = s.namespace
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// scaled returns a copy of the row with its usage multiplied by f.
+func (r ReportRow) scaled(f float64) ReportRow {
+	r.Duration = time.Duration(float64(r.Duration) * f)
+	r.GBHours *= f
+	r.VCPUHours *= f
+	r.TransferGB *= f
+	r.Cost *= f
+	return r
+}
+
+// attributionLabel returns value, or unattributedLabel if it is empty.
+func attributionLabel(value string) string {
+	if value == "" {
+		return unattributedLabel
+	}
+	return value
+}
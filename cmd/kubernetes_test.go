@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_readNodeMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    nodeMapping
+		wantErr bool
+	}{
+		{
+			name: "explicit shares",
+			csv:  "instance_id,cluster,namespace,share\ni-1,prod,web,0.6\ni-1,prod,batch,0.4\n",
+			want: nodeMapping{"i-1": {{"prod", "web", 0.6}, {"prod", "batch", 0.4}}},
+		},
+		{
+			name: "even split of remainder",
+			csv:  "instance_id,cluster,namespace,share\ni-1,prod,web,0.5\ni-1,prod,batch,\ni-1,prod,cron,\n",
+			want: nodeMapping{"i-1": {{"prod", "web", 0.5}, {"prod", "batch", 0.25}, {"prod", "cron", 0.25}}},
+		},
+		{
+			name: "unused capacity",
+			csv:  "instance_id,cluster,namespace,share\ni-1,prod,web,0.7\n",
+			want: nodeMapping{"i-1": {{"prod", "web", 0.7}, {"prod", "", 0.3}}},
+		},
+		{
+			name: "clusters only with provider IDs",
+			csv:  "cluster,instance_id\nprod,aws:///eu-west-1a/i-1\nstaging,i-2\n",
+			want: nodeMapping{"i-1": {{"prod", "", 1}}, "i-2": {{"staging", "", 1}}},
+		},
+		{name: "missing cluster column", csv: "instance_id,namespace\ni-1,web\n", wantErr: true},
+		{name: "missing cluster", csv: "instance_id,cluster\ni-1,\n", wantErr: true},
+		{name: "several clusters", csv: "instance_id,cluster\ni-1,prod\ni-1,staging\n", wantErr: true},
+		{name: "share out of range", csv: "instance_id,cluster,namespace,share\ni-1,prod,web,1.5\n", wantErr: true},
+		{name: "shares above 1", csv: "instance_id,cluster,namespace,share\ni-1,prod,web,0.6\ni-1,prod,batch,0.6\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readNodeMapping(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readNodeMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("readNodeMapping() = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if len(got[id]) != len(want) {
+					t.Fatalf("readNodeMapping()[%s] = %v, want %v", id, got[id], want)
+				}
+				for i := range want {
+					g := got[id][i]
+					if g.cluster != want[i].cluster || g.namespace != want[i].namespace || math.Abs(g.share-want[i].share) > 1e-9 {
+						t.Errorf("readNodeMapping()[%s][%d] = %v, want %v", id, i, g, want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReportSummary_add_nodes(t *testing.T) {
+	summary := newReportSummary(testDimensions(t, "cluster,namespace"))
+	summary.Nodes = nodeMapping{"i-1": {{"prod", "web", 0.75}, {"prod", "", 0.25}}}
+
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", ResourceID: "i-1", Duration: time.Hour})
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", ResourceID: "i-2", Duration: time.Hour})
+	summary.add(ReportRow{Category: categoryEBS, Region: "eu-west-1", StorageType: "gp3", ResourceID: "i-1", GBHours: 10})
+
+	if summary.LineCount != 3 {
+		t.Errorf("LineCount = %d, want 3", summary.LineCount)
+	}
+
+	want := map[string]time.Duration{
+		"prod/web":                  45 * time.Minute,
+		"prod/" + unattributedLabel: 15 * time.Minute,
+		unattributedLabel + "/" + unattributedLabel: time.Hour,
+	}
+	got := make(map[string]time.Duration)
+	for _, row := range summary.Aggregate {
+		if row.Category == categoryEC2 {
+			got[strings.Join(row.Labels, "/")] += row.Duration
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got EC2 usage %v, want %v", got, want)
+	}
+	for labels, duration := range want {
+		if got[labels] != duration {
+			t.Errorf("duration of %s = %s, want %s", labels, got[labels], duration)
+		}
+	}
+}
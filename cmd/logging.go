@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// Formats of log messages.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var logFormats = []string{logFormatText, logFormatJSON}
+
+var logFormat string
+
+// jsonLogger logs messages as JSON with --log-format json, and is nil
+// otherwise.
+var jsonLogger *slog.Logger
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, fmt.Sprintf("Format of log messages on stderr, one of: %s", strings.Join(logFormats, ", ")))
+}
+
+// setupLogging sets up the log package to write messages in the given
+// format to w.
+func setupLogging(w io.Writer, format string) error {
+	switch format {
+	case logFormatText:
+		jsonLogger = nil
+		log.SetFlags(log.LstdFlags)
+		log.SetOutput(w)
+	case logFormatJSON:
+		jsonLogger = slog.New(slog.NewJSONHandler(w, nil))
+		log.SetFlags(0)
+		log.SetOutput(slogWriter{jsonLogger})
+	default:
+		return usageErrorf("invalid --log-format value %q, must be one of: %s", format, strings.Join(logFormats, ", "))
+	}
+	return nil
+}
+
+// slogWriter passes messages of the log package on to a structured logger.
+// Messages starting with "Warning: " are logged as warnings, others as
+// information.
+type slogWriter struct {
+	logger *slog.Logger
+}
+
+func (w slogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	level := slog.LevelInfo
+	if warning, found := strings.CutPrefix(msg, "Warning: "); found {
+		msg, level = warning, slog.LevelWarn
+	}
+	w.logger.Log(context.Background(), level, msg)
+	return len(p), nil
+}
+
+// logError logs the error that stopped a command, with its exit code.
+func logError(err error, code int) {
+	if jsonLogger != nil {
+		jsonLogger.Error(err.Error(), "exit_code", code)
+		return
+	}
+	log.Printf("Error: %s", err)
+}
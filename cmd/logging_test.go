@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_setupLogging(t *testing.T) {
+	t.Cleanup(func() {
+		if err := setupLogging(os.Stderr, logFormatText); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := setupLogging(&buf, logFormatJSON); err != nil {
+		t.Fatalf("setupLogging() error = %v", err)
+	}
+	log.Printf("Warning: ignoring cached result for %s", "report.csv")
+	logError(usageErrorf("invalid --top value"), exitUsage)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("setupLogging() logged %q, want 2 lines", buf.String())
+	}
+	var records []map[string]any
+	for _, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if records[0]["level"] != "WARN" || records[0]["msg"] != "ignoring cached result for report.csv" {
+		t.Errorf("warning logged as %v", records[0])
+	}
+	if records[1]["level"] != "ERROR" || records[1]["exit_code"] != float64(exitUsage) {
+		t.Errorf("error logged as %v", records[1])
+	}
+
+	buf.Reset()
+	if err := setupLogging(&buf, logFormatText); err != nil {
+		t.Fatalf("setupLogging() error = %v", err)
+	}
+	logError(errors.New("failed"), exitFailure)
+	if !strings.HasSuffix(buf.String(), "Error: failed\n") {
+		t.Errorf("logError() wrote %q in text format", buf.String())
+	}
+
+	if err := setupLogging(&buf, "xml"); exitCode(err) != exitUsage {
+		t.Errorf("setupLogging() with invalid format = %v, want usage error", err)
+	}
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+var manifestPath string
+
+func init() {
+	analyseCmd.Flags().StringVar(&manifestPath, "manifest", "", "Write a JSON manifest of the run to this file, with the checksums of the input files and datasets, the model parameters and the totals")
+}
+
+// manifest records what a result was computed from, so that published
+// numbers can be audited and reproduced.
+type manifest struct {
+	ToolVersion string             `json:"tool_version,omitempty"`
+	CreatedAt   *time.Time         `json:"created_at,omitempty"`
+	Inputs      []manifestInput    `json:"inputs"`
+	Datasets    []DatasetInfo      `json:"datasets"`
+	Parameters  manifestParameters `json:"parameters"`
+	Usage       manifestUsage      `json:"usage"`
+	Totals      manifestTotals     `json:"totals"`
+	Partial     bool               `json:"partial"`
+}
+
+// manifestInput is a report file analysed.
+type manifestInput struct {
+	Path     string `json:"path"`
+	Checksum string `json:"checksum,omitempty"`
+	Cached   bool   `json:"cached,omitempty"`
+
+	// Error tells why the file could not be processed, if it is missing
+	// from the result.
+	Error string `json:"error,omitempty"`
+}
+
+// manifestFile is a file given as a parameter.
+type manifestFile struct {
+	Path     string `json:"path"`
+	Checksum string `json:"checksum"`
+}
+
+// manifestParameters are the options affecting the result.
+type manifestParameters struct {
+	Provider           string             `json:"provider"`
+	GroupBy            string             `json:"group_by"`
+	Start              string             `json:"start,omitempty"`
+	End                string             `json:"end,omitempty"`
+	FilterAccount      string             `json:"filter_account,omitempty"`
+	FilterRegion       string             `json:"filter_region,omitempty"`
+	FilterInstanceType string             `json:"filter_instance_type,omitempty"`
+	CoveredUsage       bool               `json:"covered_usage"`
+	Utilization        float64            `json:"utilization"`
+	UtilizationFile    *manifestFile      `json:"utilization_file,omitempty"`
+	InstanceFallback   string             `json:"instance_fallback"`
+	IntensityMode      string             `json:"intensity_mode"`
+	IntensityProvider  string             `json:"intensity_provider,omitempty"`
+	IntensityOverrides *manifestFile      `json:"intensity_overrides,omitempty"`
+	Outposts           *manifestFile      `json:"outposts,omitempty"`
+	PUE                float64            `json:"pue,omitempty"`
+	RegionPUE          map[string]float64 `json:"region_pue,omitempty"`
+	WUE                *float64           `json:"wue,omitempty"`
+	RegionWUE          map[string]float64 `json:"region_wue,omitempty"`
+	AmortizationYears  float64            `json:"amortization_years"`
+	NodeMapping        *manifestFile      `json:"node_mapping,omitempty"`
+	OpenCostAllocation *manifestFile      `json:"opencost_allocation,omitempty"`
+	AccountNames       *manifestFile      `json:"account_names,omitempty"`
+	Redact             bool               `json:"redact,omitempty"`
+}
+
+// manifestUsage summarizes the usage analysed.
+type manifestUsage struct {
+	Lines            int       `json:"lines"`
+	SkippedLines     int       `json:"skipped_lines"`
+	InvalidDateLines int       `json:"invalid_date_lines,omitempty"`
+	EarliestDate     time.Time `json:"earliest_date"`
+	LatestDate       time.Time `json:"latest_date"`
+}
+
+// manifestTotals are the totals of the result.
+type manifestTotals struct {
+	EnergyKiloWattHours float64 `json:"energy_kwh"`
+	OperationalGrams    float64 `json:"operational_grams"`
+	EmbodiedGrams       float64 `json:"embodied_grams"`
+	EmissionGrams       float64 `json:"emission_grams"`
+	WaterLiters         float64 `json:"water_liters"`
+	Cost                float64 `json:"cost"`
+}
+
+// manifestSourceInput returns the input of a source, with the checksum
+// already known from the cache, if any, or else computed.
+func manifestSourceInput(ctx context.Context, src ReportSource, checksum string) manifestInput {
+	input := manifestInput{Path: src.Name, Checksum: checksum}
+	if input.Checksum == "" && src.Checksum != nil {
+		var err error
+		input.Checksum, err = src.Checksum(ctx)
+		if err != nil {
+			input.Error = fmt.Sprintf("could not compute checksum: %s", err)
+		}
+	}
+	return input
+}
+
+// newManifest returns the manifest of a run of the analyse command with
+// the current flags.
+func newManifest(inputs []manifestInput, summary *ReportSummary, rows []AggregateReportRow, total footprint.Result, partial bool) (manifest, error) {
+	m := manifest{
+		Inputs: inputs,
+		Usage: manifestUsage{
+			Lines:            summary.LineCount,
+			SkippedLines:     summary.SkippedCount,
+			InvalidDateLines: summary.InvalidDateCount,
+			EarliestDate:     summary.EarliestDate,
+			LatestDate:       summary.LatestDate,
+		},
+		Totals: manifestTotals{
+			EnergyKiloWattHours: total.EnergyKiloWattHours,
+			OperationalGrams:    total.OperationalGrams,
+			EmbodiedGrams:       total.EmbodiedGrams,
+			EmissionGrams:       total.Total(),
+			WaterLiters:         total.WaterLiters,
+		},
+		Partial: partial,
+	}
+	if !deterministic {
+		now := time.Now().UTC()
+		m.CreatedAt = &now
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.ToolVersion = info.Main.Version
+	}
+	for _, row := range rows {
+		m.Totals.Cost += row.Cost
+	}
+
+	var err error
+	m.Datasets, err = datasetInfos()
+	if err != nil {
+		return manifest{}, err
+	}
+	m.Parameters, err = newManifestParameters()
+	if err != nil {
+		return manifest{}, err
+	}
+	return m, nil
+}
+
+// newManifestParameters returns the parameters given by the current flags.
+func newManifestParameters() (manifestParameters, error) {
+	p := manifestParameters{
+		Provider:           provider,
+		GroupBy:            groupBy,
+		Start:              start,
+		End:                end,
+		FilterAccount:      filterAccount,
+		FilterRegion:       filterRegion,
+		FilterInstanceType: filterInstanceType,
+		CoveredUsage:       coveredUsage,
+		Utilization:        utilization,
+		InstanceFallback:   instanceFallback,
+		IntensityMode:      intensityMode,
+		IntensityProvider:  intensityProvider,
+		PUE:                pue,
+		AmortizationYears:  amortizationYears,
+	}
+	if wueFlag.Changed {
+		p.WUE = &wue
+	}
+	if redact {
+		// The patterns may contain account IDs.
+		p.Redact = true
+		if p.FilterAccount != "" {
+			p.FilterAccount = "(redacted)"
+		}
+	}
+
+	var err error
+	if p.RegionPUE, err = parseRegionValues(regionPUE, "PUE"); err != nil {
+		return manifestParameters{}, fmt.Errorf("invalid --region-pue value: %w", err)
+	}
+	if p.RegionWUE, err = parseRegionValues(regionWUE, "WUE"); err != nil {
+		return manifestParameters{}, fmt.Errorf("invalid --region-wue value: %w", err)
+	}
+
+	for _, f := range []struct {
+		path string
+		file **manifestFile
+	}{
+		{utilizationFile, &p.UtilizationFile},
+		{intensityOverridesFile, &p.IntensityOverrides},
+		{outpostsFile, &p.Outposts},
+		{nodeMappingFile, &p.NodeMapping},
+		{openCostFile, &p.OpenCostAllocation},
+		{accountNamesFile, &p.AccountNames},
+	} {
+		if f.path == "" {
+			continue
+		}
+		checksum, err := fileChecksum(f.path)
+		if err != nil {
+			return manifestParameters{}, fmt.Errorf("file %s: %w", f.path, err)
+		}
+		*f.file = &manifestFile{Path: f.path, Checksum: checksum}
+	}
+	return p, nil
+}
+
+// writeManifest writes a manifest as indented JSON to path.
+func writeManifest(path string, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
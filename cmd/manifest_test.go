@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_manifestSourceInput(t *testing.T) {
+	src := ReportSource{
+		Name:     "report.csv",
+		Checksum: func(ctx context.Context) (string, error) { return "sha256:abc", nil },
+	}
+	if got := manifestSourceInput(context.Background(), src, ""); got.Checksum != "sha256:abc" {
+		t.Errorf("manifestSourceInput() checksum = %q, want sha256:abc", got.Checksum)
+	}
+	if got := manifestSourceInput(context.Background(), src, "etag:cached"); got.Checksum != "etag:cached" {
+		t.Errorf("manifestSourceInput() with known checksum = %q, want etag:cached", got.Checksum)
+	}
+
+	src.Checksum = func(ctx context.Context) (string, error) { return "", errors.New("access denied") }
+	if got := manifestSourceInput(context.Background(), src, ""); got.Error == "" {
+		t.Error("manifestSourceInput() with failing checksum has no error")
+	}
+}
+
+func Test_newManifest(t *testing.T) {
+	t.Cleanup(func() { regionsCSV, utilizationFile, regionPUE = "", "", nil })
+
+	dir := t.TempDir()
+	regionsCSV = filepath.Join(dir, "regions.csv")
+	utilizationFile = filepath.Join(dir, "utilization.csv")
+	for _, path := range []string{regionsCSV, utilizationFile} {
+		if err := os.WriteFile(path, []byte("data\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	regionPUE = []string{"eu-west-1=1.1"}
+
+	summary := &ReportSummary{LineCount: 10, SkippedCount: 2}
+	rows := []AggregateReportRow{{Cost: 1.5}, {Cost: 2.5}}
+	total := footprint.Result{OperationalGrams: 100, EmbodiedGrams: 20}
+
+	m, err := newManifest([]manifestInput{{Path: "report.csv"}}, summary, rows, total, true)
+	if err != nil {
+		t.Fatalf("newManifest() error = %v", err)
+	}
+	if m.Totals.EmissionGrams != 120 || m.Totals.Cost != 4 {
+		t.Errorf("newManifest() totals = %+v, want 120 g and cost 4", m.Totals)
+	}
+	if m.CreatedAt == nil {
+		t.Error("newManifest() has no creation time")
+	}
+	if m.Usage.Lines != 10 || m.Usage.SkippedLines != 2 || !m.Partial {
+		t.Errorf("newManifest() usage = %+v, partial %v", m.Usage, m.Partial)
+	}
+	if m.Parameters.RegionPUE["eu-west-1"] != 1.1 {
+		t.Errorf("newManifest() region PUE = %v", m.Parameters.RegionPUE)
+	}
+	if m.Parameters.AmortizationYears != footprint.DefaultAmortizationYears {
+		t.Errorf("newManifest() amortization years = %v", m.Parameters.AmortizationYears)
+	}
+	if f := m.Parameters.UtilizationFile; f == nil || f.Path != utilizationFile || f.Checksum == "" {
+		t.Errorf("newManifest() utilization file = %+v", f)
+	}
+
+	for _, d := range m.Datasets {
+		switch d.Name {
+		case "aws-regions.csv":
+			if d.Path != regionsCSV || d.SnapshotDate != "" {
+				t.Errorf("newManifest() replaced dataset = %+v", d)
+			}
+		case "aws-ec2-instances.csv":
+			if d.Path != "" || d.SnapshotDate != footprint.EC2InstancesSnapshotDate || d.License == "" {
+				t.Errorf("newManifest() embedded dataset = %+v", d)
+			}
+		}
+	}
+
+	deterministic = true
+	t.Cleanup(func() { deterministic = false })
+	if m, err := newManifest(nil, summary, rows, total, false); err != nil || m.CreatedAt != nil {
+		t.Errorf("newManifest() with --deterministic = %v, %v, want no creation time", m.CreatedAt, err)
+	}
+
+	utilizationFile = filepath.Join(dir, "missing.csv")
+	if _, err := newManifest(nil, summary, rows, total, false); err == nil {
+		t.Error("newManifest() with missing utilization file returned no error")
+	}
+}
+
+func Test_writeManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	created := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	m := manifest{
+		CreatedAt: &created,
+		Inputs:    []manifestInput{{Path: "report.csv", Checksum: "sha256:abc"}},
+	}
+	if err := writeManifest(path, m); err != nil {
+		t.Fatalf("writeManifest() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if got.CreatedAt == nil || !got.CreatedAt.Equal(created) || len(got.Inputs) != 1 || got.Inputs[0].Checksum != "sha256:abc" {
+		t.Errorf("writeManifest() wrote %+v", got)
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// notifyTopCount is the number of top emitters listed in notifications.
+const notifyTopCount = 5
+
+var (
+	notifySlackWebhook string
+	notifyTeamsWebhook string
+	notifyStateFile    string
+)
+
+func init() {
+	analyseCmd.Flags().StringVar(&notifySlackWebhook, "notify-slack-webhook", "", "URL of a Slack incoming webhook to post a summary of the result to")
+	analyseCmd.Flags().StringVar(&notifyTeamsWebhook, "notify-teams-webhook", "", "URL of a Microsoft Teams incoming webhook to post a summary of the result to")
+	analyseCmd.Flags().StringVar(&notifyStateFile, "notify-state", "", "JSON file keeping the total of the last run, to include the change to it in notifications")
+}
+
+// notifyState is the result of a run kept for the next notification.
+type notifyState struct {
+	EarliestDate  time.Time `json:"earliest_date"`
+	LatestDate    time.Time `json:"latest_date"`
+	EmissionGrams float64   `json:"emission_grams"`
+}
+
+// notification is the summary of a result posted to a chat.
+type notification struct {
+	EarliestDate time.Time
+	LatestDate   time.Time
+	Total        footprint.Result
+	Partial      bool
+
+	// Top holds the rows with the highest emissions, grouped by the
+	// dimensions of the analysis.
+	Top []AggregateReportRow
+
+	// Previous is the result of the last run, if known.
+	Previous *notifyState
+}
+
+// newNotification returns the notification about a result.
+func newNotification(summary *ReportSummary, rows []AggregateReportRow, total footprint.Result, partial bool, previous *notifyState) notification {
+	var top []AggregateReportRow
+	if len(rows) > 0 && len(rows[0].Labels) > 0 {
+		top = groupRows(rows, nil)
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].EmissionGrams > top[j].EmissionGrams
+	})
+	if len(top) > notifyTopCount {
+		top = top[:notifyTopCount]
+	}
+
+	return notification{
+		EarliestDate: summary.EarliestDate,
+		LatestDate:   summary.LatestDate,
+		Total:        total,
+		Partial:      partial,
+		Top:          top,
+		Previous:     previous,
+	}
+}
+
+// text returns the notification as Markdown, with bold text formatted by
+// bold, as chats differ in the Markdown syntax they support.
+func (n notification) text(bold func(s string) string) string {
+	var b strings.Builder
+
+	total := "Total emissions"
+	if n.Partial {
+		total = "Total emissions (partial)"
+	}
+	fmt.Fprintf(&b, "%s: %s (scope 2 %s, scope 3 %s), energy %s\n",
+		bold(total), formatGrams(n.Total.Total()), formatGrams(n.Total.OperationalGrams), formatGrams(n.Total.EmbodiedGrams),
+		formatKiloWattHours(n.Total.EnergyKiloWattHours))
+
+	if n.Previous != nil {
+		fmt.Fprintf(&b, "%s: %s (%s) compared to %s - %s\n",
+			bold("Change"),
+			formatChange(n.Previous.EmissionGrams, n.Total.Total()),
+			formatGramsDelta(n.Total.Total()-n.Previous.EmissionGrams),
+			n.Previous.EarliestDate.Format(dateLayout), n.Previous.LatestDate.Format(dateLayout))
+	}
+
+	if len(n.Top) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", bold(fmt.Sprintf("Top %d emitters", len(n.Top))))
+		for i, row := range n.Top {
+			fmt.Fprintf(&b, "%d. %s: %s\n", i+1, strings.Join(row.Labels, " / "), formatGrams(row.EmissionGrams))
+		}
+	}
+
+	return b.String()
+}
+
+// title returns the headline of the notification.
+func (n notification) title() string {
+	return fmt.Sprintf("Cloud carbon footprint %s - %s", n.EarliestDate.Format(dateLayout), n.LatestDate.Format(dateLayout))
+}
+
+// state returns the result to keep for the next notification.
+func (n notification) state() notifyState {
+	return notifyState{
+		EarliestDate:  n.EarliestDate,
+		LatestDate:    n.LatestDate,
+		EmissionGrams: n.Total.Total(),
+	}
+}
+
+// postSlack posts a notification to a Slack incoming webhook.
+func postSlack(ctx context.Context, client *http.Client, url string, n notification) error {
+	bold := func(s string) string { return "*" + s + "*" }
+	return postWebhook(ctx, client, url, map[string]any{
+		"text": bold(n.title()) + "\n" + n.text(bold),
+	})
+}
+
+// postTeams posts a notification to a Microsoft Teams incoming webhook.
+func postTeams(ctx context.Context, client *http.Client, url string, n notification) error {
+	bold := func(s string) string { return "**" + s + "**" }
+	return postWebhook(ctx, client, url, map[string]any{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  n.title(),
+		"title":    n.title(),
+		// Teams needs two line breaks for a new line.
+		"text": strings.ReplaceAll(n.text(bold), "\n", "\n\n"),
+	})
+}
+
+// postWebhook posts payload as JSON to url.
+func postWebhook(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// readNotifyState reads the result of the last run, or returns nil if there
+// is none yet.
+func readNotifyState(path string) (*notifyState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state notifyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// writeNotifyState keeps the result of this run for the next one.
+func writeNotifyState(path string, state notifyState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// notify posts a summary of the result to the chats given by the flags.
+// Errors are logged, as the result was already written.
+func notify(ctx context.Context, summary *ReportSummary, rows []AggregateReportRow, total footprint.Result, partial bool) {
+	if notifySlackWebhook == "" && notifyTeamsWebhook == "" {
+		return
+	}
+
+	var previous *notifyState
+	if notifyStateFile != "" {
+		var err error
+		previous, err = readNotifyState(notifyStateFile)
+		if err != nil {
+			log.Printf("Warning: could not read the result of the last run: %s", err)
+		}
+	}
+	n := newNotification(summary, rows, total, partial, previous)
+
+	for _, target := range []struct {
+		name, url string
+		post      func(ctx context.Context, client *http.Client, url string, n notification) error
+	}{
+		{"Slack", notifySlackWebhook, postSlack},
+		{"Teams", notifyTeamsWebhook, postTeams},
+	} {
+		if target.url == "" {
+			continue
+		}
+		if err := target.post(ctx, http.DefaultClient, target.url, n); err != nil {
+			log.Printf("Warning: could not notify %s: %s", target.name, err)
+		}
+	}
+
+	if notifyStateFile != "" {
+		if err := writeNotifyState(notifyStateFile, n.state()); err != nil {
+			log.Printf("Warning: could not keep the result for the next run: %s", err)
+		}
+	}
+}
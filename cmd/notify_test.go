@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func testNotification() notification {
+	summary := &ReportSummary{
+		EarliestDate: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+		LatestDate:   time.Date(2022, 8, 31, 0, 0, 0, 0, time.UTC),
+	}
+	var rows []AggregateReportRow
+	for i, region := range []string{"eu-west-1", "us-east-1", "eu-central-1", "ap-south-1", "sa-east-1", "eu-north-1"} {
+		rows = append(rows, AggregateReportRow{Labels: []string{region}, EmissionGrams: float64(i+1) * 1000})
+	}
+	previous := &notifyState{
+		EarliestDate:  time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+		LatestDate:    time.Date(2022, 7, 31, 0, 0, 0, 0, time.UTC),
+		EmissionGrams: 20000,
+	}
+	return newNotification(summary, rows, footprint.Result{OperationalGrams: 21000, EnergyKiloWattHours: 50}, false, previous)
+}
+
+func Test_newNotification(t *testing.T) {
+	n := testNotification()
+
+	if len(n.Top) != notifyTopCount {
+		t.Fatalf("newNotification() has %d top emitters, want %d", len(n.Top), notifyTopCount)
+	}
+	if n.Top[0].Labels[0] != "eu-north-1" || n.Top[4].Labels[0] != "us-east-1" {
+		t.Errorf("newNotification() top emitters = %v", n.Top)
+	}
+}
+
+func Test_notification_text(t *testing.T) {
+	got := testNotification().text(func(s string) string { return "*" + s + "*" })
+
+	for _, want := range []string{
+		"*Total emissions*: 21.0 kgCO2e (scope 2 21.0 kgCO2e, scope 3 0 gCO2e), energy 50.0 kWh",
+		"*Change*: +5.0% (+1000 gCO2e) compared to 2022-07-01 - 2022-07-31",
+		"*Top 5 emitters*",
+		"1. eu-north-1: 6.0 kgCO2e",
+		"5. us-east-1: 2.0 kgCO2e",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("text() is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "eu-west-1") {
+		t.Errorf("text() contains more than %d emitters:\n%s", notifyTopCount, got)
+	}
+}
+
+func Test_postSlack_postTeams(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("could not decode payload: %s", err)
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	n := testNotification()
+	if err := postSlack(context.Background(), server.Client(), server.URL, n); err != nil {
+		t.Fatalf("postSlack() error = %v", err)
+	}
+	if err := postTeams(context.Background(), server.Client(), server.URL, n); err != nil {
+		t.Fatalf("postTeams() error = %v", err)
+	}
+
+	if text, _ := payloads[0]["text"].(string); !strings.HasPrefix(text, "*Cloud carbon footprint 2022-08-01 - 2022-08-31*") {
+		t.Errorf("Slack text = %q", text)
+	}
+	if payloads[1]["@type"] != "MessageCard" || payloads[1]["title"] != "Cloud carbon footprint 2022-08-01 - 2022-08-31" {
+		t.Errorf("Teams payload = %v", payloads[1])
+	}
+	if text, _ := payloads[1]["text"].(string); !strings.Contains(text, "**Total emissions**") {
+		t.Errorf("Teams text = %q", text)
+	}
+}
+
+func Test_postWebhook_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if err := postWebhook(context.Background(), server.Client(), server.URL, map[string]any{}); err == nil {
+		t.Error("postWebhook() returned no error")
+	}
+}
+
+func Test_notifyState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := readNotifyState(path)
+	if err != nil || state != nil {
+		t.Fatalf("readNotifyState() of missing file = %v, %v, want nil", state, err)
+	}
+
+	want := testNotification().state()
+	if err := writeNotifyState(path, want); err != nil {
+		t.Fatalf("writeNotifyState() error = %v", err)
+	}
+	state, err = readNotifyState(path)
+	if err != nil {
+		t.Fatalf("readNotifyState() error = %v", err)
+	}
+	if !state.EarliestDate.Equal(want.EarliestDate) || !state.LatestDate.Equal(want.LatestDate) || state.EmissionGrams != want.EmissionGrams {
+		t.Errorf("readNotifyState() = %+v, want %+v", *state, want)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	rawNumbers         bool
+	thousandsSeparator string
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&rawNumbers, "raw-numbers", false, "Show numbers without rounding and without unit, for piping into other tools: emissions in grams or the --unit, energy in kWh and water in liters")
+	rootCmd.PersistentFlags().StringVar(&thousandsSeparator, "thousands-separator", "", `Separate thousands in numbers by this string, e.g. "," or " ". With ".", the decimal separator is ","`)
+}
+
+// formatNumber formats v with the given number of decimal places, with the
+// thousands separated by --thousands-separator.
+func formatNumber(v float64, precision int) string {
+	s := strconv.FormatFloat(v, 'f', precision, 64)
+	if thousandsSeparator == "" {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(thousandsSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		if thousandsSeparator == "." {
+			b.WriteString(",")
+		} else {
+			b.WriteString(".")
+		}
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// formatRaw formats v as precisely as needed, for --raw-numbers.
+func formatRaw(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// rawHeader returns a table header naming the unit of its column with
+// --raw-numbers, as the cells don't.
+func rawHeader(header, symbol string) string {
+	if !rawNumbers {
+		return header
+	}
+	return header + " (" + symbol + ")"
+}
+
+// emissionSymbol returns the symbol of the unit of emissions shown with
+// --raw-numbers.
+func emissionSymbol() string {
+	if u, exists := emissionUnits[unit]; exists {
+		return u.symbol
+	}
+	return emissionUnits[unitGrams].symbol
+}
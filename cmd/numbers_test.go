@@ -0,0 +1,54 @@
+package cmd
+
+import "testing"
+
+func Test_formatNumber(t *testing.T) {
+	t.Cleanup(func() { thousandsSeparator = "" })
+
+	tests := []struct {
+		separator string
+		v         float64
+		precision int
+		want      string
+	}{
+		{separator: "", v: 1234567.891, precision: 1, want: "1234567.9"},
+		{separator: ",", v: 1234567.891, precision: 1, want: "1,234,567.9"},
+		{separator: ",", v: 123, precision: 0, want: "123"},
+		{separator: ",", v: -1234, precision: 2, want: "-1,234.00"},
+		{separator: " ", v: 100000, precision: 0, want: "100 000"},
+		{separator: ".", v: 1234.5, precision: 2, want: "1.234,50"},
+	}
+
+	for _, tt := range tests {
+		thousandsSeparator = tt.separator
+		if got := formatNumber(tt.v, tt.precision); got != tt.want {
+			t.Errorf("formatNumber(%v, %d) with separator %q = %q, want %q", tt.v, tt.precision, tt.separator, got, tt.want)
+		}
+	}
+}
+
+func Test_rawNumbers(t *testing.T) {
+	t.Cleanup(func() { rawNumbers, unit = false, "" })
+
+	rawNumbers = true
+	if got := formatGrams(1234.5678); got != "1234.5678" {
+		t.Errorf("formatGrams() = %q, want 1234.5678", got)
+	}
+	if got := formatKiloWattHours(0.25); got != "0.25" {
+		t.Errorf("formatKiloWattHours() = %q, want 0.25", got)
+	}
+	if got := formatLiters(2500); got != "2500" {
+		t.Errorf("formatLiters() = %q, want 2500", got)
+	}
+	if got := rawHeader("Energy", "kWh"); got != "Energy (kWh)" {
+		t.Errorf("rawHeader() = %q, want Energy (kWh)", got)
+	}
+
+	unit = unitKilograms
+	if got := formatGrams(1500); got != "1.5" {
+		t.Errorf("formatGrams() with --unit kg = %q, want 1.5", got)
+	}
+	if got := emissionSymbol(); got != "kgCO2e" {
+		t.Errorf("emissionSymbol() = %q, want kgCO2e", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Names OpenCost gives to allocations of idle capacity and to usage without
+// a namespace.
+const (
+	openCostIdle        = "__idle__"
+	openCostUnallocated = "__unallocated__"
+)
+
+// openCostResponse is a response of the OpenCost allocation API, with a map
+// of allocations by name for each step of the window.
+type openCostResponse struct {
+	Code    int                              `json:"code"`
+	Message string                           `json:"message"`
+	Data    []map[string]*openCostAllocation `json:"data"`
+}
+
+// openCostAllocation holds the fields of an OpenCost allocation needed to
+// attribute the usage of nodes.
+type openCostAllocation struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Cluster    string `json:"cluster"`
+		Namespace  string `json:"namespace"`
+		ProviderID string `json:"providerID"`
+	} `json:"properties"`
+	CPUCost float64 `json:"cpuCost"`
+	GPUCost float64 `json:"gpuCost"`
+	RAMCost float64 `json:"ramCost"`
+}
+
+// readOpenCostAllocationFile reads a node mapping from a response of the
+// OpenCost allocation API saved to a file. See readOpenCostAllocation.
+func readOpenCostAllocationFile(path string) (nodeMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readOpenCostAllocation(f)
+}
+
+// readOpenCostAllocation reads a node mapping from a response of the
+// OpenCost allocation API, aggregated by node and namespace. The share of a
+// namespace in a node is the namespace's part of the CPU, GPU and RAM cost
+// of the node, summed over all steps. Idle allocations of a node are not
+// attributed to a namespace. Nodes are identified by the providerID
+// property of the allocations.
+func readOpenCostAllocation(r io.Reader) (nodeMapping, error) {
+	var response openCostResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, fmt.Errorf("could not parse JSON: %w", err)
+	}
+	if response.Code != 0 && response.Code != 200 {
+		return nil, fmt.Errorf("response has code %d: %s", response.Code, response.Message)
+	}
+
+	// Costs per instance ID, and per cluster and namespace of an instance.
+	totals := make(map[string]float64)
+	costs := make(map[string]map[nodeShare]float64)
+
+	for _, step := range response.Data {
+		for _, a := range step {
+			if a == nil {
+				continue
+			}
+			instanceID := nodeInstanceID(a.Properties.ProviderID)
+			if instanceID == "" {
+				continue
+			}
+			cost := a.CPUCost + a.GPUCost + a.RAMCost
+			totals[instanceID] += cost
+			if strings.Contains(a.Name, openCostIdle) {
+				continue
+			}
+
+			key := nodeShare{cluster: a.Properties.Cluster, namespace: a.Properties.Namespace}
+			if key.cluster == "" {
+				return nil, fmt.Errorf("allocation %s has no cluster", a.Name)
+			}
+			if key.namespace == openCostUnallocated {
+				key.namespace = ""
+			}
+			if costs[instanceID] == nil {
+				costs[instanceID] = make(map[nodeShare]float64)
+			}
+			for s := range costs[instanceID] {
+				if s.cluster != key.cluster {
+					return nil, fmt.Errorf("instance %s is allocated to clusters %s and %s", instanceID, s.cluster, key.cluster)
+				}
+			}
+			costs[instanceID][key] += cost
+		}
+	}
+	if len(totals) == 0 {
+		return nil, errors.New("no allocations with a providerID property, aggregate by node")
+	}
+
+	mapping := make(nodeMapping)
+	for instanceID, shares := range costs {
+		total := totals[instanceID]
+		if total <= 0 {
+			continue
+		}
+		for s, cost := range shares {
+			s.share = cost / total
+			mapping[instanceID] = append(mapping[instanceID], s)
+		}
+
+		sorted := mapping[instanceID]
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].namespace < sorted[j].namespace
+		})
+		if sum := sumShares(sorted); sum < 1-1e-9 {
+			mapping[instanceID] = append(sorted, nodeShare{cluster: sorted[0].cluster, share: 1 - sum})
+		}
+	}
+	return mapping, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_readOpenCostAllocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    nodeMapping
+		wantErr string
+	}{
+		{
+			name: "shares with idle capacity",
+			json: `{"code": 200, "data": [{
+				"prod/node-1/web": {"name": "prod/node-1/web", "properties": {"cluster": "prod", "node": "node-1", "namespace": "web", "providerID": "aws:///eu-west-1a/i-1"}, "cpuCost": 4, "ramCost": 2},
+				"prod/node-1/batch": {"name": "prod/node-1/batch", "properties": {"cluster": "prod", "node": "node-1", "namespace": "batch", "providerID": "aws:///eu-west-1a/i-1"}, "cpuCost": 1, "gpuCost": 1},
+				"prod/node-1/__idle__": {"name": "prod/node-1/__idle__", "properties": {"cluster": "prod", "node": "node-1", "providerID": "aws:///eu-west-1a/i-1"}, "cpuCost": 2},
+				"prod/node-2/__unallocated__": {"name": "prod/node-2/__unallocated__", "properties": {"cluster": "prod", "node": "node-2", "namespace": "__unallocated__", "providerID": "i-2"}, "cpuCost": 1}
+			}, {
+				"prod/node-1/web": {"name": "prod/node-1/web", "properties": {"cluster": "prod", "node": "node-1", "namespace": "web", "providerID": "aws:///eu-west-1a/i-1"}, "cpuCost": 2},
+				"__unmounted__": {"name": "__unmounted__", "properties": {"cluster": "prod"}, "pvCost": 3}
+			}]}`,
+			want: nodeMapping{
+				"i-1": {{"prod", "batch", 2.0 / 12}, {"prod", "web", 8.0 / 12}, {"prod", "", 2.0 / 12}},
+				"i-2": {{"prod", "", 1}},
+			},
+		},
+		{name: "error response", json: `{"code": 400, "message": "invalid window"}`, wantErr: "response has code 400: invalid window"},
+		{name: "no provider IDs", json: `{"code": 200, "data": [{"web": {"name": "web", "properties": {"cluster": "prod", "namespace": "web"}, "cpuCost": 1}}]}`, wantErr: "no allocations with a providerID property"},
+		{name: "several clusters", json: `{"data": [{"a": {"name": "a", "properties": {"cluster": "prod", "namespace": "a", "providerID": "i-1"}}, "b": {"name": "b", "properties": {"cluster": "staging", "namespace": "b", "providerID": "i-1"}}}]}`, wantErr: "is allocated to clusters"},
+		{name: "invalid JSON", json: `{"data": [`, wantErr: "could not parse JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readOpenCostAllocation(strings.NewReader(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readOpenCostAllocation() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readOpenCostAllocation() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("readOpenCostAllocation() = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if len(got[id]) != len(want) {
+					t.Fatalf("readOpenCostAllocation()[%s] = %v, want %v", id, got[id], want)
+				}
+				for i := range want {
+					g := got[id][i]
+					if g.cluster != want[i].cluster || g.namespace != want[i].namespace || math.Abs(g.share-want[i].share) > 1e-9 {
+						t.Errorf("readOpenCostAllocation()[%s][%d] = %v, want %v", id, i, g, want[i])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outpostPrefix prefixes the location of an AWS Outposts rack to form the
+// region code its usage is estimated with, e.g. "outpost:Frankfurt DC".
+const outpostPrefix = "outpost:"
+
+var outpostsFile string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&outpostsFile, "outposts", "", "YAML file with the carbon intensity in gCO2e/kWh, PUE and WUE of the sites of AWS Outposts racks, by Outposts location")
+}
+
+// outpostSite holds the data of the site an AWS Outposts rack is installed
+// at, which is used instead of the data of the parent region, as the rack
+// is powered by the site's electricity.
+type outpostSite struct {
+	CarbonIntensity *float64 `yaml:"carbon-intensity"`
+	PUE             float64  `yaml:"pue"`
+	WUE             float64  `yaml:"wue"`
+}
+
+// readOutposts reads a YAML file mapping the locations of AWS Outposts
+// racks, as given by the product/location column of Cost and Usage Reports,
+// to the data of their sites.
+func readOutposts(path string) (map[string]outpostSite, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var outposts map[string]outpostSite
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&outposts); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	for name, site := range outposts {
+		if site.CarbonIntensity == nil {
+			return nil, fmt.Errorf("Outpost %s has no carbon-intensity", name)
+		}
+	}
+	return outposts, nil
+}
+
+// outpostRegion returns the region code the usage of the AWS Outposts rack
+// at a location is estimated with.
+func outpostRegion(location string) string {
+	return outpostPrefix + location
+}
+
+// isOutpostRegion returns whether a region code refers to an AWS Outposts
+// rack, see outpostRegion.
+func isOutpostRegion(code string) bool {
+	return strings.HasPrefix(code, outpostPrefix)
+}
+
+// writeOutposts writes the footprint of the usage in rows on AWS Outposts
+// racks per Outpost, if any. As the racks are powered by the electricity of
+// their sites, their operational emissions are not part of the emissions
+// of the provider's data centers.
+func writeOutposts(w io.Writer, rows []AggregateReportRow) {
+	totals := make(map[string]*AggregateReportRow)
+	for _, row := range rows {
+		if !isOutpostRegion(row.Region) {
+			continue
+		}
+		total, exists := totals[row.Region]
+		if !exists {
+			total = &AggregateReportRow{}
+			totals[row.Region] = total
+		}
+		total.EnergyKiloWattHours += row.EnergyKiloWattHours
+		total.EmbodiedGrams += row.EmbodiedGrams
+		total.EmissionGrams += row.EmissionGrams
+	}
+	if len(totals) == 0 {
+		return
+	}
+
+	regions := make([]string, 0, len(totals))
+	for region := range totals {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	fmt.Fprintln(w, "\nUsage on AWS Outposts, powered by the electricity of their sites:")
+	for _, region := range regions {
+		total := totals[region]
+		fmt.Fprintf(w, "  - %s: %s, %s (scope 2: %s, scope 3: %s)\n",
+			strings.TrimPrefix(region, outpostPrefix),
+			formatKiloWattHours(total.EnergyKiloWattHours),
+			formatGrams(total.EmissionGrams),
+			formatGrams(total.EmissionGrams-total.EmbodiedGrams),
+			formatGrams(total.EmbodiedGrams))
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_readOutposts(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "valid", yaml: "Frankfurt DC:\n  carbon-intensity: 350\n  pue: 1.5\n  wue: 0.4\n"},
+		{name: "no carbon intensity", yaml: "Frankfurt DC:\n  pue: 1.5\n", wantErr: "Outpost Frankfurt DC has no carbon-intensity"},
+		{name: "unknown field", yaml: "Frankfurt DC:\n  carbon-intensity: 350\n  pue: 1.5\n  region: eu-central-1\n", wantErr: "field region not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "outposts.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readOutposts(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readOutposts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readOutposts() error = %v", err)
+			}
+			site := got["Frankfurt DC"]
+			if site.CarbonIntensity == nil || *site.CarbonIntensity != 350 || site.PUE != 1.5 || site.WUE != 0.4 {
+				t.Errorf("readOutposts() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestReportSummary_addOutpost(t *testing.T) {
+	summary := newReportSummary(nil)
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-central-1", InstanceType: "m5.large", Duration: 1, Outpost: "Frankfurt DC"})
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-central-1", InstanceType: "m5.large", Duration: 1})
+
+	if len(summary.Aggregate) != 2 || summary.Aggregate[0].Region != "outpost:Frankfurt DC" || summary.Aggregate[1].Region != "eu-central-1" {
+		t.Errorf("add() aggregated Outpost usage with regional usage: %+v", summary.Aggregate)
+	}
+}
+
+func Test_writeOutposts(t *testing.T) {
+	rows := []AggregateReportRow{
+		{Region: outpostRegion("Frankfurt DC"), EnergyKiloWattHours: 2, EmbodiedGrams: 100, EmissionGrams: 900},
+		{Region: "eu-central-1", EnergyKiloWattHours: 5, EmissionGrams: 1000},
+		{Region: outpostRegion("Frankfurt DC"), EnergyKiloWattHours: 1, EmbodiedGrams: 50, EmissionGrams: 450},
+	}
+
+	var buf bytes.Buffer
+	writeOutposts(&buf, rows)
+	want := "\nUsage on AWS Outposts, powered by the electricity of their sites:\n  - Frankfurt DC: 3.0 kWh, 1.4 kgCO2e (scope 2: 1.2 kgCO2e, scope 3: 150 gCO2e)\n"
+	if buf.String() != want {
+		t.Errorf("writeOutposts() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeOutposts(&buf, rows[1:2])
+	if buf.Len() != 0 {
+		t.Errorf("writeOutposts() without Outposts = %q, want nothing", buf.String())
+	}
+}
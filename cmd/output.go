@@ -0,0 +1,481 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	outputTable    = "table"
+	outputGeoJSON  = "geojson"
+	outputVegaLite = "vega-lite"
+	outputCSV      = "csv"
+	outputJSON     = "json"
+	outputMarkdown = "markdown"
+	outputHTML     = "html"
+
+	// worldMapURL points to the country shapes used as the background
+	// of the Vega-Lite map.
+	worldMapURL = "https://cdn.jsdelivr.net/npm/vega-datasets@v2/data/world-110m.json"
+)
+
+var outputFormats = []string{outputTable, outputGeoJSON, outputVegaLite, outputCSV, outputJSON, outputMarkdown, outputHTML}
+
+// outputFormatDescriptions describe the built-in output formats in the help
+// of the analyse command.
+var outputFormatDescriptions = map[string]string{
+	outputTable:    "a human-readable table (default)",
+	outputGeoJSON:  "a GeoJSON FeatureCollection with one point per region",
+	outputVegaLite: "a Vega-Lite map specification with one bubble per region, sized by emissions and colored by grid carbon intensity",
+	outputCSV:      "the emissions of each group as CSV, split into periods with --timeseries",
+	outputJSON:     "the emissions of each group as a JSON document, split into periods with --timeseries",
+	outputMarkdown: "the table in GitHub Flavored Markdown, e.g. to paste it into an issue",
+	outputHTML:     "a standalone HTML report with the total, a bar chart of the emissions per region and the table",
+}
+
+// RegionEmissions holds the emissions of all usage in one AWS region.
+type RegionEmissions struct {
+	Region          string
+	Location        footprint.Location
+	CarbonIntensity float64
+	UsageHours      float64
+	EmissionGrams   float64
+}
+
+// isValidOutputFormat returns whether format is a built-in output format or
+// one registered with the pipeline package.
+func isValidOutputFormat(format string) bool {
+	for _, f := range allOutputFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// outputFormatsHelp lists the output formats in the help of the analyse
+// command.
+func outputFormatsHelp() string {
+	var items []helpItem
+	for _, format := range allOutputFormats() {
+		description, exists := outputFormatDescriptions[format]
+		if !exists && !slices.Contains(outputFormats, format) {
+			description = "registered by an extension"
+		}
+		items = append(items, helpItem{name: format, description: description})
+	}
+	return helpList(items)
+}
+
+// allOutputFormats returns the built-in output formats, followed by those
+// registered with the pipeline package.
+func allOutputFormats() []string {
+	return append(append([]string(nil), outputFormats...), pipeline.WriterFormats()...)
+}
+
+// analysisResult is the result of an analysis, as output by writeResult.
+type analysisResult struct {
+	summary *ReportSummary
+
+	// rows are the aggregate rows with their footprint, and total the
+	// footprint of all of them.
+	rows  []AggregateReportRow
+	total footprint.Result
+
+	// partial is set if some reports could not be read.
+	partial bool
+}
+
+// outputOptions control how writeResult outputs a result.
+type outputOptions struct {
+	// format is the output format, a built-in one or one registered with
+	// the pipeline package.
+	format string
+
+	// tablePeriod splits the rows of tables into periods, shown with
+	// periodLayout, and seriesPeriod the rows of time series.
+	tablePeriod  periodFunc
+	periodLayout string
+	seriesPeriod periodFunc
+
+	// top limits tables to the groups with the highest emissions, if
+	// positive.
+	top int
+
+	// sortField and descending sort the rows of tables, see sortRows.
+	sortField  string
+	descending bool
+}
+
+// writeResult writes the result of an analysis to w, as the last stage of
+// the pipeline.
+func writeResult(w io.Writer, result analysisResult, options outputOptions) error {
+	dimensions := result.summary.Dimensions
+	tableRows := groupRows(result.rows, options.tablePeriod)
+	groupCount := len(tableRows)
+	totals := newTableTotals(tableRows, result.total)
+	if options.top > 0 {
+		tableRows = topRows(tableRows, options.top)
+	}
+	if options.sortField != "" {
+		tableRows = sortRows(tableRows, options.sortField, options.descending)
+	}
+	table := resultTable(dimensions, tableRows, options.periodLayout, totals, result.partial)
+	if options.top > 0 {
+		table = withShareColumn(table, tableRows, result.total.Total())
+	}
+
+	switch options.format {
+	case outputTable:
+		if options.top > 0 {
+			writeTopHeading(w, tableRows, groupCount, result.total.Total())
+		}
+		writeTableData(w, table)
+	case outputMarkdown:
+		if options.top > 0 {
+			writeTopHeading(w, tableRows, groupCount, result.total.Total())
+		}
+		writeMarkdownTable(w, table)
+	case outputHTML:
+		if err := writeHTML(w, newHTMLReport(result.summary, table, result.rows, result.total)); err != nil {
+			return fmt.Errorf("could not write HTML: %w", err)
+		}
+	case outputGeoJSON:
+		if err := writeGeoJSON(w, result.rows); err != nil {
+			return fmt.Errorf("could not write GeoJSON: %w", err)
+		}
+	case outputVegaLite:
+		if err := writeVegaLite(w, result.rows); err != nil {
+			return fmt.Errorf("could not write Vega-Lite specification: %w", err)
+		}
+	case outputCSV:
+		if err := writeSeriesCSV(w, dimensions, outputSeries(dimensions, result.rows, options.seriesPeriod)); err != nil {
+			return fmt.Errorf("could not write CSV: %w", err)
+		}
+	case outputJSON:
+		if err := writeSeriesJSON(w, dimensions, outputSeries(dimensions, result.rows, options.seriesPeriod)); err != nil {
+			return fmt.Errorf("could not write JSON: %w", err)
+		}
+	default:
+		writer, exists := pipeline.LookupWriter(options.format)
+		if !exists {
+			return fmt.Errorf("unknown output format %q", options.format)
+		}
+		err := writer.Write(w, pipelineResult(dimensions, groupRows(result.rows, options.seriesPeriod), result.total, result.partial))
+		if err != nil {
+			return fmt.Errorf("could not write %s output: %w", options.format, err)
+		}
+	}
+	return nil
+}
+
+// tableData holds the formatted cells of a result table.
+type tableData struct {
+	Header []string
+	Rows   [][]string
+	Footer []string
+}
+
+// tableTotals are the totals in the footer of a result table.
+type tableTotals struct {
+	emissions footprint.Result
+	cost      float64
+
+	// instanceVCPUHours and instanceGrams only cover the rows with
+	// instances, for the emissions per vCPU-hour.
+	instanceVCPUHours float64
+	instanceGrams     float64
+}
+
+// newTableTotals returns the totals of rows, whose footprint is total. The
+// rows must be all rows of a result, also those not shown in a table, so
+// that the cost and emissions in the footer cover the same usage.
+func newTableTotals(rows []AggregateReportRow, total footprint.Result) tableTotals {
+	totals := tableTotals{emissions: total}
+	for _, row := range rows {
+		totals.cost += row.Cost
+		if row.InstanceVCPUHours > 0 {
+			totals.instanceVCPUHours += row.InstanceVCPUHours
+			totals.instanceGrams += row.EmissionGrams
+		}
+	}
+	return totals
+}
+
+// resultTable formats rows as a table with a footer holding the totals,
+// which may cover more rows than shown, see newTableTotals. With a
+// periodLayout, a first column shows the period of each row. If the rows
+// carry billed cost, the cost and the emissions per dollar are shown, too.
+// If they include instances or tasks, the emissions per vCPU-hour are shown.
+func resultTable(dimensions []Dimension, rows []AggregateReportRow, periodLayout string, totals tableTotals, partial bool) tableData {
+	var header []string
+	if periodLayout != "" {
+		header = append(header, "Period")
+	}
+	for _, d := range dimensions {
+		header = append(header, d.Header)
+	}
+
+	withCost := totals.cost != 0
+	withVCPUs := totals.instanceVCPUHours != 0
+
+	emissions := emissionSymbol()
+	metricHeader := []string{"Usage", rawHeader("Energy", "kWh"), rawHeader("Scope 2", emissions), rawHeader("Scope 3", emissions), rawHeader("Emissions", emissions), rawHeader("Water", "L")}
+	if withVCPUs {
+		metricHeader = append(metricHeader, "gCO2e/vCPU-h")
+	}
+	if withCost {
+		metricHeader = append(metricHeader, "Cost", "gCO2e/$")
+	}
+
+	data := tableData{Header: append(append([]string{}, header...), metricHeader...)}
+
+	for _, row := range rows {
+		var cells []string
+		if periodLayout != "" {
+			cells = append(cells, row.Period.Format(periodLayout))
+		}
+		cells = append(append(cells, row.Labels...),
+			formatUsage(row),
+			formatKiloWattHours(row.EnergyKiloWattHours),
+			formatGrams(row.operationalGrams()),
+			formatGrams(row.EmbodiedGrams),
+			formatGrams(row.EmissionGrams),
+			formatLiters(row.WaterLiters),
+		)
+		if withVCPUs {
+			cells = append(cells, formatGramsPerVCPUHour(row.EmissionGrams, row.InstanceVCPUHours))
+		}
+		if withCost {
+			cells = append(cells, formatCost(row.Cost), formatGramsPerDollar(row.EmissionGrams, row.Cost))
+		}
+		data.Rows = append(data.Rows, cells)
+	}
+
+	totalLabel := "Total"
+	if partial {
+		totalLabel = "Total (partial)"
+	}
+
+	total := totals.emissions
+	footer := append(make([]string, len(header)),
+		totalLabel,
+		formatKiloWattHours(total.EnergyKiloWattHours),
+		formatGrams(total.OperationalGrams),
+		formatGrams(total.EmbodiedGrams),
+		formatGrams(total.Total()),
+		formatLiters(total.WaterLiters),
+	)
+	if withVCPUs {
+		footer = append(footer, formatGramsPerVCPUHour(totals.instanceGrams, totals.instanceVCPUHours))
+	}
+	if withCost {
+		footer = append(footer, formatCost(totals.cost), formatGramsPerDollar(total.Total(), totals.cost))
+	}
+	data.Footer = footer
+
+	return data
+}
+
+// writeTable writes all rows of a result as a plain text table, see
+// resultTable.
+func writeTable(w io.Writer, dimensions []Dimension, rows []AggregateReportRow, periodLayout string, total footprint.Result, partial bool) {
+	writeTableData(w, resultTable(dimensions, rows, periodLayout, newTableTotals(rows, total), partial))
+}
+
+// writeTableData writes a table as plain text.
+func writeTableData(w io.Writer, data tableData) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(data.Header)
+	table.AppendBulk(data.Rows)
+	table.SetFooter(data.Footer)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
+
+// writeMarkdownTable writes a table in GitHub Flavored Markdown, with the
+// footer as a last row in bold.
+func writeMarkdownTable(w io.Writer, data tableData) {
+	writeRow := func(cells []string) {
+		fmt.Fprint(w, "|")
+		for _, c := range cells {
+			fmt.Fprintf(w, " %s |", strings.ReplaceAll(c, "|", "\\|"))
+		}
+		fmt.Fprintln(w)
+	}
+
+	writeRow(data.Header)
+	separators := make([]string, len(data.Header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeRow(separators)
+
+	for _, row := range data.Rows {
+		writeRow(row)
+	}
+
+	if len(data.Footer) > 0 {
+		footer := make([]string, len(data.Footer))
+		for i, c := range data.Footer {
+			if c != "" {
+				footer[i] = "**" + c + "**"
+			}
+		}
+		writeRow(footer)
+	}
+}
+
+// emissionsByRegion sums up emissions per region. Regions without a known
+// location are skipped, as they cannot be placed on a map.
+func emissionsByRegion(rows []AggregateReportRow) []RegionEmissions {
+	byRegion := make(map[string]RegionEmissions)
+
+	for _, row := range rows {
+		val, exists := byRegion[row.Region]
+		if !exists {
+			location, err := calculator.RegionLocation(row.Region)
+			if err != nil {
+				log.Printf("Skipping region %s on map: %s", row.Region, err)
+				continue
+			}
+			ci, err := calculator.CarbonIntensity(row.Region)
+			if err != nil {
+				log.Printf("Skipping region %s on map: %s", row.Region, err)
+				continue
+			}
+			val = RegionEmissions{
+				Region:          row.Region,
+				Location:        location,
+				CarbonIntensity: ci,
+			}
+		}
+		val.UsageHours += row.Duration.Hours()
+		val.EmissionGrams += row.EmissionGrams
+		byRegion[row.Region] = val
+	}
+
+	var result []RegionEmissions
+	for _, val := range byRegion {
+		result = append(result, val)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Region < result[j].Region
+	})
+
+	return result
+}
+
+// regionProperties returns the data describing a region on a map.
+func regionProperties(r RegionEmissions) map[string]any {
+	return map[string]any{
+		"region":           r.Region,
+		"latitude":         r.Location.Latitude,
+		"longitude":        r.Location.Longitude,
+		"carbon_intensity": r.CarbonIntensity,
+		"usage_hours":      r.UsageHours,
+		"emissions_grams":  r.EmissionGrams,
+	}
+}
+
+// writeGeoJSON writes per-region emissions as a GeoJSON FeatureCollection
+// of points.
+func writeGeoJSON(w io.Writer, rows []AggregateReportRow) error {
+	features := []map[string]any{}
+	for _, r := range emissionsByRegion(rows) {
+		features = append(features, map[string]any{
+			"type": "Feature",
+			"geometry": map[string]any{
+				"type":        "Point",
+				"coordinates": []float64{r.Location.Longitude, r.Location.Latitude},
+			},
+			"properties": regionProperties(r),
+		})
+	}
+
+	return writeJSON(w, map[string]any{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+}
+
+// writeVegaLite writes a Vega-Lite specification of a world map showing
+// one bubble per region. Bubble size represents emissions, color represents
+// the carbon intensity of the region's electricity grid.
+func writeVegaLite(w io.Writer, rows []AggregateReportRow) error {
+	values := []map[string]any{}
+	for _, r := range emissionsByRegion(rows) {
+		values = append(values, regionProperties(r))
+	}
+
+	spec := map[string]any{
+		"$schema":     "https://vega.github.io/schema/vega-lite/v5.json",
+		"description": "Estimated EC2 emissions per AWS region",
+		"width":       800,
+		"height":      450,
+		"projection":  map[string]any{"type": "equalEarth"},
+		"layer": []map[string]any{
+			{
+				"data": map[string]any{
+					"url":    worldMapURL,
+					"format": map[string]any{"type": "topojson", "feature": "countries"},
+				},
+				"mark": map[string]any{"type": "geoshape", "fill": "#e5e5e5", "stroke": "#ffffff"},
+			},
+			{
+				"data": map[string]any{"values": values},
+				"mark": map[string]any{"type": "circle", "opacity": 0.8, "stroke": "#333333"},
+				"encoding": map[string]any{
+					"longitude": map[string]any{"field": "longitude", "type": "quantitative"},
+					"latitude":  map[string]any{"field": "latitude", "type": "quantitative"},
+					"size": map[string]any{
+						"field": "emissions_grams",
+						"type":  "quantitative",
+						"title": "Emissions (gCO2e)",
+						"scale": map[string]any{"range": []int{50, 3000}},
+					},
+					"color": map[string]any{
+						"field": "carbon_intensity",
+						"type":  "quantitative",
+						"title": "Grid intensity (gCO2e/kWh)",
+						"scale": map[string]any{"scheme": "yelloworangered"},
+					},
+					"tooltip": []map[string]any{
+						{"field": "region", "type": "nominal", "title": "Region"},
+						{"field": "emissions_grams", "type": "quantitative", "title": "Emissions (gCO2e)", "format": ",.0f"},
+						{"field": "carbon_intensity", "type": "quantitative", "title": "Grid intensity (gCO2e/kWh)"},
+						{"field": "usage_hours", "type": "quantitative", "title": "Usage (hours)", "format": ",.0f"},
+					},
+				},
+			},
+		},
+	}
+
+	return writeJSON(w, spec)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("could not encode JSON: %w", err)
+	}
+	return nil
+}
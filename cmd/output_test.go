@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_emissionsByRegion(t *testing.T) {
+	rows := []AggregateReportRow{
+		{Region: "eu-west-1", InstanceType: "t2.micro", Duration: 2 * time.Hour, EmissionGrams: 10},
+		{Region: "eu-west-1", InstanceType: "m5.xlarge", Duration: time.Hour, EmissionGrams: 5},
+		{Region: "eu-central-1", InstanceType: "t2.micro", Duration: time.Hour, EmissionGrams: 3},
+		{Region: "unknown", InstanceType: "t2.micro", Duration: time.Hour, EmissionGrams: 1},
+	}
+
+	got := emissionsByRegion(rows)
+
+	want := []RegionEmissions{
+		{Region: "eu-central-1", UsageHours: 1, EmissionGrams: 3, CarbonIntensity: 338},
+		{Region: "eu-west-1", UsageHours: 3, EmissionGrams: 15, CarbonIntensity: 316},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("emissionsByRegion() returned %d regions, want %d", len(got), len(want))
+	}
+	for i := range want {
+		got[i].Location.Latitude, got[i].Location.Longitude = 0, 0
+		if got[i] != want[i] {
+			t.Errorf("emissionsByRegion()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_writeGeoJSON(t *testing.T) {
+	rows := []AggregateReportRow{
+		{Region: "eu-west-1", InstanceType: "t2.micro", Duration: time.Hour, EmissionGrams: 10},
+	}
+
+	var buf bytes.Buffer
+	if err := writeGeoJSON(&buf, rows); err != nil {
+		t.Fatalf("writeGeoJSON() error = %v", err)
+	}
+
+	var got struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates []float64
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("writeGeoJSON() wrote invalid JSON: %v", err)
+	}
+
+	if got.Type != "FeatureCollection" || len(got.Features) != 1 {
+		t.Fatalf("writeGeoJSON() = %s, want FeatureCollection with one feature", buf.String())
+	}
+	f := got.Features[0]
+	if f.Geometry.Type != "Point" || f.Geometry.Coordinates[0] != -6.26 || f.Geometry.Coordinates[1] != 53.35 {
+		t.Errorf("writeGeoJSON() geometry = %v, want Point at [-6.26 53.35]", f.Geometry)
+	}
+	if f.Properties["emissions_grams"] != 10.0 {
+		t.Errorf("writeGeoJSON() emissions_grams = %v, want 10", f.Properties["emissions_grams"])
+	}
+}
+
+func Test_writeTable_cost(t *testing.T) {
+	dimensions := []Dimension{{Name: "region", Header: "Region"}}
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Duration: time.Hour, EmissionGrams: 50, Cost: 2},
+		{Labels: []string{"eu-north-1"}, Duration: time.Hour, EmissionGrams: 10},
+	}
+
+	var buf bytes.Buffer
+	writeTable(&buf, dimensions, rows, "", footprint.Result{OperationalGrams: 60}, false)
+
+	for _, want := range []string{"COST", "GCO2E/$", "2.00", "25.0", "30.0"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	rows[0].Cost = 0
+	writeTable(&buf, dimensions, rows, "", footprint.Result{OperationalGrams: 60}, false)
+	if strings.Contains(buf.String(), "COST") {
+		t.Errorf("writeTable() shows cost columns without cost:\n%s", buf.String())
+	}
+}
+
+func Test_resultTable_totals(t *testing.T) {
+	dimensions := []Dimension{{Name: "region", Header: "Region"}}
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Duration: time.Hour, EmissionGrams: 50, Cost: 2, InstanceVCPUHours: 10},
+		{Labels: []string{"eu-north-1"}, Duration: time.Hour, EmissionGrams: 10, Cost: 1, InstanceVCPUHours: 10},
+	}
+	totals := newTableTotals(rows, footprint.Result{OperationalGrams: 60})
+
+	// The footer covers all rows, even if only some are shown.
+	got := resultTable(dimensions, rows[:1], "", totals, false)
+
+	if len(got.Rows) != 1 {
+		t.Errorf("rows = %v, want one row", got.Rows)
+	}
+	// gCO2e/vCPU-h, cost and gCO2e/$ of both rows.
+	if want := []string{"3.00", "3.00", "20.0"}; !reflect.DeepEqual(got.Footer[7:], want) {
+		t.Errorf("footer = %v, want %v last", got.Footer, want)
+	}
+}
+
+func Test_writeTable_perVCPU(t *testing.T) {
+	dimensions := []Dimension{{Name: "instance-type", Header: "Instance type"}}
+	rows := []AggregateReportRow{
+		{Labels: []string{"m5.large"}, Duration: 10 * time.Hour, EmissionGrams: 50, InstanceVCPUHours: 20},
+		{Labels: []string{"gp3"}, GBHours: 100, EmissionGrams: 10},
+	}
+
+	var buf bytes.Buffer
+	writeTable(&buf, dimensions, rows, "", footprint.Result{OperationalGrams: 60}, false)
+
+	if !strings.Contains(buf.String(), "GCO2E/VCPU-H") {
+		t.Errorf("writeTable() output is missing the emissions per vCPU-hour:\n%s", buf.String())
+	}
+	// The total only covers the rows with instances.
+	if n := strings.Count(buf.String(), "2.50"); n != 2 {
+		t.Errorf("writeTable() output contains 2.50 %d times, want it for m5.large and the total:\n%s", n, buf.String())
+	}
+
+	buf.Reset()
+	writeTable(&buf, dimensions, rows[1:], "", footprint.Result{OperationalGrams: 10}, false)
+	if strings.Contains(buf.String(), "VCPU") {
+		t.Errorf("writeTable() shows emissions per vCPU-hour without instances:\n%s", buf.String())
+	}
+}
+
+func Test_writeMarkdownTable(t *testing.T) {
+	data := tableData{
+		Header: []string{"Tag", "Emissions"},
+		Rows:   [][]string{{"a|b", "10 gCO2e"}},
+		Footer: []string{"", "10 gCO2e"},
+	}
+
+	var buf bytes.Buffer
+	writeMarkdownTable(&buf, data)
+
+	want := `| Tag | Emissions |
+| --- | --- |
+| a\|b | 10 gCO2e |
+|  | **10 gCO2e** |
+`
+	if buf.String() != want {
+		t.Errorf("writeMarkdownTable() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
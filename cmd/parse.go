@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// parseBatchSize is the number of report lines handed to a worker at once.
+const parseBatchSize = 1024
+
+// recordBatch is a batch of report lines handed to a worker. Batches are
+// recycled, so that their slices are only allocated once.
+type recordBatch struct {
+	records [][]string
+	lines   []int
+	n       int
+}
+
+// recordError is an error in a report line.
+type recordError struct {
+	line int
+	err  error
+}
+
+func (e *recordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+func (e *recordError) Unwrap() error {
+	return e.err
+}
+
+// recordParser parses the fields of a report line into a line item. It
+// returns false if the line is not about usage covered by the model. Other
+// data of the line, like its cost, may be added to summary directly.
+type recordParser func(fields []string, summary *ReportSummary) (cur.LineItem, bool, error)
+
+// parseRecords reads the remaining records from reader and parses them with
+// summary.Workers goroutines. Each runs the line items of its batches
+// through the pipeline into its own copy of summary, see
+// ReportSummary.run. The copies are merged into summary at the end. Lines with invalid dates
+// are skipped if summary.LenientDates is set. On other errors, the error of
+// the earliest line is returned.
+func parseRecords(reader *csv.Reader, summary *ReportSummary, parse recordParser) error {
+	workers := summary.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	reader.ReuseRecord = true
+
+	batches := make(chan *recordBatch, workers)
+	free := make(chan *recordBatch, 2*workers)
+	for i := 0; i < 2*workers; i++ {
+		free <- &recordBatch{}
+	}
+
+	done := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(done) }) }
+
+	summaries := make([]*ReportSummary, workers)
+	errs := make([]*recordError, workers)
+
+	var wg sync.WaitGroup
+	for i := range summaries {
+		summaries[i] = summary.fork()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for b := range batches {
+				if errs[i] == nil {
+					err := summaries[i].run(&batchReader{batch: b, parse: parse, summary: summaries[i]})
+					if err != nil && !errors.As(err, &errs[i]) {
+						errs[i] = &recordError{err: err}
+					}
+					if errs[i] != nil {
+						stop()
+					}
+				}
+				free <- b
+			}
+		}(i)
+	}
+
+	readErr := readBatches(reader, batches, free, done)
+	close(batches)
+	wg.Wait()
+
+	for _, s := range summaries {
+		summary.merge(s)
+	}
+
+	var first *recordError
+	for _, err := range errs {
+		if err != nil && (first == nil || err.line < first.line) {
+			first = err
+		}
+	}
+	if first != nil {
+		return parseErrorf("line %d: %w", first.line, first.err)
+	}
+	return readErr
+}
+
+// batchReader provides the line items of a batch of report lines. Lines
+// with invalid dates are skipped if summary.LenientDates is set, other
+// errors are returned as *recordError.
+type batchReader struct {
+	batch   *recordBatch
+	next    int
+	parse   recordParser
+	summary *ReportSummary
+}
+
+func (r *batchReader) Next() (cur.LineItem, error) {
+	for r.next < r.batch.n {
+		i := r.next
+		r.next++
+		item, ok, err := r.parse(r.batch.records[i], r.summary)
+		if err != nil {
+			if r.summary.skipInvalidDate(r.batch.lines[i], err) {
+				continue
+			}
+			return cur.LineItem{}, &recordError{line: r.batch.lines[i], err: err}
+		}
+		if ok {
+			return item, nil
+		}
+	}
+	return cur.LineItem{}, io.EOF
+}
+
+// readBatches reads records into batches taken from free and sends them to
+// batches, until the end of the input or until done is closed.
+func readBatches(reader *csv.Reader, batches chan<- *recordBatch, free <-chan *recordBatch, done <-chan struct{}) error {
+	for {
+		var b *recordBatch
+		select {
+		case b = <-free:
+		case <-done:
+			return nil
+		}
+
+		var readErr error
+		b.n = 0
+		for b.n < parseBatchSize {
+			record, err := reader.Read()
+			if err == io.EOF {
+				readErr = io.EOF
+				break
+			}
+			if err != nil {
+				readErr = fmt.Errorf("could not read CSV: %w", err)
+				break
+			}
+
+			line, _ := reader.FieldPos(0)
+			if b.n == len(b.records) {
+				b.records = append(b.records, nil)
+				b.lines = append(b.lines, 0)
+			}
+			b.records[b.n] = append(b.records[b.n][:0], record...)
+			b.lines[b.n] = line
+			b.n++
+		}
+
+		if b.n > 0 {
+			select {
+			case batches <- b:
+			case <-done:
+				return nil
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
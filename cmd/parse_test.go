@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+// countRecord parses an hour of usage in the region given by the first
+// field, and fails for the region "bad".
+func countRecord(fields []string, summary *ReportSummary) (cur.LineItem, bool, error) {
+	if fields[0] == "bad" {
+		return cur.LineItem{}, false, errors.New("bad region")
+	}
+	return cur.LineItem{Region: fields[0], Duration: time.Hour}, true, nil
+}
+
+func Test_parseRecords(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 5*parseBatchSize+7; i++ {
+		fmt.Fprintf(&b, "region-%d\n", i%3)
+	}
+
+	for _, workers := range []int{1, 4} {
+		summary := newReportSummary(nil)
+		summary.Workers = workers
+
+		err := parseRecords(csv.NewReader(strings.NewReader(b.String())), summary, countRecord)
+		if err != nil {
+			t.Fatalf("parseRecords() with %d workers error = %v", workers, err)
+		}
+		if summary.LineCount != 5*parseBatchSize+7 {
+			t.Errorf("parseRecords() with %d workers counted %d lines, want %d", workers, summary.LineCount, 5*parseBatchSize+7)
+		}
+
+		var total time.Duration
+		for _, row := range summary.Aggregate {
+			total += row.Duration
+		}
+		if len(summary.Aggregate) != 3 || total != time.Duration(summary.LineCount)*time.Hour {
+			t.Errorf("parseRecords() with %d workers aggregated %d rows, total %s", workers, len(summary.Aggregate), total)
+		}
+	}
+}
+
+func Test_parseRecords_filters(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 2*parseBatchSize+7; i++ {
+		fmt.Fprintf(&b, "region-%d\n", i%3)
+	}
+
+	summary := newReportSummary(nil)
+	summary.Workers = 4
+	summary.Filters = []pipeline.Filter{func(item cur.LineItem) bool { return item.Region != "region-0" }}
+
+	err := parseRecords(csv.NewReader(strings.NewReader(b.String())), summary, countRecord)
+	if err != nil {
+		t.Fatalf("parseRecords() error = %v", err)
+	}
+	skipped := (2*parseBatchSize + 7 + 2) / 3
+	if summary.SkippedCount != skipped || summary.LineCount != 2*parseBatchSize+7-skipped {
+		t.Errorf("parseRecords() counted %d lines and skipped %d, want %d skipped", summary.LineCount, summary.SkippedCount, skipped)
+	}
+	if len(summary.Aggregate) != 2 {
+		t.Errorf("parseRecords() aggregated %d rows, want 2", len(summary.Aggregate))
+	}
+}
+
+func Test_parseRecords_error(t *testing.T) {
+	var b strings.Builder
+	for i := 1; i <= 3*parseBatchSize; i++ {
+		region := "eu-west-1"
+		if i == parseBatchSize+10 || i == 2*parseBatchSize+10 {
+			region = "bad"
+		}
+		fmt.Fprintln(&b, region)
+	}
+
+	summary := newReportSummary(nil)
+	summary.Workers = 4
+
+	err := parseRecords(csv.NewReader(strings.NewReader(b.String())), summary, countRecord)
+
+	want := fmt.Sprintf("line %d: bad region", parseBatchSize+10)
+	if err == nil || err.Error() != want {
+		t.Errorf("parseRecords() error = %v, want %s", err, want)
+	}
+}
+
+func Test_parseRecords_readError(t *testing.T) {
+	summary := newReportSummary(nil)
+
+	err := parseRecords(csv.NewReader(strings.NewReader("eu-west-1\n\"unterminated\n")), summary, countRecord)
+
+	if err == nil || !strings.Contains(err.Error(), "could not read CSV") {
+		t.Errorf("parseRecords() error = %v, want CSV error", err)
+	}
+	if summary.LineCount != 1 {
+		t.Errorf("parseRecords() counted %d lines, want 1", summary.LineCount)
+	}
+}
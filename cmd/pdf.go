@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Kinds of blocks in report markup.
+const (
+	blockTitle     = "title"
+	blockHeading   = "heading"
+	blockParagraph = "paragraph"
+	blockListItem  = "list-item"
+	blockTable     = "table"
+)
+
+// pdfFont is the font family used in PDF documents.
+const pdfFont = "Helvetica"
+
+// markupBlock is a block of report markup, a subset of Markdown.
+type markupBlock struct {
+	Kind string
+
+	// Text is the content of all blocks except tables.
+	Text string
+
+	// Rows holds the cells of tables, with the header in the first row.
+	Rows [][]string
+}
+
+// parseMarkup splits report markup into blocks. Supported are headings of
+// level 1 and 2, paragraphs, list items starting with "-" and tables.
+// Other Markdown syntax is kept as text.
+func parseMarkup(text string) []markupBlock {
+	var blocks []markupBlock
+	var current *markupBlock
+
+	flush := func() {
+		if current != nil {
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "# "):
+			flush()
+			blocks = append(blocks, markupBlock{Kind: blockTitle, Text: strings.TrimSpace(line[2:])})
+		case strings.HasPrefix(line, "## "):
+			flush()
+			blocks = append(blocks, markupBlock{Kind: blockHeading, Text: strings.TrimSpace(line[3:])})
+		case strings.HasPrefix(line, "- "):
+			flush()
+			blocks = append(blocks, markupBlock{Kind: blockListItem, Text: strings.TrimSpace(line[2:])})
+		case strings.HasPrefix(line, "|"):
+			if current == nil || current.Kind != blockTable {
+				flush()
+				current = &markupBlock{Kind: blockTable}
+			}
+			cells := tableCells(line)
+			if !isSeparatorRow(cells) {
+				current.Rows = append(current.Rows, cells)
+			}
+		default:
+			if current == nil || current.Kind != blockParagraph {
+				flush()
+				current = &markupBlock{Kind: blockParagraph, Text: line}
+				continue
+			}
+			current.Text += " " + line
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// tableCells returns the cells of a Markdown table row.
+func tableCells(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	line = strings.ReplaceAll(line, `\|`, "\x00")
+
+	var cells []string
+	for _, cell := range strings.Split(line, "|") {
+		cells = append(cells, strings.ReplaceAll(strings.TrimSpace(cell), "\x00", "|"))
+	}
+	return cells
+}
+
+// isSeparatorRow returns whether cells are the separator between header and
+// body of a Markdown table, e.g. "| --- | :---: |".
+func isSeparatorRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.Trim(cell, ":-") != "" || !strings.Contains(cell, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// isBold returns the text without the markers, and whether it is bold.
+func isBold(text string) (string, bool) {
+	if len(text) > 4 && strings.HasPrefix(text, "**") && strings.HasSuffix(text, "**") {
+		return text[2 : len(text)-2], true
+	}
+	return strings.ReplaceAll(text, "**", ""), false
+}
+
+// writePDF renders blocks of report markup as an A4 PDF document. If logo
+// is set, the image at this path is shown in the top right corner of the
+// first page.
+func writePDF(w io.Writer, blocks []markupBlock, logo string) error {
+	const margin = 20.0
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetCreator("cloud-carbon", true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*margin
+
+	if logo != "" {
+		pdf.ImageOptions(logo, pageWidth-margin-30, margin-10, 30, 0, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+	}
+
+	for _, block := range blocks {
+		pdf.SetTextColor(36, 41, 47)
+
+		switch block.Kind {
+		case blockTitle:
+			text, _ := isBold(block.Text)
+			pdf.SetFont(pdfFont, "B", 20)
+			pdf.MultiCell(contentWidth-35, 10, tr(text), "", "L", false)
+			pdf.Ln(4)
+		case blockHeading:
+			text, _ := isBold(block.Text)
+			pdf.Ln(4)
+			pdf.SetFont(pdfFont, "B", 14)
+			pdf.MultiCell(0, 8, tr(text), "", "L", false)
+			pdf.Ln(1)
+		case blockParagraph:
+			text, _ := isBold(block.Text)
+			pdf.SetFont(pdfFont, "", 10)
+			pdf.MultiCell(0, 5, tr(text), "", "L", false)
+			pdf.Ln(2)
+		case blockListItem:
+			pdf.SetFont(pdfFont, "", 10)
+			pdf.CellFormat(6, 6, tr("•"), "", 0, "L", false, 0, "")
+			writeRichText(pdf, tr, block.Text)
+			pdf.Ln(6)
+		case blockTable:
+			writePDFTable(pdf, tr, block.Rows, contentWidth)
+			pdf.Ln(3)
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// writeRichText writes a line of text with bold parts marked by "**".
+func writeRichText(pdf *fpdf.Fpdf, tr func(string) string, text string) {
+	for i, part := range strings.Split(text, "**") {
+		style := ""
+		if i%2 == 1 {
+			style = "B"
+		}
+		pdf.SetFont(pdfFont, style, 10)
+		pdf.Write(6, tr(part))
+	}
+}
+
+// writePDFTable writes rows as a table with equal column widths and the
+// first row as header. Cells marked as bold are written in bold.
+func writePDFTable(pdf *fpdf.Fpdf, tr func(string) string, rows [][]string, width float64) {
+	if len(rows) == 0 {
+		return
+	}
+
+	columns := len(rows[0])
+	colWidth := width / float64(columns)
+
+	for i, row := range rows {
+		header := i == 0
+		pdf.SetFillColor(246, 248, 250)
+		for c := 0; c < columns; c++ {
+			var cell string
+			if c < len(row) {
+				cell = row[c]
+			}
+			text, bold := isBold(cell)
+
+			style := ""
+			if header || bold {
+				style = "B"
+			}
+			pdf.SetFont(pdfFont, style, 9)
+			pdf.CellFormat(colWidth, 7, tr(text), "B", 0, "L", header, 0, "")
+		}
+		pdf.Ln(7)
+	}
+}
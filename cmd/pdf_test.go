@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_parseMarkup(t *testing.T) {
+	text := `# Title
+
+Time range
+covered.
+
+## Summary
+
+- Total: **10 gCO2e**
+
+| Region | Emissions |
+| --- | ---: |
+| eu-west-1 | 10 gCO2e |
+| a\|b | **Total** |
+`
+
+	got := parseMarkup(text)
+
+	want := []markupBlock{
+		{Kind: blockTitle, Text: "Title"},
+		{Kind: blockParagraph, Text: "Time range covered."},
+		{Kind: blockHeading, Text: "Summary"},
+		{Kind: blockListItem, Text: "Total: **10 gCO2e**"},
+		{Kind: blockTable, Rows: [][]string{
+			{"Region", "Emissions"},
+			{"eu-west-1", "10 gCO2e"},
+			{"a|b", "**Total**"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMarkup() = %v, want %v", got, want)
+	}
+}
+
+func Test_isBold(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     string
+		wantBold bool
+	}{
+		{"**Total**", "Total", true},
+		{"Total", "Total", false},
+		{"a **b** c", "a b c", false},
+		{"****", "", false},
+	}
+
+	for _, tt := range tests {
+		got, bold := isBold(tt.text)
+		if got != tt.want || bold != tt.wantBold {
+			t.Errorf("isBold(%q) = %q, %v, want %q, %v", tt.text, got, bold, tt.want, tt.wantBold)
+		}
+	}
+}
+
+func Test_writePDF(t *testing.T) {
+	blocks := parseMarkup("# Title\n\n- Total: **10 gCO2e**\n\n| A | B |\n| --- | --- |\n| 1 | 2 |\n")
+
+	var buf bytes.Buffer
+	if err := writePDF(&buf, blocks, ""); err != nil {
+		t.Fatalf("writePDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Errorf("writePDF() did not write a PDF document")
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+// registeredDimension returns the dimension registered with the pipeline
+// package under the given name, if any.
+func registeredDimension(name string) (Dimension, bool) {
+	d, exists := pipeline.LookupDimension(name)
+	if !exists {
+		return Dimension{}, false
+	}
+	return Dimension{
+		Name:     d.Name,
+		Header:   d.Header,
+		Value:    func(r ReportRow) string { return d.Value(lineItem(r)) },
+		Redacted: d.Redacted,
+	}, true
+}
+
+// lineItem returns the line item of a report row, for readers of reports
+// other than AWS Cost and Usage Reports and for extensions of the pipeline
+// package. The namespace of rows attributed to Kubernetes namespaces is not
+// included, as line items have none.
+func lineItem(r ReportRow) cur.LineItem {
+	return cur.LineItem{
+		Category:         r.Category,
+		PayerAccountID:   r.PayerAccountID,
+		UsageAccountID:   r.UsageAccountID,
+		Region:           r.Region,
+		AvailabilityZone: r.AvailabilityZone,
+		InstanceType:     r.InstanceType,
+		ResourceID:       r.ResourceID,
+		UsageStartTime:   r.UsageStartTime,
+		UsageEndTime:     r.UsageEndTime,
+		Duration:         r.Duration,
+		MultiAZ:          r.MultiAZ,
+		StorageType:      r.StorageType,
+		GBHours:          r.GBHours,
+		VCPUHours:        r.VCPUHours,
+		TransferGB:       r.TransferGB,
+		Tags:             r.Tags,
+		Cost:             r.Cost,
+		ProductCode:      r.ProductCode,
+		PurchaseOption:   r.PurchaseOption,
+		Cluster:          r.Cluster,
+		NodeGroup:        r.NodeGroup,
+		Outpost:          r.Outpost,
+	}
+}
+
+// pipelineUsage returns the usage of an aggregate row, for emitters
+// registered with the pipeline package.
+func pipelineUsage(row AggregateReportRow) pipeline.Usage {
+	return pipeline.Usage{
+		Category:     row.Category,
+		Region:       row.Region,
+		InstanceType: row.InstanceType,
+		StorageType:  row.StorageType,
+		MultiAZ:      row.MultiAZ,
+		Duration:     row.Duration,
+		GBHours:      row.GBHours,
+		VCPUHours:    row.VCPUHours,
+		TransferGB:   row.TransferGB,
+	}
+}
+
+// pipelineResult returns the result of an analysis, for writers registered
+// with the pipeline package.
+func pipelineResult(dimensions []Dimension, rows []AggregateReportRow, total footprint.Result, partial bool) pipeline.Result {
+	result := pipeline.Result{Total: total, Partial: partial}
+	for _, d := range dimensions {
+		result.Dimensions = append(result.Dimensions, d.Name)
+	}
+	for _, row := range rows {
+		result.Rows = append(result.Rows, pipeline.Row{
+			Labels: row.Labels,
+			Period: row.Period,
+			Cost:   row.Cost,
+			Footprint: footprint.Result{
+				EnergyKiloWattHours: row.EnergyKiloWattHours,
+				OperationalGrams:    row.operationalGrams(),
+				EmbodiedGrams:       row.EmbodiedGrams,
+				WaterLiters:         row.WaterLiters,
+			},
+		})
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+func init() {
+	pipeline.RegisterDimension(pipeline.Dimension{
+		Name:   "test-payer",
+		Header: "Payer",
+		Value:  func(item cur.LineItem) string { return item.PayerAccountID },
+	})
+	pipeline.RegisterEmitter("TestService", pipeline.EmitterFunc(func(c *footprint.Calculator, usage pipeline.Usage) (footprint.Result, error) {
+		return footprint.Result{EnergyKiloWattHours: usage.VCPUHours * 0.01, OperationalGrams: usage.VCPUHours}, nil
+	}))
+	pipeline.RegisterWriter("test-rows", pipeline.WriterFunc(func(w io.Writer, result pipeline.Result) error {
+		_, err := fmt.Fprintln(w, len(result.Rows))
+		return err
+	}))
+}
+
+func Test_isValidOutputFormat_registered(t *testing.T) {
+	for format, want := range map[string]bool{outputTable: true, "test-rows": true, "unknown": false} {
+		if got := isValidOutputFormat(format); got != want {
+			t.Errorf("isValidOutputFormat(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
+
+func Test_parseGroupBy_registered(t *testing.T) {
+	dimensions, err := parseGroupBy("region,test-payer")
+	if err != nil {
+		t.Fatalf("parseGroupBy() error = %v", err)
+	}
+	if got := dimensions[1].Value(ReportRow{PayerAccountID: "111111111111"}); got != "111111111111" {
+		t.Errorf("registered dimension value = %q, want 111111111111", got)
+	}
+	if dimensions[1].Header != "Payer" {
+		t.Errorf("registered dimension header = %q, want Payer", dimensions[1].Header)
+	}
+}
+
+func Test_estimateEmissions_registered(t *testing.T) {
+	got, err := estimateEmissions(AggregateReportRow{Category: "TestService", Region: "eu-west-1", VCPUHours: 200}, footprint.DefaultUtilization)
+	if err != nil {
+		t.Fatalf("estimateEmissions() error = %v", err)
+	}
+	if got.EnergyKiloWattHours != 2 || got.OperationalGrams != 200 {
+		t.Errorf("estimateEmissions() = %+v, want result of registered emitter", got)
+	}
+
+	if _, err := estimateEmissions(AggregateReportRow{Category: "Unknown"}, footprint.DefaultUtilization); err == nil {
+		t.Error("estimateEmissions() of unknown category did not fail")
+	}
+}
+
+func Test_lineItem(t *testing.T) {
+	r := ReportRow{
+		Category:       categoryEC2,
+		UsageAccountID: "222222222222",
+		Region:         "eu-west-1",
+		InstanceType:   "m5.xlarge",
+		UsageStartTime: mustParseDate("2022-08-01T00:00:00Z"),
+		Duration:       time.Hour,
+		Tags:           map[string]string{"team": "platform"},
+		Cluster:        "production",
+		NodeGroup:      "workers",
+		Outpost:        "Frankfurt DC",
+	}
+	if got := reportRow(lineItem(r)); !reflect.DeepEqual(got, r) {
+		t.Errorf("reportRow(lineItem()) = %+v, want %+v", got, r)
+	}
+}
+
+func Test_pipelineResult(t *testing.T) {
+	dimensions := testDimensions(t, "region")
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Cost: 1.5, EnergyKiloWattHours: 2, EmbodiedGrams: 10, EmissionGrams: 110, WaterLiters: 0.5},
+	}
+	total := footprint.Result{EnergyKiloWattHours: 2, OperationalGrams: 100, EmbodiedGrams: 10, WaterLiters: 0.5}
+
+	got := pipelineResult(dimensions, rows, total, true)
+
+	want := pipeline.Result{
+		Dimensions: []string{"region"},
+		Rows: []pipeline.Row{
+			{Labels: []string{"eu-west-1"}, Cost: 1.5, Footprint: total},
+		},
+		Total:   total,
+		Partial: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pipelineResult() = %+v, want %+v", got, want)
+	}
+}
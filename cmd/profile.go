@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var (
+	cpuProfile string
+	memProfile string
+)
+
+func init() {
+	analyseCmd.Flags().StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile of the analysis to this file, for go tool pprof")
+	analyseCmd.Flags().StringVar(&memProfile, "memprofile", "", "Write a memory profile at the end of the analysis to this file, for go tool pprof")
+}
+
+// startProfiles starts writing a CPU profile to cpuPath, if set. The
+// returned function stops it and writes a heap profile to memPath, if set.
+func startProfiles(cpuPath, memPath string) (stop func() error, err error) {
+	var cpu *os.File
+	if cpuPath != "" {
+		cpu, err = os.Create(cpuPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+	}
+
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				return fmt.Errorf("could not write CPU profile: %w", err)
+			}
+		}
+		if memPath != "" {
+			if err := writeHeapProfile(memPath); err != nil {
+				return fmt.Errorf("could not write memory profile: %w", err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// writeHeapProfile writes a heap profile to path. It holds the live objects
+// as of the last garbage collection and all allocations since the start.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_startProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cpuPath := filepath.Join(dir, "cpu.prof")
+	memPath := filepath.Join(dir, "mem.prof")
+
+	stop, err := startProfiles(cpuPath, memPath)
+	if err != nil {
+		t.Fatalf("startProfiles() error = %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+	for _, path := range []string{cpuPath, memPath} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("profile %s not written: %v", filepath.Base(path), err)
+		}
+	}
+
+	if _, err := startProfiles(filepath.Join(dir, "missing", "cpu.prof"), ""); err == nil {
+		t.Error("startProfiles() into missing directory returned no error")
+	}
+
+	stop, err = startProfiles("", filepath.Join(dir, "missing", "mem.prof"))
+	if err != nil {
+		t.Fatalf("startProfiles() without CPU profile error = %v", err)
+	}
+	if err := stop(); err == nil {
+		t.Error("stop() into missing directory returned no error")
+	}
+}
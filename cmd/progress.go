@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is the time between progress messages. Analyses taking
+// less time print no progress at all.
+const progressInterval = 10 * time.Second
+
+// progressReporter periodically prints the progress of an analysis: the
+// number of report bytes read, the number of lines about usage found and an
+// estimate of the remaining time. It is safe for concurrent use.
+type progressReporter struct {
+	w io.Writer
+
+	// total is the size of all reports in bytes, zero if unknown.
+	total int64
+
+	bytes atomic.Int64
+	lines atomic.Int64
+
+	started  time.Time
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newProgressReporter(w io.Writer, total int64) *progressReporter {
+	return &progressReporter{
+		w:     w,
+		total: total,
+		done:  make(chan struct{}),
+	}
+}
+
+// start prints progress every interval until stop is called.
+func (p *progressReporter) start(interval time.Duration) {
+	p.started = time.Now()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintln(p.w, p.status(time.Since(p.started)))
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop ends printing progress.
+func (p *progressReporter) stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+}
+
+// status describes the progress after the given time.
+func (p *progressReporter) status(elapsed time.Duration) string {
+	read := p.bytes.Load()
+	lines := p.lines.Load()
+
+	if p.total <= 0 {
+		return fmt.Sprintf("Progress: read %s, %d lines about usage", formatBytes(read), lines)
+	}
+
+	status := fmt.Sprintf("Progress: read %s of %s (%.0f%%), %d lines about usage", formatBytes(read), formatBytes(p.total), float64(read)/float64(p.total)*100, lines)
+	if read > 0 && read < p.total {
+		remaining := time.Duration(float64(elapsed) * float64(p.total-read) / float64(read))
+		status += fmt.Sprintf(", about %s remaining", remaining.Round(time.Second))
+	}
+	return status
+}
+
+// reader returns a reader counting the bytes read from r.
+func (p *progressReporter) reader(r io.Reader) io.Reader {
+	return &countingReader{r: r, n: &p.bytes}
+}
+
+// addLine counts a line about usage.
+func (p *progressReporter) addLine() {
+	p.lines.Add(1)
+}
+
+// countingReader adds the number of bytes read to n.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// formatBytes formats a number of bytes with a binary unit, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_progressReporter_status(t *testing.T) {
+	p := newProgressReporter(io.Discard, 4*1024*1024)
+	if _, err := io.Copy(io.Discard, p.reader(bytes.NewReader(make([]byte, 1024*1024)))); err != nil {
+		t.Fatal(err)
+	}
+	p.addLine()
+	p.addLine()
+
+	got := p.status(time.Minute)
+
+	want := "Progress: read 1.0 MiB of 4.0 MiB (25%), 2 lines about usage, about 3m0s remaining"
+	if got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+}
+
+func Test_progressReporter_status_unknownTotal(t *testing.T) {
+	p := newProgressReporter(io.Discard, 0)
+	p.addLine()
+
+	got := p.status(time.Minute)
+
+	want := "Progress: read 0 B, 1 lines about usage"
+	if got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func Test_progressReporter_start(t *testing.T) {
+	var out syncBuffer
+	p := newProgressReporter(&out, 0)
+
+	p.start(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	p.stop()
+
+	printed := out.String()
+	if !strings.HasPrefix(printed, "Progress: ") {
+		t.Errorf("start() printed %q, want progress messages", printed)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if out.String() != printed {
+		t.Errorf("progress was printed after stop()")
+	}
+}
+
+func Test_formatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{20 * 1024 * 1024 * 1024, "20.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// hoursPerMonth is the average number of hours in a month.
+const hoursPerMonth = 730
+
+// runningInstance is a virtual machine currently running.
+type runningInstance struct {
+	// Category is categoryEC2, categoryGCE or categoryAzureVM.
+	Category     string
+	Region       string
+	InstanceType string
+}
+
+// ProjectedRow holds the hourly footprint of all running instances of one
+// type in one region.
+type ProjectedRow struct {
+	Category     string
+	Region       string
+	InstanceType string
+	Count        int
+	Hourly       footprint.Result
+}
+
+// projectEmissions estimates the footprint of running the given instances
+// for one hour, per category, region and instance type, assuming the given
+// CPU utilization. Instances that cannot be estimated are logged and
+// skipped. It returns the rows sorted by category, region and instance type,
+// and the total.
+func projectEmissions(instances []runningInstance, utilization float64) ([]ProjectedRow, footprint.Result) {
+	counts := make(map[runningInstance]int)
+	for _, i := range instances {
+		counts[i]++
+	}
+
+	var rows []ProjectedRow
+	var total footprint.Result
+
+	for i, count := range counts {
+		row := AggregateReportRow{
+			Category:     i.Category,
+			Region:       i.Region,
+			InstanceType: i.InstanceType,
+			Duration:     time.Duration(count) * time.Hour,
+		}
+		result, err := estimateEmissions(row, utilization)
+		if err != nil {
+			log.Printf("Error for %d %s instances of type %s in region %s: %s", count, i.Category, i.InstanceType, i.Region, err)
+			continue
+		}
+
+		rows = append(rows, ProjectedRow{
+			Category:     i.Category,
+			Region:       i.Region,
+			InstanceType: i.InstanceType,
+			Count:        count,
+			Hourly:       result,
+		})
+		total = total.Add(result)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.InstanceType < b.InstanceType
+	})
+
+	return rows, total
+}
+
+// writeProjectionTable writes the projected emissions per hour, day and
+// month as a table.
+func writeProjectionTable(w io.Writer, rows []ProjectedRow, total footprint.Result) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Category", "Region", "Instance type", "Instances", "Energy per hour", "Per hour", "Per day", "Per month"})
+
+	var count int
+	for _, row := range rows {
+		count += row.Count
+		table.Append([]string{
+			row.Category,
+			row.Region,
+			row.InstanceType,
+			fmt.Sprintf("%d", row.Count),
+			formatKiloWattHours(row.Hourly.EnergyKiloWattHours),
+			formatGrams(row.Hourly.Total()),
+			formatGrams(row.Hourly.Total() * 24),
+			formatGrams(row.Hourly.Total() * hoursPerMonth),
+		})
+	}
+
+	table.SetFooter([]string{
+		"", "", "Total",
+		fmt.Sprintf("%d", count),
+		formatKiloWattHours(total.EnergyKiloWattHours),
+		formatGrams(total.Total()),
+		formatGrams(total.Total() * 24),
+		formatGrams(total.Total() * hoursPerMonth),
+	})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
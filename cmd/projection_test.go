@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_projectEmissions(t *testing.T) {
+	instances := []runningInstance{
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro"},
+		{Category: categoryEC2, Region: "eu-central-1", InstanceType: "m5.xlarge"},
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro"},
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "unknown"},
+	}
+
+	rows, total := projectEmissions(instances, footprint.DefaultUtilization)
+
+	if len(rows) != 2 {
+		t.Fatalf("projectEmissions() returned %d rows, want 2", len(rows))
+	}
+	if rows[0].Region != "eu-central-1" || rows[0].Count != 1 || rows[1].Region != "eu-west-1" || rows[1].Count != 2 {
+		t.Errorf("projectEmissions() = %+v", rows)
+	}
+
+	want, err := footprint.AWS("eu-west-1", "t3.micro", 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !withinTolerance(rows[1].Hourly.Total(), want.Total(), 1e-9) {
+		t.Errorf("projectEmissions() t3.micro hourly emissions = %v, want %v", rows[1].Hourly.Total(), want.Total())
+	}
+	if !withinTolerance(total.Total(), rows[0].Hourly.Total()+rows[1].Hourly.Total(), 1e-9) {
+		t.Errorf("projectEmissions() total = %v, want sum of rows", total.Total())
+	}
+}
+
+func Test_writeProjectionTable(t *testing.T) {
+	rows := []ProjectedRow{
+		{Category: categoryEC2, Region: "eu-west-1", InstanceType: "t3.micro", Count: 2, Hourly: footprint.Result{EnergyKiloWattHours: 0.01, OperationalGrams: 10}},
+	}
+
+	var buf bytes.Buffer
+	writeProjectionTable(&buf, rows, rows[0].Hourly)
+
+	for _, want := range []string{"t3.micro", "10 gCO2e", "240 gCO2e", "7.3 kgCO2e"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeProjectionTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
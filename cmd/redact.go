@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// redactedPrefix is the prefix of redacted labels, followed by a hash.
+const redactedPrefix = "redacted-"
+
+var (
+	redact    bool
+	redactKey string
+)
+
+func init() {
+	analyseCmd.Flags().BoolVar(&redact, "redact", false, "Replace account IDs, account names, tag values and Kubernetes cluster, namespace and node group names with hashes in all outputs, e.g. to share results with external auditors")
+	analyseCmd.Flags().StringVar(&redactKey, "redact-key", "", "Secret key the hashes of --redact are computed with, so that they stay the same across runs. By default, a random key is used for each run")
+}
+
+// redactor replaces labels with a keyed hash. Equal labels get the same
+// hash, so that aggregates are kept intact, but the labels cannot be
+// recovered without the key, even for short values like account IDs.
+type redactor struct {
+	key []byte
+}
+
+// newRedactor returns a redactor with the given key, or a random one if key
+// is empty.
+func newRedactor(key string) (*redactor, error) {
+	if key != "" {
+		return &redactor{key: []byte(key)}, nil
+	}
+	random := make([]byte, sha256.Size)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	return &redactor{key: random}, nil
+}
+
+// id identifies the key without revealing it.
+func (r *redactor) id() string {
+	sum := sha256.Sum256(r.key)
+	return hex.EncodeToString(sum[:])
+}
+
+// label returns the redacted form of a label. Empty labels and the labels
+// of untagged and unattributed usage are kept, as they reveal nothing.
+func (r *redactor) label(value string) string {
+	if value == "" || value == untaggedLabel || value == unattributedLabel {
+		return value
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return redactedPrefix + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// withRedaction returns dimensions with the labels of redacted dimensions,
+// like accounts, also when showing account names, tags and the Kubernetes
+// dimensions derived from tags and mapping files, replaced with hashes.
+func withRedaction(dimensions []Dimension, r *redactor) []Dimension {
+	result := append([]Dimension{}, dimensions...)
+	for i, d := range result {
+		if !d.Redacted {
+			continue
+		}
+		value := d.Value
+		result[i].Value = func(row ReportRow) string {
+			return r.label(value(row))
+		}
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+func Test_withRedaction(t *testing.T) {
+	dimensions, err := parseGroupBy("account,region,tag:team,cluster,namespace,nodegroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dimensions = withAccountNames(dimensions, map[string]string{"111111111111": "Platform"})
+	r, err := newRedactor("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := withRedaction(dimensions, r)
+
+	row := ReportRow{UsageAccountID: "222222222222", Region: "eu-west-1", Tags: map[string]string{"team": "payments"}, Cluster: "prod", Namespace: "checkout", NodeGroup: "workers"}
+	for i, d := range got {
+		label := d.Value(row)
+		redacted := strings.HasPrefix(label, redactedPrefix)
+		if redacted != (d.Name != "region") {
+			t.Errorf("label of %s = %q, want redacted %v", d.Name, label, !redacted)
+		}
+		if redacted && label != r.label(dimensions[i].Value(row)) {
+			t.Errorf("label of %s = %q, want %q", d.Name, label, r.label(dimensions[i].Value(row)))
+		}
+	}
+
+	named := got[0].Value(ReportRow{UsageAccountID: "111111111111"})
+	if named != r.label("Platform") {
+		t.Errorf("label of named account = %q, want the redacted name %q", named, r.label("Platform"))
+	}
+	if label := got[2].Value(ReportRow{}); label != untaggedLabel {
+		t.Errorf("label of untagged usage = %q, want %q", label, untaggedLabel)
+	}
+	if label := got[3].Value(ReportRow{}); label != unattributedLabel {
+		t.Errorf("label of unattributed usage = %q, want %q", label, unattributedLabel)
+	}
+	if label := dimensions[0].Value(row); label != "222222222222" {
+		t.Error("withRedaction() changed its input")
+	}
+}
+
+func Test_writeResult_redacted(t *testing.T) {
+	header := append(append([]string{}, testReportHeader...), cur.TagColumn("eks:cluster-name"), cur.TagColumn("eks:nodegroup-name"))
+	record := append(testUsageRecord("eu-west-1", "m5.large", "2022-08-01T00:00:00Z"), "prod", "workers")
+	report := strings.Join(header, ",") + "\n" + strings.Join(record, ",") + "\n"
+
+	r, err := newRedactor("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := newReportSummary(withRedaction(testDimensions(t, "account,tag:team,cluster,nodegroup"), r))
+	if err := analyseReport(strings.NewReader(report), summary); err != nil {
+		t.Fatal(err)
+	}
+	rows, total := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+
+	// GeoJSON and Vega-Lite output is by region, and registered writers may
+	// omit labels, so only the built-in formats showing labels are checked
+	// for the redacted ones.
+	for _, format := range allOutputFormats() {
+		var out bytes.Buffer
+		if err := writeResult(&out, analysisResult{summary: summary, rows: rows, total: total}, outputOptions{format: format}); err != nil {
+			t.Fatalf("writeResult(%s) error = %v", format, err)
+		}
+		for _, value := range []string{"222222222222", "platform", "prod", "workers"} {
+			if strings.Contains(out.String(), value) {
+				t.Errorf("writeResult(%s) output contains %q", format, value)
+			}
+		}
+		if slices.Contains(outputFormats, format) && format != outputGeoJSON && format != outputVegaLite && !strings.Contains(out.String(), r.label("prod")) {
+			t.Errorf("writeResult(%s) output is missing the redacted cluster %q", format, r.label("prod"))
+		}
+	}
+}
+
+func Test_redactor_label(t *testing.T) {
+	r, err := newRedactor("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newRedactor("other key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	random, err := newRedactor("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	label := r.label("111111111111")
+	if len(label) != len(redactedPrefix)+12 || strings.Contains(label, "111111111111") {
+		t.Errorf("label() = %q", label)
+	}
+	if r.label("111111111111") != label {
+		t.Error("label() is not stable")
+	}
+	if r.label("222222222222") == label {
+		t.Error("label() is the same for different values")
+	}
+	if other.label("111111111111") == label || random.label("111111111111") == label {
+		t.Error("label() is the same for different keys")
+	}
+	if r.label("") != "" {
+		t.Errorf("label() of an empty value = %q", r.label(""))
+	}
+}
+
+func Test_utilizationTable_withRedaction(t *testing.T) {
+	table, err := readUtilization(strings.NewReader("account,instance_type,utilization\n,m5.large,20\n111111111111,m5.large,35\n"), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRedactor("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redacted := table.withRedaction(r)
+	for account, want := range map[string]float64{r.label("111111111111"): 35, "111111111111": 20, r.label("222222222222"): 20} {
+		if got := redacted.lookup(account, "m5.large"); got != want {
+			t.Errorf("lookup(%q) = %v, want %v", account, got, want)
+		}
+	}
+}
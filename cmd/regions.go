@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var regionsCmd = &cobra.Command{
+	Use:   "regions",
+	Short: "List the known regions of a cloud provider with their carbon intensity",
+	Long: `List the known regions of a cloud provider with their carbon intensity.
+
+For each region, the carbon intensity of the grid and the power usage
+effectiveness (PUE) of the data centers are shown, along with their product,
+the emissions per kilowatt hour consumed by servers. Regions are ranked by
+this product, starting with the greenest region, which helps to decide where
+to place new workloads.
+`,
+	RunE: regions,
+	Args: cobra.NoArgs,
+}
+
+var (
+	regionsOutputFormat string
+	regionsProvider     string
+)
+
+func init() {
+	regionsCmd.Flags().StringVarP(&regionsOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	regionsCmd.Flags().StringVar(&regionsProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider to list the regions of, one of: %s", strings.Join(providers, ", ")))
+}
+
+// RegionInfo holds the data of a region relevant for its emissions.
+type RegionInfo struct {
+	// Rank is the position of the region when ordered by
+	// ServerCarbonIntensity, starting with 1. Regions with the same value
+	// share a rank.
+	Rank            int     `json:"rank"`
+	Region          string  `json:"region"`
+	CarbonIntensity float64 `json:"carbon_intensity"`
+	PUE             float64 `json:"pue"`
+
+	// ServerCarbonIntensity is the carbon intensity multiplied with the
+	// PUE, in grams of CO2e per kilowatt hour consumed by servers.
+	ServerCarbonIntensity float64 `json:"server_carbon_intensity"`
+
+	// EstimatedFrom describes what the data of an AWS region missing from
+	// the region dataset was estimated from.
+	EstimatedFrom string `json:"estimated_from,omitempty"`
+}
+
+func regions(cmd *cobra.Command, args []string) error {
+	if regionsOutputFormat != outputTable && regionsOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", regionsOutputFormat, outputTable, outputJSON)
+	}
+
+	infos, err := regionInfos(regionsProvider)
+	if err != nil {
+		return exitErrorf(exitData, "could not list regions: %w", err)
+	}
+
+	switch regionsOutputFormat {
+	case outputTable:
+		writeRegionsTable(os.Stdout, infos)
+	case outputJSON:
+		err = writeJSON(os.Stdout, infos)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// regionInfos returns the data of all regions of a cloud provider, ranked
+// by their server carbon intensity.
+func regionInfos(provider string) ([]RegionInfo, error) {
+	var codes []string
+	var carbonIntensity, pue func(regionCode string) (float64, error)
+	switch provider {
+	case providerAWS:
+		codes, carbonIntensity, pue = calculator.Regions(), calculator.CarbonIntensity, calculator.PUE
+	case providerGCP:
+		codes, carbonIntensity, pue = calculator.GCPRegions(), calculator.GCPCarbonIntensity, calculator.GCPPUE
+	case providerAzure:
+		codes, carbonIntensity, pue = calculator.AzureRegions(), calculator.AzureCarbonIntensity, calculator.AzurePUE
+	default:
+		return nil, fmt.Errorf("invalid provider %q, must be one of: %s", provider, strings.Join(providers, ", "))
+	}
+
+	infos := make([]RegionInfo, 0, len(codes))
+	for _, code := range codes {
+		ci, err := carbonIntensity(code)
+		if err != nil {
+			return nil, err
+		}
+		p, err := pue(code)
+		if err != nil {
+			return nil, err
+		}
+		info := RegionInfo{
+			Region:                code,
+			CarbonIntensity:       ci,
+			PUE:                   p,
+			ServerCarbonIntensity: ci * p,
+		}
+		if provider == providerAWS {
+			r, err := calculator.Region(code)
+			if err != nil {
+				return nil, err
+			}
+			info.EstimatedFrom = r.EstimatedFrom
+		}
+		infos = append(infos, info)
+	}
+
+	// Codes are sorted, so regions with the same intensity stay in
+	// alphabetical order.
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].ServerCarbonIntensity < infos[j].ServerCarbonIntensity
+	})
+	for i := range infos {
+		if i > 0 && infos[i].ServerCarbonIntensity == infos[i-1].ServerCarbonIntensity {
+			infos[i].Rank = infos[i-1].Rank
+		} else {
+			infos[i].Rank = i + 1
+		}
+	}
+
+	return infos, nil
+}
+
+// writeRegionsTable writes the regions as a table, in the order given.
+func writeRegionsTable(w io.Writer, infos []RegionInfo) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Rank", "Region", "Carbon intensity", "PUE", "Per server kWh"})
+
+	var estimated bool
+	for _, info := range infos {
+		region := info.Region
+		if info.EstimatedFrom != "" {
+			region += " *"
+			estimated = true
+		}
+		table.Append([]string{
+			strconv.Itoa(info.Rank),
+			region,
+			fmt.Sprintf("%.0f gCO2e/kWh", info.CarbonIntensity),
+			fmt.Sprintf("%.2f", info.PUE),
+			fmt.Sprintf("%.0f gCO2e/kWh", info.ServerCarbonIntensity),
+		})
+	}
+
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+
+	if estimated {
+		fmt.Fprintln(w, "\n* Missing from the region dataset, estimated from the national grid.")
+	}
+}
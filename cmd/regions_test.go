@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_regionInfos(t *testing.T) {
+	for _, provider := range providers {
+		t.Run(provider, func(t *testing.T) {
+			infos, err := regionInfos(provider)
+			if err != nil {
+				t.Fatalf("regionInfos() error = %v", err)
+			}
+			if len(infos) == 0 {
+				t.Fatal("regionInfos() returned no regions")
+			}
+			if infos[0].Rank != 1 {
+				t.Errorf("first rank = %d, want 1", infos[0].Rank)
+			}
+
+			for i, info := range infos {
+				if info.ServerCarbonIntensity != info.CarbonIntensity*info.PUE {
+					t.Errorf("%s: server carbon intensity = %v, want %v", info.Region, info.ServerCarbonIntensity, info.CarbonIntensity*info.PUE)
+				}
+				if i == 0 {
+					continue
+				}
+				prev := infos[i-1]
+				if info.ServerCarbonIntensity < prev.ServerCarbonIntensity {
+					t.Errorf("%s is ranked after %s with lower server carbon intensity", info.Region, prev.Region)
+				}
+				if info.ServerCarbonIntensity == prev.ServerCarbonIntensity && info.Rank != prev.Rank {
+					t.Errorf("%s and %s have the same server carbon intensity but ranks %d and %d", prev.Region, info.Region, prev.Rank, info.Rank)
+				}
+				if info.ServerCarbonIntensity > prev.ServerCarbonIntensity && info.Rank != i+1 {
+					t.Errorf("%s: rank = %d, want %d", info.Region, info.Rank, i+1)
+				}
+			}
+		})
+	}
+
+	if _, err := regionInfos("unknown"); err == nil {
+		t.Error("regionInfos() for unknown provider returned no error")
+	}
+}
+
+func Test_writeRegionsTable(t *testing.T) {
+	infos := []RegionInfo{
+		{Rank: 1, Region: "eu-north-1", CarbonIntensity: 8.8, PUE: 1.2, ServerCarbonIntensity: 10.56},
+		{Rank: 2, Region: "eu-west-1", CarbonIntensity: 316, PUE: 1.2, ServerCarbonIntensity: 379.2},
+	}
+
+	var buf bytes.Buffer
+	writeRegionsTable(&buf, infos)
+
+	for _, want := range []string{"RANK", "eu-north-1", "9 gCO2e/kWh", "1.20", "379 gCO2e/kWh"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeRegionsTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Index(buf.String(), "eu-north-1") > strings.Index(buf.String(), "eu-west-1") {
+		t.Errorf("writeRegionsTable() changed the order of regions:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "*") {
+		t.Errorf("writeRegionsTable() marked regions of the dataset as estimated:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	writeRegionsTable(&buf, append(infos, RegionInfo{Rank: 3, Region: "il-central-1", EstimatedFrom: "national grid of Israel"}))
+	if !strings.Contains(buf.String(), "il-central-1 *") || !strings.Contains(buf.String(), "* Missing from the region dataset") {
+		t.Errorf("writeRegionsTable() does not mark estimated regions:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay USAGE EXPECTED",
+	Short: "Verify estimates against previously recorded results",
+	Long: `Verify estimates against previously recorded results.
+
+USAGE is a report in any format accepted by the analyse command, typically a
+small, stable excerpt of a real report. EXPECTED is a JSON file holding the
+results previously computed for it.
+
+The usage is analysed with the current code and embedded datasets, and the
+results are compared to the expected ones. If any emission value deviates by
+more than the relative --tolerance, or if groups are missing or unexpected,
+the differences are printed and the command exits with a non-zero status.
+This allows to validate dataset refreshes and model changes against golden
+reports before a release.
+
+With --record, the current results are written to EXPECTED instead of being
+verified. Use this to create the expectation file, or to update it after an
+intended change.
+`,
+	RunE: replay,
+	Args: configArgs(cobra.ExactArgs(2)),
+}
+
+var (
+	replayRecord    bool
+	replayTolerance float64
+)
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayRecord, "record", false, "Write the current results to EXPECTED instead of verifying them")
+	replayCmd.Flags().Float64Var(&replayTolerance, "tolerance", 0.001, "Maximum accepted relative deviation of emission values, e.g. 0.001 for 0.1%")
+}
+
+// ExpectedResults is the content of a replay expectation file.
+type ExpectedResults struct {
+	Rows       []ExpectedRow `json:"rows"`
+	TotalGrams float64       `json:"total_grams"`
+}
+
+// ExpectedRow holds the recorded result for one aggregate report row.
+type ExpectedRow struct {
+	Category      string  `json:"category"`
+	Region        string  `json:"region"`
+	InstanceType  string  `json:"instance_type,omitempty"`
+	StorageType   string  `json:"storage_type,omitempty"`
+	MultiAZ       bool    `json:"multi_az,omitempty"`
+	DurationHours float64 `json:"duration_hours,omitempty"`
+	GBHours       float64 `json:"gb_hours,omitempty"`
+	VCPUHours     float64 `json:"vcpu_hours,omitempty"`
+	TransferGB    float64 `json:"transfer_gb,omitempty"`
+	EmissionGrams float64 `json:"emission_grams"`
+}
+
+func (r ExpectedRow) key() string {
+	return fmt.Sprintf("%s_%s_%s%s_%t", r.Category, r.Region, r.InstanceType, r.StorageType, r.MultiAZ)
+}
+
+// String identifies the row in messages, e.g. "EC2 eu-west-1 t2.micro".
+func (r ExpectedRow) String() string {
+	parts := []string{r.Category, r.Region}
+	if t := r.InstanceType + r.StorageType; t != "" {
+		parts = append(parts, t)
+	}
+	if r.MultiAZ {
+		parts = append(parts, "Multi-AZ")
+	}
+	return strings.Join(parts, " ")
+}
+
+func replay(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	usagePath, expectedPath := args[0], args[1]
+
+	dimensions, err := parseGroupBy(defaultGroupBy)
+	if err != nil {
+		return usageErrorf("invalid grouping: %w", err)
+	}
+
+	sources, err := resolveSources(cmd.Context(), []string{usagePath})
+	if err != nil {
+		return exitErrorf(exitIO, "could not determine input files: %w", err)
+	}
+
+	summary := newReportSummary(dimensions)
+	for _, src := range sources {
+		fileSummary, err := analyseSource(cmd.Context(), src, analyseReport, dimensions, summaryOptions{})
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "could not process file %s: %w", src.Name, err)
+		}
+		summary.merge(fileSummary)
+	}
+
+	rows, total := computeEmissions(cmd.Context(), summary, defaultEmissionOptions())
+	actual := toExpectedResults(rows, total.Total())
+
+	if replayRecord {
+		f, err := os.Create(expectedPath)
+		if err != nil {
+			return exitErrorf(exitIO, "could not create file: %w", err)
+		}
+		defer f.Close()
+
+		err = writeJSON(f, actual)
+		if err != nil {
+			return exitErrorf(exitIO, "could not write expected results: %w", err)
+		}
+
+		fmt.Printf("Recorded %d rows with a total of %s to %s.\n", len(actual.Rows), formatGrams(actual.TotalGrams), expectedPath)
+		return nil
+	}
+
+	expected, err := readExpectedResults(expectedPath)
+	if err != nil {
+		return exitErrorf(exitParse, "could not read expected results: %w", err)
+	}
+
+	differences := compareResults(expected, actual, replayTolerance)
+	if len(differences) > 0 {
+		fmt.Printf("Replay of %s does not match %s:\n", usagePath, expectedPath)
+		for _, d := range differences {
+			fmt.Printf("  - %s\n", d)
+		}
+		return fmt.Errorf("replay of %s does not match %s", usagePath, expectedPath)
+	}
+
+	fmt.Printf("Replay of %s matches %s: %d rows and total within tolerance of %g.\n", usagePath, expectedPath, len(expected.Rows), replayTolerance)
+	return nil
+}
+
+func toExpectedResults(rows []AggregateReportRow, total float64) *ExpectedResults {
+	results := &ExpectedResults{
+		Rows:       []ExpectedRow{},
+		TotalGrams: total,
+	}
+	for _, row := range rows {
+		results.Rows = append(results.Rows, ExpectedRow{
+			Category:      row.Category,
+			Region:        row.Region,
+			InstanceType:  row.InstanceType,
+			StorageType:   row.StorageType,
+			MultiAZ:       row.MultiAZ,
+			DurationHours: row.Duration.Hours(),
+			GBHours:       row.GBHours,
+			VCPUHours:     row.VCPUHours,
+			TransferGB:    row.TransferGB,
+			EmissionGrams: row.EmissionGrams,
+		})
+	}
+	return results
+}
+
+func readExpectedResults(path string) (*ExpectedResults, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results ExpectedResults
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&results)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	return &results, nil
+}
+
+// compareResults returns a description of each difference between expected
+// and actual results. Numbers are considered equal if their relative
+// deviation does not exceed tolerance.
+func compareResults(expected, actual *ExpectedResults, tolerance float64) []string {
+	var differences []string
+
+	actualRows := make(map[string]ExpectedRow)
+	for _, row := range actual.Rows {
+		actualRows[row.key()] = row
+	}
+
+	seen := make(map[string]bool)
+	for _, want := range expected.Rows {
+		key := want.key()
+		seen[key] = true
+
+		got, exists := actualRows[key]
+		if !exists {
+			differences = append(differences, fmt.Sprintf("%s: missing in results", want))
+			continue
+		}
+		if !withinTolerance(got.DurationHours, want.DurationHours, tolerance) {
+			differences = append(differences, fmt.Sprintf("%s: duration %s, expected %s", want, hoursToDuration(got.DurationHours), hoursToDuration(want.DurationHours)))
+		}
+		if !withinTolerance(got.GBHours, want.GBHours, tolerance) {
+			differences = append(differences, fmt.Sprintf("%s: storage %g GB-h, expected %g GB-h", want, got.GBHours, want.GBHours))
+		}
+		if !withinTolerance(got.VCPUHours, want.VCPUHours, tolerance) {
+			differences = append(differences, fmt.Sprintf("%s: vCPU time %g vCPU-h, expected %g vCPU-h", want, got.VCPUHours, want.VCPUHours))
+		}
+		if !withinTolerance(got.TransferGB, want.TransferGB, tolerance) {
+			differences = append(differences, fmt.Sprintf("%s: data transfer %g GB, expected %g GB", want, got.TransferGB, want.TransferGB))
+		}
+		if !withinTolerance(got.EmissionGrams, want.EmissionGrams, tolerance) {
+			differences = append(differences, fmt.Sprintf("%s: emissions %g g, expected %g g (%+.3f%%)", want, got.EmissionGrams, want.EmissionGrams, relativeDeviation(got.EmissionGrams, want.EmissionGrams)*100))
+		}
+	}
+
+	var unexpected []string
+	for key, got := range actualRows {
+		if !seen[key] {
+			unexpected = append(unexpected, fmt.Sprintf("%s: unexpected in results", got))
+		}
+	}
+	sort.Strings(unexpected)
+	differences = append(differences, unexpected...)
+
+	if !withinTolerance(actual.TotalGrams, expected.TotalGrams, tolerance) {
+		differences = append(differences, fmt.Sprintf("total: emissions %g g, expected %g g (%+.3f%%)", actual.TotalGrams, expected.TotalGrams, relativeDeviation(actual.TotalGrams, expected.TotalGrams)*100))
+	}
+
+	return differences
+}
+
+// relativeDeviation returns the deviation of got from want, relative to want.
+func relativeDeviation(got, want float64) float64 {
+	if want == 0 {
+		if got == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return (got - want) / math.Abs(want)
+}
+
+func withinTolerance(got, want, tolerance float64) bool {
+	return math.Abs(relativeDeviation(got, want)) <= tolerance
+}
+
+func hoursToDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour))
+}
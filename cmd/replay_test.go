@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_compareResults(t *testing.T) {
+	expected := &ExpectedResults{
+		Rows: []ExpectedRow{
+			{Category: "EC2", Region: "eu-west-1", InstanceType: "t2.micro", DurationHours: 24, EmissionGrams: 100},
+			{Category: "EC2", Region: "eu-central-1", InstanceType: "m5.xlarge", DurationHours: 24, EmissionGrams: 300},
+			{Category: "EBS", Region: "eu-west-1", StorageType: "gp3", GBHours: 720, EmissionGrams: 0},
+		},
+		TotalGrams: 400,
+	}
+
+	tests := []struct {
+		name      string
+		actual    *ExpectedResults
+		tolerance float64
+		want      []string
+	}{
+		{
+			name:      "identical",
+			actual:    expected,
+			tolerance: 0,
+			want:      nil,
+		},
+		{
+			name: "within tolerance",
+			actual: &ExpectedResults{
+				Rows: []ExpectedRow{
+					{Category: "EC2", Region: "eu-central-1", InstanceType: "m5.xlarge", DurationHours: 24, EmissionGrams: 300.2},
+					{Category: "EC2", Region: "eu-west-1", InstanceType: "t2.micro", DurationHours: 24, EmissionGrams: 99.95},
+					{Category: "EBS", Region: "eu-west-1", StorageType: "gp3", GBHours: 720.5, EmissionGrams: 0},
+				},
+				TotalGrams: 400.15,
+			},
+			tolerance: 0.001,
+			want:      nil,
+		},
+		{
+			name: "deviations",
+			actual: &ExpectedResults{
+				Rows: []ExpectedRow{
+					{Category: "EC2", Region: "eu-west-1", InstanceType: "t2.micro", DurationHours: 48, EmissionGrams: 110},
+					{Category: "EC2", Region: "us-east-1", InstanceType: "t2.micro", DurationHours: 1, EmissionGrams: 5},
+					{Category: "EBS", Region: "eu-west-1", StorageType: "gp3", GBHours: 1440, EmissionGrams: 0},
+				},
+				TotalGrams: 115,
+			},
+			tolerance: 0.001,
+			want: []string{
+				"EC2 eu-west-1 t2.micro: duration 48h0m0s, expected 24h0m0s",
+				"EC2 eu-west-1 t2.micro: emissions 110 g, expected 100 g (+10.000%)",
+				"EC2 eu-central-1 m5.xlarge: missing in results",
+				"EBS eu-west-1 gp3: storage 1440 GB-h, expected 720 GB-h",
+				"EC2 us-east-1 t2.micro: unexpected in results",
+				"total: emissions 115 g, expected 400 g (-71.250%)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareResults(expected, tt.actual, tt.tolerance)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareResults() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReplayGolden verifies that the current model and datasets reproduce
+// the recorded results for the report in testdata. After an intended change,
+// update the expectations with:
+//
+//	go run . replay --record cmd/testdata/replay-usage.csv cmd/testdata/replay-expected.json
+func TestReplayGolden(t *testing.T) {
+	dimensions, err := parseGroupBy(defaultGroupBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := analyseSource(context.Background(), localSource("testdata/replay-usage.csv"), analyseReport, dimensions, summaryOptions{})
+	if err != nil {
+		t.Fatalf("analyseSource() error = %v", err)
+	}
+
+	expected, err := readExpectedResults("testdata/replay-expected.json")
+	if err != nil {
+		t.Fatalf("readExpectedResults() error = %v", err)
+	}
+
+	rows, total := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+	for _, d := range compareResults(expected, toExpectedResults(rows, total.Total()), 1e-9) {
+		t.Error(d)
+	}
+}
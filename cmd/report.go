@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed templates/report.md.tmpl
+var defaultReportTemplate string
+
+var reportCmd = &cobra.Command{
+	Use:   "report PATH...",
+	Short: "Generate a PDF summary of the emissions in usage reports",
+	Long: `Generate a PDF summary of the emissions in usage reports.
+
+The summary shows the total emissions, the emissions per month with the
+change to the previous month, and the emissions per region and account. It
+is meant for sharing with people who do not run the tool themselves.
+
+The layout is defined by a Go template producing a subset of Markdown:
+headings (# and ##), paragraphs, lists (-), tables and bold text (**). Use
+--template to replace the default template. See templates/report.md.tmpl in
+the source code for the default, and the fields available to templates.
+`,
+	RunE: report,
+	Args: configArgs(cobra.MinimumNArgs(1)),
+}
+
+var (
+	reportLogo     string
+	reportOutput   string
+	reportProvider string
+	reportTemplate string
+	reportTitle    string
+)
+
+func init() {
+	reportCmd.Flags().StringVar(&reportLogo, "logo", "", "Path to a PNG or JPEG image shown in the top right corner of the first page")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "carbon-report.pdf", "Path of the PDF file to write")
+	reportCmd.Flags().StringVar(&reportProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "Path to a template replacing the default layout")
+	reportCmd.Flags().StringVar(&reportTitle, "title", "Cloud carbon footprint", "Title of the report")
+}
+
+// reportData holds the figures available to report templates.
+type reportData struct {
+	Title     string
+	TimeRange string
+	Total     string
+	Energy    string
+
+	// Scope2 and Scope3 split the total by GHG Protocol scope, into the
+	// operational and the embodied emissions.
+	Scope2 string
+	Scope3 string
+
+	// Water is the water consumed on site by the data centers.
+	Water string
+
+	// Months holds the emissions per calendar month, oldest first, with
+	// the change to the previous month.
+	Months []reportItem
+
+	// Regions and Accounts hold the emissions per region and account,
+	// largest first, with their share of the total.
+	Regions  []reportItem
+	Accounts []reportItem
+
+	// IntensityOverrides lists the regions with a carbon intensity
+	// overridden by --intensity-overrides, with the intensity used.
+	IntensityOverrides []string
+
+	// EstimatedRegions lists the regions missing from the region dataset,
+	// with what their carbon intensity was estimated from.
+	EstimatedRegions []string
+
+	// DataSources cites the datasets of the provider used for estimates,
+	// with their source and license.
+	DataSources []string
+
+	// AmortizationYears is the hardware lifetime manufacturing emissions
+	// are spread over.
+	AmortizationYears float64
+}
+
+// reportItem is one line of a breakdown in a report.
+type reportItem struct {
+	Name      string
+	Emissions string
+	Share     string
+	Change    string
+}
+
+func report(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	read, exists := reportReaders[reportProvider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", reportProvider, strings.Join(providers, ", "))
+	}
+
+	text := defaultReportTemplate
+	if reportTemplate != "" {
+		b, err := os.ReadFile(reportTemplate)
+		if err != nil {
+			return exitErrorf(exitParse, "could not read template: %w", err)
+		}
+		text = string(b)
+	}
+	tmpl, err := template.New("report").Parse(text)
+	if err != nil {
+		return exitErrorf(exitParse, "invalid template: %w", err)
+	}
+
+	dimensions, err := parseGroupBy("region," + accountDimension)
+	if err != nil {
+		return usageErrorf("could not determine dimensions: %w", err)
+	}
+
+	summary := newReportSummary(dimensions)
+	for _, path := range args {
+		pathSummary, err := analysePath(cmd.Context(), path, read, dimensions, summaryOptions{period: monthPeriod})
+		if err != nil {
+			return exitErrorf(readErrorCode(err), "could not analyse %s: %w", path, err)
+		}
+		summary.merge(pathSummary)
+	}
+	fmt.Printf("Processed %d lines about usage.\n", summary.LineCount)
+
+	rows, _ := computeEmissions(cmd.Context(), summary, defaultEmissionOptions())
+	data := newReportData(reportTitle, summary, rows)
+	data.DataSources, err = dataSourceNotes(reportProvider)
+	if err != nil {
+		return exitErrorf(exitIO, "could not read datasets: %w", err)
+	}
+
+	var markup bytes.Buffer
+	if err := tmpl.Execute(&markup, data); err != nil {
+		return fmt.Errorf("could not render template: %w", err)
+	}
+
+	f, err := os.Create(reportOutput)
+	if err != nil {
+		return exitErrorf(exitIO, "could not create %s: %w", reportOutput, err)
+	}
+	defer f.Close()
+
+	err = writePDF(f, parseMarkup(markup.String()), reportLogo)
+	if err != nil {
+		return exitErrorf(exitIO, "could not write %s: %w", reportOutput, err)
+	}
+	fmt.Printf("Report written to %s\n", reportOutput)
+	return nil
+}
+
+// newReportData returns the figures of a report on rows, which must be
+// grouped by region and account, in this order, and split by month.
+func newReportData(title string, summary *ReportSummary, rows []AggregateReportRow) reportData {
+	var total, energy, operational, embodied, water float64
+	for _, row := range rows {
+		total += row.EmissionGrams
+		energy += row.EnergyKiloWattHours
+		operational += row.operationalGrams()
+		embodied += row.EmbodiedGrams
+		water += row.WaterLiters
+	}
+
+	data := reportData{
+		Title:     title,
+		TimeRange: fmt.Sprintf("%s - %s", summary.EarliestDate.Format(dateLayout), summary.LatestDate.Format(dateLayout)),
+		Total:     formatGrams(total),
+		Energy:    formatKiloWattHours(energy),
+		Scope2:    formatGrams(operational),
+		Scope3:    formatGrams(embodied),
+		Water:     formatLiters(water),
+		Regions:   shareItems(rows, 0, total),
+		Accounts:  shareItems(rows, 1, total),
+
+		IntensityOverrides: intensityOverrideNotes(rows),
+		EstimatedRegions:   estimatedRegionNotes(rows),
+		AmortizationYears:  calculator.AmortizationYears(),
+	}
+
+	points := timeSeries(nil, withoutLabels(rows), monthPeriod)
+	for i, p := range points {
+		change := "-"
+		if i > 0 {
+			change = formatChange(points[i-1].EmissionGrams, p.EmissionGrams)
+		}
+		data.Months = append(data.Months, reportItem{
+			Name:      p.Period.Format(periodLayouts[periodMonth]),
+			Emissions: formatGrams(p.EmissionGrams),
+			Change:    change,
+		})
+	}
+
+	return data
+}
+
+// dataSourceNotes returns citations of the datasets of a cloud provider,
+// or the files replacing them.
+func dataSourceNotes(provider string) ([]string, error) {
+	datasets, err := datasetInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, d := range datasets {
+		// AWS regions missing from the region dataset are estimated from
+		// the national grid, so the country dataset is cited for AWS too.
+		country := provider == providerAWS && d.Name == "country-intensities.csv"
+		if !strings.HasPrefix(d.Name, provider+"-") && !country {
+			continue
+		}
+		if d.Path != "" {
+			notes = append(notes, fmt.Sprintf("%s: replaced by %s", d.Name, d.Path))
+			continue
+		}
+		note := fmt.Sprintf("%s: %s, %s", d.Name, d.Source, d.License)
+		if d.SnapshotDate != "" {
+			note += ", snapshot of " + d.SnapshotDate
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+// shareItems sums up emissions by the label at index, largest first, with
+// their share of total.
+func shareItems(rows []AggregateReportRow, index int, total float64) []reportItem {
+	byLabel := make(map[string]float64)
+	for _, row := range rows {
+		byLabel[row.Labels[index]] += row.EmissionGrams
+	}
+
+	var labels []string
+	for label := range byLabel {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := byLabel[labels[i]], byLabel[labels[j]]
+		if a != b {
+			return a > b
+		}
+		return labels[i] < labels[j]
+	})
+
+	var items []reportItem
+	for _, label := range labels {
+		share := "-"
+		if total > 0 {
+			share = fmt.Sprintf("%.1f%%", byLabel[label]/total*100)
+		}
+		items = append(items, reportItem{
+			Name:      label,
+			Emissions: formatGrams(byLabel[label]),
+			Share:     share,
+		})
+	}
+	return items
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func Test_newReportData(t *testing.T) {
+	july := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	august := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	summary := newReportSummary(nil)
+	summary.addTimeRange(july, august.AddDate(0, 0, 10))
+
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1", "111"}, Period: july, EmbodiedGrams: 10, EmissionGrams: 40, WaterLiters: 1.5},
+		{Labels: []string{"us-east-1", "111"}, Period: july, EmissionGrams: 60, WaterLiters: 2},
+		{Labels: []string{"us-east-1", "222"}, Period: august, EmissionGrams: 50},
+	}
+
+	got := newReportData("Title", summary, rows)
+
+	if got.Total != "150 gCO2e" {
+		t.Errorf("newReportData().Total = %q, want %q", got.Total, "150 gCO2e")
+	}
+	if got.Scope2 != "140 gCO2e" || got.Scope3 != "10 gCO2e" {
+		t.Errorf("newReportData() scopes = %q, %q, want %q, %q", got.Scope2, got.Scope3, "140 gCO2e", "10 gCO2e")
+	}
+	if got.Water != "3.5 L" {
+		t.Errorf("newReportData().Water = %q, want %q", got.Water, "3.5 L")
+	}
+	if got.TimeRange != "2022-07-01 - 2022-08-11" {
+		t.Errorf("newReportData().TimeRange = %q", got.TimeRange)
+	}
+
+	wantRegions := []reportItem{
+		{Name: "us-east-1", Emissions: "110 gCO2e", Share: "73.3%"},
+		{Name: "eu-west-1", Emissions: "40 gCO2e", Share: "26.7%"},
+	}
+	if len(got.Regions) != len(wantRegions) {
+		t.Fatalf("newReportData().Regions = %v, want %v", got.Regions, wantRegions)
+	}
+	for i := range wantRegions {
+		if got.Regions[i] != wantRegions[i] {
+			t.Errorf("newReportData().Regions[%d] = %v, want %v", i, got.Regions[i], wantRegions[i])
+		}
+	}
+
+	if len(got.Accounts) != 2 || got.Accounts[0].Name != "111" {
+		t.Errorf("newReportData().Accounts = %v", got.Accounts)
+	}
+
+	wantMonths := []reportItem{
+		{Name: "2022-07", Emissions: "100 gCO2e", Change: "-"},
+		{Name: "2022-08", Emissions: "50 gCO2e", Change: "-50.0%"},
+	}
+	if len(got.Months) != len(wantMonths) {
+		t.Fatalf("newReportData().Months = %v, want %v", got.Months, wantMonths)
+	}
+	for i := range wantMonths {
+		if got.Months[i] != wantMonths[i] {
+			t.Errorf("newReportData().Months[%d] = %v, want %v", i, got.Months[i], wantMonths[i])
+		}
+	}
+}
+
+func Test_defaultReportTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("report").Parse(defaultReportTemplate))
+
+	data := reportData{
+		Title:       "Footprint",
+		Regions:     []reportItem{{Name: "eu-west-1", Emissions: "10 gCO2e", Share: "100.0%"}},
+		DataSources: []string{"aws-regions.csv: https://example.com, Apache-2.0"},
+
+		AmortizationYears: 4,
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var found bool
+	for _, b := range parseMarkup(buf.String()) {
+		if b.Kind == blockTable && len(b.Rows) == 2 && b.Rows[1][0] == "eu-west-1" {
+			found = true
+		}
+	}
+	if !found || !strings.HasPrefix(buf.String(), "# Footprint") || !strings.Contains(buf.String(), "- aws-regions.csv: https://example.com") ||
+		!strings.Contains(buf.String(), "hardware lifetime of 4 years") {
+		t.Errorf("default template rendered unexpected markup:\n%s", buf.String())
+	}
+}
+
+func Test_dataSourceNotes(t *testing.T) {
+	t.Cleanup(func() { regionsCSV = "" })
+	t.Setenv(envInstancesCSV, "")
+	t.Setenv(envRegionsCSV, "")
+
+	notes, err := dataSourceNotes(providerGCP)
+	if err != nil {
+		t.Fatalf("dataSourceNotes() error = %v", err)
+	}
+	if len(notes) != 2 || !strings.HasPrefix(notes[0], "gcp-machine-types.csv: https://") || !strings.HasSuffix(notes[0], ", Apache-2.0") {
+		t.Errorf("dataSourceNotes(gcp) = %q", notes)
+	}
+
+	regionsCSV = "testdata/replay-usage.csv"
+	notes, err = dataSourceNotes(providerAWS)
+	if err != nil {
+		t.Fatalf("dataSourceNotes() error = %v", err)
+	}
+	if len(notes) != 5 || !strings.HasSuffix(notes[0], "CC-BY-4.0, snapshot of 2022-08-17") || notes[2] != "aws-regions.csv: replaced by testdata/replay-usage.csv" || !strings.HasPrefix(notes[4], "country-intensities.csv: https://ember-energy.org/") {
+		t.Errorf("dataSourceNotes(aws) = %q", notes)
+	}
+}
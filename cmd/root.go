@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -13,15 +14,63 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Here is Run.")
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return exitErrorf(exitParse, "could not read config: %w", err)
+		}
+		if err := setupLogging(os.Stderr, logFormat); err != nil {
+			return err
+		}
+		if rawNumbers && thousandsSeparator != "" {
+			return usageErrorf("--raw-numbers cannot be combined with --thousands-separator")
+		}
+		if unit != "" && !containsString(unitNames, unit) {
+			return usageErrorf("invalid --unit value %q, must be one of: %s", unit, strings.Join(unitNames, ", "))
+		}
+
+		var err error
+		calculator, err = newCalculator()
+		if err != nil {
+			return exitErrorf(exitData, "could not load datasets: %w", err)
+		}
+		return nil
+	},
+	// Errors are logged by Execute, in the --log-format.
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &exitError{code: exitUsage, err: err}
+	})
+
 	rootCmd.AddCommand(analyseCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(grpcServerCmd)
+	rootCmd.AddCommand(uiCmd)
+	rootCmd.AddCommand(estimateClusterCmd)
+	rootCmd.AddCommand(estimateAWSCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(trendCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(dataCmd)
+	rootCmd.AddCommand(regionsCmd)
+	rootCmd.AddCommand(instancesCmd)
+	rootCmd.AddCommand(estimateCmd)
+	rootCmd.AddCommand(estimateFleetCmd)
+	rootCmd.AddCommand(estimateCAPICmd)
+	rootCmd.AddCommand(estimateCFNCmd)
+	rootCmd.AddCommand(adviseCmd)
 }
 
+// Execute runs the command given by the arguments. If the command fails,
+// the error is logged and the process exits with the code of its kind.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		code := exitCode(err)
+		logError(err, code)
+		os.Exit(code)
 	}
 }
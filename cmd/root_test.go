@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func TestMain(m *testing.M) {
+	// Commands set up the calculator from the flags before running. Tests
+	// use the embedded datasets.
+	var err error
+	calculator, err = footprint.NewCalculator()
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	s3Scheme = "s3://"
+
+	// manifestSuffix is the ending of Cost and Usage Report manifest file names.
+	manifestSuffix = "-Manifest.json"
+)
+
+// billingPeriodPattern matches the name of the folder AWS creates for each
+// billing period of a Cost and Usage Report, e.g. "20220801-20220901".
+var billingPeriodPattern = regexp.MustCompile(`^\d{8}-\d{8}$`)
+
+// s3API is the part of the S3 client used to read reports.
+type s3API interface {
+	s3.ListObjectsV2APIClient
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// ReportManifest is the part of a Cost and Usage Report manifest we use.
+// AWS writes one manifest per billing period, listing the report files of
+// the most recent report version.
+type ReportManifest struct {
+	AssemblyID    string `json:"assemblyId"`
+	BillingPeriod struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"billingPeriod"`
+	ReportKeys []string `json:"reportKeys"`
+}
+
+func isS3URL(path string) bool {
+	return strings.HasPrefix(path, s3Scheme)
+}
+
+// parseS3URL splits an URL of the form s3://BUCKET/PREFIX into bucket and prefix.
+func parseS3URL(url string) (bucket, prefix string, err error) {
+	rest := strings.TrimPrefix(url, s3Scheme)
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %q, expected s3://BUCKET/PREFIX", url)
+	}
+	return bucket, prefix, nil
+}
+
+// s3Sources returns the report sources to analyse for an S3 URL.
+func s3Sources(ctx context.Context, url string) ([]ReportSource, error) {
+	bucket, prefix, err := parseS3URL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newS3Client(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := resolveS3Keys(ctx, client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []ReportSource
+	for _, key := range keys {
+		sources = append(sources, s3Source(client, bucket, key))
+	}
+
+	return sources, nil
+}
+
+// newS3Client creates an S3 client using the default AWS configuration,
+// set up for the region the bucket is located in.
+func newS3Client(ctx context.Context, bucket string) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	region, err := manager.GetBucketRegion(ctx, s3.NewFromConfig(cfg), bucket)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine region of bucket %s: %w", bucket, err)
+	}
+	cfg.Region = region
+
+	return s3.NewFromConfig(cfg), nil
+}
+
+// resolveS3Keys returns the keys of the report files to analyse for a prefix.
+//
+// If the prefix is the key of a manifest, the report files listed in it are
+// returned. If it is the key of a report file, only that file is returned.
+// Otherwise, the report files listed in the manifests of the latest report
+// version of each billing period found under the prefix are returned, see
+// selectManifests, together with the report files of billing periods without
+// a manifest, see mergeManifestReports. If there are no such manifests, all
+// report files found under the prefix are returned.
+func resolveS3Keys(ctx context.Context, client s3API, bucket, prefix string) ([]string, error) {
+	if strings.HasSuffix(prefix, manifestSuffix) {
+		return readManifestKeys(ctx, client, bucket, prefix)
+	}
+	if isReportFileName(path.Base(prefix)) {
+		return []string{prefix}, nil
+	}
+
+	var manifests []reportManifestFile
+	var reports []string
+
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list objects in s3://%s/%s: %w", bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, manifestSuffix) {
+				manifests = append(manifests, reportManifestFile{key: key, modified: aws.ToTime(obj.LastModified)})
+			} else if isReportFileName(path.Base(key)) {
+				reports = append(reports, key)
+			}
+		}
+	}
+
+	selected := selectManifests(manifests)
+	if len(selected) == 0 {
+		if len(reports) == 0 {
+			return nil, fmt.Errorf("no report files found in s3://%s/%s", bucket, prefix)
+		}
+		sort.Strings(reports)
+		return reports, nil
+	}
+
+	var listed []string
+	for _, manifest := range selected {
+		manifestKeys, err := readManifestKeys(ctx, client, bucket, manifest)
+		if err != nil {
+			return nil, err
+		}
+		listed = append(listed, manifestKeys...)
+	}
+
+	sort.Strings(reports)
+	return mergeManifestReports(reports, selected, listed), nil
+}
+
+// isBillingPeriodManifest returns true if key is the manifest AWS places in
+// the folder of a billing period, as opposed to the copies placed in the
+// folder of each report version.
+func isBillingPeriodManifest(key string) bool {
+	return strings.HasSuffix(key, manifestSuffix) && billingPeriodPattern.MatchString(path.Base(path.Dir(key)))
+}
+
+// readManifestKeys reads the manifest at key and returns the report files listed in it.
+func readManifestKeys(ctx context.Context, client s3API, bucket, key string) ([]string, error) {
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get manifest s3://%s/%s: %w", bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	var manifest ReportManifest
+	err = json.NewDecoder(out.Body).Decode(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse manifest s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return manifest.ReportKeys, nil
+}
+
+// s3Source returns the source for a report file stored in S3.
+func s3Source(client s3API, bucket, key string) ReportSource {
+	return ReportSource{
+		Name: s3Scheme + bucket + "/" + key,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			out, err := client.GetObject(ctx, &s3.GetObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not get object: %w", err)
+			}
+			return out.Body, nil
+		},
+		Checksum: func(ctx context.Context) (string, error) {
+			out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return "", fmt.Errorf("could not get object metadata: %w", err)
+			}
+			if aws.ToString(out.ETag) == "" {
+				return "", fmt.Errorf("object has no ETag")
+			}
+			return "etag:" + aws.ToString(out.ETag), nil
+		},
+	}
+}
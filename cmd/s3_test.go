@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeS3 serves objects from memory, keyed by object key. The bucket is
+// ignored. Objects missing from modified have no modification time.
+type fakeS3 struct {
+	objects  map[string]string
+	modified map[string]time.Time
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{}
+	for _, key := range keys {
+		obj := types.Object{Key: aws.String(key)}
+		if modified, exists := f.modified[key]; exists {
+			obj.LastModified = aws.Time(modified)
+		}
+		out.Contents = append(out.Contents, obj)
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	content, exists := f.objects[aws.ToString(params.Key)]
+	if !exists {
+		return nil, errors.New("no such key")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(content))}, nil
+}
+
+func (f *fakeS3) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	content, exists := f.objects[aws.ToString(params.Key)]
+	if !exists {
+		return nil, errors.New("no such key")
+	}
+	return &s3.HeadObjectOutput{ETag: aws.String(fmt.Sprintf("%q", fmt.Sprintf("%x", md5.Sum([]byte(content)))))}, nil
+}
+
+func Test_parseS3URL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantBucket string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{url: "s3://bucket/cur/report/", wantBucket: "bucket", wantPrefix: "cur/report/"},
+		{url: "s3://bucket", wantBucket: "bucket", wantPrefix: ""},
+		{url: "s3:///prefix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			bucket, prefix, err := parseS3URL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseS3URL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if bucket != tt.wantBucket || prefix != tt.wantPrefix {
+				t.Errorf("parseS3URL() = %q, %q, want %q, %q", bucket, prefix, tt.wantBucket, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func Test_resolveS3Keys(t *testing.T) {
+	withManifests := &fakeS3{objects: map[string]string{
+		"cur/report/20220801-20220901/report-Manifest.json":            `{"reportKeys": ["cur/report/20220801-20220901/v2/report-00001.csv.gz", "cur/report/20220801-20220901/v2/report-00002.csv.gz"]}`,
+		"cur/report/20220801-20220901/v1/report-Manifest.json":         `{"reportKeys": ["cur/report/20220801-20220901/v1/report-00001.csv.gz"]}`,
+		"cur/report/20220801-20220901/v1/report-00001.csv.gz":          "",
+		"cur/report/20220801-20220901/v2/report-Manifest.json":         `{"reportKeys": ["cur/report/20220801-20220901/v2/report-00001.csv.gz", "cur/report/20220801-20220901/v2/report-00002.csv.gz"]}`,
+		"cur/report/20220801-20220901/v2/report-00001.csv.gz":          "",
+		"cur/report/20220801-20220901/v2/report-00002.csv.gz":          "",
+		"cur/report/20220901-20221001/report-Manifest.json":            `{"reportKeys": ["cur/report/20220901-20221001/v3/report-00001.csv.gz"]}`,
+		"cur/report/20220901-20221001/v3/report-00001.csv.gz":          "",
+		"cur/report/20220901-20221001/v3/report-Manifest.json":         `{"reportKeys": ["cur/report/20220901-20221001/v3/report-00001.csv.gz"]}`,
+		"cur/report/20220901-20221001/v3/report-RedshiftManifest.json": "{}",
+		"cur/report/20221001-20221101/report-00001.csv.gz":             "",
+	}}
+	assembliesOnly := &fakeS3{
+		objects: map[string]string{
+			"sync/20220801-20220901/v1/report-Manifest.json": `{"reportKeys": ["cur/report/20220801-20220901/v1/report-00001.csv.gz"]}`,
+			"sync/20220801-20220901/v1/report-00001.csv.gz":  "",
+			"sync/20220801-20220901/v2/report-Manifest.json": `{"reportKeys": ["sync/20220801-20220901/v2/report-00001.csv.gz"]}`,
+			"sync/20220801-20220901/v2/report-00001.csv.gz":  "",
+		},
+		modified: map[string]time.Time{
+			"sync/20220801-20220901/v1/report-Manifest.json": time.Date(2022, 9, 2, 0, 0, 0, 0, time.UTC),
+			"sync/20220801-20220901/v2/report-Manifest.json": time.Date(2022, 9, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	withoutManifests := &fakeS3{objects: map[string]string{
+		"exports/b.csv.gz": "",
+		"exports/a.csv":    "",
+		"exports/notes.md": "",
+	}}
+
+	tests := []struct {
+		name    string
+		client  s3API
+		prefix  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "billing period manifests",
+			client: withManifests,
+			prefix: "cur/report/",
+			want: []string{
+				"cur/report/20220801-20220901/v2/report-00001.csv.gz",
+				"cur/report/20220801-20220901/v2/report-00002.csv.gz",
+				"cur/report/20220901-20221001/v3/report-00001.csv.gz",
+				"cur/report/20221001-20221101/report-00001.csv.gz",
+			},
+		},
+		{
+			name:   "single billing period",
+			client: withManifests,
+			prefix: "cur/report/20220901-20221001/",
+			want:   []string{"cur/report/20220901-20221001/v3/report-00001.csv.gz"},
+		},
+		{
+			name:   "manifest key",
+			client: withManifests,
+			prefix: "cur/report/20220801-20220901/v1/report-Manifest.json",
+			want:   []string{"cur/report/20220801-20220901/v1/report-00001.csv.gz"},
+		},
+		{
+			name:   "report file key",
+			client: withManifests,
+			prefix: "cur/report/20220801-20220901/v1/report-00001.csv.gz",
+			want:   []string{"cur/report/20220801-20220901/v1/report-00001.csv.gz"},
+		},
+		{
+			name:   "latest assembly",
+			client: assembliesOnly,
+			prefix: "sync/",
+			want:   []string{"sync/20220801-20220901/v2/report-00001.csv.gz"},
+		},
+		{
+			name:   "no manifests",
+			client: withoutManifests,
+			prefix: "exports/",
+			want:   []string{"exports/a.csv", "exports/b.csv.gz"},
+		},
+		{
+			name:    "nothing found",
+			client:  withoutManifests,
+			prefix:  "other/",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveS3Keys(context.Background(), tt.client, "bucket", tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveS3Keys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("resolveS3Keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_s3Source_Checksum(t *testing.T) {
+	client := &fakeS3{objects: map[string]string{"a.csv": "a", "b.csv": "b"}}
+
+	a, err := s3Source(client, "bucket", "a.csv").Checksum(context.Background())
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+	b, err := s3Source(client, "bucket", "b.csv").Checksum(context.Background())
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+	if a == b || !strings.HasPrefix(a, "etag:") {
+		t.Errorf("Checksum() = %q and %q, want different ETag based values", a, b)
+	}
+
+	if _, err := s3Source(client, "bucket", "missing.csv").Checksum(context.Background()); err == nil {
+		t.Errorf("Checksum() of missing object returned no error")
+	}
+}
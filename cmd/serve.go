@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve PATH...",
+	Short: "Serve the emissions of usage reports via an HTTP API",
+	Long: `Serve the emissions of usage reports via an HTTP API.
+
+The reports given as PATH are analysed once at startup, accepting the same
+paths as the analyse command, including directories and S3 prefixes. With
+--refresh, the paths are analysed again periodically, so that new report
+versions written to an S3 prefix are picked up.
+
+Emissions are served as JSON by the endpoint
+
+  GET /v1/emissions?group_by=region&from=2022-08-01&to=2022-08-07
+
+All query parameters are optional:
+
+  group_by     Comma-separated list of dimensions, as for analyse --group-by.
+               Grouping by tags is not supported.
+  from, to     Only include usage starting in this time range, as for
+               analyse --start and --end.
+  granularity  Split emissions into periods, one of: hour, day, month.
+
+The response has the format of analyse --output json.
+
+For Grafana, the endpoints of the JSON datasource contract are served below
+/grafana/, for the simple JSON datasource plugins. Targets are a metric,
+one of emission_grams, operational_grams, embodied_grams, energy_kwh,
+water_liters, cost and vcpu_hours, optionally grouped by dimensions, e.g.
+
+  emission_grams by region,instance-type
+
+Targets of type table return the totals per group.
+`,
+	RunE: serve,
+	Args: configArgs(cobra.MinimumNArgs(1)),
+}
+
+var (
+	serveAddress  string
+	serveProvider string
+	serveRefresh  time.Duration
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddress, "listen", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+	serveCmd.Flags().DurationVar(&serveRefresh, "refresh", 0, "Interval for analysing the reports again, e.g. 1h. Disabled by default")
+}
+
+// emissionsServer serves the emissions of hourly aggregate rows, grouped by
+// all dimensions except tags, so that requests can group and filter them
+// freely.
+type emissionsServer struct {
+	mu   sync.RWMutex
+	rows []AggregateReportRow
+}
+
+// serveDimensions are the dimensions the served rows are grouped by.
+var serveDimensions = availableDimensions
+
+func serve(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	read, exists := reportReaders[serveProvider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", serveProvider, strings.Join(providers, ", "))
+	}
+
+	server, err := newEmissionsServer(cmd.Context(), args, read, serveRefresh)
+	if err != nil {
+		return exitErrorf(readErrorCode(err), "could not analyse reports: %w", err)
+	}
+
+	log.Printf("Serving emissions of %d aggregate rows on %s", server.len(), serveAddress)
+	err = http.ListenAndServe(serveAddress, server.handler())
+	return exitErrorf(exitIO, "could not serve on %s: %w", serveAddress, err)
+}
+
+// newEmissionsServer returns a server of the emissions of the reports found
+// at paths. With a refresh interval, the reports are analysed again
+// periodically, keeping the previous emissions on errors.
+func newEmissionsServer(ctx context.Context, paths []string, read reportReader, refresh time.Duration) (*emissionsServer, error) {
+	rows, err := loadEmissions(ctx, paths, read)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &emissionsServer{}
+	server.set(rows)
+
+	if refresh > 0 {
+		go func() {
+			for range time.Tick(refresh) {
+				rows, err := loadEmissions(ctx, paths, read)
+				if err != nil {
+					log.Printf("Could not refresh emissions, keeping previous results: %s", err)
+					continue
+				}
+				server.set(rows)
+			}
+		}()
+	}
+	return server, nil
+}
+
+// loadEmissions analyses the reports found at paths and returns the hourly
+// emissions per combination of serveDimensions.
+func loadEmissions(ctx context.Context, paths []string, read reportReader) ([]AggregateReportRow, error) {
+	sources, err := resolveSources(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine input files: %w", err)
+	}
+
+	summary := newReportSummary(serveDimensions)
+	for _, src := range sources {
+		fileSummary, err := analyseSource(ctx, src, read, serveDimensions, summaryOptions{period: hourPeriod})
+		if err != nil {
+			return nil, fmt.Errorf("could not process file %s: %w", src.Name, err)
+		}
+		summary.merge(fileSummary)
+	}
+	log.Printf("Analysed %d lines about usage from %d files", summary.LineCount, len(sources))
+
+	rows, _ := computeEmissions(ctx, summary, defaultEmissionOptions())
+	return rows, nil
+}
+
+func (s *emissionsServer) set(rows []AggregateReportRow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows = rows
+}
+
+// len returns the number of aggregate rows served.
+func (s *emissionsServer) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.rows)
+}
+
+func (s *emissionsServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/emissions", s.handleEmissions)
+	s.registerGrafana(mux)
+	return mux
+}
+
+func (s *emissionsServer) handleEmissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = defaultGroupBy
+	}
+	dimensions, err := parseGroupBy(groupBy)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid group_by: %s", err), http.StatusBadRequest)
+		return
+	}
+	indexes, err := dimensionIndexes(dimensions)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid group_by: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	if value := query.Get("from"); value != "" {
+		from, err = parseRangeTime(value, false)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid from: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		to, err = parseRangeTime(value, true)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid to: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var period periodFunc
+	if value := query.Get("granularity"); value != "" {
+		var exists bool
+		period, exists = periods[value]
+		if !exists {
+			http.Error(w, fmt.Sprintf("invalid granularity %q, must be one of: %s", value, strings.Join(periodNames, ", ")), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.RLock()
+	rows := selectRows(s.rows, indexes, from, to)
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	err = writeSeriesJSON(w, dimensions, timeSeries(dimensions, rows, period))
+	if err != nil {
+		log.Printf("Could not write response: %s", err)
+	}
+}
+
+// dimensionIndexes returns the position of each dimension in
+// serveDimensions.
+func dimensionIndexes(dimensions []Dimension) ([]int, error) {
+	var indexes []int
+	for _, d := range dimensions {
+		index := -1
+		for i, available := range serveDimensions {
+			if available.Name == d.Name {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("dimension %q is not supported", d.Name)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// selectRows returns the rows of periods starting in the time range from
+// from to to, with the labels at the given indexes only. A zero from or to
+// leaves the range open on that side.
+func selectRows(rows []AggregateReportRow, indexes []int, from, to time.Time) []AggregateReportRow {
+	var result []AggregateReportRow
+	for _, row := range rows {
+		if !from.IsZero() && row.Period.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !row.Period.Before(to) {
+			continue
+		}
+
+		labels := make([]string, len(indexes))
+		for i, index := range indexes {
+			labels[i] = row.Labels[index]
+		}
+		row.Labels = labels
+		result = append(result, row)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_emissionsServer(t *testing.T) {
+	rows, err := loadEmissions(context.Background(), []string{"testdata/replay-usage.csv"}, analyseReport)
+	if err != nil {
+		t.Fatalf("loadEmissions() error = %v", err)
+	}
+	server := &emissionsServer{}
+	server.set(rows)
+
+	var total float64
+	for _, row := range rows {
+		total += row.EmissionGrams
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPoints int
+		wantTotal  float64
+	}{
+		{name: "default grouping", query: "", wantStatus: http.StatusOK, wantPoints: 14, wantTotal: total},
+		{name: "by region", query: "group_by=region", wantStatus: http.StatusOK, wantPoints: 4, wantTotal: total},
+		{name: "hourly", query: "group_by=region&granularity=hour", wantStatus: http.StatusOK, wantPoints: 24, wantTotal: total},
+		{name: "time range", query: "group_by=region&from=2022-08-01T02:00:00Z&to=2022-08-01T04:00:00Z&granularity=hour", wantStatus: http.StatusOK, wantPoints: 8},
+		{name: "empty time range", query: "from=2022-09-01", wantStatus: http.StatusOK, wantPoints: 0},
+		{name: "unknown dimension", query: "group_by=color", wantStatus: http.StatusBadRequest},
+		{name: "tag dimension", query: "group_by=tag:team", wantStatus: http.StatusBadRequest},
+		{name: "invalid from", query: "from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "invalid granularity", query: "granularity=week", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/emissions?"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Series []SeriesPoint `json:"series"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON response: %v", err)
+			}
+			if len(body.Series) != tt.wantPoints {
+				t.Errorf("got %d points, want %d", len(body.Series), tt.wantPoints)
+			}
+			if tt.wantTotal != 0 {
+				var sum float64
+				for _, p := range body.Series {
+					sum += p.EmissionGrams
+				}
+				if !withinTolerance(sum, tt.wantTotal, 1e-9) {
+					t.Errorf("emissions sum up to %v, want %v", sum, tt.wantTotal)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Sinks that results can be pushed to.
+const (
+	sinkInfluxDB   = "influxdb"
+	sinkPrometheus = "prometheus"
+)
+
+var sinks = []string{sinkInfluxDB, sinkPrometheus}
+
+// sinkMeasurement is the name of the InfluxDB measurement, and the prefix of
+// the Prometheus metric names.
+const sinkMeasurement = "cloud_carbon"
+
+var (
+	sinkName  string
+	sinkURL   string
+	sinkToken string
+)
+
+func init() {
+	analyseCmd.Flags().StringVar(&sinkName, "sink", "", fmt.Sprintf("Time series database to push the emissions per group to, one of: %s", strings.Join(sinks, ", ")))
+	analyseCmd.Flags().StringVar(&sinkURL, "sink-url", "", "Write URL of the --sink, e.g. http://localhost:8086/api/v2/write?org=ORG&bucket=BUCKET for InfluxDB 2 or http://localhost:9090/api/v1/write for Prometheus")
+	analyseCmd.Flags().StringVar(&sinkToken, "sink-token", "", "Token authenticating with the --sink, sent as InfluxDB token or bearer token")
+}
+
+// sinkPoint is the footprint of a group of usage at a point in time.
+type sinkPoint struct {
+	// Labels maps the names of the grouping dimensions to their values.
+	Labels map[string]string
+	Time   time.Time
+
+	EnergyKiloWattHours float64
+	OperationalGrams    float64
+	EmbodiedGrams       float64
+	EmissionGrams       float64
+}
+
+// sinkFields returns the metrics of a point, sorted by name.
+func (p sinkPoint) sinkFields() []struct {
+	name  string
+	value float64
+} {
+	return []struct {
+		name  string
+		value float64
+	}{
+		{"embodied_grams", p.EmbodiedGrams},
+		{"emission_grams", p.EmissionGrams},
+		{"energy_kwh", p.EnergyKiloWattHours},
+		{"operational_grams", p.OperationalGrams},
+	}
+}
+
+// sortedLabelNames returns the label names of a point in ascending order.
+func (p sinkPoint) sortedLabelNames() []string {
+	names := make([]string, 0, len(p.Labels))
+	for name := range p.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sinkPoints returns the points to push for the rows of a result. Rows of
+// a period are stored at the start of the period, all others at the end of
+// the time range covered, given by latest.
+func sinkPoints(dimensions []Dimension, rows []AggregateReportRow, latest time.Time) []sinkPoint {
+	var points []sinkPoint
+	for _, row := range rows {
+		labels := make(map[string]string)
+		for i, d := range dimensions {
+			if i < len(row.Labels) && row.Labels[i] != "" {
+				labels[sinkLabelName(d.Name)] = row.Labels[i]
+			}
+		}
+		t := latest
+		if !row.Period.IsZero() {
+			t = row.Period
+		}
+		points = append(points, sinkPoint{
+			Labels:              labels,
+			Time:                t,
+			EnergyKiloWattHours: row.EnergyKiloWattHours,
+			OperationalGrams:    row.operationalGrams(),
+			EmbodiedGrams:       row.EmbodiedGrams,
+			EmissionGrams:       row.EmissionGrams,
+		})
+	}
+	return points
+}
+
+// sinkLabelName turns a dimension name into a label name valid for
+// Prometheus and InfluxDB, e.g. "instance_type" for "instance-type".
+func sinkLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// influxLineProtocol encodes points in the InfluxDB line protocol, with
+// timestamps in nanoseconds.
+func influxLineProtocol(points []sinkPoint) []byte {
+	escaper := strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+	var b bytes.Buffer
+	for _, p := range points {
+		b.WriteString(sinkMeasurement)
+		for _, name := range p.sortedLabelNames() {
+			fmt.Fprintf(&b, ",%s=%s", escaper.Replace(name), escaper.Replace(p.Labels[name]))
+		}
+		for i, field := range p.sinkFields() {
+			sep := ","
+			if i == 0 {
+				sep = " "
+			}
+			fmt.Fprintf(&b, "%s%s=%s", sep, field.name, strconv.FormatFloat(field.value, 'g', -1, 64))
+		}
+		fmt.Fprintf(&b, " %d\n", p.Time.UnixNano())
+	}
+	return b.Bytes()
+}
+
+// prometheusWriteRequest encodes points as a Prometheus remote-write
+// request, with one time series per point and metric.
+func prometheusWriteRequest(points []sinkPoint) ([]byte, error) {
+	var request prompb.WriteRequest
+	for _, p := range points {
+		for _, field := range p.sinkFields() {
+			// Labels must be sorted by name, starting with __name__.
+			labels := []prompb.Label{{Name: "__name__", Value: sinkMeasurement + "_" + field.name}}
+			for _, name := range p.sortedLabelNames() {
+				labels = append(labels, prompb.Label{Name: name, Value: p.Labels[name]})
+			}
+			request.Timeseries = append(request.Timeseries, prompb.TimeSeries{
+				Labels:  labels,
+				Samples: []prompb.Sample{{Value: field.value, Timestamp: p.Time.UnixMilli()}},
+			})
+		}
+	}
+	return request.Marshal()
+}
+
+// prometheusRejectedOld returns whether the error message of a Prometheus
+// remote-write endpoint says samples were rejected for being older than
+// the data it accepts, see pushToSink.
+func prometheusRejectedOld(message string) bool {
+	return strings.Contains(message, "out of bounds") || strings.Contains(message, "too old sample")
+}
+
+// pushToSink writes points to a time series database.
+func pushToSink(ctx context.Context, client *http.Client, sink, url, token string, points []sinkPoint) error {
+	var body []byte
+	header := http.Header{}
+	switch sink {
+	case sinkInfluxDB:
+		body = influxLineProtocol(points)
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		if token != "" {
+			header.Set("Authorization", "Token "+token)
+		}
+	case sinkPrometheus:
+		request, err := prometheusWriteRequest(points)
+		if err != nil {
+			return fmt.Errorf("could not encode write request: %w", err)
+		}
+		body = snappy.Encode(nil, request)
+		header.Set("Content-Type", "application/x-protobuf")
+		header.Set("Content-Encoding", "snappy")
+		header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		if token != "" {
+			header.Set("Authorization", "Bearer "+token)
+		}
+	default:
+		return fmt.Errorf("unknown sink %q, must be one of: %s", sink, strings.Join(sinks, ", "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if sink == sinkPrometheus && prometheusRejectedOld(string(message)) {
+		return fmt.Errorf("Prometheus rejected the samples as too old (status %s): it only accepts samples older than its latest data within its out-of-order time window, which is disabled by default. To push the results of past billing periods, set storage.tsdb.out_of_order_time_window to cover them, or use --sink %s", resp.Status, sinkInfluxDB)
+	}
+	if len(message) > 0 {
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+var testSinkTime = time.Date(2022, 8, 31, 0, 0, 0, 0, time.UTC)
+
+func testSinkPoints() []sinkPoint {
+	return []sinkPoint{
+		{
+			Labels:              map[string]string{"region": "eu-west-1", "instance_type": "m5.large"},
+			Time:                testSinkTime,
+			EnergyKiloWattHours: 1.5,
+			OperationalGrams:    400,
+			EmbodiedGrams:       100,
+			EmissionGrams:       500,
+		},
+	}
+}
+
+func Test_sinkPoints(t *testing.T) {
+	dimensions, err := parseGroupBy("region,instance-type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	period := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1", "m5.large"}, EnergyKiloWattHours: 1.5, EmbodiedGrams: 100, EmissionGrams: 500},
+		{Labels: []string{"us-east-1", ""}, Period: period, EmissionGrams: 200},
+	}
+
+	got := sinkPoints(dimensions, rows, testSinkTime)
+
+	if len(got) != 2 {
+		t.Fatalf("sinkPoints() returned %d points, want 2", len(got))
+	}
+	if !reflect.DeepEqual(got[0], testSinkPoints()[0]) {
+		t.Errorf("sinkPoints()[0] = %+v, want %+v", got[0], testSinkPoints()[0])
+	}
+	if want := map[string]string{"region": "us-east-1"}; !reflect.DeepEqual(got[1].Labels, want) {
+		t.Errorf("sinkPoints()[1] labels = %v, want %v", got[1].Labels, want)
+	}
+	if !got[1].Time.Equal(period) {
+		t.Errorf("sinkPoints()[1] time = %s, want start of period %s", got[1].Time, period)
+	}
+}
+
+func Test_sinkLabelName(t *testing.T) {
+	for name, want := range map[string]string{
+		"region":        "region",
+		"instance-type": "instance_type",
+		"tag:team":      "tag_team",
+	} {
+		if got := sinkLabelName(name); got != want {
+			t.Errorf("sinkLabelName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func Test_influxLineProtocol(t *testing.T) {
+	points := testSinkPoints()
+	points[0].Labels["tag_team"] = "data platform"
+
+	got := string(influxLineProtocol(points))
+	want := `cloud_carbon,instance_type=m5.large,region=eu-west-1,tag_team=data\ platform embodied_grams=100,emission_grams=500,energy_kwh=1.5,operational_grams=400 1661904000000000000` + "\n"
+	if got != want {
+		t.Errorf("influxLineProtocol() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Test_prometheusWriteRequest(t *testing.T) {
+	data, err := prometheusWriteRequest(testSinkPoints())
+	if err != nil {
+		t.Fatalf("prometheusWriteRequest() error = %v", err)
+	}
+	var request prompb.WriteRequest
+	if err := request.Unmarshal(data); err != nil {
+		t.Fatalf("could not decode write request: %v", err)
+	}
+
+	if len(request.Timeseries) != 4 {
+		t.Fatalf("write request has %d time series, want 4", len(request.Timeseries))
+	}
+
+	// The second series is emission_grams.
+	ts := request.Timeseries[1]
+	wantLabels := []prompb.Label{{Name: "__name__", Value: "cloud_carbon_emission_grams"}, {Name: "instance_type", Value: "m5.large"}, {Name: "region", Value: "eu-west-1"}}
+	if !reflect.DeepEqual(ts.Labels, wantLabels) {
+		t.Errorf("labels = %v, want %v", ts.Labels, wantLabels)
+	}
+	wantSamples := []prompb.Sample{{Value: 500, Timestamp: testSinkTime.UnixMilli()}}
+	if !reflect.DeepEqual(ts.Samples, wantSamples) {
+		t.Errorf("samples = %v, want %v", ts.Samples, wantSamples)
+	}
+}
+
+func Test_pushToSink(t *testing.T) {
+	tests := []struct {
+		sink       string
+		wantHeader http.Header
+	}{
+		{sink: sinkInfluxDB, wantHeader: http.Header{"Authorization": {"Token secret"}, "Content-Type": {"text/plain; charset=utf-8"}}},
+		{sink: sinkPrometheus, wantHeader: http.Header{"Authorization": {"Bearer secret"}, "Content-Encoding": {"snappy"}, "Content-Type": {"application/x-protobuf"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sink, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key := range tt.wantHeader {
+					if r.Header.Get(key) != tt.wantHeader.Get(key) {
+						t.Errorf("header %s = %q, want %q", key, r.Header.Get(key), tt.wantHeader.Get(key))
+					}
+				}
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			if err := pushToSink(context.Background(), server.Client(), tt.sink, server.URL, "secret", testSinkPoints()); err != nil {
+				t.Fatalf("pushToSink() error = %v", err)
+			}
+			if len(body) == 0 {
+				t.Fatal("pushToSink() sent no data")
+			}
+			if tt.sink == sinkPrometheus {
+				data, err := snappy.Decode(nil, body)
+				if err != nil {
+					t.Fatalf("could not decompress body: %v", err)
+				}
+				var request prompb.WriteRequest
+				if err := request.Unmarshal(data); err != nil || len(request.Timeseries) != 4 {
+					t.Errorf("body decodes to %d time series, %v, want 4", len(request.Timeseries), err)
+				}
+			}
+		})
+	}
+}
+
+func Test_pushToSink_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if err := pushToSink(context.Background(), server.Client(), sinkInfluxDB, server.URL, "", testSinkPoints()); err == nil {
+		t.Error("pushToSink() returned no error")
+	}
+	if err := pushToSink(context.Background(), server.Client(), "unknown", server.URL, "", testSinkPoints()); err == nil {
+		t.Error("pushToSink() to unknown sink returned no error")
+	}
+}
+
+func Test_pushToSink_outOfBounds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of bounds", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := pushToSink(context.Background(), server.Client(), sinkPrometheus, server.URL, "", testSinkPoints())
+	if err == nil || !strings.Contains(err.Error(), "out_of_order_time_window") {
+		t.Errorf("pushToSink() error = %v, want hint at the out-of-order time window", err)
+	}
+}
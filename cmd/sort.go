@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fields the result table can be sorted by.
+const (
+	sortEmissions    = "emissions"
+	sortDuration     = "duration"
+	sortRegion       = "region"
+	sortInstanceType = "instance-type"
+)
+
+var sortFields = []string{sortEmissions, sortDuration, sortRegion, sortInstanceType}
+
+var (
+	sortField      string
+	sortDescending bool
+)
+
+func init() {
+	analyseCmd.Flags().StringVar(&sortField, "sort", "", fmt.Sprintf("Field to sort the rows of the table by, one of: %s. Defaults to the order of the groups", strings.Join(sortFields, ", ")))
+	analyseCmd.Flags().BoolVar(&sortDescending, "desc", false, "Sort in descending order, requires --sort")
+}
+
+// sortRows returns rows sorted by the given field, keeping the order of
+// periods. Rows with equal values keep their order.
+func sortRows(rows []AggregateReportRow, field string, descending bool) []AggregateReportRow {
+	less := map[string]func(a, b AggregateReportRow) bool{
+		sortEmissions:    func(a, b AggregateReportRow) bool { return a.EmissionGrams < b.EmissionGrams },
+		sortDuration:     func(a, b AggregateReportRow) bool { return a.Duration < b.Duration },
+		sortRegion:       func(a, b AggregateReportRow) bool { return a.Region < b.Region },
+		sortInstanceType: func(a, b AggregateReportRow) bool { return a.InstanceType < b.InstanceType },
+	}[field]
+
+	sorted := append([]AggregateReportRow{}, rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if !a.Period.Equal(b.Period) {
+			return a.Period.Before(b.Period)
+		}
+		if descending {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+	return sorted
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_sortRows(t *testing.T) {
+	july := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	august := july.AddDate(0, 1, 0)
+	rows := []AggregateReportRow{
+		{Labels: []string{"a"}, Period: july, Region: "eu-west-1", InstanceType: "t3.micro", Duration: 3 * time.Hour, EmissionGrams: 10},
+		{Labels: []string{"b"}, Period: july, Region: "eu-central-1", InstanceType: "m5.large", Duration: time.Hour, EmissionGrams: 30},
+		{Labels: []string{"c"}, Period: july, Region: "us-east-1", InstanceType: "c5.large", Duration: 2 * time.Hour, EmissionGrams: 20},
+		{Labels: []string{"d"}, Period: august, Region: "ap-south-1", InstanceType: "a1.large", Duration: time.Hour, EmissionGrams: 5},
+	}
+
+	tests := []struct {
+		field      string
+		descending bool
+		want       []string
+	}{
+		{field: sortEmissions, descending: true, want: []string{"b", "c", "a", "d"}},
+		{field: sortEmissions, want: []string{"a", "c", "b", "d"}},
+		{field: sortDuration, want: []string{"b", "c", "a", "d"}},
+		{field: sortRegion, want: []string{"b", "a", "c", "d"}},
+		{field: sortInstanceType, descending: true, want: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			var got []string
+			for _, row := range sortRows(rows, tt.field, tt.descending) {
+				got = append(got, row.Labels[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if rows[0].Labels[0] != "a" {
+		t.Error("sortRows() changed the order of its input")
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// gzipMagic is the byte sequence every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ReportSource is a report file to analyse, stored locally or in S3.
+type ReportSource struct {
+	// Name identifies the source in messages, e.g. a local path or an S3 URL.
+	Name string
+
+	// Open returns a reader for the raw, possibly compressed, report content.
+	Open func(ctx context.Context) (io.ReadCloser, error)
+
+	// Size is the size of the raw content in bytes, zero if unknown.
+	Size int64
+
+	// Checksum returns a value identifying the content, changing whenever
+	// the content changes.
+	Checksum func(ctx context.Context) (string, error)
+}
+
+// localSource returns the source for a report file on the local file system.
+func localSource(path string) ReportSource {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	return ReportSource{
+		Size: size,
+		Name: path,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not open file: %w", err)
+			}
+			return file, nil
+		},
+		Checksum: func(ctx context.Context) (string, error) {
+			return fileChecksum(path)
+		},
+	}
+}
+
+// resolveSources returns the report sources for the paths given by the user,
+// which can be local files, local directories, or S3 URLs.
+func resolveSources(ctx context.Context, paths []string) ([]ReportSource, error) {
+	var sources []ReportSource
+
+	for _, path := range paths {
+		if isS3URL(path) {
+			s3Sources, err := s3Sources(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, s3Sources...)
+			continue
+		}
+
+		localPaths, err := expandPaths([]string{path})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range localPaths {
+			sources = append(sources, localSource(p))
+		}
+	}
+
+	return sources, nil
+}
+
+// fileChecksum returns the SHA-256 checksum of a local file.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("could not read file: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// totalSize returns the size of all sources in bytes, or zero if the size
+// of any source is unknown.
+func totalSize(sources []ReportSource) int64 {
+	var total int64
+	for _, src := range sources {
+		if src.Size == 0 {
+			return 0
+		}
+		total += src.Size
+	}
+	return total
+}
+
+// expandPaths replaces directories in paths by the report files found in
+// them, recursively. Report files are recognized by their name ending in
+// .csv or .csv.gz. If a directory holds Cost and Usage Report manifests, only
+// the report files of the latest report version of each billing period with
+// a manifest are used, see selectManifests and mergeManifestReports. Other
+// paths are kept as they are.
+func expandPaths(paths []string) ([]string, error) {
+	var result []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			// Errors are reported when trying to read the file.
+			result = append(result, path)
+			continue
+		}
+
+		var found []string
+		var manifests []reportManifestFile
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if isReportFileName(d.Name()) {
+				found = append(found, p)
+			} else if strings.HasSuffix(d.Name(), manifestSuffix) {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				manifests = append(manifests, reportManifestFile{key: filepath.ToSlash(p), modified: info.ModTime()})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if selected := selectManifests(manifests); len(selected) > 0 {
+			var listed []string
+			for _, manifest := range selected {
+				paths, err := readLocalManifestPaths(filepath.FromSlash(manifest))
+				if err != nil {
+					return nil, err
+				}
+				for _, p := range paths {
+					listed = append(listed, filepath.ToSlash(p))
+				}
+			}
+			for i, p := range found {
+				found[i] = filepath.ToSlash(p)
+			}
+			found = mergeManifestReports(found, selected, listed)
+			for i, p := range found {
+				found[i] = filepath.FromSlash(p)
+			}
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no report files found in directory %s", path)
+		}
+
+		sort.Strings(found)
+		result = append(result, found...)
+	}
+
+	return result, nil
+}
+
+func isReportFileName(name string) bool {
+	return strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz")
+}
+
+// maybeDecompress returns a reader for the uncompressed content of r.
+// Gzip compression is detected by its magic bytes, other content is
+// passed through unchanged.
+func maybeDecompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(gzipMagic))
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		return gzip.NewReader(br)
+	}
+
+	return io.NopCloser(br), nil
+}
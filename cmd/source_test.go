@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_expandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"report-00002.csv.gz",
+		"report-00001.csv.gz",
+		"report-Manifest.json",
+		"20220801-20220901/report-00001.csv",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emptyDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "files are kept",
+			paths: []string{"b.csv.gz", "a.csv.gz"},
+			want:  []string{"b.csv.gz", "a.csv.gz"},
+		},
+		{
+			name:  "directory is expanded",
+			paths: []string{dir},
+			want: []string{
+				filepath.Join(dir, "20220801-20220901/report-00001.csv"),
+				filepath.Join(dir, "report-00001.csv.gz"),
+				filepath.Join(dir, "report-00002.csv.gz"),
+			},
+		},
+		{
+			name:    "empty directory",
+			paths:   []string{emptyDir},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPaths(tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expandPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_maybeDecompress(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write([]byte("a,b,c\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "gzip", input: compressed.Bytes(), want: "a,b,c\n"},
+		{name: "plain", input: []byte("a,b,c\n"), want: "a,b,c\n"},
+		{name: "single byte", input: []byte("a"), want: "a"},
+		{name: "empty", input: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := maybeDecompress(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("maybeDecompress() error = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("reading decompressed data: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("maybeDecompress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_totalSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(path, []byte("a,b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := totalSize([]ReportSource{localSource(path), localSource(path)}); got != 8 {
+		t.Errorf("totalSize() = %d, want 8", got)
+	}
+	if got := totalSize([]ReportSource{localSource(path), {Name: "s3://bucket/key"}}); got != 0 {
+		t.Errorf("totalSize() with unknown size = %d, want 0", got)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// addSpend adds the cost of a line to the spend on the service with the
+// given product code.
+func (s *ReportSummary) addSpend(productCode string, cost float64) {
+	if s.Spend == nil {
+		s.Spend = make(map[string]float64)
+	}
+	s.Spend[s.productCodes.intern(productCode)] += cost
+}
+
+// addProductCost adds cost of the service with the given product code to the
+// row.
+func (r *AggregateReportRow) addProductCost(productCode string, cost float64) {
+	if r.ProductCosts == nil {
+		r.ProductCosts = make(map[string]float64)
+	}
+	r.ProductCosts[productCode] += cost
+}
+
+// writeSpendCoverage prints the share of the spend covered by the carbon
+// model, in total and per service, the most expensive first, so that
+// readers know how much of the bill the estimate represents. Only the cost
+// of the rows the footprint was estimated for counts as covered, not that of
+// rows skipped e.g. for an unknown instance type. Nothing is printed for
+// reports without cost.
+func writeSpendCoverage(w io.Writer, spend map[string]float64, rows []AggregateReportRow) {
+	covered := make(map[string]float64)
+	for _, row := range rows {
+		for productCode, cost := range row.ProductCosts {
+			covered[productCode] += cost
+		}
+	}
+
+	var total, totalCovered float64
+	productCodes := make([]string, 0, len(spend))
+	for productCode, cost := range spend {
+		total += cost
+		totalCovered += covered[productCode]
+		productCodes = append(productCodes, productCode)
+	}
+	if total <= 0 {
+		return
+	}
+	sort.Slice(productCodes, func(i, j int) bool {
+		a, b := spend[productCodes[i]], spend[productCodes[j]]
+		if a != b {
+			return a > b
+		}
+		return productCodes[i] < productCodes[j]
+	})
+
+	fmt.Fprintf(w, "\nSpend covered by the carbon model: %s of %s.\n", formatShare(totalCovered, total), formatCost(total))
+	for _, productCode := range productCodes {
+		cost := spend[productCode]
+		// Services with only credits or refunds have no share.
+		share := "-"
+		if cost > 0 {
+			share = formatShare(covered[productCode], cost)
+		}
+		fmt.Fprintf(w, "  - %s: %s of %s\n", valueLabel(productCode), share, formatCost(cost))
+	}
+}
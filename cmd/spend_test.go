@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReportSummary_addSpend(t *testing.T) {
+	summary := newReportSummary(nil)
+	summary.addSpend("AmazonEC2", 2)
+	summary.addSpend("AmazonEC2", 0.5)
+
+	other := newReportSummary(nil)
+	other.addSpend("AmazonEC2", 1)
+	other.addSpend("AmazonCloudWatch", 0.25)
+	summary.merge(other)
+
+	want := map[string]float64{"AmazonEC2": 3.5, "AmazonCloudWatch": 0.25}
+	if len(summary.Spend) != len(want) {
+		t.Fatalf("Spend = %v, want %v", summary.Spend, want)
+	}
+	for productCode, cost := range want {
+		if summary.Spend[productCode] != cost {
+			t.Errorf("Spend[%q] = %v, want %v", productCode, summary.Spend[productCode], cost)
+		}
+	}
+}
+
+func TestReportSummary_addProductCost(t *testing.T) {
+	start := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	row := ReportRow{Category: categoryNetwork, Region: "eu-west-1", StorageType: "InterRegion", UsageStartTime: start, UsageEndTime: start.Add(time.Hour)}
+
+	summary := newReportSummary(nil)
+	for _, r := range []struct {
+		productCode string
+		cost        float64
+	}{{"AmazonEC2", 1}, {"AmazonS3", 0.5}, {"AmazonEC2", 2}} {
+		row.ProductCode, row.Cost = r.productCode, r.cost
+		summary.add(row)
+	}
+
+	other := newReportSummary(nil)
+	row.ProductCode, row.Cost = "AmazonS3", 0.25
+	other.add(row)
+	summary.merge(other)
+	other.add(row)
+
+	if len(summary.Aggregate) != 1 {
+		t.Fatalf("add() kept %d rows, want 1", len(summary.Aggregate))
+	}
+	got := summary.Aggregate[0]
+	if got.Cost != 3.75 || got.ProductCosts["AmazonEC2"] != 3 || got.ProductCosts["AmazonS3"] != 0.75 {
+		t.Errorf("add() row cost = %v by product %v, want 3.75 split into AmazonEC2 3 and AmazonS3 0.75", got.Cost, got.ProductCosts)
+	}
+}
+
+func Test_writeSpendCoverage(t *testing.T) {
+	tests := []struct {
+		name  string
+		spend map[string]float64
+		rows  []AggregateReportRow
+		want  string
+	}{
+		{
+			name:  "no cost",
+			spend: map[string]float64{"AmazonEC2": 0},
+		},
+		{
+			name: "services by cost",
+			spend: map[string]float64{
+				"AmazonCloudWatch": 10,
+				"AmazonEC2":        80,
+				"AmazonS3":         10,
+				"":                 -5,
+			},
+			rows: []AggregateReportRow{
+				{ProductCosts: map[string]float64{"AmazonEC2": 70}},
+				{ProductCosts: map[string]float64{"AmazonEC2": 6, "AmazonS3": 10}},
+			},
+			want: "\nSpend covered by the carbon model: 90.5% of 95.00.\n" +
+				"  - AmazonEC2: 95.0% of 80.00\n" +
+				"  - AmazonCloudWatch: 0.0% of 10.00\n" +
+				"  - AmazonS3: 100.0% of 10.00\n" +
+				"  - (empty): - of -5.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeSpendCoverage(&buf, tt.spend, tt.rows)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeSpendCoverage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeSpendCoverage_skipped(t *testing.T) {
+	start := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	summary := newReportSummary(testDimensions(t, defaultGroupBy))
+	for _, instanceType := range []string{"m5.large", "x9.unknown"} {
+		summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: instanceType, Duration: time.Hour, UsageStartTime: start, UsageEndTime: start.Add(time.Hour), Cost: 1, ProductCode: "AmazonEC2"})
+		summary.addSpend("AmazonEC2", 1)
+	}
+
+	options := defaultEmissionOptions()
+	options.coverage = newCoverage()
+	rows, _ := computeEmissions(context.Background(), summary, options)
+
+	var buf bytes.Buffer
+	writeSpendCoverage(&buf, summary.Spend, rows)
+	want := "\nSpend covered by the carbon model: 50.0% of 2.00.\n  - AmazonEC2: 50.0% of 2.00\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeSpendCoverage() = %q, want %q", got, want)
+	}
+}
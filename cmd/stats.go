@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+// emptyValueLabel is shown for line items without a line item type or
+// product code.
+const emptyValueLabel = "(empty)"
+
+var statsOnly bool
+
+func init() {
+	analyseCmd.Flags().BoolVar(&statsOnly, "stats-only", false, "Only count the lines of the reports by line item type and product code, and how many match the filters, without estimating emissions")
+}
+
+// ReportStats holds the number of lines of Cost and Usage Reports, as shown
+// with --stats-only to check what a report contains and what the filters
+// select before running a full analysis.
+type ReportStats struct {
+	// Lines is the number of lines read, without headers.
+	Lines int64 `json:"lines"`
+
+	// LineItemTypes and ProductCodes count the lines by their line item
+	// type and product code.
+	LineItemTypes map[string]int64 `json:"line_item_types"`
+	ProductCodes  map[string]int64 `json:"product_codes"`
+
+	// Covered is the number of lines about usage the footprint is estimated
+	// for, and Categories counts them by category.
+	Covered    int64            `json:"covered"`
+	Categories map[string]int64 `json:"categories"`
+
+	// Invalid is the number of lines that could not be parsed.
+	Invalid int64 `json:"invalid"`
+
+	// FilterMatched and FilterUnmatched split the covered lines by whether
+	// they match the filters.
+	FilterMatched   int64 `json:"filter_matched"`
+	FilterUnmatched int64 `json:"filter_unmatched"`
+}
+
+func newReportStats() *ReportStats {
+	return &ReportStats{
+		LineItemTypes: make(map[string]int64),
+		ProductCodes:  make(map[string]int64),
+		Categories:    make(map[string]int64),
+	}
+}
+
+// collectReportStats reads the Cost and Usage Reports of sources and counts
+// their lines, see ReportStats. The lines are only parsed, no footprint is
+// computed.
+func collectReportStats(ctx context.Context, sources []ReportSource, filter pipeline.Filter) (*ReportStats, error) {
+	stats := newReportStats()
+	for _, src := range sources {
+		if err := stats.addSource(ctx, src, filter); err != nil {
+			return stats, fmt.Errorf("could not process file %s: %w", src.Name, err)
+		}
+	}
+	return stats, nil
+}
+
+// addSource counts the lines of the report read from src.
+func (s *ReportStats) addSource(ctx context.Context, src ReportSource, filter pipeline.Filter) error {
+	r, err := src.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	csvFile, err := maybeDecompress(r)
+	if err != nil {
+		return fmt.Errorf("could not uncompress file: %w", err)
+	}
+	defer csvFile.Close()
+
+	fcsv := csv.NewReader(csvFile)
+	fcsv.ReuseRecord = true
+	header, err := fcsv.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read CSV: %w", err)
+	}
+	if err := cur.ValidateHeader(header); err != nil {
+		return parseErrorf("%w%s", err, providerHint(header))
+	}
+	// The header is copied, as the reader reuses its slice.
+	parser := cur.NewParser(append([]string(nil), header...), nil)
+
+	for {
+		fields, err := fcsv.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read CSV: %w", err)
+		}
+		s.add(parser, fields, filter)
+	}
+}
+
+// add counts a line of a report.
+func (s *ReportStats) add(parser *cur.Parser, fields []string, filter pipeline.Filter) {
+	s.Lines++
+	s.LineItemTypes[valueLabel(parser.LineItemType(fields))]++
+	s.ProductCodes[valueLabel(parser.ProductCode(fields))]++
+
+	item, ok, err := parser.Parse(fields)
+	if err != nil {
+		s.Invalid++
+		return
+	}
+	if !ok {
+		return
+	}
+	s.Covered++
+	s.Categories[item.Category]++
+	if filter == nil || filter(item) {
+		s.FilterMatched++
+	} else {
+		s.FilterUnmatched++
+	}
+}
+
+func valueLabel(value string) string {
+	if value == "" {
+		return emptyValueLabel
+	}
+	return value
+}
+
+// writeReportStats prints the line counts of stats as tables and a summary.
+func writeReportStats(w io.Writer, stats *ReportStats) {
+	fmt.Fprintf(w, "Lines: %d\n\n", stats.Lines)
+	writeCountTable(w, "Line item type", stats.LineItemTypes, stats.Lines)
+	fmt.Fprintln(w)
+	writeCountTable(w, "Product code", stats.ProductCodes, stats.Lines)
+	fmt.Fprintln(w)
+	if stats.Covered > 0 {
+		writeCountTable(w, "Category", stats.Categories, stats.Covered)
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Lines covered by the carbon model: %d, not covered: %d.\n", stats.Covered, stats.Lines-stats.Covered-stats.Invalid)
+	if stats.Invalid > 0 {
+		fmt.Fprintf(w, "Invalid lines: %d.\n", stats.Invalid)
+	}
+	fmt.Fprintf(w, "Covered lines matching the filters: %d, not matching: %d.\n", stats.FilterMatched, stats.FilterUnmatched)
+}
+
+// writeCountTable prints counts by value, the largest first, with their
+// share of total.
+func writeCountTable(w io.Writer, column string, counts map[string]int64, total int64) {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{column, "Lines", "Share"})
+	for _, value := range values {
+		table.Append([]string{
+			value,
+			strconv.FormatInt(counts[value], 10),
+			fmt.Sprintf("%.1f%%", float64(counts[value])/float64(total)*100),
+		})
+	}
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+func Test_collectReportStats(t *testing.T) {
+	filter, err := patternFilter("eu-*", func(item cur.LineItem) string { return item.Region })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := collectReportStats(context.Background(), []ReportSource{localSource("testdata/replay-usage.csv")}, filter)
+	if err != nil {
+		t.Fatalf("collectReportStats() error = %v", err)
+	}
+	if stats.Lines != 129 || stats.Covered != 102 || stats.Invalid != 0 {
+		t.Errorf("collectReportStats() lines = %d, covered = %d, invalid = %d, want 129, 102, 0", stats.Lines, stats.Covered, stats.Invalid)
+	}
+	if stats.LineItemTypes["Usage"] != 128 || stats.LineItemTypes["Tax"] != 1 {
+		t.Errorf("collectReportStats() line item types = %v", stats.LineItemTypes)
+	}
+	if stats.ProductCodes["AmazonEC2"] != 62 || stats.Categories["EC2"] != 30 {
+		t.Errorf("collectReportStats() product codes = %v, categories = %v", stats.ProductCodes, stats.Categories)
+	}
+	if stats.FilterMatched != 84 || stats.FilterUnmatched != 18 {
+		t.Errorf("collectReportStats() filter matched = %d, unmatched = %d, want 84, 18", stats.FilterMatched, stats.FilterUnmatched)
+	}
+}
+
+func Test_collectReportStats_schema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	report := "service_description,sku_description,usage_amount\nCompute Engine,N1 Predefined Instance Core running in Americas,3600\n"
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := collectReportStats(context.Background(), []ReportSource{localSource(path)}, nil)
+	if err == nil || !strings.Contains(err.Error(), "use --provider gcp") {
+		t.Errorf("collectReportStats() error = %v, want provider hint", err)
+	}
+	if code := exitCode(err); code != exitParse {
+		t.Errorf("collectReportStats() exit code = %d, want %d", code, exitParse)
+	}
+}
+
+func Test_writeReportStats(t *testing.T) {
+	stats := newReportStats()
+	stats.Lines = 4
+	stats.LineItemTypes = map[string]int64{"Usage": 3, emptyValueLabel: 1}
+	stats.ProductCodes = map[string]int64{"AmazonEC2": 3, "AWSSupportBusiness": 1}
+	stats.Covered = 2
+	stats.Categories = map[string]int64{"EC2": 2}
+	stats.Invalid = 1
+	stats.FilterMatched = 1
+	stats.FilterUnmatched = 1
+
+	var buf bytes.Buffer
+	writeReportStats(&buf, stats)
+
+	for _, want := range []string{
+		"Lines: 4",
+		"Usage           3      75.0%",
+		"(empty)         1      25.0%",
+		"EC2       2      100.0%",
+		"Lines covered by the carbon model: 2, not covered: 1.",
+		"Invalid lines: 1.",
+		"Covered lines matching the filters: 1, not matching: 1.",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeReportStats() = %q, want to contain %q", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	periodHour  = "hour"
+	periodDay   = "day"
+	periodMonth = "month"
+)
+
+// periods maps the names of the supported time series intervals to the
+// function determining the start of a period.
+var periods = map[string]periodFunc{
+	periodHour:  hourPeriod,
+	periodDay:   dayPeriod,
+	periodMonth: monthPeriod,
+}
+
+// periodLayouts holds the format used to show the start of a period in
+// tables.
+var periodLayouts = map[string]string{
+	periodHour:  "2006-01-02 15:04",
+	periodDay:   "2006-01-02",
+	periodMonth: "2006-01",
+}
+
+var periodNames = []string{periodHour, periodDay, periodMonth}
+
+// dayPeriod splits usage by UTC day.
+func dayPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// monthPeriod splits usage by UTC calendar month.
+func monthPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// SeriesPoint holds the footprint of one group in one period.
+type SeriesPoint struct {
+	// Period is the start of the period, unset for totals.
+	Period time.Time `json:"-"`
+
+	// Labels holds the values of the grouping dimensions, keyed by the
+	// dimension name.
+	Labels map[string]string `json:"labels"`
+
+	EnergyKiloWattHours float64 `json:"energy_kwh"`
+	OperationalGrams    float64 `json:"operational_grams"`
+	EmbodiedGrams       float64 `json:"embodied_grams"`
+	EmissionGrams       float64 `json:"emission_grams"`
+	Cost                float64 `json:"cost"`
+
+	// VCPUHours is the vCPU time of the instances and tasks, to compute
+	// the emissions per vCPU-hour.
+	VCPUHours float64 `json:"vcpu_hours"`
+
+	// WaterLiters is the water consumed on site by the data centers.
+	WaterLiters float64 `json:"water_liters"`
+}
+
+// MarshalJSON adds the period in RFC 3339 format, if set, and the emissions
+// by GHG Protocol scope: operational emissions are scope 2, embodied
+// emissions scope 3.
+func (p SeriesPoint) MarshalJSON() ([]byte, error) {
+	type point SeriesPoint
+	var period string
+	if !p.Period.IsZero() {
+		period = p.Period.Format(time.RFC3339)
+	}
+	return json.Marshal(struct {
+		Period string `json:"period,omitempty"`
+		point
+		Scope2Grams float64 `json:"scope2_grams"`
+		Scope3Grams float64 `json:"scope3_grams"`
+	}{period, point(p), p.OperationalGrams, p.EmbodiedGrams})
+}
+
+// timeSeries sums up emissions per group and period, using period to
+// determine the period of each row. With a nil period, the result holds the
+// totals per group. Points are sorted by period, then by labels.
+func timeSeries(dimensions []Dimension, rows []AggregateReportRow, period periodFunc) []SeriesPoint {
+	byKey := make(map[string]*SeriesPoint)
+	var keys []string
+
+	for _, row := range rows {
+		var start time.Time
+		if period != nil && !row.Period.IsZero() {
+			start = period(row.Period)
+		}
+
+		key := start.Format(time.RFC3339) + "\x00" + strings.Join(row.Labels, "\x00")
+		point, exists := byKey[key]
+		if !exists {
+			labels := make(map[string]string)
+			for i, d := range dimensions {
+				labels[d.Name] = row.Labels[i]
+			}
+			point = &SeriesPoint{Period: start, Labels: labels}
+			byKey[key] = point
+			keys = append(keys, key)
+		}
+
+		point.EnergyKiloWattHours += row.EnergyKiloWattHours
+		point.OperationalGrams += row.operationalGrams()
+		point.EmbodiedGrams += row.EmbodiedGrams
+		point.EmissionGrams += row.EmissionGrams
+		point.Cost += row.Cost
+		point.VCPUHours += row.InstanceVCPUHours
+		point.WaterLiters += row.WaterLiters
+	}
+
+	sort.Strings(keys)
+
+	result := make([]SeriesPoint, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *byKey[key])
+	}
+	return result
+}
+
+// withoutLabels returns a copy of rows without labels, to sum up all groups
+// in a time series.
+func withoutLabels(rows []AggregateReportRow) []AggregateReportRow {
+	unlabeled := make([]AggregateReportRow, len(rows))
+	for i, row := range rows {
+		row.Labels = nil
+		unlabeled[i] = row
+	}
+	return unlabeled
+}
+
+// writeSeriesCSV writes series points as CSV, with one column per dimension.
+func writeSeriesCSV(w io.Writer, dimensions []Dimension, points []SeriesPoint) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"period"}
+	for _, d := range dimensions {
+		header = append(header, d.Name)
+	}
+	header = append(header, "energy_kwh", "operational_grams", "embodied_grams", "emission_grams", "scope2_grams", "scope3_grams", "cost", "vcpu_hours", "water_liters")
+	err := writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("could not write CSV: %w", err)
+	}
+
+	for _, p := range points {
+		var period string
+		if !p.Period.IsZero() {
+			period = p.Period.Format(time.RFC3339)
+		}
+		record := []string{period}
+		for _, d := range dimensions {
+			record = append(record, p.Labels[d.Name])
+		}
+		record = append(record,
+			formatValue(p.EnergyKiloWattHours),
+			formatValue(p.OperationalGrams),
+			formatValue(p.EmbodiedGrams),
+			formatValue(p.EmissionGrams),
+			formatValue(p.OperationalGrams),
+			formatValue(p.EmbodiedGrams),
+			formatValue(p.Cost),
+			formatValue(p.VCPUHours),
+			formatValue(p.WaterLiters),
+		)
+		err := writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("could not write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeSeriesJSON writes series points as a JSON document.
+func writeSeriesJSON(w io.Writer, dimensions []Dimension, points []SeriesPoint) error {
+	var names []string
+	for _, d := range dimensions {
+		names = append(names, d.Name)
+	}
+
+	return writeJSON(w, map[string]any{
+		"dimensions": names,
+		"series":     points,
+	})
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_timeSeries(t *testing.T) {
+	dimensions := []Dimension{{Name: "region"}}
+	hour := func(h int) time.Time { return time.Date(2023, 3, 1, h, 0, 0, 0, time.UTC) }
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Period: hour(1), EnergyKiloWattHours: 1, EmbodiedGrams: 1, EmissionGrams: 3},
+		{Labels: []string{"eu-west-1"}, Period: hour(0), EnergyKiloWattHours: 2, EmbodiedGrams: 0, EmissionGrams: 4},
+		{Labels: []string{"eu-central-1"}, Period: hour(1), EnergyKiloWattHours: 1, EmbodiedGrams: 0, EmissionGrams: 2},
+		{Labels: []string{"eu-west-1"}, Period: hour(0).AddDate(0, 0, 1), EnergyKiloWattHours: 1, EmbodiedGrams: 0, EmissionGrams: 1},
+	}
+
+	tests := []struct {
+		name   string
+		period periodFunc
+		want   []SeriesPoint
+	}{
+		{
+			name:   "hourly",
+			period: hourPeriod,
+			want: []SeriesPoint{
+				{Period: hour(0), Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 2, OperationalGrams: 4, EmissionGrams: 4},
+				{Period: hour(1), Labels: map[string]string{"region": "eu-central-1"}, EnergyKiloWattHours: 1, OperationalGrams: 2, EmissionGrams: 2},
+				{Period: hour(1), Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 1, OperationalGrams: 2, EmbodiedGrams: 1, EmissionGrams: 3},
+				{Period: hour(24), Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 1, OperationalGrams: 1, EmissionGrams: 1},
+			},
+		},
+		{
+			name:   "daily",
+			period: dayPeriod,
+			want: []SeriesPoint{
+				{Period: hour(0), Labels: map[string]string{"region": "eu-central-1"}, EnergyKiloWattHours: 1, OperationalGrams: 2, EmissionGrams: 2},
+				{Period: hour(0), Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 3, OperationalGrams: 6, EmbodiedGrams: 1, EmissionGrams: 7},
+				{Period: hour(24), Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 1, OperationalGrams: 1, EmissionGrams: 1},
+			},
+		},
+		{
+			name: "totals",
+			want: []SeriesPoint{
+				{Labels: map[string]string{"region": "eu-central-1"}, EnergyKiloWattHours: 1, OperationalGrams: 2, EmissionGrams: 2},
+				{Labels: map[string]string{"region": "eu-west-1"}, EnergyKiloWattHours: 4, OperationalGrams: 7, EmbodiedGrams: 1, EmissionGrams: 8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timeSeries(dimensions, rows, tt.period)
+			if len(got) != len(tt.want) {
+				t.Fatalf("timeSeries() returned %d points, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				g := got[i]
+				if !g.Period.Equal(want.Period) || g.Labels["region"] != want.Labels["region"] ||
+					g.EnergyKiloWattHours != want.EnergyKiloWattHours || g.OperationalGrams != want.OperationalGrams ||
+					g.EmbodiedGrams != want.EmbodiedGrams || g.EmissionGrams != want.EmissionGrams {
+					t.Errorf("timeSeries()[%d] = %v, want %v", i, g, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_writeSeriesCSV(t *testing.T) {
+	dimensions := []Dimension{{Name: "region"}, {Name: "instance-type"}}
+	points := []SeriesPoint{
+		{
+			Period:              time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			Labels:              map[string]string{"region": "eu-west-1", "instance-type": "t3.micro"},
+			EnergyKiloWattHours: 0.5,
+			OperationalGrams:    150,
+			EmbodiedGrams:       10,
+			EmissionGrams:       160,
+			Cost:                0.0104,
+			VCPUHours:           4,
+			WaterLiters:         0.09,
+		},
+		{
+			Labels:        map[string]string{"region": "eu-central-1", "instance-type": "m5.large"},
+			EmissionGrams: 1,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSeriesCSV(&buf, dimensions, points); err != nil {
+		t.Fatalf("writeSeriesCSV() error = %v", err)
+	}
+
+	want := "period,region,instance-type,energy_kwh,operational_grams,embodied_grams,emission_grams,scope2_grams,scope3_grams,cost,vcpu_hours,water_liters\n" +
+		"2023-03-01T00:00:00Z,eu-west-1,t3.micro,0.5,150,10,160,150,10,0.0104,4,0.09\n" +
+		",eu-central-1,m5.large,0,0,0,1,0,0,0,0,0\n"
+	if buf.String() != want {
+		t.Errorf("writeSeriesCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_writeSeriesJSON(t *testing.T) {
+	dimensions := []Dimension{{Name: "region"}}
+	points := []SeriesPoint{
+		{
+			Period:           time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC),
+			Labels:           map[string]string{"region": "eu-west-1"},
+			OperationalGrams: 30,
+			EmbodiedGrams:    12,
+			EmissionGrams:    42,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSeriesJSON(&buf, dimensions, points); err != nil {
+		t.Fatalf("writeSeriesJSON() error = %v", err)
+	}
+
+	var got struct {
+		Dimensions []string
+		Series     []map[string]any
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("writeSeriesJSON() wrote invalid JSON: %v", err)
+	}
+
+	if len(got.Dimensions) != 1 || got.Dimensions[0] != "region" || len(got.Series) != 1 {
+		t.Fatalf("writeSeriesJSON() = %s", buf.String())
+	}
+	if got.Series[0]["period"] != "2023-03-01T13:00:00Z" || got.Series[0]["emission_grams"] != 42.0 {
+		t.Errorf("writeSeriesJSON() series = %v", got.Series[0])
+	}
+	if got.Series[0]["scope2_grams"] != 30.0 || got.Series[0]["scope3_grams"] != 12.0 {
+		t.Errorf("writeSeriesJSON() scopes = %v", got.Series[0])
+	}
+}
+
+func Test_monthPeriod(t *testing.T) {
+	got := monthPeriod(time.Date(2023, 3, 31, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60)))
+	want := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("monthPeriod() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+var top int
+
+func init() {
+	analyseCmd.Flags().IntVar(&top, "top", 0, "Only show the N groups with the highest emissions, with their share of the total. Requires output format table, markdown or html")
+}
+
+// topRows returns the n rows with the highest emissions, highest first.
+func topRows(rows []AggregateReportRow, n int) []AggregateReportRow {
+	sorted := append([]AggregateReportRow{}, rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EmissionGrams > sorted[j].EmissionGrams
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// formatShare formats a part of a total as percentage.
+func formatShare(part, total float64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", part/total*100)
+}
+
+// withShareColumn returns a result table of rows with a column after the
+// emissions showing the share of each row in total.
+func withShareColumn(data tableData, rows []AggregateReportRow, total float64) tableData {
+	index := len(data.Header)
+	for i, h := range data.Header {
+		if h == "Emissions" {
+			index = i + 1
+		}
+	}
+	insert := func(cells []string, cell string) []string {
+		result := append([]string{}, cells[:index]...)
+		result = append(result, cell)
+		return append(result, cells[index:]...)
+	}
+
+	result := tableData{Header: insert(data.Header, "Share")}
+	for i, row := range rows {
+		result.Rows = append(result.Rows, insert(data.Rows[i], formatShare(row.EmissionGrams, total)))
+	}
+	if len(data.Footer) > 0 {
+		result.Footer = insert(data.Footer, formatShare(total, total))
+	}
+	return result
+}
+
+// writeTopHeading introduces a table of the top groups.
+func writeTopHeading(w io.Writer, rows []AggregateReportRow, groupCount int, total float64) {
+	var shown float64
+	for _, row := range rows {
+		shown += row.EmissionGrams
+	}
+	fmt.Fprintf(w, "Top %d of %d groups, with %s of the emissions:\n\n", len(rows), groupCount, formatShare(shown, total))
+}
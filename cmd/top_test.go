@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func testTopRows() []AggregateReportRow {
+	return []AggregateReportRow{
+		{Labels: []string{"eu-central-1"}, Duration: time.Hour, EmissionGrams: 100},
+		{Labels: []string{"eu-west-1"}, Duration: time.Hour, EmissionGrams: 500},
+		{Labels: []string{"us-east-1"}, Duration: time.Hour, EmissionGrams: 300},
+		{Labels: []string{"us-west-2"}, Duration: time.Hour, EmissionGrams: 100},
+	}
+}
+
+func Test_topRows(t *testing.T) {
+	rows := testTopRows()
+
+	got := topRows(rows, 3)
+
+	var labels []string
+	for _, row := range got {
+		labels = append(labels, row.Labels[0])
+	}
+	if want := []string{"eu-west-1", "us-east-1", "eu-central-1"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("topRows() = %v, want %v", labels, want)
+	}
+	if rows[0].Labels[0] != "eu-central-1" {
+		t.Error("topRows() changed the order of its input")
+	}
+	if got := topRows(rows, 10); len(got) != len(rows) {
+		t.Errorf("topRows() with n above the number of rows returned %d rows, want %d", len(got), len(rows))
+	}
+}
+
+func Test_withShareColumn(t *testing.T) {
+	dimensions := []Dimension{{Name: "region", Header: "Region"}}
+	rows := topRows(testTopRows(), 2)
+	total := footprint.Result{OperationalGrams: 1000}
+
+	got := withShareColumn(resultTable(dimensions, rows, "", newTableTotals(testTopRows(), total), false), rows, total.Total())
+
+	if want := []string{"Region", "Usage", "Energy", "Scope 2", "Scope 3", "Emissions", "Share", "Water"}; !reflect.DeepEqual(got.Header, want) {
+		t.Errorf("header = %v, want %v", got.Header, want)
+	}
+	if got.Rows[0][6] != "50.0%" || got.Rows[1][6] != "30.0%" {
+		t.Errorf("rows = %v, want shares 50.0%% and 30.0%%", got.Rows)
+	}
+	if got.Footer[6] != "100.0%" {
+		t.Errorf("footer = %v, want share 100.0%%", got.Footer)
+	}
+}
+
+func Test_writeTopHeading(t *testing.T) {
+	var buf bytes.Buffer
+	writeTopHeading(&buf, topRows(testTopRows(), 2), 4, 1000)
+
+	if want := "Top 2 of 4 groups, with 80.0% of the emissions:\n\n"; buf.String() != want {
+		t.Errorf("writeTopHeading() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_writeResult_topFooter(t *testing.T) {
+	summary := newReportSummary([]Dimension{{Name: "region", Header: "Region"}})
+	rows := []AggregateReportRow{
+		{Labels: []string{"eu-west-1"}, Region: "eu-west-1", Duration: time.Hour, EmissionGrams: 50, Cost: 2, InstanceVCPUHours: 10},
+		{Labels: []string{"eu-north-1"}, Region: "eu-north-1", Duration: time.Hour, EmissionGrams: 10, Cost: 1, InstanceVCPUHours: 10},
+	}
+	result := analysisResult{summary: summary, rows: rows, total: footprint.Result{OperationalGrams: 60}}
+
+	// footer returns the footer of a Markdown table, without the share of
+	// the emissions.
+	footer := func(top int) string {
+		var buf bytes.Buffer
+		if err := writeResult(&buf, result, outputOptions{format: outputMarkdown, top: top}); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		return strings.Replace(lines[len(lines)-1], " **100.0%** |", "", 1)
+	}
+
+	want := footer(0)
+	if got := footer(1); got != want {
+		t.Errorf("footer with --top 1 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var trendCmd = &cobra.Command{
+	Use:   "trend PATH",
+	Short: "Show the emissions per month over a history of usage reports",
+	Long: `Show the emissions per month over a history of usage reports.
+
+PATH is a directory or S3 prefix holding the reports of several billing
+periods. All reports are analysed, and the emissions are summed up per
+calendar month (in UTC) and shown with the change to the previous month and
+a sparkline. With -o json, the monthly series is written in the format of
+analyse --timeseries month -o json.
+`,
+	RunE: trend,
+	Args: configArgs(cobra.ExactArgs(1)),
+}
+
+var (
+	trendOutputFormat string
+	trendProvider     string
+)
+
+func init() {
+	trendCmd.Flags().StringVarP(&trendOutputFormat, "output", "o", outputTable, fmt.Sprintf("Output format, one of: %s, %s", outputTable, outputJSON))
+	trendCmd.Flags().StringVar(&trendProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+}
+
+// sparkTicks are the characters used to draw sparklines, from low to high.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+func trend(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	if trendOutputFormat != outputTable && trendOutputFormat != outputJSON {
+		return usageErrorf("invalid output format %q, must be one of: %s, %s", trendOutputFormat, outputTable, outputJSON)
+	}
+
+	var info io.Writer = os.Stdout
+	if trendOutputFormat != outputTable {
+		info = os.Stderr
+	}
+
+	read, exists := reportReaders[trendProvider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", trendProvider, strings.Join(providers, ", "))
+	}
+
+	summary, err := analysePath(cmd.Context(), args[0], read, nil, summaryOptions{period: monthPeriod})
+	if err != nil {
+		return exitErrorf(readErrorCode(err), "could not analyse %s: %w", args[0], err)
+	}
+	fmt.Fprintf(info, "Processed %d lines about usage, %s - %s\n\n", summary.LineCount, summary.EarliestDate, summary.LatestDate)
+
+	rows, _ := computeEmissions(cmd.Context(), summary, defaultEmissionOptions())
+	points := timeSeries(nil, rows, monthPeriod)
+
+	switch trendOutputFormat {
+	case outputTable:
+		writeTrendTable(os.Stdout, points)
+	case outputJSON:
+		err = writeSeriesJSON(os.Stdout, nil, points)
+	}
+	if err != nil {
+		return exitErrorf(exitIO, "could not write output: %w", err)
+	}
+	return nil
+}
+
+// sparkline draws one character per value, scaled to the largest value.
+func sparkline(values []float64) string {
+	var max float64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(v / max * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[i])
+	}
+	return b.String()
+}
+
+// writeTrendTable writes the emissions per month with the change to the
+// previous month, followed by a sparkline of all months.
+func writeTrendTable(w io.Writer, points []SeriesPoint) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Month", "Energy", "Emissions", "Change"})
+
+	var values []float64
+	for i, p := range points {
+		change := "-"
+		if i > 0 {
+			change = formatChange(points[i-1].EmissionGrams, p.EmissionGrams)
+		}
+		table.Append([]string{
+			p.Period.Format(periodLayouts[periodMonth]),
+			formatKiloWattHours(p.EnergyKiloWattHours),
+			formatGrams(p.EmissionGrams),
+			change,
+		})
+		values = append(values, p.EmissionGrams)
+	}
+
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetColumnSeparator("")
+	table.SetCenterSeparator("")
+	table.SetRowSeparator("")
+	table.SetBorder(false)
+	table.SetTablePadding("   ")
+	table.Render()
+
+	fmt.Fprintf(w, "\nTrend: %s\n", sparkline(values))
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_sparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{[]float64{0, 4, 7}, "▁▅█"},
+		{[]float64{10, 10}, "██"},
+		{[]float64{0, 0}, "▁▁"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func Test_writeTrendTable(t *testing.T) {
+	points := []SeriesPoint{
+		{Period: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), EmissionGrams: 200},
+		{Period: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), EmissionGrams: 100},
+	}
+
+	var buf bytes.Buffer
+	writeTrendTable(&buf, points)
+
+	for _, want := range []string{"2022-07", "2022-08", "-50.0%", "Trend: █▄"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeTrendTable() output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
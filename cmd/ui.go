@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui PATH...",
+	Short: "Serve a web dashboard of the emissions of usage reports",
+	Long: `Serve a web dashboard of the emissions of usage reports.
+
+The reports given as PATH are analysed once at startup, accepting the same
+paths as the analyse command, including directories and S3 prefixes. The
+dashboard shows the total emissions, energy and water, a chart of the
+emissions per region and a table of the emissions per region, instance type
+and account, which can be filtered and limited to a time range.
+
+The dashboard is served at / and gets its data from the same API as the
+serve command, at /v1/emissions. With --refresh, the paths are analysed
+again periodically.
+
+Example:
+
+  cloud-carbon ui --listen localhost:8080 s3://BUCKET/PREFIX
+`,
+	RunE: ui,
+	Args: configArgs(cobra.MinimumNArgs(1)),
+}
+
+var (
+	uiAddress  string
+	uiProvider string
+	uiRefresh  time.Duration
+)
+
+func init() {
+	uiCmd.Flags().StringVar(&uiAddress, "listen", "localhost:8080", "Address to listen on")
+	uiCmd.Flags().StringVar(&uiProvider, "provider", providerAWS, fmt.Sprintf("Cloud provider the reports are from, one of: %s", strings.Join(providers, ", ")))
+	uiCmd.Flags().DurationVar(&uiRefresh, "refresh", 0, "Interval for analysing the reports again, e.g. 1h. Disabled by default")
+}
+
+// uiFiles holds the static assets of the dashboard.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+func ui(cmd *cobra.Command, args []string) error {
+	args = commandArgs(cmd, args)
+
+	read, exists := reportReaders[uiProvider]
+	if !exists {
+		return usageErrorf("invalid provider %q, must be one of: %s", uiProvider, strings.Join(providers, ", "))
+	}
+
+	server, err := newEmissionsServer(cmd.Context(), args, read, uiRefresh)
+	if err != nil {
+		return exitErrorf(readErrorCode(err), "could not analyse reports: %w", err)
+	}
+
+	log.Printf("Serving dashboard of %d aggregate rows on http://%s/", server.len(), uiAddress)
+	err = http.ListenAndServe(uiAddress, uiHandler(server))
+	return exitErrorf(exitIO, "could not serve on %s: %w", uiAddress, err)
+}
+
+// uiHandler returns the handler serving the dashboard assets and the
+// emissions API of server.
+func uiHandler(server *emissionsServer) http.Handler {
+	assets, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", server.handler())
+	mux.Handle("/", http.FileServerFS(assets))
+	return mux
+}
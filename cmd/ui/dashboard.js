@@ -0,0 +1,185 @@
+// Dashboard of the emissions served at /v1/emissions by cloud-carbon ui.
+"use strict";
+
+const tableDimensions = ["region", "instance-type", "account"];
+
+let rows = [];
+let sortKey = "emission_grams";
+let sortAscending = false;
+
+// Formats like the analyse command, e.g. 1,234.5 kgCO2e.
+function formatNumber(value, digits) {
+  return value.toLocaleString("en-US", { minimumFractionDigits: digits, maximumFractionDigits: digits });
+}
+
+function formatGrams(g) {
+  if (g > 1000 * 1000) {
+    return formatNumber(g / 1000 / 1000, 1) + " MTCO2e";
+  }
+  if (g > 1000) {
+    return formatNumber(g / 1000, 1) + " kgCO2e";
+  }
+  return formatNumber(g, 0) + " gCO2e";
+}
+
+function formatKiloWattHours(kwh) {
+  if (kwh > 1000) {
+    return formatNumber(kwh / 1000, 1) + " MWh";
+  }
+  if (kwh > 1) {
+    return formatNumber(kwh, 1) + " kWh";
+  }
+  return formatNumber(kwh * 1000, 0) + " Wh";
+}
+
+function formatLiters(l) {
+  if (l > 1000) {
+    return formatNumber(l / 1000, 1) + " m³";
+  }
+  if (l > 1) {
+    return formatNumber(l, 1) + " L";
+  }
+  return formatNumber(l, 2) + " L";
+}
+
+function sum(points, key) {
+  return points.reduce((total, p) => total + p[key], 0);
+}
+
+async function fetchSeries(groupBy) {
+  const query = new URLSearchParams({ group_by: groupBy });
+  const form = new FormData(document.getElementById("range"));
+  for (const name of ["from", "to"]) {
+    if (form.get(name)) {
+      query.set(name, form.get(name));
+    }
+  }
+  const response = await fetch("v1/emissions?" + query);
+  if (!response.ok) {
+    throw new Error(await response.text());
+  }
+  return (await response.json()).series;
+}
+
+function renderTotals(points) {
+  document.getElementById("total-emissions").textContent = formatGrams(sum(points, "emission_grams"));
+  document.getElementById("total-operational").textContent = formatGrams(sum(points, "operational_grams"));
+  document.getElementById("total-embodied").textContent = formatGrams(sum(points, "embodied_grams"));
+  document.getElementById("total-energy").textContent = formatKiloWattHours(sum(points, "energy_kwh"));
+  document.getElementById("total-water").textContent = formatLiters(sum(points, "water_liters"));
+}
+
+// renderChart draws a horizontal bar per region, split into operational and
+// embodied emissions, with the largest emitter first.
+function renderChart(points) {
+  const labelWidth = 160, barWidth = 480, valueWidth = 120, barHeight = 24;
+  const svg = document.getElementById("chart");
+  const ns = "http://www.w3.org/2000/svg";
+  points = [...points].sort((a, b) => b.emission_grams - a.emission_grams);
+  const max = Math.max(...points.map((p) => p.emission_grams), 1);
+
+  svg.replaceChildren();
+  svg.setAttribute("viewBox", `0 0 ${labelWidth + barWidth + valueWidth} ${points.length * barHeight}`);
+  points.forEach((p, i) => {
+    const y = i * barHeight;
+    const label = document.createElementNS(ns, "text");
+    label.setAttribute("x", 0);
+    label.setAttribute("y", y + 16);
+    label.textContent = p.labels.region;
+    svg.append(label);
+
+    let x = labelWidth;
+    for (const [key, className] of [["operational_grams", "operational"], ["embodied_grams", "embodied"]]) {
+      const width = (p[key] / max) * barWidth;
+      const rect = document.createElementNS(ns, "rect");
+      rect.setAttribute("class", className);
+      rect.setAttribute("x", x);
+      rect.setAttribute("y", y + 4);
+      rect.setAttribute("width", width);
+      rect.setAttribute("height", barHeight - 8);
+      svg.append(rect);
+      x += width;
+    }
+
+    const value = document.createElementNS(ns, "text");
+    value.setAttribute("x", x + 6);
+    value.setAttribute("y", y + 16);
+    value.textContent = formatGrams(p.emission_grams);
+    svg.append(value);
+  });
+}
+
+function cellValue(row, key) {
+  return key in row.labels ? row.labels[key] : row[key];
+}
+
+// renderTable shows the rows matching the filter, sorted by the selected
+// column, and their sums.
+function renderTable() {
+  const terms = document.getElementById("filter").value.toLowerCase().split(/\s+/).filter(Boolean);
+  const filtered = rows.filter((row) => {
+    const text = tableDimensions.map((d) => row.labels[d]).join(" ").toLowerCase();
+    return terms.every((term) => text.includes(term));
+  });
+  filtered.sort((a, b) => {
+    const x = cellValue(a, sortKey), y = cellValue(b, sortKey);
+    const order = typeof x === "number" ? x - y : x.localeCompare(y);
+    return sortAscending ? order : -order;
+  });
+
+  const body = document.getElementById("rows");
+  body.replaceChildren(...filtered.map((row) => {
+    const tr = document.createElement("tr");
+    for (const d of tableDimensions) {
+      const td = document.createElement("td");
+      td.textContent = row.labels[d];
+      tr.append(td);
+    }
+    for (const text of [formatKiloWattHours(row.energy_kwh), formatGrams(row.emission_grams), formatLiters(row.water_liters)]) {
+      const td = document.createElement("td");
+      td.className = "number";
+      td.textContent = text;
+      tr.append(td);
+    }
+    return tr;
+  }));
+
+  document.getElementById("row-count").textContent = `${filtered.length} of ${rows.length} rows`;
+  document.getElementById("filtered-energy").textContent = formatKiloWattHours(sum(filtered, "energy_kwh"));
+  document.getElementById("filtered-emissions").textContent = formatGrams(sum(filtered, "emission_grams"));
+  document.getElementById("filtered-water").textContent = formatLiters(sum(filtered, "water_liters"));
+  for (const th of document.querySelectorAll("th")) {
+    th.classList.toggle("sorted", th.dataset.key === sortKey);
+    th.classList.toggle("ascending", th.dataset.key === sortKey && sortAscending);
+  }
+}
+
+async function load() {
+  const error = document.getElementById("error");
+  try {
+    const [regions, table] = await Promise.all([fetchSeries("region"), fetchSeries(tableDimensions.join(","))]);
+    error.hidden = true;
+    renderTotals(regions);
+    renderChart(regions);
+    rows = table;
+    renderTable();
+  } catch (e) {
+    error.textContent = "Could not load emissions: " + e.message;
+    error.hidden = false;
+  }
+}
+
+document.getElementById("range").addEventListener("submit", (event) => {
+  event.preventDefault();
+  load();
+});
+document.getElementById("filter").addEventListener("input", renderTable);
+for (const th of document.querySelectorAll("th")) {
+  th.addEventListener("click", () => {
+    sortAscending = th.dataset.key === sortKey ? !sortAscending : !th.classList.contains("number");
+    sortKey = th.dataset.key;
+    renderTable();
+  });
+}
+
+load();
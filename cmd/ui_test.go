@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_uiHandler(t *testing.T) {
+	server, err := newEmissionsServer(context.Background(), []string{"testdata/replay-usage.csv"}, analyseReport, 0)
+	if err != nil {
+		t.Fatalf("newEmissionsServer() error = %v", err)
+	}
+	handler := uiHandler(server)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	index := get("/")
+	if index.Code != http.StatusOK || !strings.Contains(index.Body.String(), "<title>Cloud carbon footprint</title>") {
+		t.Fatalf("GET / = %d, want the dashboard: %s", index.Code, index.Body.String())
+	}
+
+	// All assets linked from the dashboard are embedded.
+	for _, match := range regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(index.Body.String(), -1) {
+		if rec := get("/" + match[1]); rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("GET /%s = %d", match[1], rec.Code)
+		}
+	}
+
+	if rec := get("/v1/emissions?group_by=region,instance-type,account"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"account"`) {
+		t.Errorf("GET /v1/emissions = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("/missing.js"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /missing.js = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
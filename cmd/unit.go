@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Units emissions can be shown in with --unit.
+const (
+	unitGrams     = "g"
+	unitKilograms = "kg"
+	unitTonnes    = "t"
+	unitPounds    = "lb"
+)
+
+var unitNames = []string{unitGrams, unitKilograms, unitTonnes, unitPounds}
+
+// emissionUnit is a unit of emissions.
+type emissionUnit struct {
+	// grams is the size of the unit in grams.
+	grams float64
+
+	// symbol is appended to values, e.g. "kgCO2e".
+	symbol string
+
+	// precision is the number of decimal places shown, so that values
+	// are shown to about a gram in every unit.
+	precision int
+}
+
+// emissionUnits maps the names of units to the units.
+var emissionUnits = map[string]emissionUnit{
+	unitGrams:     {grams: 1, symbol: "gCO2e", precision: 0},
+	unitKilograms: {grams: 1000, symbol: "kgCO2e", precision: 3},
+	unitTonnes:    {grams: 1000 * 1000, symbol: "MTCO2e", precision: 6},
+	unitPounds:    {grams: 453.59237, symbol: "lbCO2e", precision: 3},
+}
+
+// unit is the unit all emissions are shown in, or empty to choose a unit
+// per value.
+var unit string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&unit, "unit", "", fmt.Sprintf("Show all emissions in this unit instead of choosing a unit per value, one of: %s", strings.Join(unitNames, ", ")))
+}
+
+// format formats an amount of emissions given in grams in the unit.
+func (u emissionUnit) format(g float64) string {
+	return formatNumber(g/u.grams, u.precision) + " " + u.symbol
+}
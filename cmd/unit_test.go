@@ -0,0 +1,36 @@
+package cmd
+
+import "testing"
+
+func Test_formatGrams_unit(t *testing.T) {
+	t.Cleanup(func() { unit = "" })
+
+	tests := []struct {
+		unit string
+		g    float64
+		want string
+	}{
+		{unit: "", g: 584, want: "584 gCO2e"},
+		{unit: "", g: 1500, want: "1.5 kgCO2e"},
+		{unit: "", g: 2500000, want: "2.5 MTCO2e"},
+		{unit: unitGrams, g: 2500000, want: "2500000 gCO2e"},
+		{unit: unitKilograms, g: 584, want: "0.584 kgCO2e"},
+		{unit: unitTonnes, g: 1500, want: "0.001500 MTCO2e"},
+		{unit: unitPounds, g: 453.59237 * 2, want: "2.000 lbCO2e"},
+	}
+
+	for _, tt := range tests {
+		unit = tt.unit
+		if got := formatGrams(tt.g); got != tt.want {
+			t.Errorf("formatGrams(%v) with --unit %q = %q, want %q", tt.g, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func Test_emissionUnits(t *testing.T) {
+	for _, name := range unitNames {
+		if _, exists := emissionUnits[name]; !exists {
+			t.Errorf("unit %s is not defined", name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+	"github.com/giantswarm/cloud-carbon/pkg/pipeline"
+)
+
+// Usage categories of AWS. Each category covers one kind of resource and is
+// estimated with its own model.
+const (
+	categoryEC2     = cur.CategoryEC2
+	categoryEBS     = cur.CategoryEBS
+	categoryNetwork = cur.CategoryNetwork
+	categoryS3      = cur.CategoryS3
+	categoryRDS     = cur.CategoryRDS
+	categoryLambda  = cur.CategoryLambda
+	categoryFargate = cur.CategoryFargate
+)
+
+// Purchase options of AWS usage.
+const (
+	purchaseOnDemand    = cur.PurchaseOnDemand
+	purchaseSpot        = cur.PurchaseSpot
+	purchaseReserved    = cur.PurchaseReserved
+	purchaseSavingsPlan = cur.PurchaseSavingsPlan
+)
+
+// readUsageAmountColumn returns the usage amount of a report row from the
+// given column.
+func readUsageAmountColumn(headers reportHeaders, fields []string, column string) (float64, error) {
+	amount := headers.value(fields, column)
+	val, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing usage amount %q as float: %s", amount, err)
+	}
+	return val, nil
+}
+
+// estimateEmissions returns the footprint of an aggregate row, using the model for the row's usage category. The CPU
+// utilization in percent is applied to EC2 and RDS instances.
+func estimateEmissions(row AggregateReportRow, utilization float64) (footprint.Result, error) {
+	switch row.Category {
+	case categoryEC2:
+		return calculator.AWSAtUtilization(row.Region, row.InstanceType, utilization, row.Duration)
+	case categoryEBS:
+		return calculator.AWSStorage(row.Region, row.StorageType, row.GBHours)
+	case categoryNetwork:
+		return calculator.AWSNetwork(row.Region, row.TransferGB)
+	case categoryS3:
+		return calculator.AWSObjectStorage(row.Region, row.GBHours)
+	case categoryRDS:
+		return calculator.AWSRDSAtUtilization(row.Region, row.InstanceType, utilization, row.Duration, row.MultiAZ)
+	case categoryLambda:
+		return calculator.AWSLambda(row.Region, row.GBHours)
+	case categoryFargate:
+		return calculator.AWSFargate(row.Region, row.VCPUHours, row.GBHours)
+	case categoryGCE:
+		return calculator.GCP(row.Region, row.InstanceType, row.Duration)
+	case categoryAzureVM:
+		return calculator.Azure(row.Region, row.InstanceType, row.Duration)
+	}
+	if e, exists := pipeline.LookupEmitter(row.Category); exists {
+		return e.Estimate(calculator, pipelineUsage(row))
+	}
+	return footprint.Result{}, fmt.Errorf("unknown usage category %q", row.Category)
+}
+
+// instanceVCPUHours returns the vCPU time of the instances or tasks of an
+// aggregate row, or zero for other usage and instance types of unknown size.
+// Like the emissions, it includes the standby instance of Multi-AZ RDS
+// deployments.
+func instanceVCPUHours(row AggregateReportRow) float64 {
+	var vcpus float64
+	var err error
+	switch row.Category {
+	case categoryEC2:
+		vcpus, err = calculator.VCPUs(row.InstanceType)
+	case categoryRDS:
+		vcpus, err = calculator.VCPUs(row.InstanceType)
+		if row.MultiAZ {
+			vcpus *= 2
+		}
+	case categoryFargate:
+		return row.VCPUHours
+	case categoryGCE:
+		vcpus, err = calculator.GCPMachineTypeVCPUs(row.InstanceType)
+	case categoryAzureVM:
+		vcpus, err = calculator.AzureVMSizeVCPUs(row.InstanceType)
+	}
+	if err != nil {
+		return 0
+	}
+	return vcpus * row.Duration.Hours()
+}
+
+// estimateWithFallback returns the footprint of an aggregate row like
+// estimateEmissions, estimating EC2 and RDS instance types missing from the
+// dataset with the given fallback method, e.g. footprint.FallbackFamily. For
+// rows estimated this way, a description of the estimate is returned.
+func estimateWithFallback(row AggregateReportRow, utilization float64, fallback string) (footprint.Result, string, error) {
+	if fallback != "" && fallback != footprint.FallbackNone {
+		switch row.Category {
+		case categoryEC2:
+			return calculator.AWSEstimatedAtUtilization(row.Region, row.InstanceType, fallback, utilization, row.Duration)
+		case categoryRDS:
+			return calculator.AWSRDSEstimatedAtUtilization(row.Region, row.InstanceType, fallback, utilization, row.Duration, row.MultiAZ)
+		}
+	}
+	result, err := estimateEmissions(row, utilization)
+	return result, "", err
+}
+
+// marketBased returns the footprint of an aggregate row with operational
+// emissions based on the market-based carbon intensity. This is only
+// available for AWS usage.
+func marketBased(row AggregateReportRow, result footprint.Result) (footprint.Result, error) {
+	switch row.Category {
+	case categoryGCE, categoryAzureVM:
+		return footprint.Result{}, fmt.Errorf("no market-based carbon intensity for category %q", row.Category)
+	}
+	return calculator.AWSMarketBased(row.Region, result)
+}
+
+// hourlyBased returns the footprint of an aggregate row with operational
+// emissions based on the carbon intensity during the hour of the row's
+// period. This is only available for AWS usage.
+func hourlyBased(ctx context.Context, row AggregateReportRow, result footprint.Result, source footprint.CarbonIntensityProvider) (footprint.Result, error) {
+	switch row.Category {
+	case categoryGCE, categoryAzureVM:
+		return footprint.Result{}, fmt.Errorf("no hourly carbon intensity for category %q", row.Category)
+	}
+	ci, err := source.HourlyCarbonIntensity(ctx, row.Region, row.Period)
+	if err != nil {
+		return footprint.Result{}, err
+	}
+	return result.WithCarbonIntensity(ci), nil
+}
+
+// formatUsage returns a human-readable description of the usage in a row.
+// Rows combining several categories list the usage of each unit.
+func formatUsage(row AggregateReportRow) string {
+	var parts []string
+	if row.Duration > 0 {
+		if row.MultiAZ {
+			parts = append(parts, row.Duration.String()+" (Multi-AZ)")
+		} else {
+			parts = append(parts, row.Duration.String())
+		}
+	}
+	if row.VCPUHours > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f vCPU-h", row.VCPUHours))
+	}
+	if row.GBHours > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f GB-h", row.GBHours))
+	}
+	if row.TransferGB > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f GB transferred", row.TransferGB))
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return strings.Join(parts, ", ")
+}
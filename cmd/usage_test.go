@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_marketBased(t *testing.T) {
+	result := footprint.Result{EnergyKiloWattHours: 1, OperationalGrams: 338, EmbodiedGrams: 5}
+
+	tests := []struct {
+		name    string
+		row     AggregateReportRow
+		want    footprint.Result
+		wantErr bool
+	}{
+		{
+			name: "EC2 in renewable region",
+			row:  AggregateReportRow{Category: categoryEC2, Region: "eu-central-1"},
+			want: footprint.Result{EnergyKiloWattHours: 1, OperationalGrams: 0, EmbodiedGrams: 5},
+		},
+		{
+			name: "S3 in other region",
+			row:  AggregateReportRow{Category: categoryS3, Region: "ap-southeast-2"},
+			want: footprint.Result{EnergyKiloWattHours: 1, OperationalGrams: 790, EmbodiedGrams: 5},
+		},
+		{
+			name:    "GCP",
+			row:     AggregateReportRow{Category: categoryGCE, Region: "europe-west1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marketBased(tt.row, result)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("marketBased() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("marketBased() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_instanceVCPUHours(t *testing.T) {
+	tests := []struct {
+		name string
+		row  AggregateReportRow
+		want float64
+	}{
+		{name: "EC2", row: AggregateReportRow{Category: categoryEC2, InstanceType: "m5.xlarge", Duration: 10 * time.Hour}, want: 40},
+		{name: "RDS Multi-AZ", row: AggregateReportRow{Category: categoryRDS, InstanceType: "db.m5.large", Duration: 10 * time.Hour, MultiAZ: true}, want: 40},
+		{name: "Fargate", row: AggregateReportRow{Category: categoryFargate, VCPUHours: 12}, want: 12},
+		{name: "GCE", row: AggregateReportRow{Category: categoryGCE, InstanceType: "n1-standard-4", Duration: time.Hour}, want: 4},
+		{name: "Azure", row: AggregateReportRow{Category: categoryAzureVM, InstanceType: "Standard_D2s_v3", Duration: time.Hour}, want: 2},
+		{name: "EBS", row: AggregateReportRow{Category: categoryEBS, GBHours: 100}, want: 0},
+		{name: "unknown instance type", row: AggregateReportRow{Category: categoryEC2, InstanceType: "m5.unknown", Duration: time.Hour}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceVCPUHours(tt.row); got != tt.want {
+				t.Errorf("instanceVCPUHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
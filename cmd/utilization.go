@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Columns of a utilization file.
+const (
+	utilizationHeaderAccount      = "account"
+	utilizationHeaderInstanceType = "instance_type"
+	utilizationHeaderUtilization  = "utilization"
+)
+
+// utilizationKey identifies the instances a utilization value applies to.
+// An empty account matches all accounts.
+type utilizationKey struct {
+	account      string
+	instanceType string
+}
+
+// utilizationTable holds the average CPU utilization of instances in
+// percent, as measured e. g. with CloudWatch, and the value assumed for
+// instances without a measurement.
+type utilizationTable struct {
+	defaultValue float64
+	values       map[utilizationKey]float64
+}
+
+// fixedUtilization returns a table assuming the same utilization for all
+// instances.
+func fixedUtilization(percent float64) utilizationTable {
+	return utilizationTable{defaultValue: percent}
+}
+
+// readUtilizationFile reads the average CPU utilization per instance type,
+// and optionally account, from a CSV file with the columns instance_type,
+// utilization and account. Instances not found in the file are assumed to
+// run at defaultValue.
+func readUtilizationFile(path string, defaultValue float64) (utilizationTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return utilizationTable{}, err
+	}
+	defer f.Close()
+
+	return readUtilization(f, defaultValue)
+}
+
+func readUtilization(r io.Reader, defaultValue float64) (utilizationTable, error) {
+	table := utilizationTable{
+		defaultValue: defaultValue,
+		values:       make(map[utilizationKey]float64),
+	}
+
+	processedHeaders := false
+	var headers reportHeaders
+
+	fcsv := csv.NewReader(r)
+	for {
+		record, err := fcsv.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return table, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		if !processedHeaders {
+			headers = newReportHeaders(record)
+			for _, column := range []string{utilizationHeaderInstanceType, utilizationHeaderUtilization} {
+				if _, exists := headers.index[column]; !exists {
+					return table, fmt.Errorf("missing column %q", column)
+				}
+			}
+			processedHeaders = true
+			continue
+		}
+
+		line, _ := fcsv.FieldPos(0)
+
+		key := utilizationKey{
+			account:      strings.TrimSpace(headers.value(record, utilizationHeaderAccount)),
+			instanceType: strings.TrimSpace(headers.value(record, utilizationHeaderInstanceType)),
+		}
+		if key.instanceType == "" {
+			return table, fmt.Errorf("line %d: missing instance type", line)
+		}
+		value := headers.value(record, utilizationHeaderUtilization)
+		utilization, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return table, fmt.Errorf("line %d: error parsing utilization %q as float", line, value)
+		}
+		if utilization < 0 || utilization > 100 {
+			return table, fmt.Errorf("line %d: utilization %g must be between 0 and 100", line, utilization)
+		}
+
+		table.values[key] = utilization
+	}
+
+	return table, nil
+}
+
+// hasAccounts returns whether the table holds values for specific accounts.
+func (u utilizationTable) hasAccounts() bool {
+	for key := range u.values {
+		if key.account != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// withAccountNames returns the table with accounts referred to by the names
+// given in --account-names, like the labels of the account dimension.
+// Accounts sharing a name must have the same values.
+func (u utilizationTable) withAccountNames(names map[string]string) (utilizationTable, error) {
+	result := utilizationTable{defaultValue: u.defaultValue, values: make(map[utilizationKey]float64, len(u.values))}
+	for key, value := range u.values {
+		if name, exists := names[key.account]; exists {
+			key.account = name
+		}
+		if existing, exists := result.values[key]; exists && existing != value {
+			return u, fmt.Errorf("accounts named %q have different utilization values for %s", key.account, key.instanceType)
+		}
+		result.values[key] = value
+	}
+	return result, nil
+}
+
+// withRedaction returns the table with accounts redacted like the labels of
+// the account dimension.
+func (u utilizationTable) withRedaction(r *redactor) utilizationTable {
+	result := utilizationTable{defaultValue: u.defaultValue, values: make(map[utilizationKey]float64, len(u.values))}
+	for key, value := range u.values {
+		key.account = r.label(key.account)
+		result.values[key] = value
+	}
+	return result
+}
+
+// lookup returns the utilization of an instance type in an account. Values
+// for the specific account take precedence over those for all accounts.
+func (u utilizationTable) lookup(account, instanceType string) float64 {
+	if value, exists := u.values[utilizationKey{account, instanceType}]; exists {
+		return value
+	}
+	if value, exists := u.values[utilizationKey{"", instanceType}]; exists {
+		return value
+	}
+	return u.defaultValue
+}
+
+// rowUtilization returns the utilization to assume for the instances of an
+// aggregate row. The account is only known if usage is grouped by account.
+func (u utilizationTable) rowUtilization(dimensions []Dimension, row AggregateReportRow) float64 {
+	account := ""
+	for i, d := range dimensions {
+		if d.Name == accountDimension && i < len(row.Labels) {
+			account = row.Labels[i]
+		}
+	}
+	return u.lookup(account, row.InstanceType)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_readUtilization(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    map[utilizationKey]float64
+		wantErr bool
+	}{
+		{
+			name: "with accounts",
+			csv:  "account,instance_type,utilization\n,m5.large,20\n123456789012,m5.large,35.5\n,db.r5.xlarge,60\n",
+			want: map[utilizationKey]float64{
+				{"", "m5.large"}:             20,
+				{"123456789012", "m5.large"}: 35.5,
+				{"", "db.r5.xlarge"}:         60,
+			},
+		},
+		{
+			name: "without account column",
+			csv:  "utilization,instance_type\n12,t3.micro\n",
+			want: map[utilizationKey]float64{
+				{"", "t3.micro"}: 12,
+			},
+		},
+		{name: "missing utilization column", csv: "instance_type\nt3.micro\n", wantErr: true},
+		{name: "missing instance type", csv: "instance_type,utilization\n,12\n", wantErr: true},
+		{name: "invalid utilization", csv: "instance_type,utilization\nt3.micro,high\n", wantErr: true},
+		{name: "utilization out of range", csv: "instance_type,utilization\nt3.micro,120\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readUtilization(strings.NewReader(tt.csv), 50)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readUtilization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.values) != len(tt.want) {
+				t.Fatalf("readUtilization() got %d values, want %d", len(got.values), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if got.values[key] != want {
+					t.Errorf("readUtilization() value for %v = %v, want %v", key, got.values[key], want)
+				}
+			}
+		})
+	}
+}
+
+func Test_utilizationTable_rowUtilization(t *testing.T) {
+	table, err := readUtilization(strings.NewReader("account,instance_type,utilization\n,m5.large,20\n123456789012,m5.large,35\n"), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		groupBy string
+		row     AggregateReportRow
+		want    float64
+	}{
+		{
+			name:    "all accounts",
+			groupBy: "region,instance-type",
+			row:     AggregateReportRow{Labels: []string{"eu-west-1", "m5.large"}, InstanceType: "m5.large"},
+			want:    20,
+		},
+		{
+			name:    "specific account",
+			groupBy: "account,instance-type",
+			row:     AggregateReportRow{Labels: []string{"123456789012", "m5.large"}, InstanceType: "m5.large"},
+			want:    35,
+		},
+		{
+			name:    "other account",
+			groupBy: "account,instance-type",
+			row:     AggregateReportRow{Labels: []string{"210987654321", "m5.large"}, InstanceType: "m5.large"},
+			want:    20,
+		},
+		{
+			name:    "not measured",
+			groupBy: "account,instance-type",
+			row:     AggregateReportRow{Labels: []string{"123456789012", "t3.micro"}, InstanceType: "t3.micro"},
+			want:    50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := table.rowUtilization(testDimensions(t, tt.groupBy), tt.row)
+			if got != tt.want {
+				t.Errorf("rowUtilization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_utilizationTable_withAccountNames(t *testing.T) {
+	table, err := readUtilization(strings.NewReader("account,instance_type,utilization\n,m5.large,20\n111111111111,m5.large,35\n222222222222,m5.large,35\n333333333333,m5.large,40\n"), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	named, err := table.withAccountNames(map[string]string{"111111111111": "Platform", "222222222222": "Platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for account, want := range map[string]float64{"Platform": 35, "333333333333": 40, "Data": 20} {
+		if got := named.lookup(account, "m5.large"); got != want {
+			t.Errorf("lookup(%q) = %v, want %v", account, got, want)
+		}
+	}
+
+	if _, err := table.withAccountNames(map[string]string{"111111111111": "Platform", "333333333333": "Platform"}); err == nil {
+		t.Error("withAccountNames() accepted different values for accounts of the same name")
+	}
+}
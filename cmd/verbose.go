@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+var verbose bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log the model inputs of each aggregate row, like PUE, carbon intensity, power and embodied emissions, to audit results line by line")
+}
+
+// modelInputs returns the inputs of the model for an aggregate row with the
+// given result, as attributes for logging. The carbon intensity, power and
+// embodied emissions are derived from the result, so that they are the
+// values actually applied, whatever the intensity mode or fallback.
+func modelInputs(row AggregateReportRow, utilization float64, estimate string, result footprint.Result) []slog.Attr {
+	var attrs []slog.Attr
+	if len(row.Labels) > 0 {
+		attrs = append(attrs, slog.String("group", strings.Join(row.Labels, ",")))
+	}
+	attrs = append(attrs, slog.String("category", row.Category), slog.String("region", row.Region))
+	if row.InstanceType != "" {
+		attrs = append(attrs, slog.String("instance_type", row.InstanceType))
+	}
+	if row.StorageType != "" {
+		attrs = append(attrs, slog.String("storage_type", row.StorageType))
+	}
+	if row.MultiAZ {
+		attrs = append(attrs, slog.Bool("multi_az", true))
+	}
+	if estimate != "" {
+		attrs = append(attrs, slog.String("estimated_from", estimate))
+	}
+
+	hours := row.Duration.Hours()
+	if hours > 0 {
+		attrs = append(attrs, slog.Float64("hours", hours), slog.Float64("utilization", utilization))
+	}
+	for _, usage := range []struct {
+		key   string
+		value float64
+	}{
+		{"gb_hours", row.GBHours},
+		{"vcpu_hours", row.VCPUHours},
+		{"transfer_gb", row.TransferGB},
+	} {
+		if usage.value > 0 {
+			attrs = append(attrs, slog.Float64(usage.key, usage.value))
+		}
+	}
+
+	pue, err := rowPUE(row)
+	if err == nil {
+		attrs = append(attrs, slog.Float64("pue", pue))
+	}
+	if result.EnergyKiloWattHours > 0 {
+		attrs = append(attrs, slog.Float64("ci", result.OperationalGrams/result.EnergyKiloWattHours))
+	}
+	if hours > 0 && pue > 0 {
+		attrs = append(attrs, slog.Float64("power_w", result.EnergyKiloWattHours*1000/pue/hours))
+	}
+	if hours > 0 {
+		attrs = append(attrs, slog.Float64("embodied_g_per_hour", result.EmbodiedGrams/hours))
+	}
+
+	return append(attrs,
+		slog.Float64("energy_kwh", result.EnergyKiloWattHours),
+		slog.Float64("operational_g", result.OperationalGrams),
+		slog.Float64("embodied_g", result.EmbodiedGrams),
+	)
+}
+
+// rowPUE returns the PUE of the data centers in the region of a row.
+func rowPUE(row AggregateReportRow) (float64, error) {
+	switch row.Category {
+	case categoryGCE:
+		return calculator.GCPPUE(row.Region)
+	case categoryAzureVM:
+		return calculator.AzurePUE(row.Region)
+	}
+	return calculator.PUE(row.Region)
+}
+
+// logModelInputs logs the model inputs of an aggregate row, as attributes
+// with --log-format json, or else as key=value pairs.
+func logModelInputs(row AggregateReportRow, utilization float64, estimate string, result footprint.Result) {
+	attrs := modelInputs(row, utilization, estimate, result)
+	if jsonLogger != nil {
+		jsonLogger.LogAttrs(context.Background(), slog.LevelInfo, "Model inputs", attrs...)
+		return
+	}
+
+	pairs := make([]string, len(attrs))
+	for i, attr := range attrs {
+		pairs[i] = fmt.Sprintf("%s=%v", attr.Key, attr.Value)
+	}
+	log.Printf("Model inputs: %s", strings.Join(pairs, " "))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func Test_modelInputs(t *testing.T) {
+	row := AggregateReportRow{Labels: []string{"eu-west-1", "m5.large"}, Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.large", Duration: 10 * time.Hour}
+	result := footprint.Result{EnergyKiloWattHours: 1.2, OperationalGrams: 360, EmbodiedGrams: 50}
+
+	got := make(map[string]any)
+	for _, attr := range modelInputs(row, 50, "", result) {
+		got[attr.Key] = attr.Value.Any()
+	}
+
+	pue, err := calculator.PUE("eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"group":               "eu-west-1,m5.large",
+		"instance_type":       "m5.large",
+		"hours":               10.0,
+		"utilization":         50.0,
+		"pue":                 pue,
+		"ci":                  300.0,
+		"power_w":             1.2 * 1000 / pue / 10,
+		"embodied_g_per_hour": 5.0,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("modelInputs() %s = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, exists := got["gb_hours"]; exists {
+		t.Error("modelInputs() has gb_hours for an instance")
+	}
+}
+
+func Test_logModelInputs(t *testing.T) {
+	t.Cleanup(func() {
+		if err := setupLogging(os.Stderr, logFormatText); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := setupLogging(&buf, logFormatText); err != nil {
+		t.Fatal(err)
+	}
+	row := AggregateReportRow{Category: categoryS3, Region: "eu-west-1", GBHours: 100}
+	logModelInputs(row, 50, "", footprint.Result{EnergyKiloWattHours: 0.5, OperationalGrams: 150})
+
+	if got := buf.String(); !strings.Contains(got, "Model inputs: category=S3 region=eu-west-1 gb_hours=100") || !strings.Contains(got, "ci=300") {
+		t.Errorf("logModelInputs() logged %q", got)
+	}
+}
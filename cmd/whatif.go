@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// parseRegionMoves parses values of --move-region of the form SRC=DST into
+// a map from source to destination region.
+func parseRegionMoves(values []string) (map[string]string, error) {
+	moves := make(map[string]string)
+	for _, value := range values {
+		from, to, found := strings.Cut(value, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !found || from == "" || to == "" {
+			return nil, fmt.Errorf("%q is not of the form SRC=DST", value)
+		}
+		if from == to {
+			return nil, fmt.Errorf("%q moves a region to itself", value)
+		}
+		if _, exists := moves[from]; exists {
+			return nil, fmt.Errorf("region %s is moved more than once", from)
+		}
+		moves[from] = to
+	}
+	return moves, nil
+}
+
+// moveRegions returns a copy of the summary with the usage in each source
+// region of moves relocated to the destination region, keeping the instance
+// mix constant. Labels are not changed.
+func moveRegions(s *ReportSummary, moves map[string]string) *ReportSummary {
+	moved := newReportSummary(s.Dimensions)
+	moved.LineCount = s.LineCount
+	moved.addTimeRange(s.EarliestDate, s.LatestDate)
+
+	for _, aggregate := range s.Aggregate {
+		row := *aggregate
+		if to, exists := moves[row.Region]; exists {
+			row.Region = to
+		}
+		moved.addAggregate(row)
+	}
+	return moved
+}
+
+// printWhatIf compares the total footprint before and after moving regions.
+func printWhatIf(w io.Writer, moves map[string]string, before, after footprint.Result) {
+	var descriptions []string
+	for from, to := range moves {
+		descriptions = append(descriptions, fmt.Sprintf("%s to %s", from, to))
+	}
+	sort.Strings(descriptions)
+
+	fmt.Fprintf(w, "\nWhat-if: moving usage from %s\n", strings.Join(descriptions, ", "))
+	fmt.Fprintf(w, "  Emissions: %s instead of %s", formatGrams(after.Total()), formatGrams(before.Total()))
+	if before.Total() > 0 {
+		fmt.Fprintf(w, " (%+.1f%%)", (after.Total()-before.Total())/before.Total()*100)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseRegionMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", want: map[string]string{}},
+		{name: "two moves", values: []string{"ap-southeast-2=eu-north-1", " us-east-1 = ca-central-1 "}, want: map[string]string{"ap-southeast-2": "eu-north-1", "us-east-1": "ca-central-1"}},
+		{name: "missing destination", values: []string{"ap-southeast-2="}, wantErr: true},
+		{name: "no separator", values: []string{"ap-southeast-2"}, wantErr: true},
+		{name: "same region", values: []string{"eu-west-1=eu-west-1"}, wantErr: true},
+		{name: "moved twice", values: []string{"eu-west-1=eu-north-1", "eu-west-1=eu-west-3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRegionMoves(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRegionMoves() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseRegionMoves() = %v, want %v", got, tt.want)
+			}
+			for from, to := range tt.want {
+				if got[from] != to {
+					t.Errorf("parseRegionMoves()[%s] = %s, want %s", from, got[from], to)
+				}
+			}
+		})
+	}
+}
+
+func Test_moveRegions(t *testing.T) {
+	summary := newReportSummary(testDimensions(t, "category"))
+	summary.add(ReportRow{Category: categoryEC2, Region: "ap-southeast-2", InstanceType: "m5.xlarge", Duration: time.Hour})
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-north-1", InstanceType: "m5.xlarge", Duration: time.Hour})
+	summary.add(ReportRow{Category: categoryEC2, Region: "eu-west-1", InstanceType: "m5.xlarge", Duration: time.Hour})
+
+	moved := moveRegions(summary, map[string]string{"ap-southeast-2": "eu-north-1"})
+
+	if len(moved.Aggregate) != 2 {
+		t.Fatalf("moveRegions() returned %d aggregate rows, want 2", len(moved.Aggregate))
+	}
+	durations := make(map[string]time.Duration)
+	for _, row := range moved.Aggregate {
+		durations[row.Region] += row.Duration
+	}
+	if durations["eu-north-1"] != 2*time.Hour || durations["eu-west-1"] != time.Hour {
+		t.Errorf("moveRegions() durations = %v", durations)
+	}
+	if len(summary.Aggregate) != 3 {
+		t.Errorf("moveRegions() modified the original summary")
+	}
+
+	_, before := computeEmissions(context.Background(), summary, defaultEmissionOptions())
+	_, after := computeEmissions(context.Background(), moved, defaultEmissionOptions())
+	if after.Total() >= before.Total() {
+		t.Errorf("moving to eu-north-1 changed emissions from %v to %v, want a reduction", before.Total(), after.Total())
+	}
+
+	var buf bytes.Buffer
+	printWhatIf(&buf, map[string]string{"ap-southeast-2": "eu-north-1"}, before, after)
+	if !strings.Contains(buf.String(), "ap-southeast-2 to eu-north-1") || !strings.Contains(buf.String(), "%)") {
+		t.Errorf("printWhatIf() = %q", buf.String())
+	}
+}
@@ -0,0 +1,172 @@
+// Package cur reads AWS Cost and Usage Reports (CUR), both in the legacy
+// format and in the CUR 2.0 format created with AWS Data Exports.
+//
+// A Reader streams the line items of a report covered by the footprint
+// model, like EC2 instance hours or S3 storage, with their usage converted
+// to the units the model is based on. Line items about other usage, fees,
+// credits or taxes are skipped. Usage of other services is read by
+// registering a Service. To parse lines concurrently, the lines of a report
+// can also be passed to a Parser directly.
+package cur
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Usage categories. Each category covers one kind of resource and is
+// estimated with its own model.
+const (
+	CategoryEC2     = "EC2"
+	CategoryEBS     = "EBS"
+	CategoryNetwork = "Network"
+	CategoryS3      = "S3"
+	CategoryRDS     = "RDS"
+	CategoryLambda  = "Lambda"
+	CategoryFargate = "Fargate"
+)
+
+// Purchase options of usage.
+const (
+	PurchaseOnDemand    = "On-Demand"
+	PurchaseSpot        = "Spot"
+	PurchaseReserved    = "Reserved"
+	PurchaseSavingsPlan = "Savings Plan"
+)
+
+const (
+	headerBillPayerAccountID       = "bill/PayerAccountId"
+	headerIdentityTimeInterval     = "identity/TimeInterval"
+	headerLineItemAvailabilityZone = "lineItem/AvailabilityZone"
+	headerLineItemLineItemType     = "lineItem/LineItemType"
+	headerLineItemOperation        = "lineItem/Operation"
+	headerLineItemProductCode      = "lineItem/ProductCode"
+	headerLineItemResourceID       = "lineItem/ResourceId"
+	headerLineItemUnblendedCost    = "lineItem/UnblendedCost"
+	headerLineItemUsageAccountID   = "lineItem/UsageAccountId"
+	headerLineItemUsageAmount      = "lineItem/UsageAmount"
+	headerLineItemUsageEndDate     = "lineItem/UsageEndDate"
+	headerLineItemUsageStartDate   = "lineItem/UsageStartDate"
+	headerLineItemUsageType        = "lineItem/UsageType"
+	headerProductDeployment        = "product/deploymentOption"
+	headerProductFromRegion        = "product/fromRegionCode"
+	headerProductInstanceType      = "product/instanceType"
+	headerProductLocation          = "product/location"
+	headerProductLocationType      = "product/locationType"
+	headerProductProductFamily     = "product/productFamily"
+	headerProductRegionCode        = "product/regionCode"
+	headerProductVolumeAPI         = "product/volumeApiName"
+
+	// headerPrefixTag is the prefix of columns holding cost allocation
+	// tags. It is followed by "user:" and the key for user-defined tags,
+	// or by the full key for AWS-generated tags, e.g. "aws:createdBy".
+	headerPrefixTag = "resourceTags/"
+	// headerPrefixUserTag is the prefix of columns holding user-defined
+	// cost allocation tags, followed by the tag key.
+	headerPrefixUserTag = headerPrefixTag + "user:"
+
+	dateTimeLayout = "2006-01-02T15:04:05Z"
+
+	// locationTypeOutposts is the location type of usage on AWS Outposts.
+	locationTypeOutposts = "AWS Outposts"
+)
+
+// LineItem is a line of a report about usage covered by the footprint
+// model.
+type LineItem struct {
+	// Category is the usage category of the line item, e.g. CategoryEC2.
+	Category string
+
+	PayerAccountID   string
+	UsageAccountID   string
+	Region           string
+	AvailabilityZone string
+	InstanceType     string
+	ResourceID       string
+	UsageStartTime   time.Time
+	UsageEndTime     time.Time
+
+	// Duration is the instance usage time, for EC2 and RDS line items.
+	Duration time.Duration
+
+	// MultiAZ is set for RDS line items of Multi-AZ deployments.
+	MultiAZ bool
+
+	// StorageType is the EBS volume type or S3 storage class, and GBHours
+	// the amount of storage provisioned or used, for EBS and S3 line
+	// items. For Lambda and Fargate line items, GBHours is the amount of
+	// memory allocated.
+	StorageType string
+	GBHours     float64
+
+	// VCPUHours is the amount of vCPU time allocated, for Fargate line
+	// items.
+	VCPUHours float64
+
+	// TransferGB is the amount of data sent, for network line items.
+	TransferGB float64
+
+	// Tags holds the values of the cost allocation tags requested with
+	// WithTags, keyed by tag key as given. Tags without a value are
+	// omitted.
+	Tags map[string]string
+
+	// Cost is the unblended cost of the usage in the billing currency.
+	Cost float64
+
+	// ProductCode is the code of the service the usage is billed by, e.g.
+	// "AmazonEC2". Network usage is billed by the service sending the data.
+	ProductCode string
+
+	// PurchaseOption is how the usage was paid for, e.g. PurchaseSpot.
+	PurchaseOption string
+
+	// Cluster and NodeGroup are the Kubernetes cluster and EKS node group
+	// of an EC2 instance, if tagged.
+	Cluster   string
+	NodeGroup string
+
+	// Outpost is the location of the AWS Outposts rack the usage ran on,
+	// as given by the product/location column, or empty for usage in AWS
+	// data centers. Region is the parent region of the Outpost.
+	Outpost string
+}
+
+// TagColumn returns the name of the report column holding the tag with
+// the given key. Keys starting with "aws:" refer to AWS-generated tags, all
+// other keys to user-defined tags. The "user:" prefix is optional for
+// user-defined tags.
+func TagColumn(key string) string {
+	if strings.HasPrefix(key, "aws:") || strings.HasPrefix(key, "user:") {
+		return headerPrefixTag + key
+	}
+	return headerPrefixUserTag + key
+}
+
+// DateError is returned for a line with an invalid date. Callers may skip
+// such lines rather than fail.
+type DateError struct {
+	// Column is the column of the date, by its name in the legacy format.
+	Column string
+
+	// Value is the invalid value.
+	Value string
+}
+
+func (e *DateError) Error() string {
+	return fmt.Sprintf("invalid %s %q", e.Column, e.Value)
+}
+
+// parseDate parses a point in time in the given column of a report. Empty
+// values are the zero time.
+func parseDate(column, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	dateTime, err := time.Parse(dateTimeLayout, s)
+	if err != nil {
+		return time.Time{}, &DateError{Column: column, Value: s}
+	}
+	return dateTime, nil
+}
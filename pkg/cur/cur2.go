@@ -0,0 +1,168 @@
+package cur
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// Columns of CUR 2.0 reports, created with AWS Data Exports, holding
+// attributes as a JSON map.
+const (
+	cur2ColumnProduct      = "product"
+	cur2ColumnResourceTags = "resource_tags"
+)
+
+// cur2Prefixes maps the prefixes of CUR 2.0 column names to those of the
+// legacy column names. CUR 2.0 uses snake case, e.g. line_item_usage_type,
+// where the legacy format uses camel case, e.g. lineItem/UsageType. The
+// attribute names start in upper case, except for product and pricing
+// columns.
+var cur2Prefixes = []struct {
+	cur2   string
+	legacy string
+	upper  bool
+}{
+	{"bill_", "bill/", true},
+	{"identity_", "identity/", true},
+	{"line_item_", "lineItem/", true},
+	{"pricing_", "pricing/", false},
+	{"product_", "product/", false},
+	{"reservation_", "reservation/", true},
+	{"savings_plan_", "savingsPlan/", true},
+}
+
+// mapColumn is a CUR 2.0 column holding attributes as a JSON map.
+type mapColumn struct {
+	index int
+
+	// keys returns the possible map keys of an attribute, given the rest
+	// of its legacy column name after the prefix.
+	keys func(name string) []string
+}
+
+// newHeaders returns the column positions for the header record of an
+// AWS Cost and Usage Report. Both the legacy format and CUR 2.0 are
+// supported. For CUR 2.0, columns are made available under their legacy
+// names, so that lines can be read the same way for both formats.
+func newHeaders(record []string, tagKeys []string) headers {
+	if !isCUR2(record) {
+		return newLegacyHeaders(record, tagKeys)
+	}
+
+	columns := make([]string, len(record))
+	for i, name := range record {
+		columns[i] = legacyColumn(name)
+	}
+	h := newLegacyHeaders(columns, tagKeys)
+	h.maps = make(map[string]mapColumn)
+
+	for i, name := range record {
+		switch name {
+		case cur2ColumnProduct:
+			h.maps["product/"] = mapColumn{index: i, keys: func(name string) []string {
+				return []string{snakeCase(name)}
+			}}
+		case cur2ColumnResourceTags:
+			h.maps[headerPrefixTag] = mapColumn{index: i, keys: func(name string) []string {
+				// Tag keys like user:team are stored as user_team.
+				key := strings.Replace(name, ":", "_", 1)
+				return []string{key, snakeCase(key)}
+			}}
+			// Whether a tag is present is only known per row.
+			for _, key := range tagKeys {
+				h.tags[key] = TagColumn(key)
+			}
+		}
+	}
+
+	return h
+}
+
+// isCUR2 returns whether the header record of a report is in the CUR 2.0
+// format.
+func isCUR2(record []string) bool {
+	for _, name := range record {
+		if strings.HasPrefix(name, "line_item_") {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyColumn returns the legacy name of a CUR 2.0 column, e.g.
+// lineItem/UsageStartDate for line_item_usage_start_date. Unknown names are
+// returned unchanged.
+func legacyColumn(name string) string {
+	for _, p := range cur2Prefixes {
+		if rest, found := strings.CutPrefix(name, p.cur2); found {
+			return p.legacy + camelCase(rest, p.upper)
+		}
+	}
+	return name
+}
+
+// cur2Column returns the CUR 2.0 name of a legacy column, e.g.
+// line_item_usage_start_date for lineItem/UsageStartDate.
+func cur2Column(name string) string {
+	for _, p := range cur2Prefixes {
+		if rest, found := strings.CutPrefix(name, p.legacy); found {
+			return p.cur2 + snakeCase(rest)
+		}
+	}
+	return name
+}
+
+// camelCase converts a snake case name to camel case, starting in upper
+// case if upper is set.
+func camelCase(name string, upper bool) string {
+	var b strings.Builder
+	for i, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		if i > 0 || upper {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// snakeCase converts a camel case name to snake case.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mapValue returns the value of a column stored in a JSON map column of a
+// CUR 2.0 report, or an empty string if there is none.
+func (h headers) mapValue(fields []string, column string) string {
+	for prefix, m := range h.maps {
+		name, found := strings.CutPrefix(column, prefix)
+		if !found || m.index >= len(fields) || fields[m.index] == "" {
+			continue
+		}
+
+		var attributes map[string]string
+		if err := json.Unmarshal([]byte(fields[m.index]), &attributes); err != nil {
+			return ""
+		}
+		for _, key := range m.keys(name) {
+			if value, exists := attributes[key]; exists {
+				return value
+			}
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package cur
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_legacyColumn(t *testing.T) {
+	tests := map[string]string{
+		"line_item_usage_start_date":      headerLineItemUsageStartDate,
+		"line_item_line_item_type":        headerLineItemLineItemType,
+		"line_item_usage_account_id":      headerLineItemUsageAccountID,
+		"bill_payer_account_id":           headerBillPayerAccountID,
+		"identity_time_interval":          headerIdentityTimeInterval,
+		"product_instance_type":           headerProductInstanceType,
+		"product_from_region_code":        headerProductFromRegion,
+		"savings_plan_savings_plan_a_r_n": "savingsPlan/SavingsPlanARN",
+		"resource_tags":                   "resource_tags",
+	}
+
+	for name, want := range tests {
+		if got := legacyColumn(name); got != want {
+			t.Errorf("legacyColumn(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func Test_newHeaders_mapColumns(t *testing.T) {
+	header := []string{"line_item_product_code", "product", "resource_tags"}
+	fields := []string{"AmazonEC2", `{"volume_api_name":"gp3","deployment_option":"Multi-AZ"}`, `{"user_team":"carbon","aws_created_by":"someone"}`}
+
+	h := newHeaders(header, []string{"team", "aws:createdBy", "missing"})
+
+	tests := map[string]string{
+		headerLineItemProductCode:    "AmazonEC2",
+		headerProductVolumeAPI:       "gp3",
+		headerProductDeployment:      "Multi-AZ",
+		TagColumn("team"):            "carbon",
+		TagColumn("aws:createdBy"):   "someone",
+		TagColumn("missing"):         "",
+		headerProductInstanceType:    "",
+		headerLineItemUsageStartDate: "",
+	}
+	for column, want := range tests {
+		if got := h.value(fields, column); got != want {
+			t.Errorf("value(%q) = %q, want %q", column, got, want)
+		}
+	}
+}
+
+// toCUR2 converts a report in the legacy format to CUR 2.0, moving the
+// product attributes without a column of their own to the product map.
+func toCUR2(t *testing.T, legacy []byte) []byte {
+	t.Helper()
+
+	records, err := csv.NewReader(bytes.NewReader(legacy)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapped := map[string]string{headerProductDeployment: "deployment_option", headerProductVolumeAPI: "volume_api_name"}
+
+	var header []string
+	for _, name := range records[0] {
+		if _, exists := mapped[name]; exists {
+			continue
+		}
+		prefix, rest, _ := strings.Cut(name, "/")
+		header = append(header, snakeCase(prefix)+"_"+snakeCase(rest))
+	}
+	header = append(header, cur2ColumnProduct)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records[1:] {
+		var row []string
+		product := make(map[string]string)
+		for i, name := range records[0] {
+			if key, exists := mapped[name]; exists {
+				if record[i] != "" {
+					product[key] = record[i]
+				}
+				continue
+			}
+			row = append(row, record[i])
+		}
+		b, err := json.Marshal(product)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(append(row, string(b))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func TestReader_cur2(t *testing.T) {
+	legacy, err := os.ReadFile("testdata/report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := readAll(t, NewReader(bytes.NewReader(legacy)))
+	got := readAll(t, NewReader(bytes.NewReader(toCUR2(t, legacy))))
+
+	if len(want) == 0 {
+		t.Fatal("Reader read no line items from legacy report")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reader of CUR 2.0 report = %v, want %v", got, want)
+	}
+}
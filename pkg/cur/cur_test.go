@@ -0,0 +1,22 @@
+package cur
+
+import "testing"
+
+func TestTagColumn(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "giantswarm.io/cluster", want: "resourceTags/user:giantswarm.io/cluster"},
+		{key: "user:team", want: "resourceTags/user:team"},
+		{key: "aws:createdBy", want: "resourceTags/aws:createdBy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := TagColumn(tt.key); got != tt.want {
+				t.Errorf("TagColumn() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cur
+
+import (
+	"sort"
+	"strings"
+)
+
+// clusterTagColumnPrefix is the prefix of the columns of the
+// kubernetes.io/cluster/NAME tags of Kubernetes nodes, followed by the
+// cluster name.
+const clusterTagColumnPrefix = headerPrefixUserTag + "kubernetes.io/cluster/"
+
+// Columns of the cost allocation tags EKS sets on the instances of managed
+// node groups, or eksctl on those of its node groups, in order of
+// preference.
+var (
+	eksClusterTagColumns   = []string{TagColumn("eks:cluster-name"), TagColumn("aws:eks:cluster-name"), TagColumn("alpha.eksctl.io/cluster-name")}
+	eksNodeGroupTagColumns = []string{TagColumn("eks:nodegroup-name"), TagColumn("alpha.eksctl.io/nodegroup-name")}
+)
+
+// clusterTag is the column of the kubernetes.io/cluster/NAME tag of a
+// cluster.
+type clusterTag struct {
+	cluster string
+	column  string
+}
+
+// clusterTagColumns returns the columns of the kubernetes.io/cluster/NAME
+// tags in a report header, sorted by cluster name. In CUR 2.0 reports, tags
+// are held in a single column, so these can't be found.
+func clusterTagColumns(record []string) []clusterTag {
+	var tags []clusterTag
+	for _, column := range record {
+		if cluster, found := strings.CutPrefix(column, clusterTagColumnPrefix); found && cluster != "" {
+			tags = append(tags, clusterTag{cluster: cluster, column: column})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].cluster < tags[j].cluster })
+	return tags
+}
+
+// readEKSTags sets the Kubernetes cluster and node group of an EC2 instance
+// from the tags of EKS nodes, if the report has them.
+func readEKSTags(h headers, fields []string, item *LineItem) {
+	item.Cluster = firstValue(h, fields, eksClusterTagColumns)
+	if item.Cluster == "" {
+		for _, tag := range h.clusterTags {
+			if h.value(fields, tag.column) != "" {
+				item.Cluster = tag.cluster
+				break
+			}
+		}
+	}
+	item.NodeGroup = firstValue(h, fields, eksNodeGroupTagColumns)
+}
+
+// firstValue returns the first non-empty field of the given columns.
+func firstValue(h headers, fields []string, columns []string) string {
+	for _, column := range columns {
+		if value := h.value(fields, column); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package cur
+
+import "testing"
+
+func Test_readEKSTags(t *testing.T) {
+	header := []string{
+		"resourceTags/user:eks:cluster-name",
+		"resourceTags/user:eks:nodegroup-name",
+		"resourceTags/user:kubernetes.io/cluster/legacy",
+		"resourceTags/user:kubernetes.io/cluster/another",
+	}
+	h := newLegacyHeaders(header, nil)
+
+	tests := []struct {
+		name          string
+		fields        []string
+		wantCluster   string
+		wantNodeGroup string
+	}{
+		{name: "managed node group", fields: []string{"prod", "workers", "", ""}, wantCluster: "prod", wantNodeGroup: "workers"},
+		{name: "cluster tag key", fields: []string{"", "", "owned", ""}, wantCluster: "legacy"},
+		{name: "several cluster tag keys", fields: []string{"", "", "owned", "shared"}, wantCluster: "another"},
+		{name: "untagged", fields: []string{"", "", "", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item LineItem
+			readEKSTags(h, tt.fields, &item)
+			if item.Cluster != tt.wantCluster || item.NodeGroup != tt.wantNodeGroup {
+				t.Errorf("readEKSTags() = %q, %q, want %q, %q", item.Cluster, item.NodeGroup, tt.wantCluster, tt.wantNodeGroup)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package cur
+
+import (
+	"strconv"
+	"strings"
+)
+
+// headers holds the positions of the columns in a report.
+type headers struct {
+	// index maps column names to their position.
+	index map[string]int
+
+	// tags maps the keys of the cost allocation tags to read to the name
+	// of their column. Only tags present in the report are included.
+	tags map[string]string
+
+	// clusterTags holds the columns of the kubernetes.io/cluster/NAME tags
+	// of Kubernetes clusters.
+	clusterTags []clusterTag
+
+	// maps holds the columns of CUR 2.0 reports holding attributes as a
+	// JSON map, keyed by the prefix of the legacy column names of these
+	// attributes, e.g. "product/".
+	maps map[string]mapColumn
+}
+
+// newLegacyHeaders returns the column positions for the header record of
+// a report in the legacy format. tagKeys are the keys of the cost
+// allocation tags to read from the lines of the report.
+func newLegacyHeaders(record []string, tagKeys []string) headers {
+	h := headers{
+		index:       make(map[string]int),
+		tags:        make(map[string]string),
+		clusterTags: clusterTagColumns(record),
+	}
+	for index, field := range record {
+		h.index[field] = index
+	}
+	for _, key := range tagKeys {
+		if _, exists := h.index[TagColumn(key)]; exists {
+			h.tags[key] = TagColumn(key)
+		}
+	}
+	return h
+}
+
+// value returns the field of the given column, or an empty string if the
+// report has no such column.
+func (h headers) value(fields []string, column string) string {
+	index, exists := h.index[column]
+	if !exists {
+		return h.mapValue(fields, column)
+	}
+	if index >= len(fields) {
+		return ""
+	}
+	return fields[index]
+}
+
+// Parser converts the lines of a report into line items. Parsers are safe
+// for concurrent use.
+type Parser struct {
+	headers headers
+	tagKeys []string
+}
+
+// NewParser returns a parser for the lines of a report with the given
+// header record. tagKeys are the keys of the cost allocation tags to read,
+// see TagColumn for their format.
+func NewParser(header []string, tagKeys []string) *Parser {
+	return &Parser{headers: newHeaders(header, tagKeys), tagKeys: tagKeys}
+}
+
+// MissingTags returns the keys of the requested tags the report has no
+// column for. Tags only appear in a report if they are activated as cost
+// allocation tags, and, for resource tags, if the report includes resource
+// IDs.
+func (p *Parser) MissingTags() []string {
+	var missing []string
+	for _, key := range p.tagKeys {
+		if _, exists := p.headers.tags[key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// LineItemType returns the type of the line item in the fields of a line of
+// the report, e.g. "Usage" or "Tax".
+func (p *Parser) LineItemType(fields []string) string {
+	return p.headers.value(fields, headerLineItemLineItemType)
+}
+
+// ProductCode returns the code of the product the line item in the fields of
+// a line of the report is about, e.g. "AmazonEC2".
+func (p *Parser) ProductCode(fields []string) string {
+	return p.headers.value(fields, headerLineItemProductCode)
+}
+
+// Cost returns the unblended cost of the line item in the fields of a line
+// of the report, in the billing currency. It is zero if the report has no
+// cost or it cannot be parsed, as cost is only informational.
+func (p *Parser) Cost(fields []string) float64 {
+	cost, _ := strconv.ParseFloat(p.headers.value(fields, headerLineItemUnblendedCost), 64)
+	return cost
+}
+
+// Parse returns the line item in the fields of a line of the report. If
+// the line is not about usage covered by the footprint model, ok is false.
+// If a date of the line is missing or invalid, the error is a *DateError.
+func (p *Parser) Parse(fields []string) (item LineItem, ok bool, err error) {
+	h := p.headers
+
+	category := lineItemCategory(h, fields)
+	if category == "" {
+		return parseServiceUsage(h, fields)
+	}
+
+	item, err = readLineItem(h, fields)
+	if err != nil {
+		return LineItem{}, false, err
+	}
+	item.Category = category
+	item.PurchaseOption = purchaseOption(h, fields)
+	switch category {
+	case CategoryEC2:
+		readEC2Usage(h, fields, &item)
+	case CategoryEBS:
+		err = readEBSUsage(h, fields, &item)
+	case CategoryNetwork:
+		err = readNetworkUsage(h, fields, &item)
+	case CategoryS3:
+		err = readS3Usage(h, fields, &item)
+	case CategoryRDS:
+		readRDSUsage(h, fields, &item)
+	case CategoryLambda:
+		err = readLambdaUsage(h, fields, &item)
+	case CategoryFargate:
+		err = readFargateUsage(h, fields, &item)
+	}
+	if err != nil {
+		return LineItem{}, false, err
+	}
+	return item, true, nil
+}
+
+// readLineItem returns the attributes common to all line items. The usage
+// time is taken from the time interval of the line, if set, or else from
+// the usage start and end date.
+func readLineItem(h headers, fields []string) (LineItem, error) {
+	item := LineItem{
+		PayerAccountID:   h.value(fields, headerBillPayerAccountID),
+		UsageAccountID:   h.value(fields, headerLineItemUsageAccountID),
+		Region:           h.value(fields, headerProductRegionCode),
+		AvailabilityZone: h.value(fields, headerLineItemAvailabilityZone),
+		InstanceType:     h.value(fields, headerProductInstanceType),
+		ResourceID:       h.value(fields, headerLineItemResourceID),
+		ProductCode:      h.value(fields, headerLineItemProductCode),
+	}
+
+	if h.value(fields, headerProductLocationType) == locationTypeOutposts {
+		item.Outpost = h.value(fields, headerProductLocation)
+	}
+
+	// Fancy logic to basically compute a duration of one hour.
+	var err error
+	interval := h.value(fields, headerIdentityTimeInterval)
+	if start, end, found := strings.Cut(interval, "/"); found && !strings.Contains(end, "/") {
+		if item.UsageStartTime, err = parseDate(headerIdentityTimeInterval, start); err != nil {
+			return LineItem{}, err
+		}
+		if item.UsageEndTime, err = parseDate(headerIdentityTimeInterval, end); err != nil {
+			return LineItem{}, err
+		}
+	} else {
+		if item.UsageStartTime, err = parseDate(headerLineItemUsageStartDate, h.value(fields, headerLineItemUsageStartDate)); err != nil {
+			return LineItem{}, err
+		}
+		if item.UsageEndTime, err = parseDate(headerLineItemUsageEndDate, h.value(fields, headerLineItemUsageEndDate)); err != nil {
+			return LineItem{}, err
+		}
+	}
+	item.Duration = item.UsageEndTime.Sub(item.UsageStartTime)
+
+	// Cost is only informational, so unparseable values are ignored.
+	item.Cost, _ = strconv.ParseFloat(h.value(fields, headerLineItemUnblendedCost), 64)
+
+	if len(h.tags) > 0 {
+		item.Tags = make(map[string]string, len(h.tags))
+		for key, column := range h.tags {
+			if value := h.value(fields, column); value != "" {
+				item.Tags[key] = value
+			}
+		}
+	}
+
+	return item, nil
+}
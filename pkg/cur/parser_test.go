@@ -0,0 +1,140 @@
+package cur
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_readLineItem_cost(t *testing.T) {
+	h := newLegacyHeaders([]string{headerLineItemUsageStartDate, headerLineItemUnblendedCost}, nil)
+
+	if got, _ := readLineItem(h, []string{"2022-08-01T00:00:00Z", "0.192"}); got.Cost != 0.192 {
+		t.Errorf("readLineItem() Cost = %v, want 0.192", got.Cost)
+	}
+	if got, _ := readLineItem(h, []string{"2022-08-01T00:00:00Z", ""}); got.Cost != 0 {
+		t.Errorf("readLineItem() Cost = %v for empty value, want 0", got.Cost)
+	}
+}
+
+func Test_readLineItem_dates(t *testing.T) {
+	h := newLegacyHeaders([]string{headerIdentityTimeInterval, headerLineItemUsageStartDate, headerLineItemUsageEndDate}, nil)
+
+	tests := []struct {
+		name         string
+		fields       []string
+		wantDuration time.Duration
+		wantColumn   string
+		wantValue    string
+	}{
+		{name: "time interval", fields: []string{"2022-08-01T00:00:00Z/2022-08-01T01:00:00Z", "", ""}, wantDuration: time.Hour},
+		{name: "start and end date", fields: []string{"", "2022-08-01T00:00:00Z", "2022-08-01T02:00:00Z"}, wantDuration: 2 * time.Hour},
+		{name: "invalid time interval", fields: []string{"2022-08-01T00:00:00Z/2022-08-32T01:00:00Z", "", ""}, wantColumn: headerIdentityTimeInterval, wantValue: "2022-08-32T01:00:00Z"},
+		{name: "invalid start date", fields: []string{"", "08/01/2022", "2022-08-01T01:00:00Z"}, wantColumn: headerLineItemUsageStartDate, wantValue: "08/01/2022"},
+		{name: "invalid end date", fields: []string{"", "2022-08-01T00:00:00Z", "n/a"}, wantColumn: headerLineItemUsageEndDate, wantValue: "n/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := readLineItem(h, tt.fields)
+			if tt.wantColumn == "" {
+				if err != nil {
+					t.Fatalf("readLineItem() error = %v", err)
+				}
+				if item.Duration != tt.wantDuration {
+					t.Errorf("readLineItem() Duration = %v, want %v", item.Duration, tt.wantDuration)
+				}
+				return
+			}
+
+			var dateErr *DateError
+			if !errors.As(err, &dateErr) {
+				t.Fatalf("readLineItem() error = %v, want a DateError", err)
+			}
+			if dateErr.Column != tt.wantColumn || dateErr.Value != tt.wantValue {
+				t.Errorf("readLineItem() error = %+v, want column %s and value %q", dateErr, tt.wantColumn, tt.wantValue)
+			}
+		})
+	}
+}
+
+func Test_readLineItem_outpost(t *testing.T) {
+	h := newLegacyHeaders([]string{headerLineItemUsageStartDate, headerProductRegionCode, headerProductLocation, headerProductLocationType}, nil)
+
+	got, err := readLineItem(h, []string{"2022-08-01T00:00:00Z", "eu-central-1", "Frankfurt DC", "AWS Outposts"})
+	if err != nil {
+		t.Fatalf("readLineItem() error = %v", err)
+	}
+	if got.Outpost != "Frankfurt DC" || got.Region != "eu-central-1" {
+		t.Errorf("readLineItem() Outpost = %q, Region = %q, want Frankfurt DC in eu-central-1", got.Outpost, got.Region)
+	}
+	if got, _ := readLineItem(h, []string{"2022-08-01T00:00:00Z", "eu-central-1", "EU (Frankfurt)", "AWS Region"}); got.Outpost != "" {
+		t.Errorf("readLineItem() Outpost = %q for usage in a region, want empty", got.Outpost)
+	}
+}
+
+func Test_readLineItem_tags(t *testing.T) {
+	header := []string{headerProductRegionCode, headerPrefixUserTag + "team", headerPrefixUserTag + "env", headerPrefixTag + "aws:createdBy"}
+	record := []string{"eu-west-1", "platform", "", "AssumedRole:1234"}
+
+	h := newLegacyHeaders(header, []string{"team", "env", "aws:createdBy", "missing"})
+	got, err := readLineItem(h, record)
+	if err != nil {
+		t.Fatalf("readLineItem() error = %v", err)
+	}
+
+	want := map[string]string{"team": "platform", "aws:createdBy": "AssumedRole:1234"}
+	if len(got.Tags) != len(want) {
+		t.Fatalf("readLineItem() tags = %v, want %v", got.Tags, want)
+	}
+	for key, value := range want {
+		if got.Tags[key] != value {
+			t.Errorf("readLineItem() tag %s = %q, want %q", key, got.Tags[key], value)
+		}
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	header := []string{headerLineItemLineItemType, headerLineItemProductCode, headerProductProductFamily, headerLineItemOperation, headerLineItemUsageType, headerLineItemUsageAmount}
+	p := NewParser(header, nil)
+
+	tests := []struct {
+		name         string
+		fields       []string
+		wantOK       bool
+		wantCategory string
+		wantErr      bool
+	}{
+		{name: "EC2 Spot instance", fields: []string{"Usage", "AmazonEC2", "Compute Instance", "RunInstances", "EUC1-SpotUsage:m5.xlarge", "1"}, wantOK: true, wantCategory: CategoryEC2},
+		{name: "S3 storage", fields: []string{"Usage", "AmazonS3", "Storage", "StandardStorage", "EUC1-TimedStorage-ByteHrs", "2"}, wantOK: true, wantCategory: CategoryS3},
+		{name: "tax", fields: []string{"Tax", "AmazonEC2", "", "", "", ""}},
+		{name: "invalid amount", fields: []string{"Usage", "AmazonS3", "Storage", "StandardStorage", "EUC1-TimedStorage-ByteHrs", "n/a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, ok, err := p.Parse(tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || item.Category != tt.wantCategory {
+				t.Errorf("Parse() = %q, %v, want %q, %v", item.Category, ok, tt.wantCategory, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParser_Cost(t *testing.T) {
+	p := NewParser([]string{headerLineItemLineItemType, headerLineItemProductCode, headerLineItemUnblendedCost}, nil)
+
+	fields := []string{"Tax", "AmazonEC2", "0.04"}
+	if got := p.Cost(fields); got != 0.04 {
+		t.Errorf("Cost() = %v, want 0.04", got)
+	}
+	if got := p.ProductCode(fields); got != "AmazonEC2" {
+		t.Errorf("ProductCode() = %q, want AmazonEC2", got)
+	}
+	if got := p.Cost([]string{"Tax", "AmazonEC2", ""}); got != 0 {
+		t.Errorf("Cost() = %v for empty value, want 0", got)
+	}
+}
@@ -0,0 +1,92 @@
+package cur
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Reader reads the line items of a report one by one.
+type Reader struct {
+	csv     *csv.Reader
+	tagKeys []string
+
+	// parser is created from the header record when reading the first
+	// line item.
+	parser *Parser
+}
+
+// Option configures a Reader.
+type Option func(r *Reader)
+
+// WithTags makes the Reader read the cost allocation tags with the given
+// keys into LineItem.Tags. See TagColumn for the format of keys.
+func WithTags(keys ...string) Option {
+	return func(r *Reader) {
+		r.tagKeys = append(r.tagKeys, keys...)
+	}
+}
+
+// NewReader returns a Reader for the report read from r, which must provide
+// uncompressed CSV data. Reports delivered by AWS are gzip compressed, so
+// they need to be read with gzip.NewReader.
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	reader := &Reader{csv: csv.NewReader(r)}
+	reader.csv.ReuseRecord = true
+	for _, opt := range opts {
+		opt(reader)
+	}
+	return reader
+}
+
+// Parser returns the parser for the lines of the report, reading the header
+// record if that has not happened yet. It returns io.EOF for an empty
+// report, and a *SchemaError if the report is missing columns needed to
+// read its lines.
+func (r *Reader) Parser() (*Parser, error) {
+	if r.parser != nil {
+		return r.parser, nil
+	}
+
+	header, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV: %w", err)
+	}
+	if err := ValidateHeader(header); err != nil {
+		return nil, err
+	}
+	r.parser = NewParser(header, r.tagKeys)
+	return r.parser, nil
+}
+
+// Next returns the next line item of the report about usage covered by the
+// footprint model, skipping all other lines. At the end of the report, it
+// returns io.EOF.
+func (r *Reader) Next() (LineItem, error) {
+	parser, err := r.Parser()
+	if err != nil {
+		return LineItem{}, err
+	}
+
+	for {
+		fields, err := r.csv.Read()
+		if err == io.EOF {
+			return LineItem{}, err
+		}
+		if err != nil {
+			return LineItem{}, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		item, ok, err := parser.Parse(fields)
+		if err != nil {
+			line, _ := r.csv.FieldPos(0)
+			return LineItem{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			return item, nil
+		}
+	}
+}
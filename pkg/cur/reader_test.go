@@ -0,0 +1,148 @@
+package cur
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readAll returns all line items read by r.
+func readAll(t *testing.T, r *Reader) []LineItem {
+	t.Helper()
+
+	var items []LineItem
+	for {
+		item, err := r.Next()
+		if err == io.EOF {
+			return items
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		items = append(items, item)
+	}
+}
+
+func TestReader(t *testing.T) {
+	f, err := os.Open("testdata/report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	items := readAll(t, NewReader(f))
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[item.Category]++
+	}
+	want := map[string]int{
+		CategoryEC2:     5,
+		CategoryEBS:     3,
+		CategoryNetwork: 2,
+		CategoryS3:      2,
+		CategoryRDS:     2,
+		CategoryLambda:  1,
+		CategoryFargate: 2,
+	}
+	for category, n := range want {
+		if counts[category] != n {
+			t.Errorf("Reader read %d %s line items, want %d", counts[category], category, n)
+		}
+	}
+	if len(items) != 17 {
+		t.Errorf("Reader read %d line items, want 17", len(items))
+	}
+
+	first := items[0]
+	if first.InstanceType != "m5.xlarge" || first.Region != "eu-central-1" || first.Duration != time.Hour || first.PurchaseOption != PurchaseOnDemand {
+		t.Errorf("Reader read first line item %+v", first)
+	}
+}
+
+func TestReader_tags(t *testing.T) {
+	report := "lineItem/LineItemType,lineItem/ProductCode,product/productFamily,lineItem/Operation,lineItem/UsageType,lineItem/UsageAmount,identity/TimeInterval,resourceTags/user:team\n" +
+		"Usage,AmazonEC2,Compute Instance,RunInstances,EUC1-BoxUsage:m5.xlarge,1,2022-08-01T00:00:00Z/2022-08-01T01:00:00Z,platform\n" +
+		"Usage,AmazonEC2,Compute Instance,RunInstances,EUC1-BoxUsage:m5.xlarge,1,2022-08-01T00:00:00Z/2022-08-01T01:00:00Z,\n"
+
+	r := NewReader(strings.NewReader(report), WithTags("team", "missing"))
+	parser, err := r.Parser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing := parser.MissingTags(); len(missing) != 1 || missing[0] != "missing" {
+		t.Errorf("MissingTags() = %v, want [missing]", missing)
+	}
+
+	items := readAll(t, r)
+	if len(items) != 2 {
+		t.Fatalf("Reader read %d line items, want 2", len(items))
+	}
+	if got := items[0].Tags["team"]; got != "platform" {
+		t.Errorf("Reader read tag team = %q, want platform", got)
+	}
+	if _, exists := items[1].Tags["team"]; exists {
+		t.Errorf("Reader read empty tag team, want it omitted")
+	}
+}
+
+func TestReader_errors(t *testing.T) {
+	header := "lineItem/LineItemType,lineItem/ProductCode,lineItem/UsageType,lineItem/UsageAmount,identity/TimeInterval\n"
+
+	tests := []struct {
+		name   string
+		report string
+		want   string
+	}{
+		{name: "invalid usage amount", report: header + "Usage,AmazonS3,EUC1-TimedStorage-ByteHrs,1,\nUsage,AmazonS3,EUC1-TimedStorage-ByteHrs,n/a,\n", want: "line 3: "},
+		{name: "invalid CSV", report: header + "Usage,\"AmazonS3\n", want: "could not read CSV: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.report))
+			var err error
+			for err == nil {
+				_, err = r.Next()
+			}
+			if errors.Is(err, io.EOF) || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Next() error = %v, want %q...", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReader_empty(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("")).Next(); err != io.EOF {
+		t.Errorf("Next() of empty report error = %v, want io.EOF", err)
+	}
+}
+
+// BenchmarkReader measures reading the lines of a report, with the lines of
+// testdata/report.csv repeated to about 25,000 lines.
+func BenchmarkReader(b *testing.B) {
+	data, err := os.ReadFile("testdata/report.csv")
+	if err != nil {
+		b.Fatal(err)
+	}
+	header, lines, _ := strings.Cut(string(data), "\n")
+	report := header + "\n" + strings.Repeat(lines, 1000)
+
+	b.SetBytes(int64(len(report)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := NewReader(strings.NewReader(report))
+		for {
+			_, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
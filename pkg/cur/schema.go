@@ -0,0 +1,86 @@
+package cur
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats of reports.
+const (
+	FormatLegacy = "legacy CUR"
+	FormatCUR2   = "CUR 2.0"
+)
+
+// requiredColumns are the columns lines are classified by, so that no line
+// can be read without them.
+var requiredColumns = []string{
+	headerLineItemLineItemType,
+	headerLineItemProductCode,
+	headerLineItemUsageType,
+	headerLineItemUsageAmount,
+}
+
+// SchemaError is returned for a report missing columns needed to read its
+// lines.
+type SchemaError struct {
+	// Format is the format of the report, FormatLegacy or FormatCUR2, or
+	// an empty string if the report is in neither format.
+	Format string
+
+	// Missing holds the names of the missing columns in the format of the
+	// report, or in the legacy format if the format is unknown.
+	Missing []string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Format == "" {
+		return fmt.Sprintf("not an AWS Cost and Usage Report, missing columns %s", strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("report in %s format is missing columns %s", e.Format, strings.Join(e.Missing, ", "))
+}
+
+// DetectFormat returns the format of a report with the given header record,
+// or an empty string if it is in neither format.
+func DetectFormat(header []string) string {
+	if isCUR2(header) {
+		return FormatCUR2
+	}
+	for _, name := range header {
+		if strings.HasPrefix(name, "lineItem/") {
+			return FormatLegacy
+		}
+	}
+	return ""
+}
+
+// ValidateHeader checks that a report with the given header record has all
+// columns needed to read its lines: the line item type, product code,
+// usage type and amount, and either the time interval or the usage start
+// and end date. If columns are missing, the error is a *SchemaError.
+func ValidateHeader(header []string) error {
+	h := newHeaders(header, nil)
+	var missing []string
+	for _, column := range requiredColumns {
+		if _, exists := h.index[column]; !exists {
+			missing = append(missing, column)
+		}
+	}
+	if _, exists := h.index[headerIdentityTimeInterval]; !exists {
+		for _, column := range []string{headerLineItemUsageStartDate, headerLineItemUsageEndDate} {
+			if _, exists := h.index[column]; !exists {
+				missing = append(missing, column)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	format := DetectFormat(header)
+	if format == FormatCUR2 {
+		for i, column := range missing {
+			missing[i] = cur2Column(column)
+		}
+	}
+	return &SchemaError{Format: format, Missing: missing}
+}
@@ -0,0 +1,92 @@
+package cur
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		wantFormat  string
+		wantMissing []string
+	}{
+		{
+			name:   "legacy with time interval",
+			header: []string{"identity/TimeInterval", "lineItem/LineItemType", "lineItem/ProductCode", "lineItem/UsageType", "lineItem/UsageAmount"},
+		},
+		{
+			name:   "CUR 2.0 with usage dates",
+			header: []string{"line_item_line_item_type", "line_item_product_code", "line_item_usage_type", "line_item_usage_amount", "line_item_usage_start_date", "line_item_usage_end_date"},
+		},
+		{
+			name:        "legacy without dates",
+			header:      []string{"lineItem/LineItemType", "lineItem/ProductCode", "lineItem/UsageType", "lineItem/UsageAmount", "lineItem/UsageStartDate"},
+			wantFormat:  FormatLegacy,
+			wantMissing: []string{"lineItem/UsageEndDate"},
+		},
+		{
+			name:        "CUR 2.0 without usage amount",
+			header:      []string{"identity_time_interval", "line_item_line_item_type", "line_item_product_code", "line_item_usage_type"},
+			wantFormat:  FormatCUR2,
+			wantMissing: []string{"line_item_usage_amount"},
+		},
+		{
+			name:        "other format",
+			header:      []string{"service_description", "usage_amount"},
+			wantMissing: []string{"lineItem/LineItemType", "lineItem/ProductCode", "lineItem/UsageType", "lineItem/UsageAmount", "lineItem/UsageStartDate", "lineItem/UsageEndDate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHeader(tt.header)
+			if tt.wantMissing == nil {
+				if err != nil {
+					t.Errorf("ValidateHeader() error = %v", err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("ValidateHeader() error = %v, want a SchemaError", err)
+			}
+			if schemaErr.Format != tt.wantFormat || !reflect.DeepEqual(schemaErr.Missing, tt.wantMissing) {
+				t.Errorf("ValidateHeader() error = %+v, want format %q and missing %v", schemaErr, tt.wantFormat, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestValidateHeader_testdata(t *testing.T) {
+	data, err := os.ReadFile("testdata/report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := strings.Cut(string(data), "\n")
+	if err := ValidateHeader(strings.Split(header, ",")); err != nil {
+		t.Errorf("ValidateHeader() error = %v", err)
+	}
+
+	cur2Header, _, _ := strings.Cut(string(toCUR2(t, data)), "\n")
+	if err := ValidateHeader(strings.Split(cur2Header, ",")); err != nil {
+		t.Errorf("ValidateHeader() of CUR 2.0 report error = %v", err)
+	}
+}
+
+func TestSchemaError_Error(t *testing.T) {
+	err := &SchemaError{Format: FormatCUR2, Missing: []string{"line_item_usage_type", "line_item_usage_amount"}}
+	if want := "report in CUR 2.0 format is missing columns line_item_usage_type, line_item_usage_amount"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	err = &SchemaError{Missing: []string{"lineItem/LineItemType"}}
+	if want := "not an AWS Cost and Usage Report, missing columns lineItem/LineItemType"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,94 @@
+package cur
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Line is a line of a report.
+type Line struct {
+	headers headers
+	fields  []string
+}
+
+// Value returns the field of the given column, or an empty string if the
+// report has no such column. Columns are given by their name in the legacy
+// format, e.g. "lineItem/UsageType", also for CUR 2.0 reports.
+func (l Line) Value(column string) string {
+	return l.headers.value(l.fields, column)
+}
+
+// Service reads the usage of a service the built-in categories don't
+// cover, so that the footprint of the service can be estimated by an
+// emitter registered with the pipeline package.
+type Service interface {
+	// Read sets the category and the usage of a line item from a line of
+	// a report about usage. The attributes common to all line items, like
+	// region and usage time, are already set. If the line is not about the
+	// service, ok is false.
+	Read(line Line, item *LineItem) (ok bool, err error)
+}
+
+// namedService is a registered service.
+type namedService struct {
+	name string
+	Service
+}
+
+var (
+	servicesMu sync.RWMutex
+
+	// services holds the registered services, sorted by name.
+	services []namedService
+)
+
+// RegisterService makes the usage of a service available to all parsers,
+// under the given name. Services are asked in order of their names for
+// lines about usage not covered by the built-in categories. RegisterService
+// panics if a service with the same name is already registered.
+func RegisterService(name string, s Service) {
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+
+	if s == nil {
+		panic("cur: RegisterService service is nil")
+	}
+	i := sort.Search(len(services), func(i int) bool { return services[i].name >= name })
+	if i < len(services) && services[i].name == name {
+		panic(fmt.Sprintf("cur: RegisterService called twice for service %q", name))
+	}
+	services = append(services[:i], append([]namedService{{name: name, Service: s}}, services[i:]...)...)
+}
+
+// parseServiceUsage returns the line item in a line not covered by the
+// built-in categories, if it is about usage of a registered service.
+func parseServiceUsage(h headers, fields []string) (item LineItem, ok bool, err error) {
+	servicesMu.RLock()
+	defer servicesMu.RUnlock()
+
+	if len(services) == 0 || !isUsage(h, fields) {
+		return LineItem{}, false, nil
+	}
+
+	line := Line{headers: h, fields: fields}
+	for _, s := range services {
+		item, err := readLineItem(h, fields)
+		if err != nil {
+			return LineItem{}, false, err
+		}
+		item.PurchaseOption = purchaseOption(h, fields)
+		ok, err := s.Read(line, &item)
+		if err != nil {
+			return LineItem{}, false, fmt.Errorf("service %s: %w", s.name, err)
+		}
+		if !ok {
+			continue
+		}
+		if item.Category == "" {
+			return LineItem{}, false, fmt.Errorf("service %s did not set the category", s.name)
+		}
+		return item, true, nil
+	}
+	return LineItem{}, false, nil
+}
@@ -0,0 +1,53 @@
+package cur
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testService reads the usage of Amazon OpenSearch Service instances.
+type testService struct{}
+
+func (testService) Read(line Line, item *LineItem) (bool, error) {
+	if line.Value(headerLineItemProductCode) != "AmazonES" {
+		return false, nil
+	}
+	hours, err := strconv.ParseFloat(line.Value(headerLineItemUsageAmount), 64)
+	if err != nil {
+		return false, errors.New("invalid usage amount")
+	}
+	item.Category = "OpenSearch"
+	item.VCPUHours = hours
+	return true, nil
+}
+
+func TestRegisterService(t *testing.T) {
+	RegisterService("opensearch", testService{})
+
+	report := "lineItem/LineItemType,lineItem/ProductCode,lineItem/UsageType,lineItem/UsageAmount,identity/TimeInterval,product/regionCode\n" +
+		"Usage,AmazonES,EU-ESInstance:r6g.large,2,,eu-west-1\n" +
+		"Tax,AmazonES,,1,,eu-west-1\n" +
+		"Usage,AmazonSNS,EU-Requests-Tier1,1,,eu-west-1\n"
+
+	items := readAll(t, NewReader(strings.NewReader(report)))
+	if len(items) != 1 {
+		t.Fatalf("Reader read %d line items, want 1", len(items))
+	}
+	if got := items[0]; got.Category != "OpenSearch" || got.VCPUHours != 2 || got.Region != "eu-west-1" {
+		t.Errorf("Reader read %+v", got)
+	}
+
+	_, err := NewReader(strings.NewReader(report[:strings.Index(report, "\n")+1] + "Usage,AmazonES,EU-ESInstance:r6g.large,n/a,,eu-west-1\n")).Next()
+	if err == nil || !strings.Contains(err.Error(), "service opensearch: invalid usage amount") {
+		t.Errorf("Next() error = %v, want error of service", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterService() did not panic for duplicate name")
+		}
+	}()
+	RegisterService("opensearch", testService{})
+}
@@ -0,0 +1,328 @@
+package cur
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// usageTypeEBSVolume is contained in the usage type of EBS volume
+	// storage line items, e.g. "EUC1-EBS:VolumeUsage.gp3".
+	usageTypeEBSVolume = "EBS:VolumeUsage"
+
+	// usageTypeDataTransfer is contained in the usage type of data transfer
+	// line items, e.g. "EUC1-DataTransfer-Out-Bytes" for internet egress.
+	usageTypeDataTransfer = "DataTransfer"
+	// usageTypeInterRegionOut is the suffix of the usage type of data
+	// transferred to another region, e.g. "EUC1-EUW1-AWS-Out-Bytes".
+	usageTypeInterRegionOut = "-AWS-Out-Bytes"
+
+	// usageTypeS3Storage is contained in the usage type of S3 storage line
+	// items, e.g. "EUC1-TimedStorage-ByteHrs" for the standard storage
+	// class or "EUC1-TimedStorage-SIA-ByteHrs" for infrequent access.
+	usageTypeS3Storage = "TimedStorage-"
+
+	// usageTypeRDSInstance and usageTypeRDSMultiAZ are contained in the
+	// usage type of RDS instance line items, e.g.
+	// "EUC1-InstanceUsage:db.m5.xlarge" or "EUC1-Multi-AZUsage:db.m5.xlarge".
+	usageTypeRDSInstance = "InstanceUsage"
+	usageTypeRDSMultiAZ  = "Multi-AZUsage"
+
+	// deploymentMultiAZ is the prefix of the deployment option of Multi-AZ
+	// RDS deployments.
+	deploymentMultiAZ = "Multi-AZ"
+
+	// usageTypeLambdaDuration is contained in the usage type of Lambda
+	// function duration line items, e.g. "EUC1-Lambda-GB-Second" or
+	// "EUC1-Lambda-Provisioned-GB-Second".
+	usageTypeLambdaDuration = "GB-Second"
+
+	// usageTypeFargateVCPU and usageTypeFargateMemory are contained in the
+	// usage type of Fargate task line items, e.g.
+	// "EUC1-Fargate-vCPU-Hours:perCPU" or "EUC1-Fargate-ARM-GB-Hours".
+	usageTypeFargateVCPU   = "vCPU-Hours"
+	usageTypeFargateMemory = "GB-Hours"
+	usageTypeFargate       = "Fargate-"
+
+	// usageTypeDedicatedHost is contained in the usage type of Dedicated
+	// Host line items, followed by the instance family of the host, e.g.
+	// "EUC1-HostUsage:m5".
+	usageTypeDedicatedHost = "HostUsage:"
+
+	// usageTypeHostInstance is contained in the usage type of instances
+	// running on a Dedicated Host, e.g. "EUC1-HostBoxUsage:m5.xlarge".
+	usageTypeHostInstance = "HostBoxUsage"
+
+	// productFamilyDedicatedHost is the product family of Dedicated Host
+	// line items.
+	productFamilyDedicatedHost = "Dedicated Host"
+
+	// usageTypeSpot is contained in the usage type of Spot instances, e.g.
+	// "EUC1-SpotUsage:m5.xlarge".
+	usageTypeSpot = "SpotUsage"
+)
+
+// Line item types of usage. Usage covered by a Reserved Instance or a
+// Savings Plan has its own line item type instead of "Usage".
+const (
+	lineItemTypeUsage            = "Usage"
+	lineItemTypeDiscountedUsage  = "DiscountedUsage"
+	lineItemTypeSavingsPlanUsage = "SavingsPlanCoveredUsage"
+)
+
+// isUsage returns whether a line of a report is about usage, as opposed to
+// fees, credits or taxes.
+func isUsage(h headers, fields []string) bool {
+	switch h.value(fields, headerLineItemLineItemType) {
+	case lineItemTypeUsage, lineItemTypeDiscountedUsage, lineItemTypeSavingsPlanUsage:
+		return true
+	}
+	return false
+}
+
+// lineItemCategory returns the usage category of a line item, or an empty
+// string if the line item is not about usage covered by the model.
+func lineItemCategory(h headers, fields []string) string {
+	if !isUsage(h, fields) {
+		return ""
+	}
+
+	// Data transfer is billed under the product sending the data, so it
+	// is not limited to EC2.
+	if isDataTransferOut(h.value(fields, headerLineItemUsageType)) {
+		return CategoryNetwork
+	}
+
+	switch h.value(fields, headerLineItemProductCode) {
+	case "AmazonEC2":
+		switch h.value(fields, headerProductProductFamily) {
+		case "Compute Instance":
+			// Instances on a Dedicated Host are covered by the line
+			// items of the host.
+			if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeHostInstance) {
+				return ""
+			}
+			if strings.HasPrefix(h.value(fields, headerLineItemOperation), "RunInstances") {
+				return CategoryEC2
+			}
+		case productFamilyDedicatedHost:
+			if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeDedicatedHost) {
+				return CategoryEC2
+			}
+		case "Storage":
+			if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeEBSVolume) {
+				return CategoryEBS
+			}
+		}
+	case "AmazonRDS":
+		if h.value(fields, headerProductProductFamily) != "Database Instance" {
+			return ""
+		}
+		usageType := h.value(fields, headerLineItemUsageType)
+		if strings.Contains(usageType, usageTypeRDSInstance) || strings.Contains(usageType, usageTypeRDSMultiAZ) {
+			return CategoryRDS
+		}
+	case "AWSLambda":
+		if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeLambdaDuration) {
+			return CategoryLambda
+		}
+	case "AmazonECS", "AmazonEKS":
+		usageType := h.value(fields, headerLineItemUsageType)
+		if !strings.Contains(usageType, usageTypeFargate) {
+			return ""
+		}
+		// Ephemeral storage is billed in GB-hours, too, but is not
+		// covered by the model.
+		if strings.Contains(usageType, "EphemeralStorage") {
+			return ""
+		}
+		if strings.Contains(usageType, usageTypeFargateVCPU) || strings.Contains(usageType, usageTypeFargateMemory) {
+			return CategoryFargate
+		}
+	case "AmazonS3":
+		if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeS3Storage) {
+			return CategoryS3
+		}
+	}
+
+	return ""
+}
+
+// purchaseOption returns how the usage of a line item was paid for: at
+// On-Demand prices, as Spot instance, or covered by a Reserved Instance or a
+// Savings Plan.
+func purchaseOption(h headers, fields []string) string {
+	switch h.value(fields, headerLineItemLineItemType) {
+	case lineItemTypeDiscountedUsage:
+		return PurchaseReserved
+	case lineItemTypeSavingsPlanUsage:
+		return PurchaseSavingsPlan
+	}
+	if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeSpot) {
+		return PurchaseSpot
+	}
+	return PurchaseOnDemand
+}
+
+// isDataTransferOut returns whether a usage type refers to data sent out of
+// a region or availability zone. Inbound transfer is not counted, as it is
+// accounted for at the sending side.
+func isDataTransferOut(usageType string) bool {
+	if strings.HasSuffix(usageType, usageTypeInterRegionOut) {
+		return true
+	}
+	return strings.Contains(usageType, usageTypeDataTransfer) && !strings.Contains(usageType, "-In-")
+}
+
+// readEBSUsage sets the EBS specific fields of a line item.
+func readEBSUsage(h headers, fields []string, item *LineItem) error {
+	// The duration of a storage line item is not instance usage time.
+	item.Duration = 0
+
+	item.StorageType = h.value(fields, headerProductVolumeAPI)
+	if item.StorageType == "" {
+		item.StorageType = ebsVolumeTypeFromUsageType(h.value(fields, headerLineItemUsageType))
+	}
+
+	// EBS usage is billed in GB-months.
+	gbMonths, err := readUsageAmount(h, fields)
+	if err != nil {
+		return err
+	}
+	item.GBHours = gbMonths * hoursInMonth(item.UsageStartTime)
+
+	return nil
+}
+
+// readEC2Usage sets the EC2 specific fields of a line item. A Dedicated
+// Host is accounted as the bare metal instance type of its family, e.g.
+// m5.metal for "EUC1-HostUsage:m5", which takes up a whole host as well.
+// The cluster and node group of EKS nodes are read from their tags.
+func readEC2Usage(h headers, fields []string, item *LineItem) {
+	readEKSTags(h, fields, item)
+
+	_, family, found := strings.Cut(h.value(fields, headerLineItemUsageType), usageTypeDedicatedHost)
+	if found && family != "" {
+		item.InstanceType = family + ".metal"
+	}
+}
+
+// readRDSUsage sets the RDS specific fields of a line item.
+func readRDSUsage(h headers, fields []string, item *LineItem) {
+	item.MultiAZ = strings.HasPrefix(h.value(fields, headerProductDeployment), deploymentMultiAZ) ||
+		strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeRDSMultiAZ)
+}
+
+// readLambdaUsage sets the Lambda specific fields of a line item.
+func readLambdaUsage(h headers, fields []string, item *LineItem) error {
+	// The duration of a Lambda line item is not instance usage time.
+	item.Duration = 0
+
+	// Lambda usage is billed in GB-seconds of allocated memory.
+	gbSeconds, err := readUsageAmount(h, fields)
+	if err != nil {
+		return err
+	}
+	item.GBHours = gbSeconds / 3600
+
+	return nil
+}
+
+// readFargateUsage sets the Fargate specific fields of a line item. Each
+// line item covers either vCPU or memory usage of tasks.
+func readFargateUsage(h headers, fields []string, item *LineItem) error {
+	// The duration of a Fargate line item is not instance usage time.
+	item.Duration = 0
+
+	hours, err := readUsageAmount(h, fields)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(h.value(fields, headerLineItemUsageType), usageTypeFargateVCPU) {
+		item.VCPUHours = hours
+	} else {
+		item.GBHours = hours
+	}
+
+	return nil
+}
+
+// readS3Usage sets the S3 specific fields of a line item.
+func readS3Usage(h headers, fields []string, item *LineItem) error {
+	// The duration of a storage line item is not instance usage time.
+	item.Duration = 0
+
+	item.StorageType = s3StorageClassFromUsageType(h.value(fields, headerLineItemUsageType))
+
+	// S3 storage is billed in GB-months.
+	gbMonths, err := readUsageAmount(h, fields)
+	if err != nil {
+		return err
+	}
+	item.GBHours = gbMonths * hoursInMonth(item.UsageStartTime)
+
+	return nil
+}
+
+// s3StorageClassFromUsageType extracts the abbreviated storage class from
+// the usage type of an S3 storage line item, e.g. "SIA" for
+// "EUC1-TimedStorage-SIA-ByteHrs". Usage types without a storage class
+// refer to the standard storage class.
+func s3StorageClassFromUsageType(usageType string) string {
+	_, class, _ := strings.Cut(usageType, usageTypeS3Storage)
+	class = strings.TrimSuffix(strings.TrimSuffix(class, "ByteHrs"), "-")
+	if class == "" {
+		return "Standard"
+	}
+	return class
+}
+
+// readNetworkUsage sets the data transfer specific fields of a line item.
+func readNetworkUsage(h headers, fields []string, item *LineItem) error {
+	// The duration of a data transfer line item is not instance usage time.
+	item.Duration = 0
+
+	// Emissions are accounted to the region sending the data.
+	if from := h.value(fields, headerProductFromRegion); from != "" {
+		item.Region = from
+	}
+
+	// Data transfer is billed in GB.
+	gigabytes, err := readUsageAmount(h, fields)
+	if err != nil {
+		return err
+	}
+	item.TransferGB = gigabytes
+
+	return nil
+}
+
+// readUsageAmount returns the usage amount of a line item, in the unit
+// of its usage type.
+func readUsageAmount(h headers, fields []string) (float64, error) {
+	amount := h.value(fields, headerLineItemUsageAmount)
+	val, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing usage amount %q as float: %s", amount, err)
+	}
+	return val, nil
+}
+
+// ebsVolumeTypeFromUsageType extracts the volume type from the usage type
+// of an EBS line item. Usage types without a volume type suffix refer to
+// previous generation magnetic volumes.
+func ebsVolumeTypeFromUsageType(usageType string) string {
+	_, volumeType, found := strings.Cut(usageType, usageTypeEBSVolume+".")
+	if !found {
+		return "standard"
+	}
+	return volumeType
+}
+
+// hoursInMonth returns the number of hours in the month of t.
+func hoursInMonth(t time.Time) float64 {
+	firstOfNextMonth := time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return float64(firstOfNextMonth.AddDate(0, 0, -1).Day() * 24)
+}
@@ -0,0 +1,235 @@
+package cur
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_lineItemCategory(t *testing.T) {
+	header := []string{headerLineItemLineItemType, headerLineItemProductCode, headerProductProductFamily, headerLineItemOperation, headerLineItemUsageType}
+	h := newLegacyHeaders(header, nil)
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{name: "EC2 instance", fields: []string{"Usage", "AmazonEC2", "Compute Instance", "RunInstances:0002", "EUC1-BoxUsage:m5.xlarge"}, want: CategoryEC2},
+		{name: "Dedicated Host", fields: []string{"Usage", "AmazonEC2", "Dedicated Host", "RunInstances", "EUC1-HostUsage:m5"}, want: CategoryEC2},
+		{name: "instance on Dedicated Host", fields: []string{"Usage", "AmazonEC2", "Compute Instance", "RunInstances", "EUC1-HostBoxUsage:m5.xlarge"}, want: ""},
+		{name: "dedicated instance", fields: []string{"Usage", "AmazonEC2", "Compute Instance", "RunInstances:0002", "EUC1-DedicatedUsage:m5.xlarge"}, want: CategoryEC2},
+		{name: "EBS volume", fields: []string{"Usage", "AmazonEC2", "Storage", "CreateVolume-Gp3", "EUC1-EBS:VolumeUsage.gp3"}, want: CategoryEBS},
+		{name: "EBS snapshot", fields: []string{"Usage", "AmazonEC2", "Storage", "CreateSnapshot", "EUC1-EBS:SnapshotUsage"}, want: ""},
+		{name: "data transfer", fields: []string{"Usage", "AmazonS3", "Data Transfer", "GetObject", "EUC1-DataTransfer-Out-Bytes"}, want: CategoryNetwork},
+		{name: "S3 storage", fields: []string{"Usage", "AmazonS3", "Storage", "StandardStorage", "EUC1-TimedStorage-ByteHrs"}, want: CategoryS3},
+		{name: "S3 requests", fields: []string{"Usage", "AmazonS3", "API Request", "PutObject", "EUC1-Requests-Tier1"}, want: ""},
+		{name: "RDS instance", fields: []string{"Usage", "AmazonRDS", "Database Instance", "CreateDBInstance:0002", "EUC1-InstanceUsage:db.t3.micro"}, want: CategoryRDS},
+		{name: "RDS Multi-AZ instance", fields: []string{"Usage", "AmazonRDS", "Database Instance", "CreateDBInstance:0014", "EUC1-Multi-AZUsage:db.m5.xlarge"}, want: CategoryRDS},
+		{name: "RDS storage", fields: []string{"Usage", "AmazonRDS", "Database Storage", "CreateDBInstance:0002", "EUC1-RDS:GP2-Storage"}, want: ""},
+		{name: "Lambda duration", fields: []string{"Usage", "AWSLambda", "Serverless", "Invoke", "EUC1-Lambda-GB-Second-ARM"}, want: CategoryLambda},
+		{name: "Lambda requests", fields: []string{"Usage", "AWSLambda", "Serverless", "Invoke", "EUC1-Request-ARM"}, want: ""},
+		{name: "Fargate vCPU", fields: []string{"Usage", "AmazonECS", "Compute", "FargateTask", "EUC1-Fargate-vCPU-Hours:perCPU"}, want: CategoryFargate},
+		{name: "Fargate memory on EKS", fields: []string{"Usage", "AmazonEKS", "Compute", "FargatePod", "EUC1-Fargate-ARM-GB-Hours"}, want: CategoryFargate},
+		{name: "Fargate ephemeral storage", fields: []string{"Usage", "AmazonECS", "Compute", "FargateTask", "EUC1-Fargate-EphemeralStorage-GB-Hours"}, want: ""},
+		{name: "EC2 Reserved Instance", fields: []string{"DiscountedUsage", "AmazonEC2", "Compute Instance", "RunInstances", "EUC1-BoxUsage:m5.xlarge"}, want: CategoryEC2},
+		{name: "EC2 Savings Plan", fields: []string{"SavingsPlanCoveredUsage", "AmazonEC2", "Compute Instance", "RunInstances", "EUC1-BoxUsage:m5.xlarge"}, want: CategoryEC2},
+		{name: "Reserved Instance fee", fields: []string{"RIFee", "AmazonEC2", "Compute Instance", "RunInstances", "EUC1-HeavyUsage:m5.xlarge"}, want: ""},
+		{name: "tax", fields: []string{"Tax", "AmazonEC2", "", "", ""}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineItemCategory(h, tt.fields); got != tt.want {
+				t.Errorf("lineItemCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_purchaseOption(t *testing.T) {
+	h := newLegacyHeaders([]string{headerLineItemLineItemType, headerLineItemUsageType}, nil)
+
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{fields: []string{"Usage", "EUC1-BoxUsage:m5.xlarge"}, want: PurchaseOnDemand},
+		{fields: []string{"Usage", "EUC1-SpotUsage:m5.xlarge"}, want: PurchaseSpot},
+		{fields: []string{"DiscountedUsage", "EUC1-BoxUsage:m5.xlarge"}, want: PurchaseReserved},
+		{fields: []string{"SavingsPlanCoveredUsage", "EUC1-BoxUsage:m5.xlarge"}, want: PurchaseSavingsPlan},
+		{fields: []string{"Usage", "EUC1-TimedStorage-ByteHrs"}, want: PurchaseOnDemand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := purchaseOption(h, tt.fields); got != tt.want {
+				t.Errorf("purchaseOption(%v) = %q, want %q", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ebsVolumeTypeFromUsageType(t *testing.T) {
+	tests := []struct {
+		name      string
+		usageType string
+		want      string
+	}{
+		{name: "gp3", usageType: "EUC1-EBS:VolumeUsage.gp3", want: "gp3"},
+		{name: "st1 in us-east-1", usageType: "EBS:VolumeUsage.st1", want: "st1"},
+		{name: "magnetic", usageType: "EU-EBS:VolumeUsage", want: "standard"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ebsVolumeTypeFromUsageType(tt.usageType); got != tt.want {
+				t.Errorf("ebsVolumeTypeFromUsageType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_s3StorageClassFromUsageType(t *testing.T) {
+	tests := []struct {
+		name      string
+		usageType string
+		want      string
+	}{
+		{name: "standard", usageType: "EUC1-TimedStorage-ByteHrs", want: "Standard"},
+		{name: "infrequent access", usageType: "EUC1-TimedStorage-SIA-ByteHrs", want: "SIA"},
+		{name: "glacier in us-east-1", usageType: "TimedStorage-GlacierByteHrs", want: "Glacier"},
+		{name: "intelligent tiering", usageType: "EU-TimedStorage-INT-FA-ByteHrs", want: "INT-FA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s3StorageClassFromUsageType(tt.usageType); got != tt.want {
+				t.Errorf("s3StorageClassFromUsageType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isDataTransferOut(t *testing.T) {
+	tests := []struct {
+		name      string
+		usageType string
+		want      bool
+	}{
+		{name: "internet egress", usageType: "EUC1-DataTransfer-Out-Bytes", want: true},
+		{name: "between availability zones", usageType: "EUC1-DataTransfer-Regional-Bytes", want: true},
+		{name: "to other region", usageType: "EUC1-EUW1-AWS-Out-Bytes", want: true},
+		{name: "inbound", usageType: "EUC1-DataTransfer-In-Bytes", want: false},
+		{name: "inbound from other region", usageType: "EUC1-EUW1-AWS-In-Bytes", want: false},
+		{name: "instance usage", usageType: "EUC1-BoxUsage:m5.xlarge", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDataTransferOut(tt.usageType); got != tt.want {
+				t.Errorf("isDataTransferOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hoursInMonth(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		want float64
+	}{
+		{name: "31 days", date: "2022-08-15T10:00:00Z", want: 744},
+		{name: "30 days", date: "2022-09-30T23:00:00Z", want: 720},
+		{name: "leap year february", date: "2024-02-01T00:00:00Z", want: 696},
+		{name: "december", date: "2022-12-31T00:00:00Z", want: 744},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := parseDate(headerLineItemUsageStartDate, tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hoursInMonth(date); got != tt.want {
+				t.Errorf("hoursInMonth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readEBSUsage(t *testing.T) {
+	header := []string{headerIdentityTimeInterval, headerLineItemUsageAmount, headerLineItemUsageType, headerProductVolumeAPI}
+	h := newLegacyHeaders(header, nil)
+
+	tests := []struct {
+		name            string
+		fields          []string
+		wantStorageType string
+		wantGBHours     float64
+		wantErr         bool
+	}{
+		{
+			name:            "volume type column",
+			fields:          []string{"2022-09-01T00:00:00Z/2022-09-01T01:00:00Z", "0.5", "EUC1-EBS:VolumeUsage.gp3", "gp3"},
+			wantStorageType: "gp3",
+			wantGBHours:     360,
+		},
+		{
+			name:            "volume type from usage type",
+			fields:          []string{"2022-09-01T00:00:00Z/2022-09-01T01:00:00Z", "1", "EUC1-EBS:VolumeUsage.sc1", ""},
+			wantStorageType: "sc1",
+			wantGBHours:     720,
+		},
+		{
+			name:    "invalid amount",
+			fields:  []string{"2022-09-01T00:00:00Z/2022-09-01T01:00:00Z", "n/a", "EUC1-EBS:VolumeUsage.gp3", "gp3"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, _ := readLineItem(h, tt.fields)
+			err := readEBSUsage(h, tt.fields, &item)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readEBSUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if item.StorageType != tt.wantStorageType {
+				t.Errorf("readEBSUsage() StorageType = %v, want %v", item.StorageType, tt.wantStorageType)
+			}
+			if math.Abs(item.GBHours-tt.wantGBHours) > 1e-9 {
+				t.Errorf("readEBSUsage() GBHours = %v, want %v", item.GBHours, tt.wantGBHours)
+			}
+			if item.Duration != 0 {
+				t.Errorf("readEBSUsage() Duration = %v, want 0", item.Duration)
+			}
+		})
+	}
+}
+
+func Test_readEC2Usage(t *testing.T) {
+	h := newLegacyHeaders([]string{headerLineItemUsageType, headerProductInstanceType}, nil)
+
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{fields: []string{"EUC1-BoxUsage:m5.xlarge", "m5.xlarge"}, want: "m5.xlarge"},
+		{fields: []string{"EUC1-HostUsage:m5", ""}, want: "m5.metal"},
+		{fields: []string{"HostUsage:mac1", "mac1"}, want: "mac1.metal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fields[0], func(t *testing.T) {
+			item, _ := readLineItem(h, tt.fields)
+			readEC2Usage(h, tt.fields, &item)
+			if item.InstanceType != tt.want {
+				t.Errorf("readEC2Usage() InstanceType = %q, want %q", item.InstanceType, tt.want)
+			}
+		})
+	}
+}
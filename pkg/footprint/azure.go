@@ -0,0 +1,171 @@
+package footprint
+
+import (
+	_ "embed"
+	"io"
+	"time"
+)
+
+// Azure data is based on the methodology of the Cloud Carbon Footprint project:
+// https://www.cloudcarbonfootprint.org/docs/methodology/
+//
+// VM size power is derived from the number of vCPUs, using the average of the
+// minimum (0.78 W) and maximum (3.76 W) power of an Azure vCPU, as for a load
+// of 50 percent, plus 0.392 W per GB of memory.
+
+//go:embed azure-vm-sizes.csv
+var azureVMSizesCSV string
+
+//go:embed azure-regions.csv
+var azureRegionsCSV string
+
+type AzureVMSize struct {
+	// VCPUs is the number of vCPUs of the VM size.
+	VCPUs float64
+
+	// PowerAt50Percent is the power consumption in Watt at 50% load.
+	PowerAt50Percent float64
+}
+
+type AzureRegion struct {
+	// CarbonIntensity is the amount of CO2 emitted when producing electricity.
+	// Unit: metric gram per kilowatt hour.
+	CarbonIntensity float64
+
+	// PUE is the power usage effectiveness coefficient of the data center.
+	PUE float64
+
+	// WUE is the water usage effectiveness of the data center, in liters
+	// of water consumed per kilowatt hour of IT energy.
+	WUE float64
+}
+
+// azureColumnVMSize is the column of the Azure VM size dataset with the VM
+// size.
+const azureColumnVMSize = "VM size"
+
+func parseAzureVMSizes(r io.Reader) (map[string]AzureVMSize, error) {
+	azureVMSizes := make(map[string]AzureVMSize)
+	columns := []string{azureColumnVMSize, machineColumnVCPUs, machineColumnPower}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		vCPUs, err := c.float(record, machineColumnVCPUs)
+		if err != nil {
+			return err
+		}
+		power, err := c.float(record, machineColumnPower)
+		if err != nil {
+			return err
+		}
+
+		azureVMSizes[c.value(record, azureColumnVMSize)] = AzureVMSize{
+			VCPUs:            vCPUs,
+			PowerAt50Percent: power,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return azureVMSizes, nil
+}
+
+func parseAzureRegions(r io.Reader) (map[string]AzureRegion, error) {
+	azureRegions := make(map[string]AzureRegion)
+	columns := []string{regionColumnRegion, regionColumnCarbonIntensity, regionColumnPUE}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		carbonIntensity, err := c.float(record, regionColumnCarbonIntensity)
+		if err != nil {
+			return err
+		}
+		pue, err := c.float(record, regionColumnPUE)
+		if err != nil {
+			return err
+		}
+
+		azureRegions[c.value(record, regionColumnRegion)] = AzureRegion{
+			CarbonIntensity: carbonIntensity,
+			PUE:             pue,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return azureRegions, nil
+}
+
+// AzureRegions returns the sorted codes of the Azure regions in the dataset.
+func (c *Calculator) AzureRegions() []string {
+	return sortedKeys(c.azureRegions)
+}
+
+// AzureCarbonIntensity returns the carbon intensity for an Azure region, in grams
+// of CO2 emitted while producing one kilowatt hour of electricity.
+func (c *Calculator) AzureCarbonIntensity(regionCode string) (float64, error) {
+	val, exists := c.azureRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	} else {
+		return val.CarbonIntensity, nil
+	}
+}
+
+// AzurePUE returns the power usage effectiveness coefficient for an Azure region.
+func (c *Calculator) AzurePUE(regionCode string) (float64, error) {
+	val, exists := c.azureRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	} else {
+		return val.PUE, nil
+	}
+}
+
+// AzureVMSizeVCPUs returns the number of vCPUs of an Azure VM size.
+func (c *Calculator) AzureVMSizeVCPUs(vmSize string) (float64, error) {
+	val, exists := c.azureVMSizes[vmSize]
+	if !exists {
+		return 0, unknownInstanceType(vmSize)
+	} else {
+		return val.VCPUs, nil
+	}
+}
+
+// AzurePowerAt50Percent returns the power consumption at 50% load for an Azure VM size, in watt.
+func (c *Calculator) AzurePowerAt50Percent(machineType string) (float64, error) {
+	val, exists := c.azureVMSizes[machineType]
+	if !exists {
+		return 0, unknownInstanceType(machineType)
+	} else {
+		return val.PowerAt50Percent, nil
+	}
+}
+
+// Azure returns the footprint of an Azure virtual machine.
+// Manufacturing emissions are not accounted for.
+func (c *Calculator) Azure(region, vmSize string, duration time.Duration) (Result, error) {
+	pue, err := c.AzurePUE(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.AzureWUE(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.AzureCarbonIntensity(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	power, err := c.AzurePowerAt50Percent(vmSize)
+	if err != nil {
+		return Result{}, err
+	}
+
+	powerKiloWatt := power / 1000.0
+
+	return operationalResult(powerKiloWatt*duration.Hours(), pue, wue, ci), nil
+}
@@ -0,0 +1,105 @@
+package footprint
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_parseAzureVMSizes(t *testing.T) {
+	c := testCalculator(t)
+
+	tests := []struct {
+		vmSize string
+		value  AzureVMSize
+	}{
+		{vmSize: "Standard_D2s_v3", value: AzureVMSize{VCPUs: 2, PowerAt50Percent: 7.68}},
+		{vmSize: "Standard_B1s", value: AzureVMSize{VCPUs: 1, PowerAt50Percent: 2.66}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vmSize, func(t *testing.T) {
+			if c.azureVMSizes[tt.vmSize] != tt.value {
+				t.Errorf("parseAzureVMSizes() machine type %s - want value %v, got value %v", tt.vmSize, tt.value, c.azureVMSizes[tt.vmSize])
+			}
+		})
+	}
+}
+
+func Test_parseAzureRegions(t *testing.T) {
+	c := testCalculator(t)
+
+	tests := []struct {
+		regionCode  string
+		azureRegion AzureRegion
+	}{
+		{regionCode: "westeurope", azureRegion: AzureRegion{CarbonIntensity: 390, PUE: 1.185, WUE: azureWUE}},
+		{regionCode: "eastus", azureRegion: AzureRegion{CarbonIntensity: 379.069, PUE: 1.185, WUE: azureWUE}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regionCode, func(t *testing.T) {
+			if c.azureRegions[tt.regionCode] != tt.azureRegion {
+				t.Errorf("parseAzureRegions() code %s - want value %v, got value %v", tt.regionCode, tt.azureRegion, c.azureRegions[tt.regionCode])
+			}
+		})
+	}
+}
+
+func TestAzureVMSizeVCPUs(t *testing.T) {
+	if got, err := AzureVMSizeVCPUs("Standard_D2s_v3"); err != nil || got != 2 {
+		t.Errorf("AzureVMSizeVCPUs() = %v, %v, want 2, nil", got, err)
+	}
+	if _, err := AzureVMSizeVCPUs("Standard_Unknown"); err == nil {
+		t.Error("AzureVMSizeVCPUs() of unknown VM size returned no error")
+	}
+}
+
+func TestAzure(t *testing.T) {
+	type args struct {
+		region   string
+		vmSize   string
+		duration time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero duration", args: args{"westeurope", "Standard_D2s_v3", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", "Standard_D2s_v3", time.Hour}, want: 0, wantErr: true},
+		{name: "AWS region", args: args{"eu-west-1", "Standard_D2s_v3", time.Hour}, want: 0, wantErr: true},
+		{name: "unknown VM size", args: args{"westeurope", "unknown", time.Hour}, want: 0, wantErr: true},
+		// 7.68 W * 1.185 (PUE) * 390 g/kWh
+		{name: "westeurope Standard_D2s_v3 1 hour", args: args{"westeurope", "Standard_D2s_v3", time.Hour}, want: 3.549312, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Azure(tt.args.region, tt.args.vmSize, tt.args.duration)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Azure() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("Azure() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAzureRegions(t *testing.T) {
+	regions := AzureRegions()
+	if !sort.StringsAreSorted(regions) {
+		t.Error("AzureRegions() is not sorted")
+	}
+	for _, regionCode := range regions {
+		if _, err := AzureCarbonIntensity(regionCode); err != nil {
+			t.Errorf("AzureCarbonIntensity(%q) error = %v", regionCode, err)
+		}
+	}
+	if i := sort.SearchStrings(regions, "westeurope"); i == len(regions) || regions[i] != "westeurope" {
+		t.Error("AzureRegions() doesn't contain westeurope")
+	}
+}
@@ -0,0 +1,403 @@
+package footprint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Calculator estimates footprints based on a set of datasets. Calculators
+// are safe for concurrent use.
+type Calculator struct {
+	// ec2Instances stores data about EC2 instances, using the instance type
+	// name as key.
+	ec2Instances map[string]EC2Instance
+
+	// ec2AveragePerVCPU is the average data per vCPU of ec2Instances.
+	ec2AveragePerVCPU EC2Instance
+
+	// awsInstanceSpecs stores the specs of EC2 instance types missing from
+	// ec2Instances, using the instance type name as key.
+	awsInstanceSpecs map[string]InstanceSpec
+
+	// awsRegions stores data about AWS regions, using the region code as
+	// key.
+	awsRegions map[string]AWSRegion
+
+	// awsRegionLocations stores the approximate location of AWS regions,
+	// using the region code as key.
+	awsRegionLocations map[string]Location
+
+	// awsRegionCountries stores the ISO 3166-1 alpha-2 code of the country
+	// of AWS regions, using the region code as key.
+	awsRegionCountries map[string]string
+
+	// outposts stores data about AWS Outposts racks added with
+	// WithOutpost, using the code given as key.
+	outposts map[string]AWSRegion
+
+	// countries stores the carbon intensity of national grids, used for
+	// AWS regions missing from awsRegions, using the country code as key.
+	countries map[string]country
+
+	// gcpMachineTypes stores data about GCP machine types, using the
+	// machine type name as key.
+	gcpMachineTypes map[string]GCPMachineType
+
+	// gcpRegions stores data about GCP regions, using the region code as
+	// key.
+	gcpRegions map[string]GCPRegion
+
+	// azureVMSizes stores data about Azure VM sizes, using the VM size name
+	// as key.
+	azureVMSizes map[string]AzureVMSize
+
+	// azureRegions stores data about Azure regions, using the region code
+	// as key.
+	azureRegions map[string]AzureRegion
+
+	// pue overrides the PUE of all regions, unless zero.
+	pue float64
+
+	// regionPUE overrides the PUE of single regions of any provider, using
+	// the region code as key. It takes precedence over pue.
+	regionPUE map[string]float64
+
+	// carbonIntensity overrides the carbon intensity of single regions of
+	// any provider, using the region code as key.
+	carbonIntensity map[string]float64
+
+	// wue overrides the WUE of all regions, unless nil.
+	wue *float64
+
+	// regionWUE overrides the WUE of single regions of any provider, using
+	// the region code as key. It takes precedence over wue.
+	regionWUE map[string]float64
+
+	// amortizationYears is the lifetime of hardware its manufacturing
+	// emissions are spread over.
+	amortizationYears float64
+}
+
+// Option configures a Calculator.
+type Option func(c *Calculator) error
+
+// WithEC2Instances replaces the embedded EC2 instance dataset with the one
+// read from r, which must have the columns of the Teads dataset.
+func WithEC2Instances(r io.Reader) Option {
+	return func(c *Calculator) error {
+		instances, err := ParseEC2Instances(r)
+		if err != nil {
+			return fmt.Errorf("could not read EC2 instances: %w", err)
+		}
+		if len(instances) == 0 {
+			return fmt.Errorf("could not read EC2 instances: no instance types found")
+		}
+		c.ec2Instances = instances
+		return nil
+	}
+}
+
+// WithAWSRegions replaces the embedded AWS region dataset with the one read
+// from r, which must have the columns of the embedded aws-regions.csv.
+func WithAWSRegions(r io.Reader) Option {
+	return func(c *Calculator) error {
+		regions, err := ParseAWSRegions(r)
+		if err != nil {
+			return fmt.Errorf("could not read AWS regions: %w", err)
+		}
+		if len(regions) == 0 {
+			return fmt.Errorf("could not read AWS regions: no regions found")
+		}
+		c.awsRegions = regions
+		return nil
+	}
+}
+
+// WithPUE overrides the power usage effectiveness coefficient of the data
+// centers in all regions of all providers, e.g. with a contractual value.
+func WithPUE(pue float64) Option {
+	return func(c *Calculator) error {
+		if pue < 1 {
+			return fmt.Errorf("invalid PUE %g, must be at least 1", pue)
+		}
+		c.pue = pue
+		return nil
+	}
+}
+
+// WithRegionPUE overrides the power usage effectiveness coefficient of the
+// data centers in one region, e.g. with a newer figure published by the
+// provider. It takes precedence over WithPUE.
+func WithRegionPUE(regionCode string, pue float64) Option {
+	return func(c *Calculator) error {
+		if pue < 1 {
+			return fmt.Errorf("invalid PUE %g for region %q, must be at least 1", pue, regionCode)
+		}
+		if c.regionPUE == nil {
+			c.regionPUE = make(map[string]float64)
+		}
+		c.regionPUE[regionCode] = pue
+		return nil
+	}
+}
+
+// WithWUE overrides the water usage effectiveness of the data centers in all
+// regions of all providers, in liters per kilowatt hour of IT energy.
+func WithWUE(wue float64) Option {
+	return func(c *Calculator) error {
+		if wue < 0 {
+			return fmt.Errorf("invalid WUE %g, must not be negative", wue)
+		}
+		c.wue = &wue
+		return nil
+	}
+}
+
+// WithRegionWUE overrides the water usage effectiveness of the data centers
+// in one region, e.g. with a figure published for a single data center. It
+// takes precedence over WithWUE.
+func WithRegionWUE(regionCode string, wue float64) Option {
+	return func(c *Calculator) error {
+		if wue < 0 {
+			return fmt.Errorf("invalid WUE %g for region %q, must not be negative", wue, regionCode)
+		}
+		if c.regionWUE == nil {
+			c.regionWUE = make(map[string]float64)
+		}
+		c.regionWUE[regionCode] = wue
+		return nil
+	}
+}
+
+// WithCarbonIntensity overrides the carbon intensity of the electricity in
+// one region, in grams of CO2e per kilowatt hour, e.g. with newer data. For
+// AWS regions without renewable energy purchases, the market-based carbon
+// intensity is overridden, too.
+func WithCarbonIntensity(regionCode string, carbonIntensity float64) Option {
+	return func(c *Calculator) error {
+		if carbonIntensity < 0 {
+			return fmt.Errorf("invalid carbon intensity %g for region %q, must not be negative", carbonIntensity, regionCode)
+		}
+		if c.carbonIntensity == nil {
+			c.carbonIntensity = make(map[string]float64)
+		}
+		c.carbonIntensity[regionCode] = carbonIntensity
+		return nil
+	}
+}
+
+// WithAmortizationYears sets the lifetime of hardware its manufacturing
+// emissions are spread over, DefaultAmortizationYears by default. A shorter
+// lifetime increases the embodied emissions per hour of usage.
+func WithAmortizationYears(years float64) Option {
+	return func(c *Calculator) error {
+		if years <= 0 {
+			return fmt.Errorf("invalid amortization period of %g years, must be positive", years)
+		}
+		c.amortizationYears = years
+		return nil
+	}
+}
+
+// NewCalculator returns a calculator using the embedded datasets, unless
+// replaced by options.
+func NewCalculator(opts ...Option) (*Calculator, error) {
+	c := &Calculator{amortizationYears: DefaultAmortizationYears}
+
+	var err error
+	if c.ec2Instances, err = ParseEC2Instances(strings.NewReader(ec2instancesCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded EC2 instances: %w", err)
+	}
+	if c.awsInstanceSpecs, err = parseAWSInstanceSpecs(strings.NewReader(awsInstanceSpecsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded AWS instance specs: %w", err)
+	}
+	if c.awsRegions, err = ParseAWSRegions(strings.NewReader(awsRegionsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded AWS regions: %w", err)
+	}
+	if c.awsRegionLocations, err = parseAWSRegionLocations(strings.NewReader(awsRegionLocationsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded AWS region locations: %w", err)
+	}
+	if c.awsRegionCountries, err = parseAWSRegionCountries(strings.NewReader(awsRegionLocationsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded AWS region countries: %w", err)
+	}
+	if c.countries, err = parseCountryIntensities(strings.NewReader(countryIntensitiesCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded country intensities: %w", err)
+	}
+	if c.gcpMachineTypes, err = parseGCPMachineTypes(strings.NewReader(gcpMachineTypesCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded GCP machine types: %w", err)
+	}
+	if c.gcpRegions, err = parseGCPRegions(strings.NewReader(gcpRegionsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded GCP regions: %w", err)
+	}
+	if c.azureVMSizes, err = parseAzureVMSizes(strings.NewReader(azureVMSizesCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded Azure VM sizes: %w", err)
+	}
+	if c.azureRegions, err = parseAzureRegions(strings.NewReader(azureRegionsCSV)); err != nil {
+		return nil, fmt.Errorf("could not read embedded Azure regions: %w", err)
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.addCountryRegions(); err != nil {
+		return nil, err
+	}
+	if err := c.overridePUE(); err != nil {
+		return nil, err
+	}
+	if err := c.overrideCarbonIntensity(); err != nil {
+		return nil, err
+	}
+	if err := c.setWUE(); err != nil {
+		return nil, err
+	}
+	c.amortize()
+	c.ec2AveragePerVCPU = averagePerVCPU(c.ec2Instances)
+
+	return c, nil
+}
+
+// amortize spreads the manufacturing emissions of EC2 instances over the
+// amortization period instead of the one of the dataset. This is done once
+// all options are applied, so that it also applies to a replaced dataset.
+func (c *Calculator) amortize() {
+	if c.amortizationYears == DefaultAmortizationYears {
+		return
+	}
+	factor := DefaultAmortizationYears / c.amortizationYears
+	for instanceType, instance := range c.ec2Instances {
+		instance.ManufacturingEmissionsHourly *= factor
+		c.ec2Instances[instanceType] = instance
+	}
+}
+
+// AmortizationYears returns the lifetime of hardware its manufacturing
+// emissions are spread over.
+func (c *Calculator) AmortizationYears() float64 {
+	return c.amortizationYears
+}
+
+// overridePUE applies the PUE overrides to the region datasets. This is done
+// once all options are applied, so that overrides also apply to replaced
+// datasets.
+func (c *Calculator) overridePUE() error {
+	pueOf := func(code string, pue float64) float64 {
+		if override, exists := c.regionPUE[code]; exists {
+			return override
+		}
+		if c.pue != 0 {
+			return c.pue
+		}
+		return pue
+	}
+
+	for code, r := range c.awsRegions {
+		r.PUE = pueOf(code, r.PUE)
+		c.awsRegions[code] = r
+	}
+	for code, r := range c.gcpRegions {
+		r.PUE = pueOf(code, r.PUE)
+		c.gcpRegions[code] = r
+	}
+	for code, r := range c.azureRegions {
+		r.PUE = pueOf(code, r.PUE)
+		c.azureRegions[code] = r
+	}
+
+	for _, code := range sortedKeys(c.regionPUE) {
+		_, aws := c.awsRegions[code]
+		_, gcp := c.gcpRegions[code]
+		_, azure := c.azureRegions[code]
+		if !aws && !gcp && !azure {
+			return fmt.Errorf("could not override PUE: %w", unknownRegion(code))
+		}
+	}
+	return nil
+}
+
+// overrideCarbonIntensity applies the carbon intensity overrides to the
+// region datasets, see overridePUE.
+func (c *Calculator) overrideCarbonIntensity() error {
+	for _, code := range sortedKeys(c.carbonIntensity) {
+		ci := c.carbonIntensity[code]
+		if r, exists := c.awsRegions[code]; exists {
+			if r.MarketCarbonIntensity == r.CarbonIntensity {
+				r.MarketCarbonIntensity = ci
+			}
+			r.CarbonIntensity = ci
+			c.awsRegions[code] = r
+		} else if r, exists := c.gcpRegions[code]; exists {
+			r.CarbonIntensity = ci
+			c.gcpRegions[code] = r
+		} else if r, exists := c.azureRegions[code]; exists {
+			r.CarbonIntensity = ci
+			c.azureRegions[code] = r
+		} else {
+			return fmt.Errorf("could not override carbon intensity: %w", unknownRegion(code))
+		}
+	}
+	return nil
+}
+
+// setWUE sets the WUE of all regions to the provider's average, unless
+// overridden, see overridePUE.
+func (c *Calculator) setWUE() error {
+	wueOf := func(code string, wue float64) float64 {
+		if override, exists := c.regionWUE[code]; exists {
+			return override
+		}
+		if c.wue != nil {
+			return *c.wue
+		}
+		return wue
+	}
+
+	for code, r := range c.awsRegions {
+		r.WUE = wueOf(code, awsWUE)
+		c.awsRegions[code] = r
+	}
+	for code, r := range c.gcpRegions {
+		r.WUE = wueOf(code, gcpWUE)
+		c.gcpRegions[code] = r
+	}
+	for code, r := range c.azureRegions {
+		r.WUE = wueOf(code, azureWUE)
+		c.azureRegions[code] = r
+	}
+
+	for _, code := range sortedKeys(c.regionWUE) {
+		_, aws := c.awsRegions[code]
+		_, gcp := c.gcpRegions[code]
+		_, azure := c.azureRegions[code]
+		if !aws && !gcp && !azure {
+			return fmt.Errorf("could not override WUE: %w", unknownRegion(code))
+		}
+	}
+	return nil
+}
+
+// CarbonIntensityOverrides returns the carbon intensities overridden with
+// WithCarbonIntensity, using the region code as key.
+func (c *Calculator) CarbonIntensityOverrides() map[string]float64 {
+	overrides := make(map[string]float64, len(c.carbonIntensity))
+	for code, ci := range c.carbonIntensity {
+		overrides[code] = ci
+	}
+	return overrides
+}
+
+// defaultCalculator is the calculator used by the package-level functions.
+var defaultCalculator = sync.OnceValues(func() (*Calculator, error) {
+	return NewCalculator()
+})
+
+// Default returns a shared calculator using the embedded datasets. It is
+// used by the package-level functions.
+func Default() (*Calculator, error) {
+	return defaultCalculator()
+}
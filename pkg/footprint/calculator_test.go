@@ -0,0 +1,313 @@
+package footprint
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCalculator returns a calculator with the embedded datasets.
+func testCalculator(t *testing.T) *Calculator {
+	t.Helper()
+	c, err := NewCalculator()
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+	return c
+}
+
+// testEC2InstancesCSV returns a dataset with the columns of the Teads
+// dataset used, in another order, with one instance type.
+func testEC2InstancesCSV(instanceType string, powerAt50Percent string) string {
+	values := map[string]string{
+		ec2ColumnInstanceType:  instanceType,
+		ec2ColumnVCPUs:         "2",
+		ec2ColumnGPUs:          "N/A",
+		ec2ColumnManufacturing: "1",
+		"Release Date":         "2099",
+	}
+	for _, column := range ec2PowerColumns {
+		values[column] = "1"
+	}
+	values[ec2PowerColumns[2]] = powerAt50Percent
+	for _, column := range ec2GPUPowerColumns {
+		values[column] = "0"
+	}
+
+	var header, record []string
+	for _, column := range sortedKeys(values) {
+		header = append(header, column)
+		record = append(record, values[column])
+	}
+	return strings.Join(header, ",") + "\n" + strings.Join(record, ",") + "\n"
+}
+
+func TestNewCalculator_WithEC2Instances(t *testing.T) {
+	c, err := NewCalculator(WithEC2Instances(strings.NewReader(testEC2InstancesCSV("m99.large", "10"))))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	power, err := c.PowerAt50Percent("m99.large")
+	if err != nil || power != 10 {
+		t.Errorf("PowerAt50Percent() = %v, %v, want 10, nil", power, err)
+	}
+	if _, err := c.PowerAt50Percent("t2.micro"); !errors.Is(err, ErrUnknownInstanceType) {
+		t.Errorf("PowerAt50Percent() for instance type of the replaced dataset error = %v, want %v", err, ErrUnknownInstanceType)
+	}
+	if c.ec2AveragePerVCPU.PowerAt50Percent != 5 {
+		t.Errorf("average power per vCPU = %v, want the average of the replaced dataset", c.ec2AveragePerVCPU.PowerAt50Percent)
+	}
+
+	// The default calculator is not affected.
+	if _, err := PowerAt50Percent("t2.micro"); err != nil {
+		t.Errorf("PowerAt50Percent() of default calculator error = %v", err)
+	}
+
+	for _, invalid := range []string{"", "Instance type,Power\nm5.large,12\n", testEC2InstancesCSV("m99.large", "ten")} {
+		if _, err := NewCalculator(WithEC2Instances(strings.NewReader(invalid))); err == nil {
+			t.Errorf("NewCalculator() with dataset %q returned no error", invalid)
+		}
+	}
+}
+
+func TestNewCalculator_WithAWSRegions(t *testing.T) {
+	data := "Region,Region Name,Country,NERC Region,CO2e (metric gram/kWh),Source,PUE,Dataset Source,Market-based CO2e (metric gram/kWh)\nxx-test-1,Test,Test,,100,,1.5,,0\n"
+	c, err := NewCalculator(WithAWSRegions(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	ci, err := c.CarbonIntensity("xx-test-1")
+	if err != nil || ci != 100 {
+		t.Errorf("CarbonIntensity() = %v, %v, want 100, nil", ci, err)
+	}
+	result, err := c.AWS("xx-test-1", "t2.micro", time.Hour)
+	if err != nil || result.Total() == 0 {
+		t.Errorf("AWS() = %v, %v, want a result for the injected region", result, err)
+	}
+
+	if _, err := NewCalculator(WithAWSRegions(strings.NewReader("Region,CO2e\nxx-test-1,100\n"))); err == nil {
+		t.Error("NewCalculator() with missing region columns returned no error")
+	}
+}
+
+func TestNewCalculator_countryRegions(t *testing.T) {
+	c := testCalculator(t)
+
+	r, err := c.Region("il-central-1")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	want := AWSRegion{CarbonIntensity: 575, PUE: 1.2, WUE: awsWUE, MarketCarbonIntensity: 575, EstimatedFrom: "national grid of Israel"}
+	if math.Abs(r.PUE-want.PUE) > 1e-9 {
+		t.Errorf("Region() PUE = %v, want %v", r.PUE, want.PUE)
+	}
+	r.PUE = want.PUE
+	if r != want {
+		t.Errorf("Region() = %+v, want %+v", r, want)
+	}
+	if r, _ := c.Region("eu-west-1"); r.EstimatedFrom != "" {
+		t.Errorf("Region(eu-west-1) EstimatedFrom = %q, want empty", r.EstimatedFrom)
+	}
+
+	data := "Region,CO2e (metric gram/kWh),PUE,Market-based CO2e (metric gram/kWh)\neu-west-1,300,1.1,0\neu-west-2,200,1.3,0\n"
+	c, err = NewCalculator(WithAWSRegions(strings.NewReader(data)), WithCarbonIntensity("il-central-1", 500))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+	if r, _ := c.Region("il-central-1"); r.CarbonIntensity != 500 || math.Abs(r.PUE-1.2) > 1e-9 {
+		t.Errorf("Region() with replaced dataset and override = %+v, want carbon intensity 500 and average PUE 1.2", r)
+	}
+	if _, err := c.Region("eu-central-1"); err != nil {
+		t.Errorf("Region() of region missing from replaced dataset error = %v", err)
+	}
+}
+
+func TestNewCalculator_WithPUE(t *testing.T) {
+	c, err := NewCalculator(WithPUE(1.5), WithRegionPUE("eu-west-1", 1.1), WithRegionPUE("westeurope", 1.3))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		pue  func(regionCode string) (float64, error)
+		code string
+		want float64
+	}{
+		{name: "AWS region override", pue: c.PUE, code: "eu-west-1", want: 1.1},
+		{name: "AWS global override", pue: c.PUE, code: "us-east-1", want: 1.5},
+		{name: "GCP global override", pue: c.GCPPUE, code: "europe-west1", want: 1.5},
+		{name: "Azure region override", pue: c.AzurePUE, code: "westeurope", want: 1.3},
+	} {
+		if got, err := tt.pue(tt.code); err != nil || got != tt.want {
+			t.Errorf("%s: PUE(%q) = %v, %v, want %v", tt.name, tt.code, got, err, tt.want)
+		}
+	}
+
+	// The override flows through the estimates.
+	defaultResult, _ := AWS("us-east-1", "m5.large", time.Hour)
+	result, err := c.AWS("us-east-1", "m5.large", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := defaultResult.EnergyKiloWattHours / 1.2 * 1.5; math.Abs(result.EnergyKiloWattHours-want) > 1e-9 {
+		t.Errorf("AWS() energy = %v, want %v", result.EnergyKiloWattHours, want)
+	}
+
+	for _, opt := range []Option{WithPUE(0.9), WithRegionPUE("eu-west-1", 0), WithRegionPUE("xx-west-1", 1.2)} {
+		if _, err := NewCalculator(opt); err == nil {
+			t.Error("NewCalculator() with invalid PUE override returned no error")
+		}
+	}
+}
+
+func TestNewCalculator_WithWUE(t *testing.T) {
+	c, err := NewCalculator(WithRegionWUE("eu-west-1", 0.5))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		wue  func(regionCode string) (float64, error)
+		code string
+		want float64
+	}{
+		{name: "AWS region override", wue: c.WUE, code: "eu-west-1", want: 0.5},
+		{name: "AWS default", wue: c.WUE, code: "us-east-1", want: awsWUE},
+		{name: "GCP default", wue: c.GCPWUE, code: "europe-west1", want: gcpWUE},
+		{name: "Azure default", wue: c.AzureWUE, code: "westeurope", want: azureWUE},
+	} {
+		if got, err := tt.wue(tt.code); err != nil || got != tt.want {
+			t.Errorf("%s: WUE(%q) = %v, %v, want %v", tt.name, tt.code, got, err, tt.want)
+		}
+	}
+
+	// Water is consumed per kilowatt hour of IT energy, excluding the PUE.
+	result, err := c.AWS("eu-west-1", "m5.large", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pue, _ := c.PUE("eu-west-1")
+	if want := result.EnergyKiloWattHours / pue * 0.5; math.Abs(result.WaterLiters-want) > 1e-9 {
+		t.Errorf("AWS() water = %v, want %v", result.WaterLiters, want)
+	}
+
+	c, err = NewCalculator(WithWUE(0), WithRegionWUE("westeurope", 0.2))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+	if got, _ := c.GCPWUE("europe-west1"); got != 0 {
+		t.Errorf("GCPWUE() with global override = %v, want 0", got)
+	}
+	if got, _ := c.AzureWUE("westeurope"); got != 0.2 {
+		t.Errorf("AzureWUE() with region override = %v, want 0.2", got)
+	}
+
+	for _, opt := range []Option{WithWUE(-1), WithRegionWUE("eu-west-1", -0.1), WithRegionWUE("xx-west-1", 0.2)} {
+		if _, err := NewCalculator(opt); err == nil {
+			t.Error("NewCalculator() with invalid WUE override returned no error")
+		}
+	}
+}
+
+func TestNewCalculator_WithAmortizationYears(t *testing.T) {
+	c, err := NewCalculator(WithAmortizationYears(6))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+	if got := c.AmortizationYears(); got != 6 {
+		t.Errorf("AmortizationYears() = %v, want 6", got)
+	}
+
+	// Embodied emissions of instances in the dataset, estimated from their
+	// specs and from similar instances are spread over 6 instead of 4
+	// years, operational emissions are unchanged.
+	for _, instanceType := range []string{"m5.large", "m5.96xlarge", "m8g.large"} {
+		defaultResult, _, err := AWSEstimatedAtUtilization("us-east-1", instanceType, FallbackFamily, DefaultUtilization, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, _, err := c.AWSEstimatedAtUtilization("us-east-1", instanceType, FallbackFamily, DefaultUtilization, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := defaultResult.EmbodiedGrams * 4 / 6; math.Abs(result.EmbodiedGrams-want) > 1e-9 || result.OperationalGrams != defaultResult.OperationalGrams {
+			t.Errorf("%s: embodied = %v, operational = %v, want %v and %v", instanceType, result.EmbodiedGrams, result.OperationalGrams, want, defaultResult.OperationalGrams)
+		}
+	}
+
+	if got := testCalculator(t).AmortizationYears(); got != DefaultAmortizationYears {
+		t.Errorf("AmortizationYears() by default = %v, want %v", got, DefaultAmortizationYears)
+	}
+	if _, err := NewCalculator(WithAmortizationYears(0)); err == nil {
+		t.Error("NewCalculator() with amortization period of 0 years returned no error")
+	}
+}
+
+func TestNewCalculator_WithCarbonIntensity(t *testing.T) {
+	c, err := NewCalculator(
+		WithCarbonIntensity("ap-southeast-2", 500),
+		WithCarbonIntensity("eu-west-1", 250),
+		WithCarbonIntensity("europe-west1", 100),
+		WithCarbonIntensity("westeurope", 200),
+	)
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		ci   func(regionCode string) (float64, error)
+		code string
+		want float64
+	}{
+		{name: "AWS", ci: c.CarbonIntensity, code: "ap-southeast-2", want: 500},
+		{name: "AWS market-based without renewables", ci: c.MarketCarbonIntensity, code: "ap-southeast-2", want: 500},
+		{name: "AWS market-based with renewables", ci: c.MarketCarbonIntensity, code: "eu-west-1", want: 0},
+		{name: "GCP", ci: c.GCPCarbonIntensity, code: "europe-west1", want: 100},
+		{name: "Azure", ci: c.AzureCarbonIntensity, code: "westeurope", want: 200},
+		{name: "not overridden", ci: c.CarbonIntensity, code: "eu-central-1", want: 338},
+	} {
+		if got, err := tt.ci(tt.code); err != nil || got != tt.want {
+			t.Errorf("%s: carbon intensity of %q = %v, %v, want %v", tt.name, tt.code, got, err, tt.want)
+		}
+	}
+
+	if got := c.CarbonIntensityOverrides(); len(got) != 4 || got["eu-west-1"] != 250 {
+		t.Errorf("CarbonIntensityOverrides() = %v", got)
+	}
+	if got := testCalculator(t).CarbonIntensityOverrides(); len(got) != 0 {
+		t.Errorf("CarbonIntensityOverrides() without overrides = %v, want none", got)
+	}
+
+	for _, opt := range []Option{WithCarbonIntensity("eu-west-1", -1), WithCarbonIntensity("xx-west-1", 100)} {
+		if _, err := NewCalculator(opt); err == nil {
+			t.Error("NewCalculator() with invalid carbon intensity override returned no error")
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	a, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	b, _ := Default()
+	if a != b {
+		t.Error("Default() returned different calculators")
+	}
+}
+
+func BenchmarkNewCalculator(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewCalculator(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package footprint
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// datasetColumns holds the positions of the columns of a dataset, located
+// by name in its header row, so that datasets can gain columns or reorder
+// them without breaking.
+type datasetColumns map[string]int
+
+// newDatasetColumns returns the positions of the named columns in the
+// header row of a dataset. It fails listing all missing columns.
+func newDatasetColumns(header []string, names []string) (datasetColumns, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet exports may start with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		if _, exists := index[name]; !exists {
+			index[name] = i
+		}
+	}
+
+	columns := make(datasetColumns, len(names))
+	var missing []string
+	for _, name := range names {
+		i, exists := index[name]
+		if !exists {
+			missing = append(missing, strconv.Quote(name))
+			continue
+		}
+		columns[name] = i
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// value returns the field of the named column in a record.
+func (c datasetColumns) value(record []string, name string) string {
+	i, exists := c[name]
+	if !exists || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// float returns the field of the named column in a record as a number.
+func (c datasetColumns) float(record []string, name string) (float64, error) {
+	value := c.value(record, name)
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s %q as float: %s", name, value, err)
+	}
+	return f, nil
+}
+
+// readDataset reads a dataset in CSV format from r, locating the named
+// columns in its header row, and calls row for each following record.
+// Errors of row are returned with the line number.
+func readDataset(r io.Reader, names []string, row func(c datasetColumns, record []string) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns, err := newDatasetColumns(header, names)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := row(columns, record); err != nil {
+			line, _ := reader.FieldPos(0)
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
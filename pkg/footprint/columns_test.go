@@ -0,0 +1,63 @@
+package footprint
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_newDatasetColumns(t *testing.T) {
+	columns, err := newDatasetColumns([]string{"\ufeffRegion", "Comment", "PUE", "PUE"}, []string{"Region", "PUE"})
+	if err != nil {
+		t.Fatalf("newDatasetColumns() error = %v", err)
+	}
+	record := []string{"eu-west-1", "", "1.1", "9"}
+	if got := columns.value(record, "Region"); got != "eu-west-1" {
+		t.Errorf("value() = %q, want eu-west-1", got)
+	}
+	if got, err := columns.float(record, "PUE"); err != nil || got != 1.1 {
+		t.Errorf("float() = %v, %v, want the first PUE column", got, err)
+	}
+	if _, err := columns.float([]string{"eu-west-1", "", "n/a"}, "PUE"); err == nil || !strings.Contains(err.Error(), `PUE "n/a"`) {
+		t.Errorf("float() error = %v, want error naming column and value", err)
+	}
+
+	_, err = newDatasetColumns([]string{"Region", "Name"}, []string{"Region", "PUE", "CO2e (metric gram/kWh)"})
+	if want := `missing columns "PUE", "CO2e (metric gram/kWh)"`; err == nil || err.Error() != want {
+		t.Errorf("newDatasetColumns() error = %v, want %s", err, want)
+	}
+}
+
+func Test_readDataset(t *testing.T) {
+	data := "Name,Value\na,1\nb,x\n"
+
+	var names []string
+	err := readDataset(strings.NewReader(data), []string{"Value", "Name"}, func(c datasetColumns, record []string) error {
+		if _, err := c.float(record, "Value"); err != nil {
+			return err
+		}
+		names = append(names, c.value(record, "Name"))
+		return nil
+	})
+	if want := `line 3: error parsing Value "x" as float: strconv.ParseFloat: parsing "x": invalid syntax`; err == nil || err.Error() != want {
+		t.Errorf("readDataset() error = %v, want %s", err, want)
+	}
+	if len(names) != 1 || names[0] != "a" {
+		t.Errorf("readDataset() read %v before the error, want [a]", names)
+	}
+
+	if err := readDataset(strings.NewReader(""), []string{"Name"}, nil); err != nil {
+		t.Errorf("readDataset() of empty dataset error = %v", err)
+	}
+}
+
+func TestParseAWSRegions_columnOrder(t *testing.T) {
+	data := "Market-based CO2e (metric gram/kWh),PUE,New column,CO2e (metric gram/kWh),Region\n10,1.2,x,300,xx-test-1\n"
+	regions, err := ParseAWSRegions(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseAWSRegions() error = %v", err)
+	}
+	want := AWSRegion{CarbonIntensity: 300, PUE: 1.2, MarketCarbonIntensity: 10}
+	if got := regions["xx-test-1"]; got != want {
+		t.Errorf("ParseAWSRegions() = %+v, want %+v", got, want)
+	}
+}
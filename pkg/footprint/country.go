@@ -0,0 +1,103 @@
+package footprint
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+)
+
+//go:embed country-intensities.csv
+var countryIntensitiesCSV string
+
+// Columns of the country datasets. The AWS region locations have the
+// country code column too.
+const (
+	countryColumnCode            = "Country Code"
+	countryColumnName            = "Country"
+	countryColumnCarbonIntensity = "CO2e (metric gram/kWh)"
+)
+
+// country holds the average carbon intensity of a country's electricity
+// grid.
+type country struct {
+	Name string
+
+	// CarbonIntensity is the amount of CO2 emitted when producing electricity.
+	// Unit: metric gram per kilowatt hour.
+	CarbonIntensity float64
+}
+
+// parseCountryIntensities reads a dataset with the columns of the embedded
+// country-intensities.csv from r, and returns the data by ISO 3166-1
+// alpha-2 country code.
+func parseCountryIntensities(r io.Reader) (map[string]country, error) {
+	countries := make(map[string]country)
+	columns := []string{countryColumnCode, countryColumnName, countryColumnCarbonIntensity}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		carbonIntensity, err := c.float(record, countryColumnCarbonIntensity)
+		if err != nil {
+			return err
+		}
+
+		countries[c.value(record, countryColumnCode)] = country{
+			Name:            c.value(record, countryColumnName),
+			CarbonIntensity: carbonIntensity,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+// parseAWSRegionCountries reads the country codes of the AWS region
+// locations from r, and returns them by region code.
+func parseAWSRegionCountries(r io.Reader) (map[string]string, error) {
+	regionCountries := make(map[string]string)
+	columns := []string{regionColumnRegion, countryColumnCode}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		regionCountries[c.value(record, regionColumnRegion)] = c.value(record, countryColumnCode)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return regionCountries, nil
+}
+
+// addCountryRegions adds the AWS regions with a known location but missing
+// from the region dataset, e.g. regions opened after it was published. Their
+// carbon intensity is the average of the national grid, and their PUE the
+// average of the regions in the dataset. This is done once all options are
+// applied, so that it also applies to a replaced dataset, and before the
+// overrides, so that these apply to the added regions as well.
+func (c *Calculator) addCountryRegions() error {
+	if len(c.awsRegions) == 0 {
+		return nil
+	}
+	var pue float64
+	for _, r := range c.awsRegions {
+		pue += r.PUE
+	}
+	pue /= float64(len(c.awsRegions))
+
+	for code, countryCode := range c.awsRegionCountries {
+		if _, exists := c.awsRegions[code]; exists {
+			continue
+		}
+		country, exists := c.countries[countryCode]
+		if !exists {
+			return fmt.Errorf("region %s is located in country %q, which has no carbon intensity", code, countryCode)
+		}
+		c.awsRegions[code] = AWSRegion{
+			CarbonIntensity:       country.CarbonIntensity,
+			PUE:                   pue,
+			MarketCarbonIntensity: country.CarbonIntensity,
+			EstimatedFrom:         "national grid of " + country.Name,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package footprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// EC2InstancesSnapshotDate is the date of the snapshot of the Teads dataset
+// embedded as aws-ec2-instances.csv.
+const EC2InstancesSnapshotDate = "2022-08-17"
+
+// Sources of the embedded datasets.
+const (
+	teadsSource        = "https://docs.google.com/spreadsheets/d/1DqYgQnEDLQVQm5acMAhLgHLD8xXCG9BIrk-_Nv6jF3k/edit#gid=504755275"
+	ccfSource          = "https://www.cloudcarbonfootprint.org/docs/methodology/"
+	awsInstancesSource = "https://aws.amazon.com/ec2/instance-types/"
+	awsRegionsSource   = "https://aws.amazon.com/about-aws/global-infrastructure/regions_az/"
+	emberSource        = "https://ember-energy.org/data/yearly-electricity-data/"
+)
+
+// Licenses of the embedded datasets, as SPDX identifiers. Data compiled for
+// this project from public documentation of the providers is distributed
+// under the license of the project.
+const (
+	licenseCCBY    = "CC-BY-4.0"
+	licenseApache2 = "Apache-2.0"
+)
+
+// Dataset describes a dataset embedded in the package.
+type Dataset struct {
+	// Name is the file name of the dataset, e.g. "aws-ec2-instances.csv".
+	Name string
+
+	// SnapshotDate is the date of the data, if known.
+	SnapshotDate string
+
+	// Checksum is the SHA-256 checksum of the data, prefixed by "sha256:".
+	Checksum string
+
+	// Rows is the number of rows of the dataset, not counting the header.
+	Rows int
+
+	// Source is the URL the data or the methodology it is derived from was
+	// published at.
+	Source string
+
+	// License is the SPDX identifier of the license of the data.
+	License string
+}
+
+// DatasetInfo returns the datasets embedded in the package, which are used
+// unless replaced by options, with their provenance, so that reports can
+// cite them.
+func DatasetInfo() []Dataset {
+	return []Dataset{
+		newDataset("aws-ec2-instances.csv", ec2instancesCSV, EC2InstancesSnapshotDate, teadsSource, licenseCCBY),
+		newDataset("aws-instance-specs.csv", awsInstanceSpecsCSV, "", awsInstancesSource, licenseApache2),
+		newDataset("aws-regions.csv", awsRegionsCSV, "", ccfSource, licenseApache2),
+		newDataset("aws-region-locations.csv", awsRegionLocationsCSV, "", awsRegionsSource, licenseApache2),
+		newDataset("country-intensities.csv", countryIntensitiesCSV, "", emberSource, licenseCCBY),
+		newDataset("gcp-machine-types.csv", gcpMachineTypesCSV, "", ccfSource, licenseApache2),
+		newDataset("gcp-regions.csv", gcpRegionsCSV, "", ccfSource, licenseApache2),
+		newDataset("azure-vm-sizes.csv", azureVMSizesCSV, "", ccfSource, licenseApache2),
+		newDataset("azure-regions.csv", azureRegionsCSV, "", ccfSource, licenseApache2),
+	}
+}
+
+// newDataset returns the description of the embedded dataset data.
+func newDataset(name, data, snapshotDate, source, license string) Dataset {
+	return Dataset{
+		Name:         name,
+		SnapshotDate: snapshotDate,
+		Checksum:     checksum(data),
+		Rows:         countRows(data),
+		Source:       source,
+		License:      license,
+	}
+}
+
+// checksum returns the SHA-256 checksum of data, prefixed by "sha256:".
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// countRows returns the number of records of data in CSV format, not
+// counting the header row.
+func countRows(data string) int {
+	var rows int
+	// Embedded datasets are read in full by the tests of their parsers, so
+	// errors are not expected here.
+	_ = readDataset(strings.NewReader(data), nil, func(datasetColumns, []string) error {
+		rows++
+		return nil
+	})
+	return rows
+}
@@ -0,0 +1,47 @@
+package footprint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDatasetInfo(t *testing.T) {
+	datasets := DatasetInfo()
+
+	if len(datasets) != 9 {
+		t.Errorf("DatasetInfo() returned %d datasets, want 9", len(datasets))
+	}
+	seen := make(map[string]bool)
+	for _, d := range datasets {
+		if seen[d.Checksum] {
+			t.Errorf("dataset %s has the checksum of another dataset", d.Name)
+		}
+		seen[d.Checksum] = true
+		if !strings.HasPrefix(d.Checksum, "sha256:") || len(d.Checksum) != len("sha256:")+64 {
+			t.Errorf("dataset %s has checksum %q, want SHA-256", d.Name, d.Checksum)
+		}
+		if d.Rows == 0 || !strings.HasPrefix(d.Source, "https://") || d.License == "" {
+			t.Errorf("dataset %s lacks provenance: %+v", d.Name, d)
+		}
+	}
+	if datasets[0].Name != "aws-ec2-instances.csv" || datasets[0].SnapshotDate != EC2InstancesSnapshotDate || datasets[0].License != "CC-BY-4.0" {
+		t.Errorf("first dataset = %+v, want Teads dataset with snapshot date", datasets[0])
+	}
+	if instances := InstanceTypes(); datasets[0].Rows != len(instances) {
+		t.Errorf("dataset %s has %d rows, want %d instance types", datasets[0].Name, datasets[0].Rows, len(instances))
+	}
+}
+
+func Test_countRows(t *testing.T) {
+	tests := map[string]int{
+		"":                    0,
+		"Name,Value\n":        0,
+		"Name,Value\na,1\n":   1,
+		"Name,Value\na,1\nb,": 2,
+	}
+	for data, want := range tests {
+		if got := countRows(data); got != want {
+			t.Errorf("countRows(%q) = %d, want %d", data, got, want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package footprint
+
+import "time"
+
+// The package-level functions below use the default calculator, with the
+// embedded datasets. See Calculator for their documentation.
+
+// withDefault calls f with the default calculator.
+func withDefault[T any](f func(c *Calculator) (T, error)) (T, error) {
+	c, err := Default()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return f(c)
+}
+
+// PowerAt50Percent calls Calculator.PowerAt50Percent on the default calculator.
+func PowerAt50Percent(ec2InstanceType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.PowerAt50Percent(ec2InstanceType) })
+}
+
+// PowerAtUtilization calls Calculator.PowerAtUtilization on the default calculator.
+func PowerAtUtilization(ec2InstanceType string, utilization float64) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.PowerAtUtilization(ec2InstanceType, utilization) })
+}
+
+// GPUPowerAtUtilization calls Calculator.GPUPowerAtUtilization on the default calculator.
+func GPUPowerAtUtilization(ec2InstanceType string, utilization float64) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GPUPowerAtUtilization(ec2InstanceType, utilization) })
+}
+
+// ManufacturingEmissions calls Calculator.ManufacturingEmissions on the default calculator.
+func ManufacturingEmissions(ec2InstanceType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.ManufacturingEmissions(ec2InstanceType) })
+}
+
+// CarbonIntensity calls Calculator.CarbonIntensity on the default calculator.
+func CarbonIntensity(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.CarbonIntensity(regionCode) })
+}
+
+// MarketCarbonIntensity calls Calculator.MarketCarbonIntensity on the default calculator.
+func MarketCarbonIntensity(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.MarketCarbonIntensity(regionCode) })
+}
+
+// AWSMarketBased calls Calculator.AWSMarketBased on the default calculator.
+func AWSMarketBased(regionCode string, result Result) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSMarketBased(regionCode, result) })
+}
+
+// PUE calls Calculator.PUE on the default calculator.
+func PUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.PUE(regionCode) })
+}
+
+// WUE calls Calculator.WUE on the default calculator.
+func WUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.WUE(regionCode) })
+}
+
+// RegionLocation calls Calculator.RegionLocation on the default calculator.
+func RegionLocation(regionCode string) (Location, error) {
+	return withDefault(func(c *Calculator) (Location, error) { return c.RegionLocation(regionCode) })
+}
+
+// Regions calls Calculator.Regions on the default calculator. It returns nil
+// if the embedded datasets can't be read.
+func Regions() []string {
+	c, err := Default()
+	if err != nil {
+		return nil
+	}
+	return c.Regions()
+}
+
+// Region calls Calculator.Region on the default calculator.
+func Region(regionCode string) (AWSRegion, error) {
+	return withDefault(func(c *Calculator) (AWSRegion, error) { return c.Region(regionCode) })
+}
+
+// InstanceTypes calls Calculator.InstanceTypes on the default calculator. It
+// returns nil if the embedded datasets can't be read.
+func InstanceTypes() []string {
+	c, err := Default()
+	if err != nil {
+		return nil
+	}
+	return c.InstanceTypes()
+}
+
+// Instance calls Calculator.Instance on the default calculator.
+func Instance(instanceType string) (EC2Instance, error) {
+	return withDefault(func(c *Calculator) (EC2Instance, error) { return c.Instance(instanceType) })
+}
+
+// VCPUs calls Calculator.VCPUs on the default calculator.
+func VCPUs(instanceType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.VCPUs(instanceType) })
+}
+
+// AWS calls Calculator.AWS on the default calculator.
+func AWS(regionCode, instanceType string, duration time.Duration) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWS(regionCode, instanceType, duration) })
+}
+
+// AWSAtUtilization calls Calculator.AWSAtUtilization on the default calculator.
+func AWSAtUtilization(regionCode, instanceType string, utilization float64, duration time.Duration) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) {
+		return c.AWSAtUtilization(regionCode, instanceType, utilization, duration)
+	})
+}
+
+// EstimatedInstance calls Calculator.EstimatedInstance on the default calculator.
+func EstimatedInstance(instanceType, method string) (EC2Instance, string, error) {
+	c, err := Default()
+	if err != nil {
+		return EC2Instance{}, "", err
+	}
+	return c.EstimatedInstance(instanceType, method)
+}
+
+// AWSEstimatedAtUtilization calls Calculator.AWSEstimatedAtUtilization on the default calculator.
+func AWSEstimatedAtUtilization(regionCode, instanceType, method string, utilization float64, duration time.Duration) (Result, string, error) {
+	c, err := Default()
+	if err != nil {
+		return Result{}, "", err
+	}
+	return c.AWSEstimatedAtUtilization(regionCode, instanceType, method, utilization, duration)
+}
+
+// AWSRDSEstimatedAtUtilization calls Calculator.AWSRDSEstimatedAtUtilization on the default calculator.
+func AWSRDSEstimatedAtUtilization(regionCode, dbInstanceType, method string, utilization float64, duration time.Duration, multiAZ bool) (Result, string, error) {
+	c, err := Default()
+	if err != nil {
+		return Result{}, "", err
+	}
+	return c.AWSRDSEstimatedAtUtilization(regionCode, dbInstanceType, method, utilization, duration, multiAZ)
+}
+
+// AWSInstanceSpec calls Calculator.AWSInstanceSpec on the default calculator.
+func AWSInstanceSpec(instanceType string) (InstanceSpec, error) {
+	return withDefault(func(c *Calculator) (InstanceSpec, error) { return c.AWSInstanceSpec(instanceType) })
+}
+
+// RDSInstanceType calls Calculator.RDSInstanceType on the default calculator.
+func RDSInstanceType(dbInstanceType string) (string, error) {
+	return withDefault(func(c *Calculator) (string, error) { return c.RDSInstanceType(dbInstanceType) })
+}
+
+// AWSRDS calls Calculator.AWSRDS on the default calculator.
+func AWSRDS(regionCode, dbInstanceType string, duration time.Duration, multiAZ bool) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSRDS(regionCode, dbInstanceType, duration, multiAZ) })
+}
+
+// AWSRDSAtUtilization calls Calculator.AWSRDSAtUtilization on the default calculator.
+func AWSRDSAtUtilization(regionCode, dbInstanceType string, utilization float64, duration time.Duration, multiAZ bool) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) {
+		return c.AWSRDSAtUtilization(regionCode, dbInstanceType, utilization, duration, multiAZ)
+	})
+}
+
+// AWSLambda calls Calculator.AWSLambda on the default calculator.
+func AWSLambda(regionCode string, gbHours float64) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSLambda(regionCode, gbHours) })
+}
+
+// AWSFargate calls Calculator.AWSFargate on the default calculator.
+func AWSFargate(region string, vcpuHours, gbHours float64) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSFargate(region, vcpuHours, gbHours) })
+}
+
+// AWSStorage calls Calculator.AWSStorage on the default calculator.
+func AWSStorage(regionCode, volumeType string, gbHours float64) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSStorage(regionCode, volumeType, gbHours) })
+}
+
+// AWSObjectStorage calls Calculator.AWSObjectStorage on the default calculator.
+func AWSObjectStorage(regionCode string, gbHours float64) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSObjectStorage(regionCode, gbHours) })
+}
+
+// AWSNetwork calls Calculator.AWSNetwork on the default calculator.
+func AWSNetwork(regionCode string, gigabytes float64) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.AWSNetwork(regionCode, gigabytes) })
+}
+
+// GCPRegions calls Calculator.GCPRegions on the default calculator. It
+// returns nil if the embedded datasets can't be read.
+func GCPRegions() []string {
+	c, err := Default()
+	if err != nil {
+		return nil
+	}
+	return c.GCPRegions()
+}
+
+// GCPMachineTypeVCPUs calls Calculator.GCPMachineTypeVCPUs on the default calculator.
+func GCPMachineTypeVCPUs(machineType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GCPMachineTypeVCPUs(machineType) })
+}
+
+// GCPCarbonIntensity calls Calculator.GCPCarbonIntensity on the default calculator.
+func GCPCarbonIntensity(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GCPCarbonIntensity(regionCode) })
+}
+
+// GCPPUE calls Calculator.GCPPUE on the default calculator.
+func GCPPUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GCPPUE(regionCode) })
+}
+
+// GCPWUE calls Calculator.GCPWUE on the default calculator.
+func GCPWUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GCPWUE(regionCode) })
+}
+
+// GCPPowerAt50Percent calls Calculator.GCPPowerAt50Percent on the default calculator.
+func GCPPowerAt50Percent(machineType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.GCPPowerAt50Percent(machineType) })
+}
+
+// GCP calls Calculator.GCP on the default calculator.
+func GCP(regionCode, machineType string, duration time.Duration) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.GCP(regionCode, machineType, duration) })
+}
+
+// AzureRegions calls Calculator.AzureRegions on the default calculator. It
+// returns nil if the embedded datasets can't be read.
+func AzureRegions() []string {
+	c, err := Default()
+	if err != nil {
+		return nil
+	}
+	return c.AzureRegions()
+}
+
+// AzureCarbonIntensity calls Calculator.AzureCarbonIntensity on the default calculator.
+func AzureCarbonIntensity(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.AzureCarbonIntensity(regionCode) })
+}
+
+// AzurePUE calls Calculator.AzurePUE on the default calculator.
+func AzurePUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.AzurePUE(regionCode) })
+}
+
+// AzureWUE calls Calculator.AzureWUE on the default calculator.
+func AzureWUE(regionCode string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.AzureWUE(regionCode) })
+}
+
+// AzureVMSizeVCPUs calls Calculator.AzureVMSizeVCPUs on the default calculator.
+func AzureVMSizeVCPUs(vmSize string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.AzureVMSizeVCPUs(vmSize) })
+}
+
+// AzurePowerAt50Percent calls Calculator.AzurePowerAt50Percent on the default calculator.
+func AzurePowerAt50Percent(machineType string) (float64, error) {
+	return withDefault(func(c *Calculator) (float64, error) { return c.AzurePowerAt50Percent(machineType) })
+}
+
+// Azure calls Calculator.Azure on the default calculator.
+func Azure(region, vmSize string, duration time.Duration) (Result, error) {
+	return withDefault(func(c *Calculator) (Result, error) { return c.Azure(region, vmSize, duration) })
+}
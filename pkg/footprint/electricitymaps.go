@@ -0,0 +1,205 @@
+package footprint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ElectricityMapsURL is the base URL of the Electricity Maps API.
+	ElectricityMapsURL = "https://api.electricitymap.org"
+
+	// electricityMapsRange is the longest time range the API returns hourly
+	// data for in one request.
+	electricityMapsRange = 10 * 24 * time.Hour
+
+	// electricityMapsTimeLayout is the format of timestamps in API requests.
+	electricityMapsTimeLayout = "2006-01-02T15:04:05Z"
+)
+
+// electricityMapsZones maps AWS region codes to the Electricity Maps zone
+// of the grid the region's data centers are connected to.
+var electricityMapsZones = map[string]string{
+	"us-east-1":      "US-MIDA-PJM",
+	"us-east-2":      "US-MIDA-PJM",
+	"us-west-1":      "US-CAL-CISO",
+	"us-west-2":      "US-NW-BPAT",
+	"af-south-1":     "ZA",
+	"ap-east-1":      "HK",
+	"ap-south-1":     "IN-WE",
+	"ap-northeast-3": "JP-KN",
+	"ap-northeast-2": "KR",
+	"ap-southeast-1": "SG",
+	"ap-southeast-2": "AU-NSW",
+	"ap-northeast-1": "JP-TK",
+	"ca-central-1":   "CA-QC",
+	"cn-north-1":     "CN",
+	"cn-northwest-1": "CN",
+	"eu-central-1":   "DE",
+	"eu-west-1":      "IE",
+	"eu-west-2":      "GB",
+	"eu-south-1":     "IT-NO",
+	"eu-west-3":      "FR",
+	"eu-north-1":     "SE-SE3",
+	"me-south-1":     "BH",
+	"sa-east-1":      "BR-CS",
+	"ap-east-2":      "TW",
+	"ap-south-2":     "IN-SO",
+	"ap-southeast-3": "ID",
+	"ap-southeast-4": "AU-VIC",
+	"ap-southeast-5": "MY-WM",
+	"ap-southeast-6": "NZ",
+	"ap-southeast-7": "TH",
+	"ca-west-1":      "CA-AB",
+	"eu-central-2":   "CH",
+	"eu-south-2":     "ES",
+	"il-central-1":   "IL",
+	"me-central-1":   "AE",
+	"mx-central-1":   "MX",
+}
+
+// ElectricityMaps is a CarbonIntensityProvider fetching historical hourly
+// carbon intensity of the grid from the Electricity Maps API. Results are
+// cached, so that each time range is only requested once per zone.
+type ElectricityMaps struct {
+	// BaseURL is the URL of the API, ElectricityMapsURL by default.
+	BaseURL string
+
+	// Token is the API key sent with each request.
+	Token string
+
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+
+	cache hourlyCache
+}
+
+// NewElectricityMaps returns a client for the Electricity Maps API using
+// the given API key.
+func NewElectricityMaps(token string) *ElectricityMaps {
+	return &ElectricityMaps{
+		BaseURL: ElectricityMapsURL,
+		Token:   token,
+		Client:  http.DefaultClient,
+		cache:   hourlyCache{rangeLength: electricityMapsRange},
+	}
+}
+
+// ElectricityMapsZone returns the Electricity Maps zone of an AWS region.
+func ElectricityMapsZone(regionCode string) (string, error) {
+	zone, exists := electricityMapsZones[ParentRegion(regionCode)]
+	if !exists {
+		return "", unknownRegion(regionCode)
+	} else {
+		return zone, nil
+	}
+}
+
+// HourlyCarbonIntensity returns the carbon intensity of the grid of an AWS
+// region during the hour containing t, in grams of CO2 per kilowatt hour.
+func (e *ElectricityMaps) HourlyCarbonIntensity(ctx context.Context, regionCode string, t time.Time) (float64, error) {
+	zone, err := ElectricityMapsZone(regionCode)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.cache.get(ctx, zone, t, e.fetch)
+}
+
+// fetch requests the hourly carbon intensity of a zone in a time range.
+func (e *ElectricityMaps) fetch(ctx context.Context, zone string, start, end time.Time) (map[time.Time]float64, error) {
+	query := url.Values{}
+	query.Set("zone", zone)
+	query.Set("start", start.Format(electricityMapsTimeLayout))
+	query.Set("end", end.Format(electricityMapsTimeLayout))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"/v3/carbon-intensity/past-range?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("auth-token", e.Token)
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not request carbon intensity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not request carbon intensity for zone %s: %s", zone, resp.Status)
+	}
+
+	var body struct {
+		Data []struct {
+			CarbonIntensity float64   `json:"carbonIntensity"`
+			Datetime        time.Time `json:"datetime"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode carbon intensity response: %w", err)
+	}
+
+	values := make(map[time.Time]float64)
+	for _, entry := range body.Data {
+		values[entry.Datetime.UTC().Truncate(time.Hour)] = entry.CarbonIntensity
+	}
+
+	return values, nil
+}
+
+// CarbonIntensityForecast returns the forecast carbon intensity of the grid
+// of an AWS region per hour, for up to the given number of hours.
+func (e *ElectricityMaps) CarbonIntensityForecast(ctx context.Context, regionCode string, hours int) ([]HourlyIntensity, error) {
+	zone, err := ElectricityMapsZone(regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("zone", zone)
+	query.Set("horizonHours", strconv.Itoa(hours))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"/v3/carbon-intensity/forecast?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("auth-token", e.Token)
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not request carbon intensity forecast: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not request carbon intensity forecast for zone %s: %s", zone, resp.Status)
+	}
+
+	var body struct {
+		Forecast []struct {
+			CarbonIntensity float64   `json:"carbonIntensity"`
+			Datetime        time.Time `json:"datetime"`
+		} `json:"forecast"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode carbon intensity forecast response: %w", err)
+	}
+
+	values := make(map[time.Time]float64)
+	for _, entry := range body.Forecast {
+		values[entry.Datetime.UTC().Truncate(time.Hour)] = entry.CarbonIntensity
+	}
+
+	forecast := sortedHours(values)
+	if len(forecast) > hours {
+		forecast = forecast[:hours]
+	}
+	return forecast, nil
+}
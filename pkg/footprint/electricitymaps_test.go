@@ -0,0 +1,108 @@
+package footprint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestElectricityMaps_HourlyCarbonIntensity(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("auth-token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v3/carbon-intensity/past-range" || r.URL.Query().Get("zone") != "DE" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"zone":"DE","data":[
+			{"zone":"DE","carbonIntensity":302,"datetime":"2022-08-01T00:00:00.000Z"},
+			{"zone":"DE","carbonIntensity":287,"datetime":"2022-08-01T01:00:00.000Z"}
+		]}`)
+	}))
+	defer server.Close()
+
+	e := NewElectricityMaps("secret")
+	e.BaseURL = server.URL
+
+	tests := []struct {
+		name       string
+		regionCode string
+		t          time.Time
+		want       float64
+		wantErr    bool
+	}{
+		{name: "first hour", regionCode: "eu-central-1", t: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), want: 302},
+		{name: "within second hour", regionCode: "eu-central-1", t: time.Date(2022, 8, 1, 1, 30, 0, 0, time.UTC), want: 287},
+		{name: "missing hour", regionCode: "eu-central-1", t: time.Date(2022, 8, 1, 2, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "unknown region", regionCode: "unknown", t: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "zone not served", regionCode: "eu-west-1", t: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.HourlyCarbonIntensity(context.Background(), tt.regionCode, tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HourlyCarbonIntensity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HourlyCarbonIntensity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// One request for eu-central-1, one for eu-west-1.
+	if requests != 2 {
+		t.Errorf("HourlyCarbonIntensity() made %d requests, want 2", requests)
+	}
+}
+
+func Test_electricityMapsZonesComplete(t *testing.T) {
+	c := testCalculator(t)
+	for regionCode := range c.awsRegions {
+		if _, err := ElectricityMapsZone(regionCode); err != nil {
+			t.Errorf("ElectricityMapsZone(%q) error = %v", regionCode, err)
+		}
+	}
+}
+
+func TestElectricityMaps_CarbonIntensityForecast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/carbon-intensity/forecast" || r.URL.Query().Get("zone") != "DE" || r.URL.Query().Get("horizonHours") != "2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"zone":"DE","forecast":[
+			{"carbonIntensity":287,"datetime":"2022-08-01T01:00:00.000Z"},
+			{"carbonIntensity":302,"datetime":"2022-08-01T00:00:00.000Z"},
+			{"carbonIntensity":250,"datetime":"2022-08-01T02:00:00.000Z"}
+		],"updatedAt":"2022-08-01T00:00:00.000Z"}`)
+	}))
+	defer server.Close()
+
+	e := NewElectricityMaps("secret")
+	e.BaseURL = server.URL
+
+	got, err := e.CarbonIntensityForecast(context.Background(), "eu-central-1", 2)
+	if err != nil {
+		t.Fatalf("CarbonIntensityForecast() error = %v", err)
+	}
+	want := []HourlyIntensity{
+		{Hour: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), CarbonIntensity: 302},
+		{Hour: time.Date(2022, 8, 1, 1, 0, 0, 0, time.UTC), CarbonIntensity: 287},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CarbonIntensityForecast() = %v, want %v", got, want)
+	}
+
+	if _, err := e.CarbonIntensityForecast(context.Background(), "eu-west-1", 2); err == nil {
+		t.Error("CarbonIntensityForecast() error = nil, want error for zone not served")
+	}
+}
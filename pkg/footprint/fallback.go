@@ -0,0 +1,260 @@
+package footprint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Methods of estimating the footprint of EC2 instance types missing from
+// the dataset, e.g. because they were released after the data snapshot.
+const (
+	// FallbackNone doesn't estimate unknown instance types.
+	FallbackNone = "none"
+
+	// FallbackFamily scales the data of the nearest size in the same
+	// instance family by the ratio of the sizes, e.g. m5.24xlarge by 4/3
+	// for m5.32xlarge. If the family is unknown, FallbackSpec is used.
+	FallbackFamily = "family"
+
+	// FallbackSpec uses a generic power model per vCPU and per gigabyte of
+	// memory, with the specs of the instance type taken from an embedded
+	// table. If the instance type is missing from the table, FallbackVCPU
+	// is used.
+	FallbackSpec = "spec"
+
+	// FallbackVCPU uses the average power consumption and manufacturing
+	// emissions per vCPU of all instance types in the dataset, multiplied
+	// by the number of vCPUs of the instance type, derived from its size.
+	FallbackVCPU = "vcpu"
+)
+
+// metalSize is the size of bare metal instance types, e.g. m5.metal.
+const metalSize = "metal"
+
+// FallbackMethods lists the supported methods of estimating unknown
+// instance types.
+var FallbackMethods = []string{FallbackNone, FallbackFamily, FallbackSpec, FallbackVCPU}
+
+// IsFallbackMethod returns whether method is one of FallbackMethods.
+func IsFallbackMethod(method string) bool {
+	for _, m := range FallbackMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimatedInstance returns footprint data for an EC2 instance type missing
+// from the dataset, estimated with the given fallback method, along with a
+// description of the estimate, e.g. "scaled from m6i.large".
+func (c *Calculator) EstimatedInstance(instanceType, method string) (EC2Instance, string, error) {
+	switch method {
+	case FallbackNone:
+		return EC2Instance{}, "", unknownInstanceType(instanceType)
+	case FallbackFamily, FallbackSpec, FallbackVCPU:
+	default:
+		return EC2Instance{}, "", fmt.Errorf("unknown fallback method %q", method)
+	}
+
+	family, size, found := splitInstanceType(instanceType)
+	if !found {
+		return EC2Instance{}, "", unknownInstanceType(instanceType)
+	}
+	units, unitsKnown := sizeUnits(size)
+
+	if method == FallbackFamily && unitsKnown {
+		if similar, similarUnits, ok := c.nearestSize(family, units); ok {
+			factor := units / similarUnits
+			return c.ec2Instances[similar].scale(factor), fmt.Sprintf("scaled from %s", similar), nil
+		}
+	}
+
+	// A metal instance takes up a whole host, like the largest size of its
+	// family.
+	if method == FallbackFamily && size == metalSize {
+		if largest, _, ok := c.nearestSize(family, math.Inf(1)); ok {
+			return c.ec2Instances[largest], fmt.Sprintf("largest size %s", largest), nil
+		}
+	}
+
+	if method != FallbackVCPU {
+		if spec, exists := c.awsInstanceSpecs[instanceType]; exists {
+			return c.specInstance(spec), fmt.Sprintf("power model for %g vCPUs and %g GB memory", spec.VCPUs, spec.MemoryGB), nil
+		}
+	}
+
+	if !unitsKnown {
+		return EC2Instance{}, "", fmt.Errorf("%w: unknown size %q", unknownInstanceType(instanceType), size)
+	}
+	vcpus := math.Max(units/2, 1)
+	return c.ec2AveragePerVCPU.scale(vcpus), fmt.Sprintf("average of %g vCPUs", vcpus), nil
+}
+
+// AWSEstimatedAtUtilization returns the footprint of an EC2 instance like
+// AWSAtUtilization. Instance types missing from the dataset are estimated
+// with the given fallback method, and the description of the estimate is
+// returned. The description is empty for instance types in the dataset.
+func (c *Calculator) AWSEstimatedAtUtilization(regionCode, instanceType, method string, utilization float64, duration time.Duration) (Result, string, error) {
+	if _, exists := c.ec2Instances[instanceType]; exists {
+		result, err := c.AWSAtUtilization(regionCode, instanceType, utilization, duration)
+		return result, "", err
+	}
+	if utilization < 0 || utilization > 100 {
+		return Result{}, "", fmt.Errorf("utilization must be between 0 and 100 percent")
+	}
+
+	instance, description, err := c.EstimatedInstance(instanceType, method)
+	if err != nil {
+		return Result{}, "", err
+	}
+
+	result, err := c.awsInstanceAtUtilization(regionCode, instance, utilization, duration)
+	if err != nil {
+		return Result{}, "", err
+	}
+	return result, description, nil
+}
+
+// AWSRDSEstimatedAtUtilization returns the footprint of an RDS database
+// instance like AWSRDSAtUtilization, estimating instance types missing from
+// the dataset like AWSEstimatedAtUtilization.
+func (c *Calculator) AWSRDSEstimatedAtUtilization(regionCode, dbInstanceType, method string, utilization float64, duration time.Duration, multiAZ bool) (Result, string, error) {
+	if _, err := c.RDSInstanceType(dbInstanceType); err == nil {
+		result, err := c.AWSRDSAtUtilization(regionCode, dbInstanceType, utilization, duration, multiAZ)
+		return result, "", err
+	}
+	ec2InstanceType, found := strings.CutPrefix(dbInstanceType, rdsInstanceTypePrefix)
+	if !found {
+		return Result{}, "", fmt.Errorf("not an RDS instance type")
+	}
+
+	result, description, err := c.AWSEstimatedAtUtilization(regionCode, ec2InstanceType, method, utilization, duration)
+	if err != nil {
+		return Result{}, "", err
+	}
+
+	if multiAZ {
+		result = result.scale(rdsMultiAZReplicationFactor)
+	}
+
+	return result, description, nil
+}
+
+// splitInstanceType splits an instance type like m5.xlarge into family and
+// size.
+func splitInstanceType(instanceType string) (family, size string, found bool) {
+	i := strings.LastIndex(instanceType, ".")
+	if i < 0 {
+		return "", "", false
+	}
+	return instanceType[:i], instanceType[i+1:], true
+}
+
+// sizeUnits returns the relative size of an instance size name, in units
+// of a small instance. Within a family, resources double with each step
+// from nano to xlarge, and an Nxlarge instance is N times an xlarge one.
+// Metal sizes are only known in the Nxlarge variant, e.g. metal-24xl.
+func sizeUnits(size string) (float64, bool) {
+	switch size {
+	case "nano":
+		return 0.25, true
+	case "micro":
+		return 0.5, true
+	case "small":
+		return 1, true
+	case "medium":
+		return 2, true
+	case "large":
+		return 4, true
+	case "xlarge":
+		return 8, true
+	}
+
+	size = strings.TrimPrefix(size, "metal-")
+	n, found := strings.CutSuffix(size, "xlarge")
+	if !found {
+		n, found = strings.CutSuffix(size, "xl")
+	}
+	if !found {
+		return 0, false
+	}
+	factor, err := strconv.ParseFloat(n, 64)
+	if err != nil || factor <= 0 {
+		return 0, false
+	}
+	return 8 * factor, true
+}
+
+// nearestSize returns the instance type of the dataset in the given family
+// whose size is closest to units, and its size. On ties, the larger size
+// is used.
+func (c *Calculator) nearestSize(family string, units float64) (string, float64, bool) {
+	var candidates []string
+	for instanceType := range c.ec2Instances {
+		if f, _, _ := splitInstanceType(instanceType); f == family {
+			candidates = append(candidates, instanceType)
+		}
+	}
+	sort.Strings(candidates)
+
+	var best string
+	var bestUnits, bestDistance float64
+	for _, instanceType := range candidates {
+		_, size, _ := splitInstanceType(instanceType)
+		u, ok := sizeUnits(size)
+		if !ok {
+			continue
+		}
+		distance := math.Abs(math.Log(u / units))
+		if best == "" || distance < bestDistance || (distance == bestDistance && u > bestUnits) {
+			best, bestUnits, bestDistance = instanceType, u, distance
+		}
+	}
+	return best, bestUnits, best != ""
+}
+
+// averagePerVCPU returns the average power consumption and manufacturing
+// emissions per vCPU of the given instance types.
+func averagePerVCPU(instances map[string]EC2Instance) EC2Instance {
+	// Instance types are summed up in a fixed order, for reproducible
+	// rounding.
+	var sum EC2Instance
+	for _, instanceType := range sortedKeys(instances) {
+		instance := instances[instanceType]
+		if instance.VCPUs <= 0 {
+			continue
+		}
+		sum.VCPUs += instance.VCPUs
+		sum.PowerAtIdle += instance.PowerAtIdle
+		sum.PowerAt10Percent += instance.PowerAt10Percent
+		sum.PowerAt50Percent += instance.PowerAt50Percent
+		sum.PowerAt100Percent += instance.PowerAt100Percent
+		sum.ManufacturingEmissionsHourly += instance.ManufacturingEmissionsHourly
+	}
+	if sum.VCPUs == 0 {
+		return EC2Instance{}
+	}
+	return sum.scale(1 / sum.VCPUs)
+}
+
+// scale returns the instance data multiplied by factor.
+func (i EC2Instance) scale(factor float64) EC2Instance {
+	return EC2Instance{
+		VCPUs:                        i.VCPUs * factor,
+		PowerAtIdle:                  i.PowerAtIdle * factor,
+		PowerAt10Percent:             i.PowerAt10Percent * factor,
+		PowerAt50Percent:             i.PowerAt50Percent * factor,
+		PowerAt100Percent:            i.PowerAt100Percent * factor,
+		ManufacturingEmissionsHourly: i.ManufacturingEmissionsHourly * factor,
+		GPUs:                         i.GPUs * factor,
+		GPUPowerAtIdle:               i.GPUPowerAtIdle * factor,
+		GPUPowerAt10Percent:          i.GPUPowerAt10Percent * factor,
+		GPUPowerAt50Percent:          i.GPUPowerAt50Percent * factor,
+		GPUPowerAt100Percent:         i.GPUPowerAt100Percent * factor,
+	}
+}
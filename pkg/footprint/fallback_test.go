@@ -0,0 +1,230 @@
+package footprint
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_sizeUnits(t *testing.T) {
+	tests := []struct {
+		size   string
+		want   float64
+		wantOK bool
+	}{
+		{size: "nano", want: 0.25, wantOK: true},
+		{size: "medium", want: 2, wantOK: true},
+		{size: "xlarge", want: 8, wantOK: true},
+		{size: "24xlarge", want: 192, wantOK: true},
+		{size: "metal-48xl", want: 384, wantOK: true},
+		{size: metalSize, want: 0, wantOK: false},
+		{size: "0xlarge", want: 0, wantOK: false},
+		{size: "huge", want: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, ok := sizeUnits(tt.size)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("sizeUnits() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_nearestSize(t *testing.T) {
+	c := testCalculator(t)
+	tests := []struct {
+		name      string
+		family    string
+		units     float64
+		want      string
+		wantUnits float64
+		wantOK    bool
+	}{
+		{name: "larger than all", family: "m5", units: 256, want: "m5.24xlarge", wantUnits: 192, wantOK: true},
+		{name: "between sizes", family: "m5", units: 80, want: "m5.12xlarge", wantUnits: 96, wantOK: true},
+		{name: "unknown family", family: "m99", units: 8, want: "", wantUnits: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotUnits, ok := c.nearestSize(tt.family, tt.units)
+			if got != tt.want || gotUnits != tt.wantUnits || ok != tt.wantOK {
+				t.Errorf("nearestSize() = %v, %v, %v, want %v, %v, %v", got, gotUnits, ok, tt.want, tt.wantUnits, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEstimatedInstance(t *testing.T) {
+	c := testCalculator(t)
+	average := c.ec2AveragePerVCPU
+
+	tests := []struct {
+		name            string
+		instanceType    string
+		method          string
+		want            EC2Instance
+		wantDescription string
+		wantErr         bool
+	}{
+		{
+			name:            "scaled from nearest size",
+			instanceType:    "m5.32xlarge",
+			method:          FallbackFamily,
+			want:            c.ec2Instances["m5.24xlarge"].scale(4.0 / 3),
+			wantDescription: "scaled from m5.24xlarge",
+		},
+		{
+			name:            "unknown family",
+			instanceType:    "m99.xlarge",
+			method:          FallbackFamily,
+			want:            average.scale(4),
+			wantDescription: "average of 4 vCPUs",
+		},
+		{
+			name:            "metal as largest size",
+			instanceType:    "m6i.metal",
+			method:          FallbackFamily,
+			want:            c.ec2Instances["m6i.32xlarge"],
+			wantDescription: "largest size m6i.32xlarge",
+		},
+		{
+			name:            "metal from spec table",
+			instanceType:    "x2idn.metal",
+			method:          FallbackFamily,
+			want:            c.specInstance(InstanceSpec{VCPUs: 128, MemoryGB: 2048}),
+			wantDescription: "power model for 128 vCPUs and 2048 GB memory",
+		},
+		{
+			name:            "new family from spec table",
+			instanceType:    "m7i.large",
+			method:          FallbackFamily,
+			want:            c.specInstance(InstanceSpec{VCPUs: 2, MemoryGB: 8}),
+			wantDescription: "power model for 2 vCPUs and 8 GB memory",
+		},
+		{
+			name:            "spec table",
+			instanceType:    "c7g.metal",
+			method:          FallbackSpec,
+			want:            c.specInstance(InstanceSpec{VCPUs: 64, MemoryGB: 128}),
+			wantDescription: "power model for 64 vCPUs and 128 GB memory",
+		},
+		{
+			name:            "missing from spec table",
+			instanceType:    "m5.32xlarge",
+			method:          FallbackSpec,
+			want:            average.scale(128),
+			wantDescription: "average of 128 vCPUs",
+		},
+		{
+			name:            "per vCPU ignores spec table",
+			instanceType:    "m7i.large",
+			method:          FallbackVCPU,
+			want:            average.scale(2),
+			wantDescription: "average of 2 vCPUs",
+		},
+		{
+			name:            "per vCPU",
+			instanceType:    "m5.32xlarge",
+			method:          FallbackVCPU,
+			want:            average.scale(128),
+			wantDescription: "average of 128 vCPUs",
+		},
+		{name: "no fallback", instanceType: "m5.32xlarge", method: FallbackNone, wantErr: true},
+		{name: "unknown size", instanceType: "m5.metal", method: FallbackVCPU, wantErr: true},
+		{name: "no size", instanceType: "unknown", method: FallbackVCPU, wantErr: true},
+		{name: "unknown method", instanceType: "m5.32xlarge", method: "guess", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, description, err := EstimatedInstance(tt.instanceType, tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EstimatedInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EstimatedInstance() = %v, want %v", got, tt.want)
+			}
+			if description != tt.wantDescription {
+				t.Errorf("EstimatedInstance() description = %q, want %q", description, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func Test_averagePerVCPU(t *testing.T) {
+	got := averagePerVCPU(testCalculator(t).ec2Instances)
+	if math.Abs(got.VCPUs-1) > 1e-9 {
+		t.Errorf("averagePerVCPU().VCPUs = %v, want 1", got.VCPUs)
+	}
+	if got.PowerAtIdle <= 0 || got.PowerAt100Percent <= got.PowerAtIdle || got.ManufacturingEmissionsHourly <= 0 {
+		t.Errorf("averagePerVCPU() = %v, want positive values increasing with load", got)
+	}
+}
+
+func TestAWSEstimatedAtUtilization(t *testing.T) {
+	known, err := AWSAtUtilization("eu-west-1", "m5.24xlarge", 50, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, description, err := AWSEstimatedAtUtilization("eu-west-1", "m5.24xlarge", FallbackFamily, 50, time.Hour)
+	if err != nil || got != known || description != "" {
+		t.Errorf("AWSEstimatedAtUtilization() for known type = %v, %q, %v, want %v, \"\", nil", got, description, err, known)
+	}
+
+	got, description, err = AWSEstimatedAtUtilization("eu-west-1", "m5.32xlarge", FallbackFamily, 50, time.Hour)
+	if err != nil {
+		t.Fatalf("AWSEstimatedAtUtilization() error = %v", err)
+	}
+	if math.Abs(got.Total()-known.Total()*4/3) > 1e-9 {
+		t.Errorf("AWSEstimatedAtUtilization() = %v, want %v", got.Total(), known.Total()*4/3)
+	}
+	if description != "scaled from m5.24xlarge" {
+		t.Errorf("AWSEstimatedAtUtilization() description = %q", description)
+	}
+
+	_, _, err = AWSEstimatedAtUtilization("unknown", "m5.32xlarge", FallbackFamily, 50, time.Hour)
+	if !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("AWSEstimatedAtUtilization() error = %v, want %v", err, ErrUnknownRegion)
+	}
+
+	_, _, err = AWSEstimatedAtUtilization("eu-west-1", "m5.32xlarge", FallbackNone, 50, time.Hour)
+	if !errors.Is(err, ErrUnknownInstanceType) {
+		t.Errorf("AWSEstimatedAtUtilization() error = %v, want %v", err, ErrUnknownInstanceType)
+	}
+}
+
+func TestAWSRDSEstimatedAtUtilization(t *testing.T) {
+	micro, err := AWSRDSAtUtilization("eu-west-1", "db.t4g.micro", 50, time.Hour, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, description, err := AWSRDSEstimatedAtUtilization("eu-west-1", "db.t4g.micro", FallbackFamily, 50, time.Hour, false)
+	if err != nil || got != micro || description != "" {
+		t.Errorf("AWSRDSEstimatedAtUtilization() for known type = %v, %q, %v, want %v, \"\", nil", got, description, err, micro)
+	}
+
+	known, err := AWSAtUtilization("eu-west-1", "m5.24xlarge", 50, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, description, err = AWSRDSEstimatedAtUtilization("eu-west-1", "db.m5.32xlarge", FallbackFamily, 50, time.Hour, true)
+	if err != nil {
+		t.Fatalf("AWSRDSEstimatedAtUtilization() error = %v", err)
+	}
+	if want := known.Total() * 4 / 3 * 2; math.Abs(got.Total()-want) > 1e-9 {
+		t.Errorf("AWSRDSEstimatedAtUtilization() = %v, want %v", got.Total(), want)
+	}
+	if description != "scaled from m5.24xlarge" {
+		t.Errorf("AWSRDSEstimatedAtUtilization() description = %q", description)
+	}
+
+	if _, _, err := AWSRDSEstimatedAtUtilization("eu-west-1", "m5.32xlarge", FallbackFamily, 50, time.Hour, false); err == nil {
+		t.Error("AWSRDSEstimatedAtUtilization() for non-RDS type returned no error")
+	}
+}
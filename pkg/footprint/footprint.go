@@ -1,12 +1,17 @@
 // Package footprint provides data and functions
 // to estimate the carbon emissions of AWS EC2
-// instance operation.
+// instance operation and other cloud usage.
+//
+// Estimates are made by a Calculator, created with NewCalculator, which holds
+// the datasets. Options replace embedded datasets, e.g. with a newer
+// snapshot. The package-level functions use a shared calculator with the
+// embedded datasets.
 //
 // Data source: https://docs.google.com/spreadsheets/d/1DqYgQnEDLQVQm5acMAhLgHLD8xXCG9BIrk-_Nv6jF3k/edit#gid=504755275
 // Data and methodology provided by Teads engineering, under the
 // Creative Commons Attribution 4.0 International License.
 //
-// Data snapshot date: 2022-08-17
+// Data snapshot date: 2022-08-17, see EC2InstancesSnapshotDate
 //
 // More background on the methodology:
 // https://medium.com/teads-engineering/building-an-aws-ec2-carbon-emissions-dataset-3f0fd76c98ac
@@ -14,11 +19,10 @@ package footprint
 
 import (
 	_ "embed"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 )
@@ -29,19 +33,72 @@ var ec2instancesCSV string
 //go:embed aws-regions.csv
 var awsRegionsCSV string
 
-// ec2instances stores data about EC2 instances, using the instance type name as key.
-var ec2instances map[string]EC2Instance
+//go:embed aws-region-locations.csv
+var awsRegionLocationsCSV string
+
+// DefaultUtilization is the CPU utilization in percent assumed for instances
+// when no other value is given.
+const DefaultUtilization = 50
+
+// DefaultAmortizationYears is the lifetime of hardware its manufacturing
+// emissions are spread over in the Teads dataset.
+const DefaultAmortizationYears = 4
+
+var (
+	// ErrUnknownInstanceType is wrapped by the errors returned for EC2
+	// instance types, GCP machine types and Azure VM sizes missing from the
+	// datasets. The error message contains the name of the instance type.
+	ErrUnknownInstanceType = errors.New("unknown instance type")
 
-// awsRegions stores data about AWS regions, using the region code as key.
-var awsRegions map[string]AWSRegion
+	// ErrUnknownRegion is wrapped by the errors returned for region codes
+	// missing from the datasets. The error message contains the region
+	// code.
+	ErrUnknownRegion = errors.New("unknown region code")
+)
+
+// unknownInstanceType returns an error wrapping ErrUnknownInstanceType for
+// the given instance type.
+func unknownInstanceType(instanceType string) error {
+	return fmt.Errorf("%w %q", ErrUnknownInstanceType, instanceType)
+}
+
+// unknownRegion returns an error wrapping ErrUnknownRegion for the given
+// region code.
+func unknownRegion(regionCode string) error {
+	return fmt.Errorf("%w %q", ErrUnknownRegion, regionCode)
+}
 
 type EC2Instance struct {
-	// WattAt50Percent is the instance power consumtion in Watt at 50% load
+	// VCPUs is the number of virtual CPUs of the instance.
+	VCPUs float64
+
+	// PowerAtIdle is the instance power consumption in Watt when idle
+	PowerAtIdle float64
+
+	// PowerAt10Percent is the instance power consumption in Watt at 10% load
+	PowerAt10Percent float64
+
+	// PowerAt50Percent is the instance power consumption in Watt at 50% load
 	PowerAt50Percent float64
 
+	// PowerAt100Percent is the instance power consumption in Watt at full load
+	PowerAt100Percent float64
+
 	// ManufacturingEmissionsHourly is the emissions created during production of the
 	// hardware, calculated as contribution to the hourly footprint, in metric grams CO2e.
 	ManufacturingEmissionsHourly float64
+
+	// GPUs is the number of GPUs of the instance, zero for instance types
+	// without GPU.
+	GPUs float64
+
+	// GPUPowerAtIdle to GPUPowerAt100Percent are the power consumption of
+	// the GPUs in Watt when idle and at 10%, 50% and full load. They are
+	// part of the instance power consumption above.
+	GPUPowerAtIdle       float64
+	GPUPowerAt10Percent  float64
+	GPUPowerAt50Percent  float64
+	GPUPowerAt100Percent float64
 }
 
 type AWSRegion struct {
@@ -52,121 +109,249 @@ type AWSRegion struct {
 	// PUE is the power usage effectiveness coefficient of the data center.
 	// See https://en.wikipedia.org/wiki/Power_usage_effectiveness for details.
 	PUE float64
-}
 
-func init() {
-	err := readEC2Instances()
-	if err != nil {
-		log.Fatal(err)
-	}
+	// WUE is the water usage effectiveness of the data center, in liters
+	// of water consumed per kilowatt hour of IT energy.
+	WUE float64
 
-	err = readAWSRegions()
-	if err != nil {
-		log.Fatal(err)
-	}
+	// MarketCarbonIntensity is the market-based carbon intensity, taking
+	// into account the renewable energy purchased by AWS for the region.
+	// Unit: metric gram per kilowatt hour.
+	MarketCarbonIntensity float64
+
+	// EstimatedFrom describes what the data of a region missing from the
+	// dataset was estimated from, e.g. "national grid of Israel". It is
+	// empty for regions in the dataset.
+	EstimatedFrom string
+}
+
+// Location is a geographic position in decimal degrees.
+type Location struct {
+	Latitude  float64
+	Longitude float64
 }
 
-func readEC2Instances() error {
-	reader := csv.NewReader(strings.NewReader(ec2instancesCSV))
-	lineCount := 0
-	ec2instances = make(map[string]EC2Instance)
+// Columns of the Teads EC2 instance dataset.
+const (
+	ec2ColumnInstanceType  = "Instance type"
+	ec2ColumnVCPUs         = "Instance vCPU"
+	ec2ColumnGPUs          = "Instance Number of GPU"
+	ec2ColumnManufacturing = "Instance Hourly Manufacturing Emissions (gCO₂eq)"
+)
+
+// ec2PowerColumns and ec2GPUPowerColumns are the columns of the Teads EC2
+// instance dataset with the power of instances and of their GPUs at idle,
+// 10%, 50% and 100% load.
+var (
+	ec2PowerColumns    = [4]string{"Instance @ Idle", "Instance @ 10%", "Instance @ 50%", "Instance @ 100%"}
+	ec2GPUPowerColumns = [4]string{"GPUWatt @ Idle", "GPUWatt @ 10%", "GPUWatt @ 50%", "GPUWatt @ 100%"}
+)
 
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+// Columns of the region datasets. Only the AWS dataset has the market-based
+// carbon intensity, and the AWS region locations the latitude and
+// longitude.
+const (
+	regionColumnRegion                = "Region"
+	regionColumnCarbonIntensity       = "CO2e (metric gram/kWh)"
+	regionColumnPUE                   = "PUE"
+	regionColumnMarketCarbonIntensity = "Market-based CO2e (metric gram/kWh)"
+	regionColumnLatitude              = "Latitude"
+	regionColumnLongitude             = "Longitude"
+)
+
+// ParseEC2Instances reads an EC2 instance dataset with the columns of the
+// Teads dataset from r, and returns the data by instance type. Columns are
+// located by name, so that their order does not matter.
+func ParseEC2Instances(r io.Reader) (map[string]EC2Instance, error) {
+	instances := make(map[string]EC2Instance)
+	columns := append([]string{ec2ColumnInstanceType, ec2ColumnVCPUs, ec2ColumnGPUs, ec2ColumnManufacturing}, ec2PowerColumns[:]...)
+	columns = append(columns, ec2GPUPowerColumns[:]...)
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		var power [4]float64
+		for i, column := range ec2PowerColumns {
+			var err error
+			if power[i], err = c.float(record, column); err != nil {
+				return err
+			}
 		}
+
+		vcpus, err := c.float(record, ec2ColumnVCPUs)
 		if err != nil {
 			return err
 		}
 
-		// Skip first row containing column headers.
-		lineCount++
-		if lineCount == 1 {
-			continue
+		manuf, err := c.float(record, ec2ColumnManufacturing)
+		if err != nil {
+			return err
 		}
 
-		// Process record.
-		// We expect the first column to contain the instance type,
-		// 30th column to contain power at 50% load,
-		// 37th column to contain manufacturing emissions.
-		power, err := strconv.ParseFloat(record[29], 64)
-		if err != nil {
-			return fmt.Errorf("error parsing %q as float: %s", record[29], err)
+		var gpus float64
+		if c.value(record, ec2ColumnGPUs) != "N/A" {
+			if gpus, err = c.float(record, ec2ColumnGPUs); err != nil {
+				return err
+			}
 		}
 
-		manuf, err := strconv.ParseFloat(record[36], 64)
-		if err != nil {
-			return fmt.Errorf("error parsing %q as float: %s", record[36], err)
+		var gpuPower [4]float64
+		for i, column := range ec2GPUPowerColumns {
+			if gpuPower[i], err = c.float(record, column); err != nil {
+				return err
+			}
 		}
 
-		ec2instances[record[0]] = EC2Instance{
-			PowerAt50Percent:             power,
+		instances[c.value(record, ec2ColumnInstanceType)] = EC2Instance{
+			VCPUs:                        vcpus,
+			PowerAtIdle:                  power[0],
+			PowerAt10Percent:             power[1],
+			PowerAt50Percent:             power[2],
+			PowerAt100Percent:            power[3],
 			ManufacturingEmissionsHourly: manuf,
+			GPUs:                         gpus,
+			GPUPowerAtIdle:               gpuPower[0],
+			GPUPowerAt10Percent:          gpuPower[1],
+			GPUPowerAt50Percent:          gpuPower[2],
+			GPUPowerAt100Percent:         gpuPower[3],
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return instances, nil
 }
 
-func readAWSRegions() error {
-	reader := csv.NewReader(strings.NewReader(awsRegionsCSV))
-	lineCount := 0
-	awsRegions = make(map[string]AWSRegion)
+// ParseAWSRegions reads an AWS region dataset with the columns of the
+// embedded aws-regions.csv from r, and returns the data by region code.
+// Columns are located by name, so that their order does not matter.
+func ParseAWSRegions(r io.Reader) (map[string]AWSRegion, error) {
+	regions := make(map[string]AWSRegion)
+	columns := []string{regionColumnRegion, regionColumnCarbonIntensity, regionColumnPUE, regionColumnMarketCarbonIntensity}
 
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		carbonIntensity, err := c.float(record, regionColumnCarbonIntensity)
+		if err != nil {
+			return err
 		}
+		pue, err := c.float(record, regionColumnPUE)
+		if err != nil {
+			return err
+		}
+		marketCarbonIntensity, err := c.float(record, regionColumnMarketCarbonIntensity)
 		if err != nil {
 			return err
 		}
 
-		// Skip first row containing column headers.
-		lineCount++
-		if lineCount == 1 {
-			continue
+		regions[c.value(record, regionColumnRegion)] = AWSRegion{
+			CarbonIntensity:       carbonIntensity,
+			PUE:                   pue,
+			MarketCarbonIntensity: marketCarbonIntensity,
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+func parseAWSRegionLocations(r io.Reader) (map[string]Location, error) {
+	awsRegionLocations := make(map[string]Location)
+	columns := []string{regionColumnRegion, regionColumnLatitude, regionColumnLongitude}
 
-		// Process record.
-		// We expect the first column to contain the region code,
-		// 5th column to contain carbon intensity,
-		// 7th column to contain PUE.
-		carbonIntensity, err := strconv.ParseFloat(record[4], 64)
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		latitude, err := c.float(record, regionColumnLatitude)
 		if err != nil {
-			return fmt.Errorf("error parsing carbon intensity %q as float: %s", record[4], err)
+			return err
 		}
-		pue, err := strconv.ParseFloat(record[6], 64)
+		longitude, err := c.float(record, regionColumnLongitude)
 		if err != nil {
-			return fmt.Errorf("error parsing PUE %q as float: %s", record[6], err)
+			return err
 		}
 
-		awsRegions[record[0]] = AWSRegion{
-			CarbonIntensity: carbonIntensity,
-			PUE:             pue,
+		awsRegionLocations[c.value(record, regionColumnRegion)] = Location{
+			Latitude:  latitude,
+			Longitude: longitude,
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return awsRegionLocations, nil
 }
 
 // PowerAt50Percent returns the power consumption at 50% load for an EC2 instance type, in watt.
-func PowerAt50Percent(ec2InstanceType string) (float64, error) {
-	val, exists := ec2instances[ec2InstanceType]
+func (c *Calculator) PowerAt50Percent(ec2InstanceType string) (float64, error) {
+	val, exists := c.ec2Instances[ec2InstanceType]
 	if !exists {
-		return 0, fmt.Errorf("unknown instance type")
+		return 0, unknownInstanceType(ec2InstanceType)
 	} else {
 		return val.PowerAt50Percent, nil
 	}
 }
 
+// PowerAtUtilization returns the power consumption of an EC2 instance type
+// at the given CPU utilization in percent, in watt. The power is interpolated
+// linearly between the load points of the dataset.
+func (c *Calculator) PowerAtUtilization(ec2InstanceType string, utilization float64) (float64, error) {
+	if utilization < 0 || utilization > 100 {
+		return 0, fmt.Errorf("utilization must be between 0 and 100 percent")
+	}
+
+	val, exists := c.ec2Instances[ec2InstanceType]
+	if !exists {
+		return 0, unknownInstanceType(ec2InstanceType)
+	}
+
+	return val.powerAt(utilization), nil
+}
+
+// GPUPowerAtUtilization returns the power consumption of the GPUs of an EC2
+// instance type at the given utilization in percent, in watt, interpolated
+// like PowerAtUtilization. It is zero for instance types without GPU. The GPU
+// power is included in the power of the instance, as the dataset assumes the
+// GPUs to be as busy as the CPUs.
+func (c *Calculator) GPUPowerAtUtilization(ec2InstanceType string, utilization float64) (float64, error) {
+	if utilization < 0 || utilization > 100 {
+		return 0, fmt.Errorf("utilization must be between 0 and 100 percent")
+	}
+
+	val, exists := c.ec2Instances[ec2InstanceType]
+	if !exists {
+		return 0, unknownInstanceType(ec2InstanceType)
+	}
+
+	return interpolatePower(utilization, [4]float64{val.GPUPowerAtIdle, val.GPUPowerAt10Percent, val.GPUPowerAt50Percent, val.GPUPowerAt100Percent}), nil
+}
+
+// powerAt returns the power consumption of the instance at the given CPU
+// utilization in percent, in watt.
+func (i EC2Instance) powerAt(utilization float64) float64 {
+	return interpolatePower(utilization, [4]float64{i.PowerAtIdle, i.PowerAt10Percent, i.PowerAt50Percent, i.PowerAt100Percent})
+}
+
+// interpolatePower returns the power at the given utilization in percent,
+// interpolated linearly between the powers at idle, 10%, 50% and 100% load,
+// the load points of the SPECpower measurements the Teads dataset is
+// derived from.
+func interpolatePower(utilization float64, powers [4]float64) float64 {
+	loads := []float64{0, 10, 50, 100}
+	n := 1
+	for n < len(loads)-1 && utilization > loads[n] {
+		n++
+	}
+	share := (utilization - loads[n-1]) / (loads[n] - loads[n-1])
+
+	return powers[n-1] + share*(powers[n]-powers[n-1])
+}
+
 // ManufacturingEmissions returns manufacturing emissions for a machine, as an hourly
 // contribution to emissions in grams.
-func ManufacturingEmissions(ec2InstanceType string) (float64, error) {
-	val, exists := ec2instances[ec2InstanceType]
+func (c *Calculator) ManufacturingEmissions(ec2InstanceType string) (float64, error) {
+	val, exists := c.ec2Instances[ec2InstanceType]
 	if !exists {
-		return 0, fmt.Errorf("unknown instance type")
+		return 0, unknownInstanceType(ec2InstanceType)
 	} else {
 		return val.ManufacturingEmissionsHourly, nil
 	}
@@ -175,53 +360,179 @@ func ManufacturingEmissions(ec2InstanceType string) (float64, error) {
 // CarbonIntensity returns the carbon intensity for an AWS region.
 // The return value is the number of grams of CO2 emitted while producing one
 // kilowatt hour of electricity for the data center.
-func CarbonIntensity(regionCode string) (float64, error) {
-	val, exists := awsRegions[regionCode]
+func (c *Calculator) CarbonIntensity(regionCode string) (float64, error) {
+	val, exists := c.awsRegion(regionCode)
 	if !exists {
-		return 0, fmt.Errorf("unknown AWS region code")
+		return 0, unknownRegion(regionCode)
 	} else {
 		return val.CarbonIntensity, nil
 	}
 }
 
+// MarketCarbonIntensity returns the market-based carbon intensity for an AWS
+// region, in grams of CO2 per kilowatt hour. For regions where AWS matches the
+// consumption with renewable energy purchases, this is zero. For all other
+// regions, it equals the location-based carbon intensity.
+func (c *Calculator) MarketCarbonIntensity(regionCode string) (float64, error) {
+	val, exists := c.awsRegion(regionCode)
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	} else {
+		return val.MarketCarbonIntensity, nil
+	}
+}
+
+// AWSMarketBased returns a footprint of usage in an AWS region with the
+// operational emissions based on the market-based instead of the
+// location-based carbon intensity.
+func (c *Calculator) AWSMarketBased(regionCode string, result Result) (Result, error) {
+	ci, err := c.MarketCarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result.WithCarbonIntensity(ci), nil
+}
+
 // PUE returns the power usage effectiveness coefficient for an AWS region.
 // See https://en.wikipedia.org/wiki/Power_usage_effectiveness for details.
-func PUE(regionCode string) (float64, error) {
-	val, exists := awsRegions[regionCode]
+func (c *Calculator) PUE(regionCode string) (float64, error) {
+	val, exists := c.awsRegion(regionCode)
 	if !exists {
-		return 0, fmt.Errorf("unknown AWS region code")
+		return 0, unknownRegion(regionCode)
 	} else {
 		return val.PUE, nil
 	}
 }
 
-// AWS returns the footprint in gram CO2 equivalents
-func AWS(regionCode, instanceType string, duration time.Duration) (float64, error) {
-	pue, err := PUE(regionCode)
-	if err != nil {
-		return 0, err
+// RegionLocation returns the approximate geographic location of an AWS region,
+// which is the location of the city the region is named after or located near.
+func (c *Calculator) RegionLocation(regionCode string) (Location, error) {
+	val, exists := c.awsRegionLocations[ParentRegion(regionCode)]
+	if !exists {
+		return Location{}, unknownRegion(regionCode)
+	} else {
+		return val, nil
+	}
+}
+
+// awsRegion returns the data of an AWS region, of the parent region of a
+// Local Zone or Wavelength Zone, or of an Outpost added with WithOutpost.
+func (c *Calculator) awsRegion(regionCode string) (AWSRegion, bool) {
+	if val, exists := c.outposts[regionCode]; exists {
+		return val, true
+	}
+	val, exists := c.awsRegions[ParentRegion(regionCode)]
+	return val, exists
+}
+
+// Regions returns the sorted codes of the AWS regions in the dataset.
+func (c *Calculator) Regions() []string {
+	return sortedKeys(c.awsRegions)
+}
+
+// Region returns the data of an AWS region. Local Zones and Wavelength Zones
+// have the data of their parent region, see ParentRegion, and Outposts the
+// data given to WithOutpost.
+func (c *Calculator) Region(regionCode string) (AWSRegion, error) {
+	val, exists := c.awsRegion(regionCode)
+	if !exists {
+		return AWSRegion{}, unknownRegion(regionCode)
 	}
+	return val, nil
+}
+
+// InstanceTypes returns the sorted names of the EC2 instance types in the
+// dataset. Instance types missing from it can still be estimated, see
+// EstimatedInstance.
+func (c *Calculator) InstanceTypes() []string {
+	return sortedKeys(c.ec2Instances)
+}
+
+// Instance returns the data of an EC2 instance type in the dataset.
+func (c *Calculator) Instance(instanceType string) (EC2Instance, error) {
+	val, exists := c.ec2Instances[instanceType]
+	if !exists {
+		return EC2Instance{}, unknownInstanceType(instanceType)
+	}
+	return val, nil
+}
+
+// VCPUs returns the number of vCPUs of an EC2 or RDS instance type. Instance
+// types missing from the dataset are looked up in the instance specs, and
+// RDS instance types as their EC2 equivalent.
+func (c *Calculator) VCPUs(instanceType string) (float64, error) {
+	if val, exists := c.ec2Instances[instanceType]; exists {
+		return val.VCPUs, nil
+	}
+	if spec, exists := c.awsInstanceSpecs[instanceType]; exists {
+		return spec.VCPUs, nil
+	}
+	if ec2InstanceType, found := strings.CutPrefix(instanceType, rdsInstanceTypePrefix); found {
+		vcpus, err := c.VCPUs(ec2InstanceType)
+		if err != nil {
+			return 0, unknownInstanceType(instanceType)
+		}
+		return vcpus, nil
+	}
+	return 0, unknownInstanceType(instanceType)
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AWS returns the footprint of an EC2 instance, assuming a CPU utilization of
+// DefaultUtilization.
+func (c *Calculator) AWS(regionCode, instanceType string, duration time.Duration) (Result, error) {
+	return c.AWSAtUtilization(regionCode, instanceType, DefaultUtilization, duration)
+}
+
+// AWSAtUtilization returns the footprint of an EC2 instance running at the
+// given CPU utilization in percent.
+func (c *Calculator) AWSAtUtilization(regionCode, instanceType string, utilization float64, duration time.Duration) (Result, error) {
+	if utilization < 0 || utilization > 100 {
+		return Result{}, fmt.Errorf("utilization must be between 0 and 100 percent")
+	}
+
+	instance, exists := c.ec2Instances[instanceType]
+	if !exists {
+		return Result{}, unknownInstanceType(instanceType)
+	}
+
+	return c.awsInstanceAtUtilization(regionCode, instance, utilization, duration)
+}
 
-	ci, err := CarbonIntensity(regionCode)
+// awsInstanceAtUtilization returns the footprint of an EC2 instance with the
+// given data, running at the given CPU utilization in percent.
+func (c *Calculator) awsInstanceAtUtilization(regionCode string, instance EC2Instance, utilization float64, duration time.Duration) (Result, error) {
+	pue, err := c.PUE(regionCode)
 	if err != nil {
-		return 0, err
+		return Result{}, err
 	}
 
-	power, err := PowerAt50Percent(instanceType)
+	wue, err := c.WUE(regionCode)
 	if err != nil {
-		return 0, err
+		return Result{}, err
 	}
 
-	manufacturing, err := ManufacturingEmissions(instanceType)
+	ci, err := c.CarbonIntensity(regionCode)
 	if err != nil {
-		return 0, err
+		return Result{}, err
 	}
 
-	powerKiloWatt := power / 1000.0
+	powerKiloWatt := instance.powerAt(utilization) / 1000.0
 
 	hours := float64(duration.Hours())
 
-	//log.Printf("AWS(%s, %s, %s): pue=%v ci=%v power=%v manufacturing=%v hours=%v ", regionCode, instanceType, duration, pue, ci, power, manufacturing, hours)
+	result := operationalResult(powerKiloWatt*hours, pue, wue, ci)
+	result.EmbodiedGrams = instance.ManufacturingEmissionsHourly * hours
 
-	return ((powerKiloWatt * pue * ci) + manufacturing) * hours, nil
+	return result, nil
 }
@@ -2,15 +2,16 @@ package footprint
 
 import (
 	_ "embed"
+	"errors"
+	"math"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
 
-func Test_readEC2Instances(t *testing.T) {
-	err := readEC2Instances()
-	if err != nil {
-		t.Errorf("readEC2Instances() error = %v", err)
-	}
+func TestParseEC2Instances(t *testing.T) {
+	c := testCalculator(t)
 
 	tests := []struct {
 		instanceType string
@@ -19,45 +20,51 @@ func Test_readEC2Instances(t *testing.T) {
 		{
 			instanceType: "m5d.16xlarge",
 			value: EC2Instance{
+				VCPUs:                        64,
+				PowerAtIdle:                  141.1,
+				PowerAt10Percent:             223.3,
 				PowerAt50Percent:             451.9,
+				PowerAt100Percent:            638.5,
 				ManufacturingEmissionsHourly: 38.8,
 			},
 		},
 		{
 			instanceType: "t2.micro",
 			value: EC2Instance{
+				VCPUs:                        1,
+				PowerAtIdle:                  1.8,
+				PowerAt10Percent:             3.0,
 				PowerAt50Percent:             4.9,
+				PowerAt100Percent:            6.4,
 				ManufacturingEmissionsHourly: 0.9,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.instanceType, func(t *testing.T) {
-			if ec2instances[tt.instanceType] != tt.value {
-				t.Errorf("readEC2Instances() instance type %s - want value %v, got value %v", tt.instanceType, tt.value, ec2instances[tt.instanceType])
+			if c.ec2Instances[tt.instanceType] != tt.value {
+				t.Errorf("ParseEC2Instances() instance type %s - want value %v, got value %v", tt.instanceType, tt.value, c.ec2Instances[tt.instanceType])
 			}
 		})
 	}
 }
 
-func Test_readAWSRegions(t *testing.T) {
-	err := readAWSRegions()
-	if err != nil {
-		t.Errorf("readAWSRegions() error = %v", err)
-	}
+func TestParseAWSRegions(t *testing.T) {
+	c := testCalculator(t)
 
 	tests := []struct {
 		regionCode string
 		awsRegion  AWSRegion
 	}{
-		{regionCode: "eu-central-1", awsRegion: AWSRegion{CarbonIntensity: 338, PUE: 1.2}},
-		{regionCode: "eu-west-1", awsRegion: AWSRegion{CarbonIntensity: 316, PUE: 1.2}},
-		{regionCode: "us-east-1", awsRegion: AWSRegion{CarbonIntensity: 415.755, PUE: 1.2}},
+		{regionCode: "eu-central-1", awsRegion: AWSRegion{CarbonIntensity: 338, PUE: 1.2, WUE: awsWUE, MarketCarbonIntensity: 0}},
+		{regionCode: "eu-west-1", awsRegion: AWSRegion{CarbonIntensity: 316, PUE: 1.2, WUE: awsWUE, MarketCarbonIntensity: 0}},
+		{regionCode: "us-east-1", awsRegion: AWSRegion{CarbonIntensity: 415.755, PUE: 1.2, WUE: awsWUE, MarketCarbonIntensity: 0}},
+		{regionCode: "ap-southeast-2", awsRegion: AWSRegion{CarbonIntensity: 790, PUE: 1.2, WUE: awsWUE, MarketCarbonIntensity: 790}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.regionCode, func(t *testing.T) {
-			if awsRegions[tt.regionCode] != tt.awsRegion {
-				t.Errorf("readAWSRegions() code %s - want value %v, got value %v", tt.regionCode, tt.awsRegion, awsRegions[tt.regionCode])
+			if c.awsRegions[tt.regionCode] != tt.awsRegion {
+				t.Errorf("ParseAWSRegions() code %s - want value %v, got value %v", tt.regionCode, tt.awsRegion, c.awsRegions[tt.regionCode])
 			}
 		})
 	}
@@ -93,6 +100,63 @@ func TestCarbonIntensity(t *testing.T) {
 	}
 }
 
+func TestMarketCarbonIntensity(t *testing.T) {
+	type args struct {
+		regionCode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "eu-central-1", args: args{"eu-central-1"}, want: 0, wantErr: false},
+		{name: "ap-southeast-2", args: args{"ap-southeast-2"}, want: 790, wantErr: false},
+		{name: "unknown", args: args{"unknown"}, want: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarketCarbonIntensity(tt.args.regionCode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarketCarbonIntensity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MarketCarbonIntensity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSMarketBased(t *testing.T) {
+	location := Result{EnergyKiloWattHours: 2, OperationalGrams: 1580, EmbodiedGrams: 10}
+
+	tests := []struct {
+		name       string
+		regionCode string
+		want       Result
+		wantErr    bool
+	}{
+		{name: "renewable region", regionCode: "eu-central-1", want: Result{EnergyKiloWattHours: 2, OperationalGrams: 0, EmbodiedGrams: 10}},
+		{name: "other region", regionCode: "ap-southeast-2", want: Result{EnergyKiloWattHours: 2, OperationalGrams: 1580, EmbodiedGrams: 10}},
+		{name: "unknown", regionCode: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSMarketBased(tt.regionCode, location)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSMarketBased() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("AWSMarketBased() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPUE(t *testing.T) {
 	type args struct {
 		regionCode string
@@ -152,6 +216,118 @@ func TestPowerAt50Percent(t *testing.T) {
 	}
 }
 
+func TestPowerAtUtilization(t *testing.T) {
+	type args struct {
+		ec2InstanceType string
+		utilization     float64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "t2.micro idle", args: args{"t2.micro", 0}, want: 1.8, wantErr: false},
+		{name: "t2.micro 5%", args: args{"t2.micro", 5}, want: 2.4, wantErr: false},
+		{name: "t2.micro 30%", args: args{"t2.micro", 30}, want: 3.95, wantErr: false},
+		{name: "t2.micro 50%", args: args{"t2.micro", 50}, want: 4.9, wantErr: false},
+		{name: "t2.micro 75%", args: args{"t2.micro", 75}, want: 5.65, wantErr: false},
+		{name: "t2.micro 100%", args: args{"t2.micro", 100}, want: 6.4, wantErr: false},
+		{name: "negative", args: args{"t2.micro", -1}, want: 0, wantErr: true},
+		{name: "above 100%", args: args{"t2.micro", 101}, want: 0, wantErr: true},
+		{name: "unknown", args: args{"unknown", 50}, want: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PowerAtUtilization(tt.args.ec2InstanceType, tt.args.utilization)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PowerAtUtilization() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PowerAtUtilization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGPUPowerAtUtilization(t *testing.T) {
+	type args struct {
+		ec2InstanceType string
+		utilization     float64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "g4dn.xlarge idle", args: args{"g4dn.xlarge", 0}, want: 8.1, wantErr: false},
+		{name: "g4dn.xlarge 30%", args: args{"g4dn.xlarge", 30}, want: 37.5, wantErr: false},
+		{name: "p3.2xlarge 100%", args: args{"p3.2xlarge", 100}, want: 305.6, wantErr: false},
+		{name: "without GPU", args: args{"m5.large", 50}, want: 0, wantErr: false},
+		{name: "above 100%", args: args{"g4dn.xlarge", 101}, want: 0, wantErr: true},
+		{name: "unknown", args: args{"unknown", 50}, want: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GPUPowerAtUtilization(tt.args.ec2InstanceType, tt.args.utilization)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GPUPowerAtUtilization() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("GPUPowerAtUtilization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVCPUs(t *testing.T) {
+	tests := []struct {
+		instanceType string
+		want         float64
+		wantErr      bool
+	}{
+		{instanceType: "m5.xlarge", want: 4},
+		{instanceType: "db.r5.2xlarge", want: 8},
+		{instanceType: "m6id.large", want: 2},
+		{instanceType: "db.m6id.xlarge", want: 4},
+		{instanceType: "m5.unknown", wantErr: true},
+		{instanceType: "db.unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.instanceType, func(t *testing.T) {
+			got, err := VCPUs(tt.instanceType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VCPUs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VCPUs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEC2Instances_gpu(t *testing.T) {
+	instance, err := Instance("p3.8xlarge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instance.GPUs != 4 {
+		t.Errorf("p3.8xlarge GPUs = %v, want 4", instance.GPUs)
+	}
+	// The GPU power is part of the instance power.
+	if instance.GPUPowerAt100Percent <= 0 || instance.GPUPowerAt100Percent >= instance.PowerAt100Percent {
+		t.Errorf("p3.8xlarge GPU power = %v, want positive and below instance power %v", instance.GPUPowerAt100Percent, instance.PowerAt100Percent)
+	}
+
+	instance, _ = Instance("m5.large")
+	if instance.GPUs != 0 || instance.GPUPowerAt100Percent != 0 {
+		t.Errorf("m5.large GPUs = %v, GPU power = %v, want none", instance.GPUs, instance.GPUPowerAt100Percent)
+	}
+}
+
 func TestManufacturingEmissions(t *testing.T) {
 	type args struct {
 		ec2InstanceType string
@@ -209,9 +385,198 @@ func TestAWS(t *testing.T) {
 				t.Errorf("AWS() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWS() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownErrors(t *testing.T) {
+	c := testCalculator(t)
+
+	tests := []struct {
+		name     string
+		f        func() error
+		want     error
+		wantName string
+	}{
+		{name: "AWS region", f: func() error { _, err := c.AWS("xx-west-1", "t2.micro", time.Hour); return err }, want: ErrUnknownRegion, wantName: "xx-west-1"},
+		{name: "AWS instance type", f: func() error { _, err := c.AWS("eu-west-1", "t2.huge", time.Hour); return err }, want: ErrUnknownInstanceType, wantName: "t2.huge"},
+		{name: "RDS instance type", f: func() error { _, err := c.AWSRDS("eu-west-1", "db.t2.huge", time.Hour, false); return err }, want: ErrUnknownInstanceType, wantName: "db.t2.huge"},
+		{name: "GCP region", f: func() error { _, err := c.GCP("xx-west1", "n1-standard-4", time.Hour); return err }, want: ErrUnknownRegion, wantName: "xx-west1"},
+		{name: "GCP machine type", f: func() error { _, err := c.GCP("europe-west1", "n1-huge", time.Hour); return err }, want: ErrUnknownInstanceType, wantName: "n1-huge"},
+		{name: "Azure region", f: func() error { _, err := c.Azure("xxeurope", "Standard_D2s_v3", time.Hour); return err }, want: ErrUnknownRegion, wantName: "xxeurope"},
+		{name: "Azure VM size", f: func() error { _, err := c.Azure("westeurope", "Standard_Huge", time.Hour); return err }, want: ErrUnknownInstanceType, wantName: "Standard_Huge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.f()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.wantName) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestAWSAtUtilization(t *testing.T) {
+	type args struct {
+		regionCode   string
+		instanceType string
+		utilization  float64
+		duration     time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "eu-west-1 t2.micro 50%", args: args{"eu-west-1", "t2.micro", 50, time.Hour}, want: 2.75808, wantErr: false},
+		{name: "eu-west-1 t2.micro 100%", args: args{"eu-west-1", "t2.micro", 100, time.Hour}, want: 3.32688, wantErr: false},
+		{name: "eu-west-1 t2.micro idle", args: args{"eu-west-1", "t2.micro", 0, time.Hour}, want: 1.58256, wantErr: false},
+		{name: "invalid utilization", args: args{"eu-west-1", "t2.micro", 150, time.Hour}, want: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSAtUtilization(tt.args.regionCode, tt.args.instanceType, tt.args.utilization, tt.args.duration)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSAtUtilization() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSAtUtilization() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		regionCode string
+		want       Location
+		wantErr    bool
+	}{
+		{name: "eu-central-1", regionCode: "eu-central-1", want: Location{Latitude: 50.11, Longitude: 8.68}, wantErr: false},
+		{name: "sa-east-1", regionCode: "sa-east-1", want: Location{Latitude: -23.55, Longitude: -46.63}, wantErr: false},
+		{name: "unknown", regionCode: "unknown", want: Location{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RegionLocation(tt.regionCode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegionLocation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
 			if got != tt.want {
-				t.Errorf("AWS() = %v, want %v", got, tt.want)
+				t.Errorf("RegionLocation() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_regionLocationsComplete(t *testing.T) {
+	c := testCalculator(t)
+	for regionCode := range c.awsRegions {
+		if _, exists := c.awsRegionLocations[regionCode]; !exists {
+			t.Errorf("region %s has no location", regionCode)
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	regions := Regions()
+	if len(regions) == 0 {
+		t.Fatal("Regions() returned no regions")
+	}
+	if !sort.StringsAreSorted(regions) {
+		t.Error("Regions() is not sorted")
+	}
+	for _, regionCode := range regions {
+		if _, err := Region(regionCode); err != nil {
+			t.Errorf("Region(%q) error = %v", regionCode, err)
+		}
+	}
+}
+
+func TestRegion(t *testing.T) {
+	got, err := Region("eu-west-1")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	ci, _ := CarbonIntensity("eu-west-1")
+	pue, _ := PUE("eu-west-1")
+	if got.CarbonIntensity != ci || got.PUE != pue {
+		t.Errorf("Region() = %+v, want carbon intensity %v and PUE %v", got, ci, pue)
+	}
+
+	if _, err := Region("unknown"); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("Region() error = %v, want %v", err, ErrUnknownRegion)
+	}
+}
+
+func TestInstanceTypes(t *testing.T) {
+	instanceTypes := InstanceTypes()
+	if len(instanceTypes) == 0 {
+		t.Fatal("InstanceTypes() returned no instance types")
+	}
+	if !sort.StringsAreSorted(instanceTypes) {
+		t.Error("InstanceTypes() is not sorted")
+	}
+	if i := sort.SearchStrings(instanceTypes, "t2.micro"); i == len(instanceTypes) || instanceTypes[i] != "t2.micro" {
+		t.Error("InstanceTypes() doesn't contain t2.micro")
+	}
+}
+
+func TestInstance(t *testing.T) {
+	got, err := Instance("t2.micro")
+	if err != nil {
+		t.Fatalf("Instance() error = %v", err)
+	}
+	power, _ := PowerAt50Percent("t2.micro")
+	if got.VCPUs != 1 || got.PowerAt50Percent != power {
+		t.Errorf("Instance() = %+v, want 1 vCPU and power at 50%% %v", got, power)
+	}
+
+	if _, err := Instance("t2.huge"); !errors.Is(err, ErrUnknownInstanceType) {
+		t.Errorf("Instance() error = %v, want %v", err, ErrUnknownInstanceType)
+	}
+}
+
+func BenchmarkCalculator_AWSAtUtilization(b *testing.B) {
+	c, err := NewCalculator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.AWSAtUtilization("eu-west-1", "m5.xlarge", 35, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalculator_AWSEstimatedAtUtilization(b *testing.B) {
+	c, err := NewCalculator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// The fallback is used for instance types missing from the Teads
+	// dataset.
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := c.AWSEstimatedAtUtilization("eu-west-1", "m5.7xlarge", FallbackFamily, 35, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package footprint
+
+import (
+	_ "embed"
+	"io"
+	"time"
+)
+
+// GCP data is based on the methodology of the Cloud Carbon Footprint project:
+// https://www.cloudcarbonfootprint.org/docs/methodology/
+//
+// Machine type power is derived from the number of vCPUs, using the average of
+// the minimum (0.71 W) and maximum (4.26 W) power of a GCP vCPU, as for a load
+// of 50 percent, plus 0.392 W per GB of memory. Shared-core machine types are
+// accounted with their share of a vCPU.
+
+//go:embed gcp-machine-types.csv
+var gcpMachineTypesCSV string
+
+//go:embed gcp-regions.csv
+var gcpRegionsCSV string
+
+type GCPMachineType struct {
+	// VCPUs is the number of vCPUs of the machine type.
+	VCPUs float64
+
+	// PowerAt50Percent is the power consumption in Watt at 50% load.
+	PowerAt50Percent float64
+}
+
+type GCPRegion struct {
+	// CarbonIntensity is the amount of CO2 emitted when producing electricity.
+	// Unit: metric gram per kilowatt hour.
+	CarbonIntensity float64
+
+	// PUE is the power usage effectiveness coefficient of the data center.
+	PUE float64
+
+	// WUE is the water usage effectiveness of the data center, in liters
+	// of water consumed per kilowatt hour of IT energy.
+	WUE float64
+}
+
+// Columns of the GCP machine type dataset. The Azure VM size dataset has
+// the same columns for vCPUs and power.
+const (
+	gcpColumnMachineType = "Machine type"
+	machineColumnVCPUs   = "vCPUs"
+	machineColumnPower   = "Power at 50% (W)"
+)
+
+func parseGCPMachineTypes(r io.Reader) (map[string]GCPMachineType, error) {
+	gcpMachineTypes := make(map[string]GCPMachineType)
+	columns := []string{gcpColumnMachineType, machineColumnVCPUs, machineColumnPower}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		vCPUs, err := c.float(record, machineColumnVCPUs)
+		if err != nil {
+			return err
+		}
+		power, err := c.float(record, machineColumnPower)
+		if err != nil {
+			return err
+		}
+
+		gcpMachineTypes[c.value(record, gcpColumnMachineType)] = GCPMachineType{
+			VCPUs:            vCPUs,
+			PowerAt50Percent: power,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gcpMachineTypes, nil
+}
+
+func parseGCPRegions(r io.Reader) (map[string]GCPRegion, error) {
+	gcpRegions := make(map[string]GCPRegion)
+	columns := []string{regionColumnRegion, regionColumnCarbonIntensity, regionColumnPUE}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		carbonIntensity, err := c.float(record, regionColumnCarbonIntensity)
+		if err != nil {
+			return err
+		}
+		pue, err := c.float(record, regionColumnPUE)
+		if err != nil {
+			return err
+		}
+
+		gcpRegions[c.value(record, regionColumnRegion)] = GCPRegion{
+			CarbonIntensity: carbonIntensity,
+			PUE:             pue,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gcpRegions, nil
+}
+
+// GCPRegions returns the sorted codes of the GCP regions in the dataset.
+func (c *Calculator) GCPRegions() []string {
+	return sortedKeys(c.gcpRegions)
+}
+
+// GCPMachineTypeVCPUs returns the number of vCPUs of a GCP machine type.
+func (c *Calculator) GCPMachineTypeVCPUs(machineType string) (float64, error) {
+	val, exists := c.gcpMachineTypes[machineType]
+	if !exists {
+		return 0, unknownInstanceType(machineType)
+	} else {
+		return val.VCPUs, nil
+	}
+}
+
+// GCPCarbonIntensity returns the carbon intensity for a GCP region, in grams
+// of CO2 emitted while producing one kilowatt hour of electricity.
+func (c *Calculator) GCPCarbonIntensity(regionCode string) (float64, error) {
+	val, exists := c.gcpRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	} else {
+		return val.CarbonIntensity, nil
+	}
+}
+
+// GCPPUE returns the power usage effectiveness coefficient for a GCP region.
+func (c *Calculator) GCPPUE(regionCode string) (float64, error) {
+	val, exists := c.gcpRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	} else {
+		return val.PUE, nil
+	}
+}
+
+// GCPPowerAt50Percent returns the power consumption at 50% load for a GCP machine type, in watt.
+func (c *Calculator) GCPPowerAt50Percent(machineType string) (float64, error) {
+	val, exists := c.gcpMachineTypes[machineType]
+	if !exists {
+		return 0, unknownInstanceType(machineType)
+	} else {
+		return val.PowerAt50Percent, nil
+	}
+}
+
+// GCP returns the footprint of a Compute Engine VM.
+// Manufacturing emissions are not accounted for.
+func (c *Calculator) GCP(regionCode, machineType string, duration time.Duration) (Result, error) {
+	pue, err := c.GCPPUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.GCPWUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.GCPCarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	power, err := c.GCPPowerAt50Percent(machineType)
+	if err != nil {
+		return Result{}, err
+	}
+
+	powerKiloWatt := power / 1000.0
+
+	return operationalResult(powerKiloWatt*duration.Hours(), pue, wue, ci), nil
+}
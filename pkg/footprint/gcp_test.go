@@ -0,0 +1,96 @@
+package footprint
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_parseGCPMachineTypes(t *testing.T) {
+	c := testCalculator(t)
+
+	tests := []struct {
+		machineType string
+		value       GCPMachineType
+	}{
+		{machineType: "n1-standard-4", value: GCPMachineType{VCPUs: 4, PowerAt50Percent: 15.82}},
+		{machineType: "e2-micro", value: GCPMachineType{VCPUs: 2, PowerAt50Percent: 1.01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.machineType, func(t *testing.T) {
+			if c.gcpMachineTypes[tt.machineType] != tt.value {
+				t.Errorf("parseGCPMachineTypes() machine type %s - want value %v, got value %v", tt.machineType, tt.value, c.gcpMachineTypes[tt.machineType])
+			}
+		})
+	}
+}
+
+func Test_parseGCPRegions(t *testing.T) {
+	c := testCalculator(t)
+
+	tests := []struct {
+		regionCode string
+		gcpRegion  GCPRegion
+	}{
+		{regionCode: "europe-west1", gcpRegion: GCPRegion{CarbonIntensity: 212, PUE: 1.1, WUE: gcpWUE}},
+		{regionCode: "us-central1", gcpRegion: GCPRegion{CarbonIntensity: 479, PUE: 1.1, WUE: gcpWUE}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regionCode, func(t *testing.T) {
+			if c.gcpRegions[tt.regionCode] != tt.gcpRegion {
+				t.Errorf("parseGCPRegions() code %s - want value %v, got value %v", tt.regionCode, tt.gcpRegion, c.gcpRegions[tt.regionCode])
+			}
+		})
+	}
+}
+
+func TestGCP(t *testing.T) {
+	type args struct {
+		regionCode  string
+		machineType string
+		duration    time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero duration", args: args{"europe-west1", "n1-standard-4", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", "n1-standard-4", time.Hour}, want: 0, wantErr: true},
+		{name: "AWS region", args: args{"eu-west-1", "n1-standard-4", time.Hour}, want: 0, wantErr: true},
+		{name: "unknown machine type", args: args{"europe-west1", "unknown", time.Hour}, want: 0, wantErr: true},
+		// 15.82 W * 1.1 (PUE) * 212 g/kWh
+		{name: "europe-west1 n1-standard-4 1 hour", args: args{"europe-west1", "n1-standard-4", time.Hour}, want: 3.689224, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GCP(tt.args.regionCode, tt.args.machineType, tt.args.duration)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GCP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("GCP() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGCPRegions(t *testing.T) {
+	regions := GCPRegions()
+	if !sort.StringsAreSorted(regions) {
+		t.Error("GCPRegions() is not sorted")
+	}
+	for _, regionCode := range regions {
+		if _, err := GCPCarbonIntensity(regionCode); err != nil {
+			t.Errorf("GCPCarbonIntensity(%q) error = %v", regionCode, err)
+		}
+	}
+	if i := sort.SearchStrings(regions, "europe-west1"); i == len(regions) || regions[i] != "europe-west1" {
+		t.Error("GCPRegions() doesn't contain europe-west1")
+	}
+}
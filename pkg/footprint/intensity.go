@@ -0,0 +1,101 @@
+package footprint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CarbonIntensityProvider provides the carbon intensity of the grid of an
+// AWS region during an hour, in grams of CO2 per kilowatt hour.
+type CarbonIntensityProvider interface {
+	HourlyCarbonIntensity(ctx context.Context, regionCode string, t time.Time) (float64, error)
+}
+
+// HourlyIntensity is the carbon intensity of the grid during an hour, in
+// grams of CO2 per kilowatt hour.
+type HourlyIntensity struct {
+	// Hour is the start of the hour.
+	Hour            time.Time
+	CarbonIntensity float64
+}
+
+// CarbonIntensityForecaster forecasts the carbon intensity of the grid of an
+// AWS region.
+type CarbonIntensityForecaster interface {
+	// CarbonIntensityForecast returns the forecast carbon intensity per
+	// hour for up to the given number of hours, starting with the current
+	// hour, in chronological order.
+	CarbonIntensityForecast(ctx context.Context, regionCode string, hours int) ([]HourlyIntensity, error)
+}
+
+// sortedHours returns hourly carbon intensity keyed by the start of the hour
+// in chronological order.
+func sortedHours(values map[time.Time]float64) []HourlyIntensity {
+	hours := make([]HourlyIntensity, 0, len(values))
+	for hour, ci := range values {
+		hours = append(hours, HourlyIntensity{Hour: hour, CarbonIntensity: ci})
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Hour.Before(hours[j].Hour) })
+	return hours
+}
+
+// hourlyFetchFunc requests the hourly carbon intensity of a grid zone in a
+// time range, keyed by the start of the hour.
+type hourlyFetchFunc func(ctx context.Context, zone string, start, end time.Time) (map[time.Time]float64, error)
+
+// hourlyCache holds hourly carbon intensity per grid zone. Values are
+// requested in ranges of fixed length, so that each range is only requested
+// once per zone.
+type hourlyCache struct {
+	// rangeLength is the length of the ranges requested.
+	rangeLength time.Duration
+
+	mu sync.Mutex
+
+	// values holds the carbon intensity per zone, keyed by the start of the
+	// hour.
+	values map[string]map[time.Time]float64
+
+	// fetched records which ranges have been requested per zone, keyed by
+	// the start of the range.
+	fetched map[string]map[time.Time]bool
+}
+
+// get returns the carbon intensity of a zone during the hour containing t,
+// requesting the range containing it with fetch if needed.
+func (c *hourlyCache) get(ctx context.Context, zone string, t time.Time, fetch hourlyFetchFunc) (float64, error) {
+	hour := t.UTC().Truncate(time.Hour)
+	start := hour.Truncate(c.rangeLength)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil {
+		c.values = make(map[string]map[time.Time]float64)
+		c.fetched = make(map[string]map[time.Time]bool)
+	}
+	if c.values[zone] == nil {
+		c.values[zone] = make(map[time.Time]float64)
+		c.fetched[zone] = make(map[time.Time]bool)
+	}
+
+	if !c.fetched[zone][start] {
+		values, err := fetch(ctx, zone, start, start.Add(c.rangeLength))
+		if err != nil {
+			return 0, err
+		}
+		for hour, value := range values {
+			c.values[zone][hour] = value
+		}
+		c.fetched[zone][start] = true
+	}
+
+	ci, exists := c.values[zone][hour]
+	if !exists {
+		return 0, fmt.Errorf("no carbon intensity for zone %s at %s", zone, hour.Format(time.RFC3339))
+	}
+	return ci, nil
+}
@@ -0,0 +1,34 @@
+package footprint
+
+// networkKiloWattHoursPerGB is the energy needed to transfer data over the
+// network, in kilowatt-hours per gigabyte. This follows the methodology of
+// the Cloud Carbon Footprint project:
+// https://www.cloudcarbonfootprint.org/docs/methodology/#networking
+const networkKiloWattHoursPerGB = 0.001
+
+// AWSNetwork returns the footprint of data transferred out of an AWS region,
+// in gram CO2 equivalents. The amount of data is given in gigabytes.
+//
+// Only the energy used within the source region is accounted for, using
+// its PUE and carbon intensity. Manufacturing emissions of networking
+// hardware are not accounted for.
+func (c *Calculator) AWSNetwork(regionCode string, gigabytes float64) (Result, error) {
+	pue, err := c.PUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.WUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.CarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	kiloWattHours := gigabytes * networkKiloWattHoursPerGB
+
+	return operationalResult(kiloWattHours, pue, wue, ci), nil
+}
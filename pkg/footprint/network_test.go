@@ -0,0 +1,38 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAWSNetwork(t *testing.T) {
+	type args struct {
+		regionCode string
+		gigabytes  float64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero", args: args{"eu-west-1", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", 1000}, want: 0, wantErr: true},
+		// 1 TB: 1 kWh * 1.2 (PUE) * 316 g/kWh
+		{name: "eu-west-1 1 TB", args: args{"eu-west-1", 1000}, want: 379.2, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSNetwork(tt.args.regionCode, tt.args.gigabytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSNetwork() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSNetwork() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
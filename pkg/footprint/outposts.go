@@ -0,0 +1,33 @@
+package footprint
+
+import "fmt"
+
+// WithOutpost adds an AWS Outposts rack, which is estimated like an AWS
+// region with the given code, but with the carbon intensity of the grid, the
+// PUE and the WUE of the site it is installed at. Outposts are powered by
+// the customer's electricity, so the data of the parent region does not
+// apply, nor do the overrides of regions. The market-based carbon intensity
+// equals the given one.
+func WithOutpost(code string, carbonIntensity, pue, wue float64) Option {
+	return func(c *Calculator) error {
+		if carbonIntensity < 0 {
+			return fmt.Errorf("invalid carbon intensity %g for Outpost %q, must not be negative", carbonIntensity, code)
+		}
+		if pue < 1 {
+			return fmt.Errorf("invalid PUE %g for Outpost %q, must be at least 1", pue, code)
+		}
+		if wue < 0 {
+			return fmt.Errorf("invalid WUE %g for Outpost %q, must not be negative", wue, code)
+		}
+		if c.outposts == nil {
+			c.outposts = make(map[string]AWSRegion)
+		}
+		c.outposts[code] = AWSRegion{
+			CarbonIntensity:       carbonIntensity,
+			PUE:                   pue,
+			WUE:                   wue,
+			MarketCarbonIntensity: carbonIntensity,
+		}
+		return nil
+	}
+}
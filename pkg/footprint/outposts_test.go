@@ -0,0 +1,39 @@
+package footprint
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithOutpost(t *testing.T) {
+	c, err := NewCalculator(WithOutpost("outpost:dc-1", 100, 1.5, 0.5), WithPUE(1.1), WithCarbonIntensity("eu-central-1", 50))
+	if err != nil {
+		t.Fatalf("NewCalculator() error = %v", err)
+	}
+
+	r, err := c.Region("outpost:dc-1")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	if want := (AWSRegion{CarbonIntensity: 100, PUE: 1.5, WUE: 0.5, MarketCarbonIntensity: 100}); r != want {
+		t.Errorf("Region() = %+v, want %+v unaffected by overrides", r, want)
+	}
+
+	outpost, err := c.AWS("outpost:dc-1", "m5.large", time.Hour)
+	if err != nil {
+		t.Fatalf("AWS() error = %v", err)
+	}
+	region, err := c.AWS("eu-central-1", "m5.large", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outpost.OperationalGrams <= region.OperationalGrams || outpost.EmbodiedGrams != region.EmbodiedGrams {
+		t.Errorf("AWS() on Outpost = %+v, in region = %+v, want higher operational and same embodied emissions", outpost, region)
+	}
+
+	for _, opt := range []Option{WithOutpost("outpost:dc-1", -1, 1.5, 0), WithOutpost("outpost:dc-1", 100, 0.9, 0), WithOutpost("outpost:dc-1", 100, 1.5, -1)} {
+		if _, err := NewCalculator(opt); err == nil {
+			t.Error("NewCalculator() with invalid Outpost returned no error")
+		}
+	}
+}
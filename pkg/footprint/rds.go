@@ -0,0 +1,63 @@
+package footprint
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// rdsInstanceTypePrefix is the prefix of RDS instance types. The rest
+	// of the name is the EC2 instance type the database runs on, e.g.
+	// db.m5.xlarge runs on m5.xlarge.
+	rdsInstanceTypePrefix = "db."
+
+	// rdsMultiAZReplicationFactor accounts for the standby instance of a
+	// Multi-AZ deployment, which is billed as part of the primary instance.
+	rdsMultiAZReplicationFactor = 2
+)
+
+// RDSInstanceType returns the instance type to use for footprint data of an
+// RDS instance type. This is the RDS instance type itself if the dataset
+// covers it, otherwise the equivalent EC2 instance type.
+func (c *Calculator) RDSInstanceType(dbInstanceType string) (string, error) {
+	ec2InstanceType, found := strings.CutPrefix(dbInstanceType, rdsInstanceTypePrefix)
+	if !found {
+		return "", fmt.Errorf("not an RDS instance type")
+	}
+
+	if _, exists := c.ec2Instances[dbInstanceType]; exists {
+		return dbInstanceType, nil
+	}
+	if _, exists := c.ec2Instances[ec2InstanceType]; !exists {
+		return "", unknownInstanceType(dbInstanceType)
+	}
+
+	return ec2InstanceType, nil
+}
+
+// AWSRDS returns the footprint of an RDS database instance, assuming a CPU utilization of DefaultUtilization. For Multi-AZ
+// deployments, the standby instance is included.
+func (c *Calculator) AWSRDS(regionCode, dbInstanceType string, duration time.Duration, multiAZ bool) (Result, error) {
+	return c.AWSRDSAtUtilization(regionCode, dbInstanceType, DefaultUtilization, duration, multiAZ)
+}
+
+// AWSRDSAtUtilization returns the footprint of an RDS database instance
+// running at the given CPU utilization in percent.
+func (c *Calculator) AWSRDSAtUtilization(regionCode, dbInstanceType string, utilization float64, duration time.Duration, multiAZ bool) (Result, error) {
+	instanceType, err := c.RDSInstanceType(dbInstanceType)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := c.AWSAtUtilization(regionCode, instanceType, utilization, duration)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if multiAZ {
+		result = result.scale(rdsMultiAZReplicationFactor)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRDSInstanceType(t *testing.T) {
+	tests := []struct {
+		name           string
+		dbInstanceType string
+		want           string
+		wantErr        bool
+	}{
+		{name: "covered by dataset", dbInstanceType: "db.t2.micro", want: "db.t2.micro", wantErr: false},
+		{name: "EC2 equivalent", dbInstanceType: "db.t4g.micro", want: "t4g.micro", wantErr: false},
+		{name: "unknown", dbInstanceType: "db.unknown", want: "", wantErr: true},
+		{name: "not an RDS instance type", dbInstanceType: "t2.micro", want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RDSInstanceType(tt.dbInstanceType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RDSInstanceType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RDSInstanceType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSRDS(t *testing.T) {
+	type args struct {
+		regionCode     string
+		dbInstanceType string
+		duration       time.Duration
+		multiAZ        bool
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "unknown region", args: args{"unknown", "db.t2.micro", time.Hour, false}, want: 0, wantErr: true},
+		{name: "unknown instance", args: args{"eu-west-1", "db.unknown", time.Hour, false}, want: 0, wantErr: true},
+		{name: "eu-west-1 db.t2.micro 1 hour", args: args{"eu-west-1", "db.t2.micro", time.Hour, false}, want: 2.75808, wantErr: false},
+		{name: "eu-west-1 db.t2.micro 1 hour Multi-AZ", args: args{"eu-west-1", "db.t2.micro", time.Hour, true}, want: 5.51616, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSRDS(tt.args.regionCode, tt.args.dbInstanceType, tt.args.duration, tt.args.multiAZ)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSRDS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSRDS() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package footprint
+
+// Result is the footprint of cloud usage, broken down into the energy
+// consumed and the emissions caused by it and by the hardware's production.
+type Result struct {
+	// EnergyKiloWattHours is the energy consumed, including the overhead of
+	// the data center as given by the PUE.
+	EnergyKiloWattHours float64
+
+	// OperationalGrams is the emission from producing the energy consumed,
+	// in metric grams CO2e.
+	OperationalGrams float64
+
+	// EmbodiedGrams is the share of the emissions created during production
+	// of the hardware, in metric grams CO2e.
+	EmbodiedGrams float64
+
+	// WaterLiters is the water consumed on site by the data center, e.g.
+	// for cooling, as given by the WUE.
+	WaterLiters float64
+}
+
+// Total returns the total emissions in metric grams CO2e.
+func (r Result) Total() float64 {
+	return r.OperationalGrams + r.EmbodiedGrams
+}
+
+// Add returns the sum of two results.
+func (r Result) Add(o Result) Result {
+	return Result{
+		EnergyKiloWattHours: r.EnergyKiloWattHours + o.EnergyKiloWattHours,
+		OperationalGrams:    r.OperationalGrams + o.OperationalGrams,
+		EmbodiedGrams:       r.EmbodiedGrams + o.EmbodiedGrams,
+		WaterLiters:         r.WaterLiters + o.WaterLiters,
+	}
+}
+
+// WithCarbonIntensity returns the result with the operational emissions
+// calculated from the energy consumed and the given carbon intensity, in
+// grams of CO2 per kilowatt hour.
+func (r Result) WithCarbonIntensity(carbonIntensity float64) Result {
+	r.OperationalGrams = r.EnergyKiloWattHours * carbonIntensity
+	return r
+}
+
+// scale returns the result multiplied by factor.
+func (r Result) scale(factor float64) Result {
+	return Result{
+		EnergyKiloWattHours: r.EnergyKiloWattHours * factor,
+		OperationalGrams:    r.OperationalGrams * factor,
+		EmbodiedGrams:       r.EmbodiedGrams * factor,
+		WaterLiters:         r.WaterLiters * factor,
+	}
+}
+
+// operationalResult returns the result of consuming the given IT energy in a
+// data center with the given PUE, WUE and carbon intensity.
+func operationalResult(kiloWattHours, pue, wue, carbonIntensity float64) Result {
+	energy := kiloWattHours * pue
+	return Result{
+		EnergyKiloWattHours: energy,
+		OperationalGrams:    energy * carbonIntensity,
+		WaterLiters:         kiloWattHours * wue,
+	}
+}
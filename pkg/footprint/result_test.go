@@ -0,0 +1,36 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResult_Add(t *testing.T) {
+	a := Result{EnergyKiloWattHours: 1, OperationalGrams: 300, EmbodiedGrams: 20, WaterLiters: 0.25}
+	b := Result{EnergyKiloWattHours: 0.5, OperationalGrams: 100, WaterLiters: 0.5}
+
+	got := a.Add(b)
+	want := Result{EnergyKiloWattHours: 1.5, OperationalGrams: 400, EmbodiedGrams: 20, WaterLiters: 0.75}
+	if got != want {
+		t.Errorf("Add() = %+v, want %+v", got, want)
+	}
+	if got.Total() != 420 {
+		t.Errorf("Total() = %v, want 420", got.Total())
+	}
+}
+
+func TestAWS_breakdown(t *testing.T) {
+	got, err := AWS("eu-west-1", "t2.micro", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 4.9 W at a PUE of 1.2 and 316 g/kWh, plus 0.9 g manufacturing per hour
+	want := Result{EnergyKiloWattHours: 0.00588, OperationalGrams: 1.85808, EmbodiedGrams: 0.9}
+	if math.Abs(got.EnergyKiloWattHours-want.EnergyKiloWattHours) > 1e-9 ||
+		math.Abs(got.OperationalGrams-want.OperationalGrams) > 1e-9 ||
+		math.Abs(got.EmbodiedGrams-want.EmbodiedGrams) > 1e-9 {
+		t.Errorf("AWS() = %+v, want %+v", got, want)
+	}
+}
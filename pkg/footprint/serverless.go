@@ -0,0 +1,81 @@
+package footprint
+
+// Compute and memory energy coefficients for serverless usage, where the
+// underlying instance types are unknown. These follow the methodology of the
+// Cloud Carbon Footprint project, using the average of the minimum and
+// maximum power of an AWS vCPU, as for a load of 50 percent:
+// https://www.cloudcarbonfootprint.org/docs/methodology/#compute
+const (
+	vCPUMinWatts = 0.74
+	vCPUMaxWatts = 3.5
+
+	// memoryKiloWattHoursPerGBHour is the energy used by one gigabyte of
+	// memory during one hour.
+	memoryKiloWattHoursPerGBHour = 0.000392
+
+	// lambdaMegabytesPerVCPU is the memory allocation at which a Lambda
+	// function gets one full vCPU. CPU power is allocated proportionally.
+	lambdaMegabytesPerVCPU = 1769
+)
+
+// vCPUWattsAt50Percent returns the average power of a vCPU at 50% load, in watt.
+func vCPUWattsAt50Percent() float64 {
+	return vCPUMinWatts + 0.5*(vCPUMaxWatts-vCPUMinWatts)
+}
+
+// serverlessKiloWattHours returns the energy used by serverless compute,
+// given in vCPU-hours and memory gigabyte-hours, in kilowatt-hours.
+func serverlessKiloWattHours(vCPUHours, gbHours float64) float64 {
+	return vCPUHours*vCPUWattsAt50Percent()/1000.0 + gbHours*memoryKiloWattHoursPerGBHour
+}
+
+// AWSLambda returns the footprint of Lambda function execution in gram CO2
+// equivalents. The usage is given as allocated memory in gigabyte-hours,
+// which is the billed amount of GB-seconds divided by 3600.
+//
+// The vCPU share is derived from the memory allocation. Manufacturing
+// emissions are not accounted for.
+func (c *Calculator) AWSLambda(regionCode string, gbHours float64) (Result, error) {
+	pue, err := c.PUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.WUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.CarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	vCPUHours := gbHours * 1024 / lambdaMegabytesPerVCPU
+
+	return operationalResult(serverlessKiloWattHours(vCPUHours, gbHours), pue, wue, ci), nil
+}
+
+// AWSFargate returns the footprint of ECS and EKS tasks on Fargate in gram
+// CO2 equivalents. The usage is given as vCPU-hours and memory
+// gigabyte-hours, as billed.
+//
+// Manufacturing emissions are not accounted for.
+func (c *Calculator) AWSFargate(region string, vcpuHours, gbHours float64) (Result, error) {
+	pue, err := c.PUE(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.WUE(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.CarbonIntensity(region)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return operationalResult(serverlessKiloWattHours(vcpuHours, gbHours), pue, wue, ci), nil
+}
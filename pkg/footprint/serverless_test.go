@@ -0,0 +1,72 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAWSLambda(t *testing.T) {
+	type args struct {
+		regionCode string
+		gbHours    float64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero", args: args{"eu-west-1", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", 1}, want: 0, wantErr: true},
+		// 1769 MB for 1 hour, which is 1 vCPU-hour:
+		// (2.12 W / 1000 + 1.7275 GB * 0.000392 kWh) * 1.2 (PUE) * 316 g/kWh
+		{name: "eu-west-1 1 vCPU 1 hour", args: args{"eu-west-1", 1769.0 / 1024}, want: 1.0606964625, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSLambda(tt.args.regionCode, tt.args.gbHours)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSLambda() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSLambda() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSFargate(t *testing.T) {
+	type args struct {
+		region    string
+		vcpuHours float64
+		gbHours   float64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero", args: args{"eu-west-1", 0, 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", 1, 2}, want: 0, wantErr: true},
+		// (1000 vCPU-h * 2.12 W / 1000 + 2000 GB-h * 0.000392 kWh) * 1.2 (PUE) * 316 g/kWh
+		{name: "eu-west-1 1 vCPU 2 GB 1000 hours", args: args{"eu-west-1", 1000, 2000}, want: 1101.1968, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSFargate(tt.args.region, tt.args.vcpuHours, tt.args.gbHours)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSFargate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSFargate() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package footprint
+
+import (
+	_ "embed"
+	"io"
+)
+
+// awsInstanceSpecsCSV lists the number of vCPUs and the memory of EC2
+// instance types missing from the Teads dataset, mostly newer generations.
+// Source: https://aws.amazon.com/ec2/instance-types/
+//
+//go:embed aws-instance-specs.csv
+var awsInstanceSpecsCSV string
+
+// memoryWattsPerGB is the power of one gigabyte of memory, in watt.
+const memoryWattsPerGB = memoryKiloWattHoursPerGBHour * 1000
+
+// InstanceSpec describes the resources of an EC2 instance type.
+type InstanceSpec struct {
+	VCPUs    float64
+	MemoryGB float64
+}
+
+// Columns of the AWS instance specs dataset.
+const (
+	specColumnInstanceType = "Instance type"
+	specColumnVCPUs        = "vCPUs"
+	specColumnMemory       = "Memory (in GB)"
+)
+
+func parseAWSInstanceSpecs(r io.Reader) (map[string]InstanceSpec, error) {
+	awsInstanceSpecs := make(map[string]InstanceSpec)
+	columns := []string{specColumnInstanceType, specColumnVCPUs, specColumnMemory}
+
+	err := readDataset(r, columns, func(c datasetColumns, record []string) error {
+		vcpus, err := c.float(record, specColumnVCPUs)
+		if err != nil {
+			return err
+		}
+		memory, err := c.float(record, specColumnMemory)
+		if err != nil {
+			return err
+		}
+
+		awsInstanceSpecs[c.value(record, specColumnInstanceType)] = InstanceSpec{
+			VCPUs:    vcpus,
+			MemoryGB: memory,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return awsInstanceSpecs, nil
+}
+
+// AWSInstanceSpec returns the specs of an EC2 instance type missing from the
+// Teads dataset.
+func (c *Calculator) AWSInstanceSpec(instanceType string) (InstanceSpec, error) {
+	val, exists := c.awsInstanceSpecs[instanceType]
+	if !exists {
+		return InstanceSpec{}, unknownInstanceType(instanceType)
+	}
+	return val, nil
+}
+
+// specInstance returns footprint data for an instance with the given specs,
+// using a generic power model: each vCPU consumes between vCPUMinWatts when
+// idle and vCPUMaxWatts at full load, and each gigabyte of memory
+// memoryWattsPerGB. Manufacturing emissions are the average per vCPU of the
+// dataset.
+func (c *Calculator) specInstance(spec InstanceSpec) EC2Instance {
+	powerAt := func(utilization float64) float64 {
+		cpu := vCPUMinWatts + utilization/100*(vCPUMaxWatts-vCPUMinWatts)
+		return spec.VCPUs*cpu + spec.MemoryGB*memoryWattsPerGB
+	}
+
+	return EC2Instance{
+		VCPUs:                        spec.VCPUs,
+		PowerAtIdle:                  powerAt(0),
+		PowerAt10Percent:             powerAt(10),
+		PowerAt50Percent:             powerAt(50),
+		PowerAt100Percent:            powerAt(100),
+		ManufacturingEmissionsHourly: c.ec2AveragePerVCPU.ManufacturingEmissionsHourly * spec.VCPUs,
+	}
+}
@@ -0,0 +1,66 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_parseAWSInstanceSpecs(t *testing.T) {
+	c := testCalculator(t)
+
+	for instanceType := range c.awsInstanceSpecs {
+		if _, exists := c.ec2Instances[instanceType]; exists {
+			t.Errorf("instance type %s is in the spec table and in the dataset", instanceType)
+		}
+	}
+}
+
+func TestAWSInstanceSpec(t *testing.T) {
+	tests := []struct {
+		instanceType string
+		want         InstanceSpec
+		wantErr      bool
+	}{
+		{instanceType: "m7i.large", want: InstanceSpec{VCPUs: 2, MemoryGB: 8}},
+		{instanceType: "c7g.metal", want: InstanceSpec{VCPUs: 64, MemoryGB: 128}},
+		{instanceType: "r8g.medium", want: InstanceSpec{VCPUs: 1, MemoryGB: 8}},
+		{instanceType: "m5.large", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.instanceType, func(t *testing.T) {
+			got, err := AWSInstanceSpec(tt.instanceType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AWSInstanceSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AWSInstanceSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_specInstance(t *testing.T) {
+	c := testCalculator(t)
+	got := c.specInstance(InstanceSpec{VCPUs: 2, MemoryGB: 8})
+
+	want := []struct {
+		name  string
+		got   float64
+		watts float64
+	}{
+		{"idle", got.PowerAtIdle, 4.616},
+		{"10%", got.PowerAt10Percent, 5.168},
+		{"50%", got.PowerAt50Percent, 7.376},
+		{"100%", got.PowerAt100Percent, 10.136},
+	}
+	for _, w := range want {
+		if math.Abs(w.got-w.watts) > 1e-9 {
+			t.Errorf("specInstance() power at %s = %v, want %v", w.name, w.got, w.watts)
+		}
+	}
+
+	if want := c.ec2AveragePerVCPU.ManufacturingEmissionsHourly * 2; math.Abs(got.ManufacturingEmissionsHourly-want) > 1e-9 {
+		t.Errorf("specInstance() manufacturing emissions = %v, want %v", got.ManufacturingEmissionsHourly, want)
+	}
+}
@@ -0,0 +1,120 @@
+package footprint
+
+import (
+	"fmt"
+)
+
+// Storage energy coefficients, in watt-hours per terabyte-hour of stored data.
+// These and the replication factors below follow the methodology of the
+// Cloud Carbon Footprint project:
+// https://www.cloudcarbonfootprint.org/docs/methodology/#storage
+const (
+	ssdWattHoursPerTBHour = 1.2
+	hddWattHoursPerTBHour = 0.65
+
+	// ebsReplicationFactor accounts for EBS volumes being replicated within
+	// their availability zone.
+	ebsReplicationFactor = 2
+
+	// s3ReplicationFactor accounts for S3 objects being stored redundantly
+	// across availability zones.
+	s3ReplicationFactor = 3
+)
+
+// StorageMedium is the kind of drive data is stored on.
+type StorageMedium string
+
+const (
+	SSD StorageMedium = "SSD"
+	HDD StorageMedium = "HDD"
+)
+
+// ebsVolumeTypes maps EBS volume types, as used in the EC2 API, to the
+// storage medium they are backed by.
+var ebsVolumeTypes = map[string]StorageMedium{
+	"gp2":      SSD,
+	"gp3":      SSD,
+	"io1":      SSD,
+	"io2":      SSD,
+	"st1":      HDD,
+	"sc1":      HDD,
+	"standard": HDD,
+}
+
+// EBSVolumeMedium returns the storage medium backing an EBS volume type.
+func EBSVolumeMedium(volumeType string) (StorageMedium, error) {
+	val, exists := ebsVolumeTypes[volumeType]
+	if !exists {
+		return "", fmt.Errorf("unknown EBS volume type")
+	} else {
+		return val, nil
+	}
+}
+
+// wattHoursPerTBHour returns the energy coefficient of a storage medium.
+func wattHoursPerTBHour(medium StorageMedium) float64 {
+	if medium == SSD {
+		return ssdWattHoursPerTBHour
+	}
+	return hddWattHoursPerTBHour
+}
+
+// AWSStorage returns the footprint of EBS volume storage in gram CO2 equivalents.
+// The amount of storage is given in gigabyte-hours, e.g. 720 for a
+// volume of 1 GB provisioned for 30 days.
+//
+// Manufacturing emissions of storage hardware are not accounted for.
+func (c *Calculator) AWSStorage(regionCode, volumeType string, gbHours float64) (Result, error) {
+	pue, err := c.PUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.WUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.CarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	medium, err := EBSVolumeMedium(volumeType)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return operationalResult(storageKiloWattHours(medium, ebsReplicationFactor, gbHours), pue, wue, ci), nil
+}
+
+// AWSObjectStorage returns the footprint of S3 object storage in gram CO2
+// equivalents. The amount of storage is given in gigabyte-hours.
+//
+// All storage classes are assumed to be backed by HDDs. Manufacturing
+// emissions of storage hardware are not accounted for.
+func (c *Calculator) AWSObjectStorage(regionCode string, gbHours float64) (Result, error) {
+	pue, err := c.PUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	wue, err := c.WUE(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ci, err := c.CarbonIntensity(regionCode)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return operationalResult(storageKiloWattHours(HDD, s3ReplicationFactor, gbHours), pue, wue, ci), nil
+}
+
+// storageKiloWattHours returns the energy needed to store data, including
+// replicas, in kilowatt-hours.
+func storageKiloWattHours(medium StorageMedium, replicationFactor, gbHours float64) float64 {
+	tbHours := gbHours / 1000.0
+	return tbHours * wattHoursPerTBHour(medium) * replicationFactor / 1000.0
+}
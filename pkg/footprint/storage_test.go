@@ -0,0 +1,102 @@
+package footprint
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEBSVolumeMedium(t *testing.T) {
+	tests := []struct {
+		name       string
+		volumeType string
+		want       StorageMedium
+		wantErr    bool
+	}{
+		{name: "gp3", volumeType: "gp3", want: SSD, wantErr: false},
+		{name: "io2", volumeType: "io2", want: SSD, wantErr: false},
+		{name: "st1", volumeType: "st1", want: HDD, wantErr: false},
+		{name: "standard", volumeType: "standard", want: HDD, wantErr: false},
+		{name: "unknown", volumeType: "unknown", want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EBSVolumeMedium(tt.volumeType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EBSVolumeMedium() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("EBSVolumeMedium() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSStorage(t *testing.T) {
+	type args struct {
+		regionCode string
+		volumeType string
+		gbHours    float64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero", args: args{"eu-west-1", "gp3", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", "gp3", 1000}, want: 0, wantErr: true},
+		{name: "unknown volume type", args: args{"eu-west-1", "unknown", 1000}, want: 0, wantErr: true},
+		// 1 TB for 1000 hours: 1.2 kWh * 2 (replication) * 1.2 (PUE) * 316 g/kWh
+		{name: "eu-west-1 gp3 1 TB 1000 hours", args: args{"eu-west-1", "gp3", 1000 * 1000}, want: 910.08, wantErr: false},
+		// 1 TB for 1000 hours: 0.65 kWh * 2 (replication) * 1.2 (PUE) * 338 g/kWh
+		{name: "eu-central-1 st1 1 TB 1000 hours", args: args{"eu-central-1", "st1", 1000 * 1000}, want: 527.28, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSStorage(tt.args.regionCode, tt.args.volumeType, tt.args.gbHours)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSStorage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSStorage() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSObjectStorage(t *testing.T) {
+	type args struct {
+		regionCode string
+		gbHours    float64
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{name: "zero", args: args{"eu-west-1", 0}, want: 0, wantErr: false},
+		{name: "unknown region", args: args{"unknown", 1000}, want: 0, wantErr: true},
+		// 1 TB for 1000 hours: 0.65 kWh * 3 (replication) * 1.2 (PUE) * 316 g/kWh
+		{name: "eu-west-1 1 TB 1000 hours", args: args{"eu-west-1", 1000 * 1000}, want: 739.44, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AWSObjectStorage(tt.args.regionCode, tt.args.gbHours)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AWSObjectStorage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if math.Abs(got.Total()-tt.want) > 1e-9 {
+				t.Errorf("AWSObjectStorage() = %v, want %v", got.Total(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package footprint
+
+// Water usage effectiveness (WUE) of the data centers of each provider, in
+// liters of water consumed on site per kilowatt hour of IT energy. The
+// providers only publish averages of all their data centers, so these apply
+// to all regions unless overridden with WithRegionWUE. Water consumed for
+// generating the electricity is not included.
+const (
+	// awsWUE is the average reported by AWS for 2023.
+	awsWUE = 0.18
+
+	// gcpWUE is a rough estimate, as Google doesn't publish a WUE. It
+	// relates the water consumption of Google's data centers to their
+	// electricity consumption, as reported for 2023.
+	gcpWUE = 1.0
+
+	// azureWUE is the average reported by Microsoft for its fiscal year
+	// 2024.
+	azureWUE = 0.30
+)
+
+// WUE returns the water usage effectiveness for an AWS region, in liters per
+// kilowatt hour of IT energy.
+func (c *Calculator) WUE(regionCode string) (float64, error) {
+	val, exists := c.awsRegion(regionCode)
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	}
+	return val.WUE, nil
+}
+
+// GCPWUE returns the water usage effectiveness for a GCP region, in liters
+// per kilowatt hour of IT energy.
+func (c *Calculator) GCPWUE(regionCode string) (float64, error) {
+	val, exists := c.gcpRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	}
+	return val.WUE, nil
+}
+
+// AzureWUE returns the water usage effectiveness for an Azure region, in
+// liters per kilowatt hour of IT energy.
+func (c *Calculator) AzureWUE(regionCode string) (float64, error) {
+	val, exists := c.azureRegions[regionCode]
+	if !exists {
+		return 0, unknownRegion(regionCode)
+	}
+	return val.WUE, nil
+}
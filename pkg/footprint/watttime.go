@@ -0,0 +1,254 @@
+package footprint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// WattTimeURL is the base URL of the WattTime API.
+	WattTimeURL = "https://api.watttime.org"
+
+	// wattTimeRange is the longest time range requested at once. The API
+	// returns data in 5 minute intervals for up to 32 days per request.
+	wattTimeRange = 30 * 24 * time.Hour
+
+	// wattTimeSignal is the signal requested, the marginal operating
+	// emissions rate (MOER) of CO2.
+	wattTimeSignal = "co2_moer"
+
+	// gramsPerPound converts the pounds per megawatt hour returned by the API
+	// into grams per kilowatt hour.
+	gramsPerPound = 453.59237
+)
+
+// WattTime is a CarbonIntensityProvider fetching the historical marginal
+// emissions rate from the WattTime API, averaged per hour. The marginal
+// emissions rate is the intensity of the power plants responding to a change
+// in demand, rather than the average of all plants of the grid. The WattTime
+// region of an AWS region is determined from the region's location.
+type WattTime struct {
+	// BaseURL is the URL of the API, WattTimeURL by default.
+	BaseURL string
+
+	// Username and Password are the credentials of the WattTime account.
+	Username string
+	Password string
+
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+
+	cache hourlyCache
+
+	mu sync.Mutex
+
+	// regions maps AWS region codes to WattTime regions.
+	regions map[string]string
+
+	tokenMu sync.Mutex
+
+	// token is the access token obtained by logging in.
+	token string
+}
+
+// NewWattTime returns a client for the WattTime API using the given
+// account credentials.
+func NewWattTime(username, password string) *WattTime {
+	return &WattTime{
+		BaseURL:  WattTimeURL,
+		Username: username,
+		Password: password,
+		Client:   http.DefaultClient,
+		cache:    hourlyCache{rangeLength: wattTimeRange},
+	}
+}
+
+// HourlyCarbonIntensity returns the average marginal emissions rate of the
+// grid of an AWS region during the hour containing t, in grams of CO2 per
+// kilowatt hour.
+func (w *WattTime) HourlyCarbonIntensity(ctx context.Context, regionCode string, t time.Time) (float64, error) {
+	region, err := w.region(ctx, regionCode)
+	if err != nil {
+		return 0, err
+	}
+
+	return w.cache.get(ctx, region, t, w.fetch)
+}
+
+// region returns the WattTime region of an AWS region.
+func (w *WattTime) region(ctx context.Context, regionCode string) (string, error) {
+	location, err := RegionLocation(regionCode)
+	if err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if region, exists := w.regions[regionCode]; exists {
+		return region, nil
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	query.Set("signal_type", wattTimeSignal)
+
+	var body struct {
+		Region string `json:"region"`
+	}
+	err = w.get(ctx, "/v3/region-from-loc", query, &body)
+	if err != nil {
+		return "", fmt.Errorf("could not determine WattTime region of %s: %w", regionCode, err)
+	}
+
+	if w.regions == nil {
+		w.regions = make(map[string]string)
+	}
+	w.regions[regionCode] = body.Region
+
+	return body.Region, nil
+}
+
+// fetch requests the marginal emissions rate of a region in a time range and
+// returns the hourly averages.
+func (w *WattTime) fetch(ctx context.Context, region string, start, end time.Time) (map[time.Time]float64, error) {
+	query := url.Values{}
+	query.Set("region", region)
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Format(time.RFC3339))
+	query.Set("signal_type", wattTimeSignal)
+
+	var body wattTimeData
+	err := w.get(ctx, "/v3/historical", query, &body)
+	if err != nil {
+		return nil, fmt.Errorf("could not request marginal emissions for region %s: %w", region, err)
+	}
+
+	return body.hourly(), nil
+}
+
+// CarbonIntensityForecast returns the forecast marginal emissions rate of
+// the grid of an AWS region, averaged per hour, for up to the given number
+// of hours.
+func (w *WattTime) CarbonIntensityForecast(ctx context.Context, regionCode string, hours int) ([]HourlyIntensity, error) {
+	region, err := w.region(ctx, regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("region", region)
+	query.Set("signal_type", wattTimeSignal)
+	query.Set("horizon_hours", strconv.Itoa(hours))
+
+	var body wattTimeData
+	err = w.get(ctx, "/v3/forecast", query, &body)
+	if err != nil {
+		return nil, fmt.Errorf("could not request marginal emissions forecast for region %s: %w", region, err)
+	}
+
+	forecast := sortedHours(body.hourly())
+	if len(forecast) > hours {
+		forecast = forecast[:hours]
+	}
+	return forecast, nil
+}
+
+// wattTimeData is the response of the historical and forecast endpoints of
+// the API, with values in 5 minute intervals.
+type wattTimeData struct {
+	Data []struct {
+		PointTime time.Time `json:"point_time"`
+		Value     float64   `json:"value"`
+	} `json:"data"`
+}
+
+// hourly returns the hourly averages of the data in grams per kilowatt
+// hour, keyed by the start of the hour.
+func (d wattTimeData) hourly() map[time.Time]float64 {
+	sums := make(map[time.Time]float64)
+	counts := make(map[time.Time]int)
+	for _, entry := range d.Data {
+		hour := entry.PointTime.UTC().Truncate(time.Hour)
+		sums[hour] += entry.Value
+		counts[hour]++
+	}
+
+	values := make(map[time.Time]float64)
+	for hour, sum := range sums {
+		// Pounds per megawatt hour to grams per kilowatt hour.
+		values[hour] = sum / float64(counts[hour]) * gramsPerPound / 1000
+	}
+	return values
+}
+
+// get sends an authenticated request to the API and decodes the JSON
+// response into v.
+func (w *WattTime) get(ctx context.Context, path string, query url.Values, v any) error {
+	token, err := w.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.BaseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// login returns an access token, logging in on first use.
+func (w *WattTime) login(ctx context.Context) (string, error) {
+	w.tokenMu.Lock()
+	defer w.tokenMu.Unlock()
+
+	if w.token != "" {
+		return w.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.BaseURL+"/login", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(w.Username, w.Password)
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not log in to WattTime: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not log in to WattTime: %s", resp.Status)
+	}
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", fmt.Errorf("could not decode WattTime login response: %w", err)
+	}
+
+	w.token = body.Token
+	return w.token, nil
+}
@@ -0,0 +1,130 @@
+package footprint
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWattTime_HourlyCarbonIntensity(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins++
+			fmt.Fprint(w, `{"token":"abc"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/region-from-loc":
+			fmt.Fprint(w, `{"region":"CAISO_NORTH","region_full_name":"California ISO Northern","signal_type":"co2_moer"}`)
+		case "/v3/historical":
+			if r.URL.Query().Get("region") != "CAISO_NORTH" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"data":[
+				{"point_time":"2022-08-01T00:00:00+00:00","value":900},
+				{"point_time":"2022-08-01T00:05:00+00:00","value":1000},
+				{"point_time":"2022-08-01T01:00:00+00:00","value":500}
+			],"meta":{"units":"lbs_co2_per_mwh"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	wt := NewWattTime("user", "secret")
+	wt.BaseURL = server.URL
+
+	tests := []struct {
+		name       string
+		regionCode string
+		t          time.Time
+		want       float64
+		wantErr    bool
+	}{
+		{name: "averaged hour", regionCode: "us-west-1", t: time.Date(2022, 8, 1, 0, 30, 0, 0, time.UTC), want: 950 * gramsPerPound / 1000},
+		{name: "single value", regionCode: "us-west-1", t: time.Date(2022, 8, 1, 1, 0, 0, 0, time.UTC), want: 500 * gramsPerPound / 1000},
+		{name: "missing hour", regionCode: "us-west-1", t: time.Date(2022, 8, 1, 2, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "unknown region", regionCode: "unknown", t: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wt.HourlyCarbonIntensity(context.Background(), tt.regionCode, tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HourlyCarbonIntensity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("HourlyCarbonIntensity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if logins != 1 {
+		t.Errorf("HourlyCarbonIntensity() logged in %d times, want 1", logins)
+	}
+}
+
+func TestWattTime_CarbonIntensityForecast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprint(w, `{"token":"abc"}`)
+		case "/v3/region-from-loc":
+			fmt.Fprint(w, `{"region":"CAISO_NORTH","region_full_name":"California ISO Northern","signal_type":"co2_moer"}`)
+		case "/v3/forecast":
+			if r.URL.Query().Get("region") != "CAISO_NORTH" || r.URL.Query().Get("horizon_hours") != "24" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"data":[
+				{"point_time":"2022-08-01T01:00:00+00:00","value":500},
+				{"point_time":"2022-08-01T00:00:00+00:00","value":900},
+				{"point_time":"2022-08-01T00:05:00+00:00","value":1000}
+			],"meta":{"units":"lbs_co2_per_mwh"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	wt := NewWattTime("user", "secret")
+	wt.BaseURL = server.URL
+
+	got, err := wt.CarbonIntensityForecast(context.Background(), "us-west-1", 24)
+	if err != nil {
+		t.Fatalf("CarbonIntensityForecast() error = %v", err)
+	}
+	if len(got) != 2 || !got[0].Hour.Equal(time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)) || math.Abs(got[0].CarbonIntensity-950*gramsPerPound/1000) > 1e-9 || math.Abs(got[1].CarbonIntensity-500*gramsPerPound/1000) > 1e-9 {
+		t.Errorf("CarbonIntensityForecast() = %v, want hourly averages in chronological order", got)
+	}
+}
+
+func TestWattTime_login(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	wt := NewWattTime("user", "wrong")
+	wt.BaseURL = server.URL
+
+	_, err := wt.HourlyCarbonIntensity(context.Background(), "us-west-1", time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC))
+	if err == nil {
+		t.Error("HourlyCarbonIntensity() error = nil, want error for failed login")
+	}
+}
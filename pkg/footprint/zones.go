@@ -0,0 +1,97 @@
+package footprint
+
+// awsZoneRegions maps the codes of AWS Local Zones and Wavelength Zones to
+// their parent region. Usage in these zones is reported with the zone code
+// as region, e.g. "us-east-1-bos-1", but the datasets only have data for
+// regions. The zones are small deployments in metro areas close to the
+// parent region, so its data is used for them. Zones are listed explicitly
+// rather than derived from the code, so that unknown codes are reported
+// instead of being attributed to a region by guess.
+var awsZoneRegions = map[string]string{
+	// Local Zones.
+	"af-south-1-los-1":     "af-south-1",
+	"ap-northeast-1-tpe-1": "ap-northeast-1",
+	"ap-south-1-ccu-1":     "ap-south-1",
+	"ap-south-1-del-1":     "ap-south-1",
+	"ap-southeast-1-bkk-1": "ap-southeast-1",
+	"ap-southeast-1-mnl-1": "ap-southeast-1",
+	"ap-southeast-2-akl-1": "ap-southeast-2",
+	"ap-southeast-2-per-1": "ap-southeast-2",
+	"eu-central-1-ham-1":   "eu-central-1",
+	"eu-central-1-waw-1":   "eu-central-1",
+	"eu-north-1-cph-1":     "eu-north-1",
+	"eu-north-1-hel-1":     "eu-north-1",
+	"me-south-1-mct-1":     "me-south-1",
+	"us-east-1-atl-1":      "us-east-1",
+	"us-east-1-atl-2":      "us-east-1",
+	"us-east-1-bos-1":      "us-east-1",
+	"us-east-1-bue-1":      "us-east-1",
+	"us-east-1-chi-1":      "us-east-1",
+	"us-east-1-chi-2":      "us-east-1",
+	"us-east-1-dfw-1":      "us-east-1",
+	"us-east-1-dfw-2":      "us-east-1",
+	"us-east-1-iah-1":      "us-east-1",
+	"us-east-1-iah-2":      "us-east-1",
+	"us-east-1-lim-1":      "us-east-1",
+	"us-east-1-mci-1":      "us-east-1",
+	"us-east-1-mia-1":      "us-east-1",
+	"us-east-1-mia-2":      "us-east-1",
+	"us-east-1-msp-1":      "us-east-1",
+	"us-east-1-nyc-1":      "us-east-1",
+	"us-east-1-nyc-2":      "us-east-1",
+	"us-east-1-phl-1":      "us-east-1",
+	"us-east-1-qro-1":      "us-east-1",
+	"us-east-1-scl-1":      "us-east-1",
+	"us-west-2-den-1":      "us-west-2",
+	"us-west-2-hnl-1":      "us-west-2",
+	"us-west-2-las-1":      "us-west-2",
+	"us-west-2-lax-1":      "us-west-2",
+	"us-west-2-lax-2":      "us-west-2",
+	"us-west-2-pdx-1":      "us-west-2",
+	"us-west-2-phx-1":      "us-west-2",
+	"us-west-2-phx-2":      "us-west-2",
+	"us-west-2-sea-1":      "us-west-2",
+
+	// Wavelength Zones.
+	"ap-northeast-1-wl1-kix-wlz-1": "ap-northeast-1",
+	"ap-northeast-1-wl1-nrt-wlz-1": "ap-northeast-1",
+	"ap-northeast-2-wl1-cjj-wlz-1": "ap-northeast-2",
+	"ap-northeast-2-wl1-sel-wlz-1": "ap-northeast-2",
+	"ca-central-1-wl1-yto-wlz-1":   "ca-central-1",
+	"eu-central-1-wl1-ber-wlz-1":   "eu-central-1",
+	"eu-central-1-wl1-dtm-wlz-1":   "eu-central-1",
+	"eu-central-1-wl1-muc-wlz-1":   "eu-central-1",
+	"eu-west-2-wl1-lon-wlz-1":      "eu-west-2",
+	"eu-west-2-wl1-man-wlz-1":      "eu-west-2",
+	"eu-west-2-wl2-man-wlz-1":      "eu-west-2",
+	"us-east-1-wl1-atl-wlz-1":      "us-east-1",
+	"us-east-1-wl1-bna-wlz-1":      "us-east-1",
+	"us-east-1-wl1-bos-wlz-1":      "us-east-1",
+	"us-east-1-wl1-chi-wlz-1":      "us-east-1",
+	"us-east-1-wl1-clt-wlz-1":      "us-east-1",
+	"us-east-1-wl1-dfw-wlz-1":      "us-east-1",
+	"us-east-1-wl1-dtw-wlz-1":      "us-east-1",
+	"us-east-1-wl1-iah-wlz-1":      "us-east-1",
+	"us-east-1-wl1-mci-wlz-1":      "us-east-1",
+	"us-east-1-wl1-mia-wlz-1":      "us-east-1",
+	"us-east-1-wl1-msp-wlz-1":      "us-east-1",
+	"us-east-1-wl1-nyc-wlz-1":      "us-east-1",
+	"us-east-1-wl1-tpa-wlz-1":      "us-east-1",
+	"us-east-1-wl1-was-wlz-1":      "us-east-1",
+	"us-west-2-wl1-den-wlz-1":      "us-west-2",
+	"us-west-2-wl1-las-wlz-1":      "us-west-2",
+	"us-west-2-wl1-lax-wlz-1":      "us-west-2",
+	"us-west-2-wl1-phx-wlz-1":      "us-west-2",
+	"us-west-2-wl1-sea-wlz-1":      "us-west-2",
+	"us-west-2-wl1-sfo-wlz-1":      "us-west-2",
+}
+
+// ParentRegion returns the code of the region an AWS Local Zone or
+// Wavelength Zone belongs to, e.g. "us-east-1" for "us-east-1-bos-1". Other
+// codes are returned unchanged.
+func ParentRegion(regionCode string) string {
+	if parent, exists := awsZoneRegions[regionCode]; exists {
+		return parent
+	}
+	return regionCode
+}
@@ -0,0 +1,54 @@
+package footprint
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParentRegion(t *testing.T) {
+	tests := []struct {
+		regionCode string
+		want       string
+	}{
+		{regionCode: "us-east-1-bos-1", want: "us-east-1"},
+		{regionCode: "us-west-2-lax-1", want: "us-west-2"},
+		{regionCode: "eu-west-2-wl1-lon-wlz-1", want: "eu-west-2"},
+		{regionCode: "eu-west-1", want: "eu-west-1"},
+		{regionCode: "us-east-1-xyz-1", want: "us-east-1-xyz-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.regionCode, func(t *testing.T) {
+			if got := ParentRegion(tt.regionCode); got != tt.want {
+				t.Errorf("ParentRegion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_awsZoneRegionsComplete(t *testing.T) {
+	c := testCalculator(t)
+	for zone, parent := range awsZoneRegions {
+		if _, exists := c.awsRegions[parent]; !exists {
+			t.Errorf("zone %s has parent region %s missing from the dataset", zone, parent)
+		}
+	}
+}
+
+func TestCalculator_zone(t *testing.T) {
+	c := testCalculator(t)
+
+	ci, err := c.CarbonIntensity("us-east-1-bos-1")
+	if err != nil {
+		t.Fatalf("CarbonIntensity() error = %v", err)
+	}
+	if want, _ := c.CarbonIntensity("us-east-1"); ci != want {
+		t.Errorf("CarbonIntensity() = %v, want %v of the parent region", ci, want)
+	}
+	if _, err := c.AWS("eu-central-1-wl1-ber-wlz-1", "t3.medium", 0); err != nil {
+		t.Errorf("AWS() in Wavelength Zone error = %v", err)
+	}
+	if _, err := c.PUE("us-east-1-xyz-1"); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("PUE() of unknown zone error = %v, want ErrUnknownRegion", err)
+	}
+}
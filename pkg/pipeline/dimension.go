@@ -0,0 +1,47 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// Dimension is a property of line items that usage can be grouped by.
+type Dimension struct {
+	// Name identifies the dimension, e.g. in the --group-by flag of the
+	// analyse command.
+	Name string
+
+	// Header is the column header used in output.
+	Header string
+
+	// Value returns the dimension's value for a line item.
+	Value func(item cur.LineItem) string
+
+	// Redacted is set for dimensions whose values may reveal the internal
+	// structure of an organization, e.g. because they are derived from tags.
+	// Their values are replaced with hashes by the --redact flag of the
+	// analyse command.
+	Redacted bool
+}
+
+var dimensions = registry[Dimension]{kind: "dimension"}
+
+// RegisterDimension makes a dimension available for grouping. It panics if
+// a dimension with the same name is already registered.
+func RegisterDimension(d Dimension) {
+	if d.Value == nil {
+		panic(fmt.Sprintf("pipeline: dimension %q has no value function", d.Name))
+	}
+	dimensions.register(d.Name, d)
+}
+
+// LookupDimension returns the registered dimension with the given name.
+func LookupDimension(name string) (Dimension, bool) {
+	return dimensions.lookup(name)
+}
+
+// DimensionNames returns the names of the registered dimensions, sorted.
+func DimensionNames() []string {
+	return dimensions.names()
+}
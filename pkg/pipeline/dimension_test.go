@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+func TestRegisterDimension(t *testing.T) {
+	RegisterDimension(Dimension{
+		Name:   "test-payer",
+		Header: "Payer",
+		Value:  func(item cur.LineItem) string { return item.PayerAccountID },
+	})
+
+	d, exists := LookupDimension("test-payer")
+	if !exists {
+		t.Fatal("LookupDimension() did not find registered dimension")
+	}
+	if got := d.Value(cur.LineItem{PayerAccountID: "111111111111"}); got != "111111111111" {
+		t.Errorf("dimension value = %q, want 111111111111", got)
+	}
+	if !contains(DimensionNames(), "test-payer") {
+		t.Errorf("DimensionNames() = %v, want test-payer included", DimensionNames())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterDimension() did not panic without value function")
+		}
+	}()
+	RegisterDimension(Dimension{Name: "test-nil"})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// Usage is the usage of line items with the same category, region, instance
+// type and storage type, summed up.
+type Usage struct {
+	Category     string
+	Region       string
+	InstanceType string
+	StorageType  string
+	MultiAZ      bool
+	Duration     time.Duration
+	GBHours      float64
+	VCPUHours    float64
+	TransferGB   float64
+}
+
+// Emitter estimates the footprint of the usage of a category.
+type Emitter interface {
+	Estimate(c *footprint.Calculator, usage Usage) (footprint.Result, error)
+}
+
+// EmitterFunc is an Emitter implemented by a function.
+type EmitterFunc func(c *footprint.Calculator, usage Usage) (footprint.Result, error)
+
+// Estimate calls f.
+func (f EmitterFunc) Estimate(c *footprint.Calculator, usage Usage) (footprint.Result, error) {
+	return f(c, usage)
+}
+
+var emitters = registry[Emitter]{kind: "emitter"}
+
+// RegisterEmitter makes an emitter estimate the footprint of the usage of a
+// category. It panics if an emitter for the category is already registered.
+func RegisterEmitter(category string, e Emitter) {
+	if e == nil {
+		panic("pipeline: emitter for category " + category + " is nil")
+	}
+	emitters.register(category, e)
+}
+
+// LookupEmitter returns the emitter registered for a category.
+func LookupEmitter(category string) (Emitter, bool) {
+	return emitters.lookup(category)
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+func TestRegisterEmitter(t *testing.T) {
+	RegisterEmitter("TestService", EmitterFunc(func(c *footprint.Calculator, usage Usage) (footprint.Result, error) {
+		return footprint.Result{EnergyKiloWattHours: usage.VCPUHours * 0.01}, nil
+	}))
+
+	e, exists := LookupEmitter("TestService")
+	if !exists {
+		t.Fatal("LookupEmitter() did not find registered emitter")
+	}
+	got, err := e.Estimate(nil, Usage{Category: "TestService", VCPUHours: 100})
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	if got.EnergyKiloWattHours != 1 {
+		t.Errorf("Estimate() energy = %v, want 1", got.EnergyKiloWattHours)
+	}
+	if _, exists := LookupEmitter("Unknown"); exists {
+		t.Error("LookupEmitter() found unregistered category")
+	}
+}
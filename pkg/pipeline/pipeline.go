@@ -0,0 +1,65 @@
+// Package pipeline defines the stages of analysing usage reports: readers
+// provide line items, filters select them, aggregators sum them up, and
+// writers output the footprint of the result.
+//
+// The analyse command can be extended without modifying it, by registering
+// extensions in the init function of a package imported by the program:
+//
+//   - RegisterDimension adds a dimension usage can be grouped by.
+//   - RegisterEmitter estimates the footprint of a new usage category. The
+//     line items of the category are read by a service registered with
+//     cur.RegisterService.
+//   - RegisterWriter adds an output format.
+//
+// Built-in dimensions, categories and output formats of the analyse command
+// take precedence over registered extensions with the same name.
+package pipeline
+
+import (
+	"io"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// Reader provides the line items of usage reports, like cur.Reader. At the
+// end of the input, Next returns io.EOF.
+type Reader interface {
+	Next() (cur.LineItem, error)
+}
+
+// Filter returns whether a line item is included in an analysis.
+type Filter func(item cur.LineItem) bool
+
+// Aggregator sums up the usage of line items, e.g. grouped by dimensions.
+type Aggregator interface {
+	Add(item cur.LineItem)
+}
+
+// Run reads all line items from r and adds those included by all filters to
+// each of the aggregators.
+func Run(r Reader, filters []Filter, aggregators ...Aggregator) error {
+	for {
+		item, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if included(item, filters) {
+			for _, a := range aggregators {
+				a.Add(item)
+			}
+		}
+	}
+}
+
+// included returns whether a line item passes all filters.
+func included(item cur.LineItem, filters []Filter) bool {
+	for _, f := range filters {
+		if !f(item) {
+			return false
+		}
+	}
+	return true
+}
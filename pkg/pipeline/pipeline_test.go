@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/cur"
+)
+
+// sliceReader provides the line items of a slice, followed by err or
+// io.EOF.
+type sliceReader struct {
+	items []cur.LineItem
+	err   error
+}
+
+func (r *sliceReader) Next() (cur.LineItem, error) {
+	if len(r.items) == 0 {
+		if r.err != nil {
+			return cur.LineItem{}, r.err
+		}
+		return cur.LineItem{}, io.EOF
+	}
+	item := r.items[0]
+	r.items = r.items[1:]
+	return item, nil
+}
+
+// hoursByRegion sums up the usage time per region.
+type hoursByRegion map[string]float64
+
+func (a hoursByRegion) Add(item cur.LineItem) {
+	a[item.Region] += item.Duration.Hours()
+}
+
+func TestRun(t *testing.T) {
+	items := []cur.LineItem{
+		{Category: cur.CategoryEC2, Region: "eu-west-1", Duration: time.Hour},
+		{Category: cur.CategoryEC2, Region: "eu-central-1", Duration: 2 * time.Hour},
+		{Category: cur.CategoryEBS, Region: "eu-west-1", GBHours: 100},
+		{Category: cur.CategoryEC2, Region: "eu-west-1", Duration: time.Hour},
+	}
+	ec2Only := func(item cur.LineItem) bool { return item.Category == cur.CategoryEC2 }
+
+	a, b := hoursByRegion{}, hoursByRegion{}
+	if err := Run(&sliceReader{items: items}, []Filter{ec2Only}, a, b); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := hoursByRegion{"eu-west-1": 2, "eu-central-1": 2}
+	for region, hours := range want {
+		if a[region] != hours || b[region] != hours {
+			t.Errorf("Run() aggregated %v and %v hours in %s, want %v", a[region], b[region], region, hours)
+		}
+	}
+	if len(a) != len(want) {
+		t.Errorf("Run() aggregated %v, want %v", a, want)
+	}
+}
+
+func TestRun_error(t *testing.T) {
+	readErr := errors.New("broken report")
+	a := hoursByRegion{}
+
+	err := Run(&sliceReader{items: []cur.LineItem{{Region: "eu-west-1", Duration: time.Hour}}, err: readErr}, nil, a)
+	if !errors.Is(err, readErr) {
+		t.Errorf("Run() error = %v, want %v", err, readErr)
+	}
+	if a["eu-west-1"] != 1 {
+		t.Errorf("Run() aggregated %v before the error, want 1 hour", a)
+	}
+}
@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// registry holds extensions of one kind by name. Registries are safe for
+// concurrent use.
+type registry[T any] struct {
+	// kind names the extensions in panics, e.g. "dimension".
+	kind string
+
+	mu      sync.RWMutex
+	entries map[string]T
+}
+
+// register adds an extension under the given name. It panics if the name is
+// empty or already taken, as that is a programming error.
+func (r *registry[T]) register(name string, value T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name == "" {
+		panic(fmt.Sprintf("pipeline: %s name is empty", r.kind))
+	}
+	if _, exists := r.entries[name]; exists {
+		panic(fmt.Sprintf("pipeline: %s %q registered twice", r.kind, name))
+	}
+	if r.entries == nil {
+		r.entries = make(map[string]T)
+	}
+	r.entries[name] = value
+}
+
+// lookup returns the extension with the given name, if registered.
+func (r *registry[T]) lookup(name string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, exists := r.entries[name]
+	return value, exists
+}
+
+// names returns the names of all extensions, sorted.
+func (r *registry[T]) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
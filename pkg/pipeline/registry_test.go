@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_registry(t *testing.T) {
+	r := registry[int]{kind: "number"}
+	r.register("two", 2)
+	r.register("one", 1)
+
+	if got, exists := r.lookup("two"); !exists || got != 2 {
+		t.Errorf("lookup(two) = %v, %v, want 2, true", got, exists)
+	}
+	if _, exists := r.lookup("three"); exists {
+		t.Error("lookup(three) found unregistered name")
+	}
+	if got, want := r.names(), []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names() = %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"one", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("register(%q) did not panic", name)
+				}
+			}()
+			r.register(name, 0)
+		}()
+	}
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"io"
+	"time"
+
+	"github.com/giantswarm/cloud-carbon/pkg/footprint"
+)
+
+// Result is the result of an analysis.
+type Result struct {
+	// Dimensions are the names of the dimensions rows are grouped by.
+	Dimensions []string
+
+	Rows  []Row
+	Total footprint.Result
+
+	// Partial is set if some reports could not be read.
+	Partial bool
+}
+
+// Row is the footprint of the usage in one group.
+type Row struct {
+	// Labels holds the values of the dimensions, in the order of
+	// Result.Dimensions.
+	Labels []string
+
+	// Period is the start of the period of the usage, if the result is
+	// split into periods.
+	Period time.Time
+
+	// Cost is the cost of the usage in the billing currency, if known.
+	Cost float64
+
+	Footprint footprint.Result
+}
+
+// Writer writes the result of an analysis in an output format.
+type Writer interface {
+	Write(w io.Writer, result Result) error
+}
+
+// WriterFunc is a Writer implemented by a function.
+type WriterFunc func(w io.Writer, result Result) error
+
+// Write calls f.
+func (f WriterFunc) Write(w io.Writer, result Result) error {
+	return f(w, result)
+}
+
+var writers = registry[Writer]{kind: "writer"}
+
+// RegisterWriter makes a writer available as output format. It panics if a
+// writer for the format is already registered.
+func RegisterWriter(format string, w Writer) {
+	if w == nil {
+		panic("pipeline: writer for format " + format + " is nil")
+	}
+	writers.register(format, w)
+}
+
+// LookupWriter returns the writer registered for an output format.
+func LookupWriter(format string) (Writer, bool) {
+	return writers.lookup(format)
+}
+
+// WriterFormats returns the output formats of the registered writers,
+// sorted.
+func WriterFormats() []string {
+	return writers.names()
+}
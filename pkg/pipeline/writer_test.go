@@ -0,0 +1,32 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestRegisterWriter(t *testing.T) {
+	RegisterWriter("test-total", WriterFunc(func(w io.Writer, result Result) error {
+		_, err := fmt.Fprintf(w, "%d rows, %.0f g", len(result.Rows), result.Total.Total())
+		return err
+	}))
+
+	writer, exists := LookupWriter("test-total")
+	if !exists {
+		t.Fatal("LookupWriter() did not find registered writer")
+	}
+	var b bytes.Buffer
+	result := Result{Rows: make([]Row, 2)}
+	result.Total.OperationalGrams = 42
+	if err := writer.Write(&b, result); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got, want := b.String(), "2 rows, 42 g"; got != want {
+		t.Errorf("Write() wrote %q, want %q", got, want)
+	}
+	if !contains(WriterFormats(), "test-total") {
+		t.Errorf("WriterFormats() = %v, want test-total included", WriterFormats())
+	}
+}
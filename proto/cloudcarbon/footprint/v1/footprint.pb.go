@@ -0,0 +1,522 @@
+// The footprint service of cloud-carbon grpc-server, estimating the
+// emissions of cloud usage with the datasets of cloud-carbon, so that
+// services get consistent estimates without embedding the datasets.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: cloudcarbon/footprint/v1/footprint.proto
+
+package footprintv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EstimateInstanceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cloud provider of the instances, one of: aws, gcp, azure. Defaults to
+	// aws.
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Region   string `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// Instance type, machine type or VM size. With provider aws, RDS instance
+	// types like db.m5.large are supported, too.
+	InstanceType string `protobuf:"bytes,3,opt,name=instance_type,json=instanceType,proto3" json:"instance_type,omitempty"`
+	// Number of instances, 1 if unset.
+	Count int32 `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// Duration the instances run for, in hours.
+	DurationHours float64 `protobuf:"fixed64,5,opt,name=duration_hours,json=durationHours,proto3" json:"duration_hours,omitempty"`
+	// Average CPU utilization of the instances in percent, 50 if unset.
+	Utilization *float64 `protobuf:"fixed64,6,opt,name=utilization,proto3,oneof" json:"utilization,omitempty"`
+	// How to estimate EC2 instance types missing from the dataset, one of:
+	// none, family, spec, vcpu. Defaults to family.
+	InstanceFallback string `protobuf:"bytes,7,opt,name=instance_fallback,json=instanceFallback,proto3" json:"instance_fallback,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *EstimateInstanceRequest) Reset() {
+	*x = EstimateInstanceRequest{}
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateInstanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateInstanceRequest) ProtoMessage() {}
+
+func (x *EstimateInstanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateInstanceRequest.ProtoReflect.Descriptor instead.
+func (*EstimateInstanceRequest) Descriptor() ([]byte, []int) {
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EstimateInstanceRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *EstimateInstanceRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *EstimateInstanceRequest) GetInstanceType() string {
+	if x != nil {
+		return x.InstanceType
+	}
+	return ""
+}
+
+func (x *EstimateInstanceRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *EstimateInstanceRequest) GetDurationHours() float64 {
+	if x != nil {
+		return x.DurationHours
+	}
+	return 0
+}
+
+func (x *EstimateInstanceRequest) GetUtilization() float64 {
+	if x != nil && x.Utilization != nil {
+		return *x.Utilization
+	}
+	return 0
+}
+
+func (x *EstimateInstanceRequest) GetInstanceFallback() string {
+	if x != nil {
+		return x.InstanceFallback
+	}
+	return ""
+}
+
+type EstimateEnergyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cloud provider of the region, one of: aws, gcp, azure. Defaults to aws.
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Region   string `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// IT energy consumed by servers, in kilowatt hours.
+	EnergyKwh     float64 `protobuf:"fixed64,3,opt,name=energy_kwh,json=energyKwh,proto3" json:"energy_kwh,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EstimateEnergyRequest) Reset() {
+	*x = EstimateEnergyRequest{}
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateEnergyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateEnergyRequest) ProtoMessage() {}
+
+func (x *EstimateEnergyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateEnergyRequest.ProtoReflect.Descriptor instead.
+func (*EstimateEnergyRequest) Descriptor() ([]byte, []int) {
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EstimateEnergyRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *EstimateEnergyRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *EstimateEnergyRequest) GetEnergyKwh() float64 {
+	if x != nil {
+		return x.EnergyKwh
+	}
+	return 0
+}
+
+type GetRegionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cloud provider of the region, one of: aws, gcp, azure. Defaults to aws.
+	Provider      string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Region        string `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegionRequest) Reset() {
+	*x = GetRegionRequest{}
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegionRequest) ProtoMessage() {}
+
+func (x *GetRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegionRequest.ProtoReflect.Descriptor instead.
+func (*GetRegionRequest) Descriptor() ([]byte, []int) {
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRegionRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GetRegionRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+type Footprint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Energy consumed including the data center overhead, in kilowatt hours.
+	EnergyKwh        float64 `protobuf:"fixed64,1,opt,name=energy_kwh,json=energyKwh,proto3" json:"energy_kwh,omitempty"`
+	OperationalGrams float64 `protobuf:"fixed64,2,opt,name=operational_grams,json=operationalGrams,proto3" json:"operational_grams,omitempty"`
+	EmbodiedGrams    float64 `protobuf:"fixed64,3,opt,name=embodied_grams,json=embodiedGrams,proto3" json:"embodied_grams,omitempty"`
+	// Total emissions, the sum of operational and embodied emissions, in gram
+	// CO2 equivalents.
+	EmissionGrams float64 `protobuf:"fixed64,4,opt,name=emission_grams,json=emissionGrams,proto3" json:"emission_grams,omitempty"`
+	WaterLiters   float64 `protobuf:"fixed64,5,opt,name=water_liters,json=waterLiters,proto3" json:"water_liters,omitempty"`
+	// Describes how the footprint of an instance type missing from the
+	// dataset was estimated.
+	EstimatedFrom string `protobuf:"bytes,6,opt,name=estimated_from,json=estimatedFrom,proto3" json:"estimated_from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Footprint) Reset() {
+	*x = Footprint{}
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Footprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Footprint) ProtoMessage() {}
+
+func (x *Footprint) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Footprint.ProtoReflect.Descriptor instead.
+func (*Footprint) Descriptor() ([]byte, []int) {
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Footprint) GetEnergyKwh() float64 {
+	if x != nil {
+		return x.EnergyKwh
+	}
+	return 0
+}
+
+func (x *Footprint) GetOperationalGrams() float64 {
+	if x != nil {
+		return x.OperationalGrams
+	}
+	return 0
+}
+
+func (x *Footprint) GetEmbodiedGrams() float64 {
+	if x != nil {
+		return x.EmbodiedGrams
+	}
+	return 0
+}
+
+func (x *Footprint) GetEmissionGrams() float64 {
+	if x != nil {
+		return x.EmissionGrams
+	}
+	return 0
+}
+
+func (x *Footprint) GetWaterLiters() float64 {
+	if x != nil {
+		return x.WaterLiters
+	}
+	return 0
+}
+
+func (x *Footprint) GetEstimatedFrom() string {
+	if x != nil {
+		return x.EstimatedFrom
+	}
+	return ""
+}
+
+type Region struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Provider string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Region   string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// Carbon intensity of the grid, in gram CO2 equivalents per kilowatt hour.
+	CarbonIntensity float64 `protobuf:"fixed64,3,opt,name=carbon_intensity,json=carbonIntensity,proto3" json:"carbon_intensity,omitempty"`
+	// Market-based carbon intensity, only set for AWS regions. Zero for
+	// regions matched with renewable energy purchases.
+	MarketCarbonIntensity *float64 `protobuf:"fixed64,4,opt,name=market_carbon_intensity,json=marketCarbonIntensity,proto3,oneof" json:"market_carbon_intensity,omitempty"`
+	Pue                   float64  `protobuf:"fixed64,5,opt,name=pue,proto3" json:"pue,omitempty"`
+	// Water usage effectiveness, in liters per kilowatt hour of IT energy.
+	Wue           float64 `protobuf:"fixed64,6,opt,name=wue,proto3" json:"wue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Region) Reset() {
+	*x = Region{}
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Region) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Region) ProtoMessage() {}
+
+func (x *Region) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Region.ProtoReflect.Descriptor instead.
+func (*Region) Descriptor() ([]byte, []int) {
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Region) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Region) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Region) GetCarbonIntensity() float64 {
+	if x != nil {
+		return x.CarbonIntensity
+	}
+	return 0
+}
+
+func (x *Region) GetMarketCarbonIntensity() float64 {
+	if x != nil && x.MarketCarbonIntensity != nil {
+		return *x.MarketCarbonIntensity
+	}
+	return 0
+}
+
+func (x *Region) GetPue() float64 {
+	if x != nil {
+		return x.Pue
+	}
+	return 0
+}
+
+func (x *Region) GetWue() float64 {
+	if x != nil {
+		return x.Wue
+	}
+	return 0
+}
+
+var File_cloudcarbon_footprint_v1_footprint_proto protoreflect.FileDescriptor
+
+const file_cloudcarbon_footprint_v1_footprint_proto_rawDesc = "" +
+	"\n" +
+	"(cloudcarbon/footprint/v1/footprint.proto\x12\x18cloudcarbon.footprint.v1\"\x93\x02\n" +
+	"\x17EstimateInstanceRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12#\n" +
+	"\rinstance_type\x18\x03 \x01(\tR\finstanceType\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05count\x12%\n" +
+	"\x0eduration_hours\x18\x05 \x01(\x01R\rdurationHours\x12%\n" +
+	"\vutilization\x18\x06 \x01(\x01H\x00R\vutilization\x88\x01\x01\x12+\n" +
+	"\x11instance_fallback\x18\a \x01(\tR\x10instanceFallbackB\x0e\n" +
+	"\f_utilization\"j\n" +
+	"\x15EstimateEnergyRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x1d\n" +
+	"\n" +
+	"energy_kwh\x18\x03 \x01(\x01R\tenergyKwh\"F\n" +
+	"\x10GetRegionRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\"\xef\x01\n" +
+	"\tFootprint\x12\x1d\n" +
+	"\n" +
+	"energy_kwh\x18\x01 \x01(\x01R\tenergyKwh\x12+\n" +
+	"\x11operational_grams\x18\x02 \x01(\x01R\x10operationalGrams\x12%\n" +
+	"\x0eembodied_grams\x18\x03 \x01(\x01R\rembodiedGrams\x12%\n" +
+	"\x0eemission_grams\x18\x04 \x01(\x01R\remissionGrams\x12!\n" +
+	"\fwater_liters\x18\x05 \x01(\x01R\vwaterLiters\x12%\n" +
+	"\x0eestimated_from\x18\x06 \x01(\tR\restimatedFrom\"\xe4\x01\n" +
+	"\x06Region\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12)\n" +
+	"\x10carbon_intensity\x18\x03 \x01(\x01R\x0fcarbonIntensity\x12;\n" +
+	"\x17market_carbon_intensity\x18\x04 \x01(\x01H\x00R\x15marketCarbonIntensity\x88\x01\x01\x12\x10\n" +
+	"\x03pue\x18\x05 \x01(\x01R\x03pue\x12\x10\n" +
+	"\x03wue\x18\x06 \x01(\x01R\x03wueB\x1a\n" +
+	"\x18_market_carbon_intensity2\xc1\x02\n" +
+	"\x10FootprintService\x12j\n" +
+	"\x10EstimateInstance\x121.cloudcarbon.footprint.v1.EstimateInstanceRequest\x1a#.cloudcarbon.footprint.v1.Footprint\x12f\n" +
+	"\x0eEstimateEnergy\x12/.cloudcarbon.footprint.v1.EstimateEnergyRequest\x1a#.cloudcarbon.footprint.v1.Footprint\x12Y\n" +
+	"\tGetRegion\x12*.cloudcarbon.footprint.v1.GetRegionRequest\x1a .cloudcarbon.footprint.v1.RegionBOZMgithub.com/giantswarm/cloud-carbon/proto/cloudcarbon/footprint/v1;footprintv1b\x06proto3"
+
+var (
+	file_cloudcarbon_footprint_v1_footprint_proto_rawDescOnce sync.Once
+	file_cloudcarbon_footprint_v1_footprint_proto_rawDescData []byte
+)
+
+func file_cloudcarbon_footprint_v1_footprint_proto_rawDescGZIP() []byte {
+	file_cloudcarbon_footprint_v1_footprint_proto_rawDescOnce.Do(func() {
+		file_cloudcarbon_footprint_v1_footprint_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cloudcarbon_footprint_v1_footprint_proto_rawDesc), len(file_cloudcarbon_footprint_v1_footprint_proto_rawDesc)))
+	})
+	return file_cloudcarbon_footprint_v1_footprint_proto_rawDescData
+}
+
+var file_cloudcarbon_footprint_v1_footprint_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_cloudcarbon_footprint_v1_footprint_proto_goTypes = []any{
+	(*EstimateInstanceRequest)(nil), // 0: cloudcarbon.footprint.v1.EstimateInstanceRequest
+	(*EstimateEnergyRequest)(nil),   // 1: cloudcarbon.footprint.v1.EstimateEnergyRequest
+	(*GetRegionRequest)(nil),        // 2: cloudcarbon.footprint.v1.GetRegionRequest
+	(*Footprint)(nil),               // 3: cloudcarbon.footprint.v1.Footprint
+	(*Region)(nil),                  // 4: cloudcarbon.footprint.v1.Region
+}
+var file_cloudcarbon_footprint_v1_footprint_proto_depIdxs = []int32{
+	0, // 0: cloudcarbon.footprint.v1.FootprintService.EstimateInstance:input_type -> cloudcarbon.footprint.v1.EstimateInstanceRequest
+	1, // 1: cloudcarbon.footprint.v1.FootprintService.EstimateEnergy:input_type -> cloudcarbon.footprint.v1.EstimateEnergyRequest
+	2, // 2: cloudcarbon.footprint.v1.FootprintService.GetRegion:input_type -> cloudcarbon.footprint.v1.GetRegionRequest
+	3, // 3: cloudcarbon.footprint.v1.FootprintService.EstimateInstance:output_type -> cloudcarbon.footprint.v1.Footprint
+	3, // 4: cloudcarbon.footprint.v1.FootprintService.EstimateEnergy:output_type -> cloudcarbon.footprint.v1.Footprint
+	4, // 5: cloudcarbon.footprint.v1.FootprintService.GetRegion:output_type -> cloudcarbon.footprint.v1.Region
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_cloudcarbon_footprint_v1_footprint_proto_init() }
+func file_cloudcarbon_footprint_v1_footprint_proto_init() {
+	if File_cloudcarbon_footprint_v1_footprint_proto != nil {
+		return
+	}
+	file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[0].OneofWrappers = []any{}
+	file_cloudcarbon_footprint_v1_footprint_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cloudcarbon_footprint_v1_footprint_proto_rawDesc), len(file_cloudcarbon_footprint_v1_footprint_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cloudcarbon_footprint_v1_footprint_proto_goTypes,
+		DependencyIndexes: file_cloudcarbon_footprint_v1_footprint_proto_depIdxs,
+		MessageInfos:      file_cloudcarbon_footprint_v1_footprint_proto_msgTypes,
+	}.Build()
+	File_cloudcarbon_footprint_v1_footprint_proto = out.File
+	file_cloudcarbon_footprint_v1_footprint_proto_goTypes = nil
+	file_cloudcarbon_footprint_v1_footprint_proto_depIdxs = nil
+}
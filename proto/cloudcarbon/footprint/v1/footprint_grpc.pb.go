@@ -0,0 +1,213 @@
+// The footprint service of cloud-carbon grpc-server, estimating the
+// emissions of cloud usage with the datasets of cloud-carbon, so that
+// services get consistent estimates without embedding the datasets.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: cloudcarbon/footprint/v1/footprint.proto
+
+package footprintv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FootprintService_EstimateInstance_FullMethodName = "/cloudcarbon.footprint.v1.FootprintService/EstimateInstance"
+	FootprintService_EstimateEnergy_FullMethodName   = "/cloudcarbon.footprint.v1.FootprintService/EstimateEnergy"
+	FootprintService_GetRegion_FullMethodName        = "/cloudcarbon.footprint.v1.FootprintService/GetRegion"
+)
+
+// FootprintServiceClient is the client API for FootprintService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FootprintServiceClient interface {
+	// EstimateInstance returns the footprint of running instances, like the
+	// estimate command. Fails with NOT_FOUND for unknown regions and instance
+	// types.
+	EstimateInstance(ctx context.Context, in *EstimateInstanceRequest, opts ...grpc.CallOption) (*Footprint, error)
+	// EstimateEnergy returns the footprint of consuming IT energy in a region,
+	// accounting for the PUE and WUE of its data centers.
+	EstimateEnergy(ctx context.Context, in *EstimateEnergyRequest, opts ...grpc.CallOption) (*Footprint, error)
+	// GetRegion returns the carbon intensity, PUE and WUE of a region.
+	GetRegion(ctx context.Context, in *GetRegionRequest, opts ...grpc.CallOption) (*Region, error)
+}
+
+type footprintServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFootprintServiceClient(cc grpc.ClientConnInterface) FootprintServiceClient {
+	return &footprintServiceClient{cc}
+}
+
+func (c *footprintServiceClient) EstimateInstance(ctx context.Context, in *EstimateInstanceRequest, opts ...grpc.CallOption) (*Footprint, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Footprint)
+	err := c.cc.Invoke(ctx, FootprintService_EstimateInstance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *footprintServiceClient) EstimateEnergy(ctx context.Context, in *EstimateEnergyRequest, opts ...grpc.CallOption) (*Footprint, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Footprint)
+	err := c.cc.Invoke(ctx, FootprintService_EstimateEnergy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *footprintServiceClient) GetRegion(ctx context.Context, in *GetRegionRequest, opts ...grpc.CallOption) (*Region, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Region)
+	err := c.cc.Invoke(ctx, FootprintService_GetRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FootprintServiceServer is the server API for FootprintService service.
+// All implementations must embed UnimplementedFootprintServiceServer
+// for forward compatibility.
+type FootprintServiceServer interface {
+	// EstimateInstance returns the footprint of running instances, like the
+	// estimate command. Fails with NOT_FOUND for unknown regions and instance
+	// types.
+	EstimateInstance(context.Context, *EstimateInstanceRequest) (*Footprint, error)
+	// EstimateEnergy returns the footprint of consuming IT energy in a region,
+	// accounting for the PUE and WUE of its data centers.
+	EstimateEnergy(context.Context, *EstimateEnergyRequest) (*Footprint, error)
+	// GetRegion returns the carbon intensity, PUE and WUE of a region.
+	GetRegion(context.Context, *GetRegionRequest) (*Region, error)
+	mustEmbedUnimplementedFootprintServiceServer()
+}
+
+// UnimplementedFootprintServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFootprintServiceServer struct{}
+
+func (UnimplementedFootprintServiceServer) EstimateInstance(context.Context, *EstimateInstanceRequest) (*Footprint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateInstance not implemented")
+}
+func (UnimplementedFootprintServiceServer) EstimateEnergy(context.Context, *EstimateEnergyRequest) (*Footprint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateEnergy not implemented")
+}
+func (UnimplementedFootprintServiceServer) GetRegion(context.Context, *GetRegionRequest) (*Region, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegion not implemented")
+}
+func (UnimplementedFootprintServiceServer) mustEmbedUnimplementedFootprintServiceServer() {}
+func (UnimplementedFootprintServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeFootprintServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FootprintServiceServer will
+// result in compilation errors.
+type UnsafeFootprintServiceServer interface {
+	mustEmbedUnimplementedFootprintServiceServer()
+}
+
+func RegisterFootprintServiceServer(s grpc.ServiceRegistrar, srv FootprintServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFootprintServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FootprintService_ServiceDesc, srv)
+}
+
+func _FootprintService_EstimateInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FootprintServiceServer).EstimateInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FootprintService_EstimateInstance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FootprintServiceServer).EstimateInstance(ctx, req.(*EstimateInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FootprintService_EstimateEnergy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateEnergyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FootprintServiceServer).EstimateEnergy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FootprintService_EstimateEnergy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FootprintServiceServer).EstimateEnergy(ctx, req.(*EstimateEnergyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FootprintService_GetRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FootprintServiceServer).GetRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FootprintService_GetRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FootprintServiceServer).GetRegion(ctx, req.(*GetRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FootprintService_ServiceDesc is the grpc.ServiceDesc for FootprintService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FootprintService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cloudcarbon.footprint.v1.FootprintService",
+	HandlerType: (*FootprintServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EstimateInstance",
+			Handler:    _FootprintService_EstimateInstance_Handler,
+		},
+		{
+			MethodName: "EstimateEnergy",
+			Handler:    _FootprintService_EstimateEnergy_Handler,
+		},
+		{
+			MethodName: "GetRegion",
+			Handler:    _FootprintService_GetRegion_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cloudcarbon/footprint/v1/footprint.proto",
+}